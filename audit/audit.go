@@ -0,0 +1,148 @@
+// Package audit Пакет журнала действий: каждый запуск программы, её аргументы и прочитанные и записанные файлы
+// дописываются в конец журнала, что позволяет проверить происхождение отчётов о посещаемости
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек журнала действий
+type Configuration struct {
+	//Вести ли журнал действий
+	Enabled bool
+	//Путь до файла журнала
+	Path string
+}
+
+// Entry Структура записи журнала действий. Записи одного запуска программы объединяются идентификатором процесса
+type Entry struct {
+	//Время записи
+	Time string `json:"time"`
+	//Пользователь, запустивший программу
+	User string `json:"user"`
+	//Компьютер, на котором запущена программа
+	Host string `json:"host"`
+	//Идентификатор процесса
+	PID int `json:"pid"`
+	//Событие: start - запуск, read - чтение файла, write - запись файла, finalize - закрытие собраний, force -
+	// изменение закрытого собрания, finish - завершение
+	Event string `json:"event"`
+	//Аргументы командной строки (для события start)
+	Arguments []string `json:"arguments,omitempty"`
+	//Прочитанный или записанный файл (для событий read и write)
+	File string `json:"file,omitempty"`
+	//Закрытые собрания или изменённое закрытое собрание (для событий finalize и force)
+	Meeting string `json:"meeting,omitempty"`
+}
+
+// Log Структура открытого журнала действий. Методы нулевого журнала (журнал выключен) ничего не делают
+type Log struct {
+	mutex sync.Mutex
+	file  *os.File
+	user  string
+	host  string
+	pid   int
+}
+
+/*====================================================================================================================*/
+
+// Open Функция, открывающая журнал действий на дозапись и записывающая в него запуск программы с аргументами
+// командной строки. Если журнал выключен, возвращается нулевой журнал
+func Open(settings Configuration, arguments []string) (*Log, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(settings.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия журнала действий: %w", err)
+	}
+
+	journal := &Log{file: file, pid: os.Getpid()}
+	if current, err := user.Current(); err == nil {
+		journal.user = current.Username
+	}
+	journal.host, _ = os.Hostname()
+
+	if err := journal.write(Entry{Event: "start", Arguments: arguments}); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return journal, nil
+}
+
+// Read Функция, записывающая в журнал чтение файла
+func (journal *Log) Read(path string) error {
+	return journal.write(Entry{Event: "read", File: path})
+}
+
+// Write Функция, записывающая в журнал запись файла
+func (journal *Log) Write(path string) error {
+	return journal.write(Entry{Event: "write", File: path})
+}
+
+// Finalize Функция, записывающая в журнал закрытие собраний
+func (journal *Log) Finalize(meetings string) error {
+	return journal.write(Entry{Event: "finalize", Meeting: meetings})
+}
+
+// Force Функция, записывающая в журнал изменение закрытого собрания с флагом --force
+func (journal *Log) Force(meeting string) error {
+	return journal.write(Entry{Event: "force", Meeting: meeting})
+}
+
+// Enabled Функция, проверяющая, ведётся ли журнал действий
+func (journal *Log) Enabled() bool {
+	return journal != nil
+}
+
+// Close Функция, записывающая в журнал завершение программы и закрывающая журнал. Запуск без записи о завершении
+// означает, что программа завершилась с ошибкой
+func (journal *Log) Close() error {
+	if journal == nil {
+		return nil
+	}
+
+	if err := journal.write(Entry{Event: "finish"}); err != nil {
+		journal.file.Close()
+		return err
+	}
+
+	if err := journal.file.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия журнала действий: %w", err)
+	}
+
+	return nil
+}
+
+// write Вспомогательная функция, дописывающая запись в конец журнала одной строкой в формате JSON
+func (journal *Log) write(entry Entry) error {
+	if journal == nil {
+		return nil
+	}
+
+	entry.Time = time.Now().Format(time.RFC3339)
+	entry.User, entry.Host, entry.PID = journal.user, journal.host, journal.pid
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования записи журнала действий: %w", err)
+	}
+
+	journal.mutex.Lock()
+	defer journal.mutex.Unlock()
+
+	if _, err := journal.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("ошибка записи в журнал действий: %w", err)
+	}
+
+	return nil
+}
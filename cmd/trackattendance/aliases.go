@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/audit"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/roster"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+/*====================================================================================================================*/
+
+// RunAliases Функция команды aliases learn, находящая в истории посещаемости гостей, которые на нескольких собраниях
+// сопоставлялись по похожему ФИО всегда с одним и тем же студентом базы, и после подтверждения дописывающая их в файл
+// псевдонимов. Такие гости в следующих отчётах получают ФИО из базы сразу, без сопоставления по похожему ФИО
+func RunAliases(ctx context.Context, arguments []string, configuration config.Configuration, base roster.Base,
+	journal *audit.Log) error {
+	if len(arguments) == 0 || arguments[0] != "learn" {
+		return fmt.Errorf("неизвестная команда, используйте: aliases learn [--min-meetings 3] [--yes]")
+	}
+
+	//Флаги команды: наименьшее количество собраний сопоставления и подтверждение без вопроса
+	flags := flag.NewFlagSet("aliases learn", flag.ContinueOnError)
+	minMeetings := flags.Int("min-meetings", 3, "наименьшее количество собраний, на которых гость сопоставлен с одним студентом")
+	yes := flags.Bool("yes", false, "дописать найденные псевдонимы без подтверждения")
+	if err := flags.Parse(arguments[1:]); err != nil {
+		return err
+	}
+	if *minMeetings < 1 {
+		return fmt.Errorf("количество собраний --min-meetings должно быть не меньше 1")
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего искать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	matches, err := store.NameMatches(ctx)
+	if err != nil {
+		return err
+	}
+
+	//Гость становится псевдонимом, только если он всегда сопоставлялся с одним студентом, который есть в базе, на
+	// достаточном количестве собраний и ещё не указан в файле псевдонимов
+	students := make(map[string]map[string]int)
+	for _, match := range matches {
+		if students[match.Name] == nil {
+			students[match.Name] = make(map[string]int)
+		}
+		students[match.Name][match.FullName] += match.Meetings
+	}
+	var candidates []history.NameMatch
+	for _, match := range matches {
+		if len(students[match.Name]) != 1 || match.Meetings < *minMeetings {
+			continue
+		}
+		if _, ok := base[match.FullName]; !ok {
+			continue
+		}
+		if _, ok := roster.ResolveName(match.Name); ok {
+			continue
+		}
+		candidates = append(candidates, match)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Println(i18n.T("Новых псевдонимов не найдено"))
+		return nil
+	}
+
+	//Выводим найденные псевдонимы для проверки
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "%v\t%v\t%v\n", i18n.T("Имя в MS Teams"), i18n.T("ФИО в базе групп"), i18n.T("Собраний"))
+	for _, candidate := range candidates {
+		fmt.Fprintf(writer, "%v\t%v\t%d\n", candidate.Name, candidate.FullName, candidate.Meetings)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	//Псевдонимы дописываются только после подтверждения
+	if !*yes {
+		fmt.Printf(i18n.T("Дописать псевдонимы (%d) в файл %v? [y/N]: "), len(candidates), configuration.NameAliasesPath)
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("ошибка чтения ответа: %w", err)
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" && answer != "д" &&
+			answer != "да" {
+			fmt.Println(i18n.T("Псевдонимы не записаны"))
+			return nil
+		}
+	}
+
+	aliases := make([][2]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		aliases = append(aliases, [2]string{candidate.Name, candidate.FullName})
+	}
+	if err := roster.AppendNameAliases(configuration.NameAliasesPath, aliases); err != nil {
+		return err
+	}
+	if err := journal.Write(configuration.NameAliasesPath); err != nil {
+		return err
+	}
+	fmt.Printf(i18n.T("Псевдонимы (%d) дописаны в файл %v\n"), len(aliases), configuration.NameAliasesPath)
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"mod.go/audit"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/pipeline"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/teamsreport"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// xlsxContentType Тип содержимого книги Excel, по которому отчёт, переданный телом запроса, сохраняется файлом .xlsx
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// reportAPI Структура сервера приёма отчётов MS Teams: общие данные обработки и ограничения запросов. Отчёты
+// обрабатываются по одному, так как общие данные обработки, история посещаемости и журналы общие для всех запросов
+type reportAPI struct {
+	mutex   sync.Mutex
+	runtime *pipeline.Runtime
+	store   *history.Store
+	journal *audit.Log
+	//Ключ доступа, передаваемый в заголовке Authorization: Bearer (пустой - запросы принимаются без ключа)
+	token string
+	//Наибольший размер запроса в байтах
+	maxSize int64
+}
+
+/*====================================================================================================================*/
+
+// RunAPI Функция команды api, запускающая HTTP сервер, через который портал факультета отправляет отчёты MS Teams на
+// обработку вместо установки программы у каждого преподавателя. Сервер работает до прерывания программы:
+//
+//	POST /reports?format=json|csv - обработка отчёта, переданного телом запроса или файлами report формы
+//	  multipart/form-data (несколько файлов - отчёты одного собрания, объединяемые в один)
+//
+// Отчёт обрабатывается так же, как при запуске программы: итоговые отчёты сохраняются в папку отчётов, собрание
+// записывается в историю, оповещения отправляются. В ответе возвращается итоговый отчёт в формате format (по-умолчанию
+// json). Коды ответа: 409 - отчёт уже сформирован или собрание закрыто, 422 - технический созвон, 413 - отчёт
+// превышает ограничения
+func RunAPI(ctx context.Context, arguments []string, configuration config.Configuration, base roster.Base,
+	store *history.Store, journal *audit.Log) error {
+	//Флаги команды: адрес, порт, заменяющий порт адреса, ключ доступа и наибольший размер отчёта
+	flags := flag.NewFlagSet("api", flag.ContinueOnError)
+	address := flags.String("address", "127.0.0.1:8081", "адрес, на котором принимаются запросы")
+	port := flags.Int("port", 0, "порт, заменяющий порт адреса")
+	token := flags.String("token", os.Getenv("TRACKATTENDANCE_API_TOKEN"), "ключ доступа, который передаётся в "+
+		"заголовке Authorization: Bearer (по-умолчанию - переменная окружения TRACKATTENDANCE_API_TOKEN)")
+	maxSize := flags.Int64("max-size", 32, "наибольший размер запроса в мегабайтах")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if *port != 0 {
+		host, _, err := net.SplitHostPort(*address)
+		if err != nil {
+			return fmt.Errorf("некорректный адрес сервера %v: %w", *address, err)
+		}
+		*address = net.JoinHostPort(host, strconv.Itoa(*port))
+	}
+
+	//Итоговые отчёты возвращаются из папки отчётов, поэтому вывод в стандартный вывод не поддерживается
+	if configuration.ReportLocationPath == "-" {
+		return fmt.Errorf("команда api сохраняет отчёты в папку отчётов, вывод в стандартный вывод (--output -) " +
+			"не поддерживается")
+	}
+	if configuration.DryRun {
+		return fmt.Errorf("команда api не поддерживает пробный запуск (--dry-run)")
+	}
+
+	//Общие данные обработки считываются один раз при запуске сервера, поэтому после изменения файлов, указанных
+	// флагами, сервер перезапускается. Оба формата ответа формируются вместе с форматами из конфигураций, чтобы любой
+	// запрос обрабатывался с одними и теми же общими данными
+	for _, format := range []string{report.FormatJSON, report.FormatCSV} {
+		if !slices.Contains(configuration.Formats, format) {
+			configuration.Formats = append(slices.Clone(configuration.Formats), format)
+		}
+	}
+	runtime, err := pipeline.NewRuntime(configuration, base)
+	if err != nil {
+		return err
+	}
+
+	api := &reportAPI{runtime: runtime, store: store, journal: journal, token: *token, maxSize: *maxSize << 20}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/reports", api.submit)
+	server := &http.Server{Addr: *address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	//Останавливаем сервер при прерывании программы
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	if *token == "" {
+		slog.Warn(i18n.T("Ключ доступа не задан, отчёты принимаются от любого клиента"), "address", *address)
+	}
+	slog.Info(i18n.T("Отчёты MS Teams принимаются"), "address", "http://"+*address+"/reports")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("ошибка работы сервера: %w", err)
+	}
+
+	return nil
+}
+
+// submit Функция, обрабатывающая запрос POST /reports: сохраняет присланные отчёты во временный каталог, обрабатывает
+// их и возвращает итоговый отчёт собрания
+func (api *reportAPI) submit(w http.ResponseWriter, r *http.Request) {
+	//Отчёты принимаются только методом POST
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, i18n.T("отчёт передаётся методом POST"), http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorized(r, api.token) {
+		http.Error(w, i18n.T("неверный ключ доступа"), http.StatusUnauthorized)
+		return
+	}
+
+	//Формат ответа проверяется до чтения отчёта, чтобы не обрабатывать отчёт, который нельзя вернуть
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		format = report.FormatJSON
+	case report.FormatJSON, report.FormatCSV:
+	default:
+		http.Error(w, i18n.Sprintf("неизвестный формат ответа: %v (допустимы json, csv)", format),
+			http.StatusBadRequest)
+		return
+	}
+
+	//Присланные отчёты сохраняются во временный каталог, который удаляется после ответа
+	directory, err := os.MkdirTemp("", "trackattendance-api-")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(directory)
+
+	//Размер запроса ограничивается при чтении, так как заголовок Content-Length может отсутствовать
+	r.Body = http.MaxBytesReader(w, r.Body, api.maxSize)
+	paths, err := saveUploads(r, directory)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	//Отчёты обрабатываются по одному: пути итоговых отчётов в общих данных обработки перезаписываются каждым отчётом
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	runtime := api.runtime
+	err = runtime.Process(r.Context(), paths, api.store, api.journal)
+	switch {
+	case errors.Is(err, pipeline.ErrReportExists), errors.Is(err, pipeline.ErrFinalized):
+		http.Error(w, i18n.T(err.Error()), http.StatusConflict)
+		return
+	case errors.Is(err, pipeline.ErrTechnicalCall):
+		http.Error(w, i18n.T(err.Error()), http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, teamsreport.ErrLimitExceeded):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		slog.Error(i18n.T("Ошибка обработки отчёта"), "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	//Сводка оповещений отправляется сразу, так как сервер не знает, когда придёт следующий отчёт
+	if err := runtime.FlushNotifications(r.Context()); err != nil {
+		slog.Warn(i18n.T("Ошибка отправки общей сводки оповещений"), "error", err)
+	}
+
+	//Итоговый отчёт выбранного формата возвращается из папки отчётов
+	path := runtime.Reports[format]
+	file, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	slog.Info(i18n.T("Отчёт обработан"), "report", path, "remote", r.RemoteAddr)
+	if format == report.FormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+	if _, err := io.Copy(w, file); err != nil {
+		slog.Warn(i18n.T("Ошибка отправки отчёта"), "error", err)
+	}
+}
+
+/*====================================================================================================================*/
+
+// saveUploads Вспомогательная функция, сохраняющая в каталог directory отчёты MS Teams из запроса: файлы report формы
+// multipart/form-data или тело запроса. Расширение файла (.csv или .xlsx) сохраняется, так как по нему определяется
+// вид отчёта: для файлов формы оно берётся из названия файла, для тела запроса - из заголовка Content-Type
+func saveUploads(r *http.Request, directory string) ([]string, error) {
+	//Отчёт, переданный телом запроса, сохраняется одним файлом, вид которого определяется по заголовку Content-Type
+	contentType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "multipart/form-data") {
+		extension := ".csv"
+		if strings.HasPrefix(contentType, xlsxContentType) {
+			extension = ".xlsx"
+		}
+		path := filepath.Join(directory, "report"+extension)
+		return []string{path}, saveUpload(r.Body, path)
+	}
+
+	//Файлы формы больше 8 МБ временно сохраняются на диск и удаляются после сохранения отчётов
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		return nil, fmt.Errorf("%v: %w", i18n.T("некорректная форма запроса"), err)
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["report"]
+	if len(files) == 0 {
+		return nil, errors.New(i18n.T("в форме запроса нет файлов report"))
+	}
+	paths := make([]string, 0, len(files))
+	for i, header := range files {
+		//Файлы нумеруются по порядку в форме, так как названия файлов клиента могут совпадать или быть небезопасными
+		extension := strings.ToLower(filepath.Ext(header.Filename))
+		if extension != ".xlsx" {
+			extension = ".csv"
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(directory, fmt.Sprintf("report%d%v", i+1, extension))
+		err = saveUpload(file, path)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// saveUpload Вспомогательная функция, записывающая присланный отчёт в файл
+func saveUpload(source io.Reader, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	//При ошибке чтения запроса (например, превышении его размера) файл закрывается и возвращается ошибка чтения
+	if _, err := io.Copy(file, source); err != nil {
+		file.Close()
+		return err
+	}
+
+	//Ошибка закрытия файла означает, что отчёт мог быть записан не полностью
+	return file.Close()
+}
+
+// authorized Вспомогательная функция, проверяющая ключ доступа из заголовка Authorization: Bearer (пустой ключ -
+// запросы принимаются без ключа). Ключ сравнивается за постоянное время, чтобы его нельзя было подобрать по времени
+// ответа
+func authorized(r *http.Request, token string) bool {
+	return token == "" ||
+		subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"mod.go/graph"
+	"mod.go/i18n"
+	"mod.go/schedule"
+	"sync"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// calendarMatch Структура результата поиска события календаря организатора для собрания
+type calendarMatch struct {
+	event schedule.Event
+	found bool
+}
+
+/*====================================================================================================================*/
+
+// CalendarLookup Функция, возвращающая поиск событий календаря организатора в Microsoft Graph для сопоставления
+// собраний с запланированными парами. Токен доступа запрашивается при первом поиске, результат поиска запоминается по
+// времени начала собрания. Ошибка Microsoft Graph записывается в журнал, после чего календарь больше не запрашивается и
+// пары определяются по расписанию
+func CalendarLookup(ctx context.Context, settings graph.Configuration) func(time.Time) (schedule.Event, bool) {
+	var mutex sync.Mutex
+	token, failed := "", false
+	matches := make(map[int64]calendarMatch)
+
+	return func(start time.Time) (schedule.Event, bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if match, ok := matches[start.Unix()]; ok {
+			return match.event, match.found
+		}
+		if failed {
+			return schedule.Event{}, false
+		}
+
+		if token == "" {
+			requested, err := graph.RequestToken(ctx, settings)
+			if err != nil {
+				slog.Warn(i18n.T("Ошибка поиска события календаря организатора"), "error", err)
+				failed = true
+				return schedule.Event{}, false
+			}
+			token = requested
+		}
+
+		event, found, err := graph.FindEvent(ctx, settings, token, start)
+		if err != nil {
+			slog.Warn(i18n.T("Ошибка поиска события календаря организатора"), "error", err)
+			failed = true
+			return schedule.Event{}, false
+		}
+
+		//Плановое время события переводится в часовой пояс времени начала собрания
+		event.Start, event.End = event.Start.In(start.Location()), event.End.In(start.Location())
+		matches[start.Unix()] = calendarMatch{event, found}
+		if found {
+			slog.Debug(i18n.T("Собрание сопоставлено с событием календаря"), "subject", event.Subject,
+				"start", event.Start.Format("02.01.2006 15:04"), "end", event.End.Format("15:04"))
+		}
+
+		return event, found
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"mod.go/config"
+	"mod.go/graph"
+	"mod.go/report"
+	"mod.go/schedule"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunConfig Функция команды config show, выводящая файл конфигураций как есть или, с флагом --effective, итоговые
+// конфигурации после применения значений по-умолчанию и флагов командной строки вместе с таблицей расписания пар
+func RunConfig(arguments []string, configPath string, configuration config.Configuration) error {
+	if len(arguments) == 0 || arguments[0] != "show" {
+		return fmt.Errorf("неизвестная команда, используйте: config show [--effective]")
+	}
+
+	//Флаг команды: вывод итоговых конфигураций вместо содержимого файла
+	flags := flag.NewFlagSet("config show", flag.ContinueOnError)
+	effective := flags.Bool("effective", false, "вывести итоговые конфигурации и таблицу расписания пар")
+	if err := flags.Parse(arguments[1:]); err != nil {
+		return err
+	}
+
+	//Без флага выводим файл конфигураций без изменений
+	if !*effective {
+		file, err := os.Open(configPath)
+		if err != nil {
+			return fmt.Errorf("ошибка открытия файла конфигураций: %w", err)
+		}
+		defer file.Close()
+
+		_, err = io.Copy(os.Stdout, file)
+		return err
+	}
+
+	return WriteEffectiveConfig(os.Stdout, configPath, configuration)
+}
+
+// configSection Секция итоговых конфигураций: название и пары ключ - значение в порядке вывода
+type configSection struct {
+	name string
+	keys [][2]string
+}
+
+// WriteEffectiveConfig Функция, выводящая итоговые конфигурации в виде секций .ini файла. Секреты (секрет приложения
+// Microsoft Graph, пароли и токены) скрываются, указывается только их наличие
+func WriteEffectiveConfig(out io.Writer, configPath string, configuration config.Configuration) error {
+	lessons := configuration.Schedule
+	graphSettings := configuration.Graph
+
+	//Период загрузки собраний выводится, только если он задан
+	var dateFrom, dateTo string
+	if !graphSettings.DateFrom.IsZero() {
+		dateFrom, dateTo = graphSettings.DateFrom.Format("02.01.2006"), graphSettings.DateTo.Format("02.01.2006")
+	}
+
+	//Расписание пар в виде, в котором оно указывается в файле конфигураций
+	lateThresholds := make([]string, 0, len(lessons.Lessons))
+	names := make([]string, 0, len(lessons.Lessons))
+	for _, lesson := range lessons.Lessons {
+		lateThresholds = append(lateThresholds, strconv.Itoa(lesson.LateThreshold/60))
+		names = append(names, lesson.Label)
+	}
+	if strings.Join(names, "") == "" {
+		names = nil
+	}
+	//Незаданные часовые пояса выводятся пустыми
+	timeZone, reportTimeZone := "", ""
+	if lessons.TimeZone != nil {
+		timeZone = lessons.TimeZone.String()
+	}
+	if lessons.ReportTimeZone != nil {
+		reportTimeZone = lessons.ReportTimeZone.String()
+	}
+	//Списки пар отдельных дней недели выводятся для всех дней с понедельника по воскресенье
+	scheduleKeys := [][2]string{{"lessons", formatBounds(lessons.Lessons)}}
+	for _, weekday := range weekdayOrder {
+		scheduleKeys = append(scheduleKeys, [2]string{config.WeekdayKeys[weekday],
+			formatBounds(lessons.Weekdays[weekday])})
+	}
+	scheduleKeys = append(scheduleKeys, [][2]string{
+		{"lesson_names", strings.Join(names, ",")},
+		{"tolerance_before", fmt.Sprint(lessons.ToleranceBefore / 60)},
+		{"tolerance_after", fmt.Sprint(lessons.ToleranceAfter / 60)},
+		{"late_after_minutes", strings.Join(lateThresholds, ",")},
+		{"grace_minutes", fmt.Sprint(lessons.GracePeriod / 60)},
+		{"early_exit_threshold", fmt.Sprint(lessons.EarlyExitThreshold / 60)},
+		{"presence_share", fmt.Sprint(lessons.PresenceShare)},
+		{"technical_call_threshold", fmt.Sprint(lessons.TechnicalCallThreshold / 60)},
+		{"skip_technical_calls", fmt.Sprint(configuration.SkipTechnicalCalls)},
+		{"clock_drift_tolerance", fmt.Sprint(lessons.ClockDriftTolerance / 60)},
+		{"timezone", timeZone},
+		{"report_timezone", reportTimeZone},
+	}...)
+
+	//Шаблоны групп выводятся в том виде, в котором они указываются в файле конфигураций
+	patterns := make([]string, 0, len(configuration.GroupPatterns))
+	for _, pattern := range configuration.GroupPatterns {
+		patterns = append(patterns, strings.TrimSuffix(strings.TrimPrefix(pattern.String(), "(?i)^(?:"), ")"))
+	}
+	aliases := make([]string, 0, len(configuration.GroupAliases))
+	for old, current := range configuration.GroupAliases {
+		aliases = append(aliases, old+"="+current)
+	}
+	sort.Strings(aliases)
+	columns := configuration.Columns
+	notifySettings := configuration.Notify
+
+	sections := []configSection{
+		{"paths", [][2]string{
+			{"download_folder_path", configuration.DownloadFolderPath},
+			{"report_location_folder", configuration.ReportLocationPath},
+			{"curators_path", configuration.CuratorsPath},
+			{"groups_base", configuration.GroupsBaseSource},
+			{"exemptions_path", configuration.ExemptionsPath},
+			{"excuses_path", configuration.ExcusesPath},
+			{"goals_path", configuration.GoalsPath},
+			{"staff_path", configuration.StaffPath},
+			{"name_aliases_path", configuration.NameAliasesPath},
+			{"sent_notifications_path", configuration.SentNotificationsPath},
+		}},
+		{"schedule", scheduleKeys},
+		{"report", [][2]string{
+			{"formats", strings.Join(configuration.Formats, ",")},
+			{"template_path", configuration.TemplatePath},
+			{"xlsx_password", maskSecret(configuration.XLSXPassword)},
+			{"xlsx_password_command", configuration.XLSXPasswordCommand},
+			{"platform_stats", fmt.Sprint(configuration.PlatformStats)},
+			{"badge", fmt.Sprint(configuration.Badge)},
+			{"staff_block", fmt.Sprint(configuration.StaffBlock)},
+			{"totals_block", fmt.Sprint(configuration.TotalsBlock)},
+			{"merge_recreated", fmt.Sprint(configuration.MergeRecreated)},
+			{"lecturer", configuration.Lecturer},
+			{"profile", configuration.Profile},
+			{"guest_policy", configuration.GuestPolicy},
+			{"guest_match_distance", fmt.Sprint(configuration.GuestMatchDistance)},
+			{"id_salt", maskSecret(configuration.IDSalt)},
+			{"only_present", fmt.Sprint(configuration.OnlyPresent)},
+			{"only", strings.Join(configuration.Only, ",")},
+			{"strict_parsing", fmt.Sprint(configuration.StrictParsing)},
+			{"staff_roles", strings.Join(configuration.StaffRoles, ", ")},
+			{"existing", configuration.ExistingReports},
+			{"quorum_share", fmt.Sprint(configuration.QuorumShare)},
+			{"quorum_time_share", fmt.Sprint(configuration.QuorumTimeShare)},
+			{"exam_tolerance", fmt.Sprint(configuration.ExamTolerance / 60)},
+			{"language", configuration.Language},
+		}},
+		{"columns", [][2]string{
+			{"group", columns.Group},
+			{"full_name", columns.FullName},
+			{"record_book", columns.RecordBook},
+			{"presence", columns.Presence},
+			{"delay", columns.Delay},
+			{"early_exit", columns.EarlyExit},
+			{"participation", columns.Participation},
+			{"booking", columns.Booking},
+		}},
+		{"groups", [][2]string{
+			{"patterns", strings.Join(patterns, " ")},
+			{"aliases", strings.Join(aliases, ",")},
+			{"min_size", fmt.Sprint(configuration.MinGroupSize)},
+			{"allowed", strings.Join(configuration.AllowedGroups, ",")},
+		}},
+	}
+	fmt.Fprintf(out, "; Итоговые конфигурации, файл: %v\n\n", configPath)
+	writeConfigSections(out, sections)
+
+	//Дисциплины выводятся строками "шаблон = дисциплина | группы | преподаватель" без пустых полей в конце
+	fmt.Fprintln(out, "[courses]")
+	for _, course := range configuration.Courses {
+		line := fmt.Sprintf("%v = %v | %v | %v", strings.TrimPrefix(course.Pattern.String(), "(?i)"), course.Name,
+			strings.Join(course.Groups, ", "), course.Teacher)
+		fmt.Fprintln(out, strings.TrimRight(line, " |"))
+	}
+	fmt.Fprintln(out)
+
+	writeConfigSections(out, []configSection{
+		{"graph", [][2]string{
+			{"enabled", fmt.Sprint(graphSettings.Enabled)},
+			{"auth_flow", graphSettings.AuthFlow},
+			{"tenant_id", graphSettings.TenantID},
+			{"client_id", graphSettings.ClientID},
+			{"client_secret", maskSecret(graphSettings.ClientSecret)},
+			{"user_id", graphSettings.UserID},
+			{"meeting_id", graphSettings.MeetingID},
+			{"date_from", dateFrom},
+			{"date_to", dateTo},
+			{"endpoint", graph.Endpoint},
+			{"login_endpoint", graph.LoginEndpoint},
+			{"calendar_check", fmt.Sprint(graphSettings.CalendarCheck)},
+		}},
+		{"notify", [][2]string{
+			{"webhook_url", notifySettings.WebhookURL},
+			{"twilio_account_sid", notifySettings.TwilioAccountSID},
+			{"twilio_auth_token", maskSecret(notifySettings.TwilioAuthToken)},
+			{"twilio_from", notifySettings.TwilioFrom},
+			{"telegram_bot_token", maskSecret(notifySettings.TelegramBotToken)},
+			{"telegram_chat_id", notifySettings.TelegramChatID},
+			{"telegram_attach_report", fmt.Sprint(notifySettings.TelegramAttachReport)},
+			{"followup_absences", fmt.Sprint(notifySettings.FollowUpAbsences)},
+			{"batch", fmt.Sprint(notifySettings.Batch)},
+			{"telegram_rate", fmt.Sprint(notifySettings.TelegramRate)},
+			{"webhook_rate", fmt.Sprint(notifySettings.WebhookRate)},
+			{"twilio_rate", fmt.Sprint(notifySettings.TwilioRate)},
+		}},
+		{"email", [][2]string{
+			{"send_report", fmt.Sprint(configuration.Email.SendReport)},
+			{"smtp_host", configuration.Email.Host},
+			{"smtp_port", fmt.Sprint(configuration.Email.Port)},
+			{"username", configuration.Email.Username},
+			{"password", maskSecret(configuration.Email.Password)},
+			{"from", configuration.Email.From},
+			{"recipients", strings.Join(configuration.Email.Recipients, ",")},
+		}},
+		{"limits", [][2]string{
+			{"max_file_size", fmt.Sprint(configuration.Limits.MaxFileSize >> 20)},
+			{"max_rows", fmt.Sprint(configuration.Limits.MaxRows)},
+			{"file_timeout", fmt.Sprint(int(configuration.Limits.FileTimeout / time.Second))},
+		}},
+		{"sheets", [][2]string{
+			{"enabled", fmt.Sprint(configuration.Sheets.Enabled)},
+			{"spreadsheet_id", configuration.Sheets.SpreadsheetID},
+			{"credentials_path", configuration.Sheets.CredentialsPath},
+			{"layout", configuration.Sheets.Layout},
+		}},
+		{"xapi", [][2]string{
+			{"enabled", fmt.Sprint(configuration.XAPI.Enabled)},
+			{"endpoint", configuration.XAPI.Endpoint},
+			{"username", configuration.XAPI.Username},
+			{"password", maskSecret(configuration.XAPI.Password)},
+			{"homepage", configuration.XAPI.HomePage},
+		}},
+		{"audit", [][2]string{
+			{"enabled", fmt.Sprint(configuration.Audit.Enabled)},
+			{"log_path", configuration.Audit.Path},
+		}},
+		{"log", [][2]string{
+			{"path", configuration.Log.Path},
+			{"level", strings.ToLower(configuration.Log.Level.String())},
+			{"max_size", fmt.Sprint(configuration.Log.MaxSize >> 20)},
+			{"max_backups", fmt.Sprint(configuration.Log.MaxBackups)},
+		}},
+		{"history", [][2]string{
+			{"enabled", fmt.Sprint(configuration.History.Enabled)},
+			{"database_path", configuration.History.Path},
+		}},
+	})
+
+	//Таблица расписания: границы пары и промежутки, в которых собрание относится к паре и участник считается
+	// опоздавшим или ушедшим раньше. Для дней недели со своим списком пар выводятся отдельные таблицы
+	fmt.Fprintln(out, "; Расписание пар")
+	if err := writeLessonTable(out, lessons); err != nil {
+		return err
+	}
+	for _, weekday := range weekdayOrder {
+		if weekdayLessons, ok := lessons.Weekdays[weekday]; ok {
+			fmt.Fprintf(out, "\n; Расписание пар (%v)\n", config.WeekdayKeys[weekday])
+			daySchedule := lessons
+			daySchedule.Lessons = weekdayLessons
+			if err := writeLessonTable(out, daySchedule); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeConfigSections Функция, выводящая секции итоговых конфигураций строками ключ=значение с пустой строкой после
+// каждой секции
+func writeConfigSections(out io.Writer, sections []configSection) {
+	for _, section := range sections {
+		fmt.Fprintf(out, "[%v]\n", section.name)
+		for _, key := range section.keys {
+			fmt.Fprintf(out, "%v=%v\n", key[0], key[1])
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// maskSecret Функция, скрывающая секрет в итоговых конфигурациях: вместо заданного секрета выводятся звёздочки,
+// незаданный секрет выводится пустым
+func maskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return "********"
+}
+
+// weekdayOrder Дни недели в порядке с понедельника по воскресенье
+var weekdayOrder = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday,
+	time.Sunday}
+
+// formatBounds Функция, возвращающая список пар в виде, в котором он указывается в файле конфигураций
+func formatBounds(lessons []schedule.Lesson) string {
+	bounds := make([]string, 0, len(lessons))
+	for _, lesson := range lessons {
+		bounds = append(bounds, schedule.FormatClock(lesson.Start)+"-"+schedule.FormatClock(lesson.End))
+	}
+
+	return strings.Join(bounds, ",")
+}
+
+// writeLessonTable Функция, выводящая таблицу пар расписания
+func writeLessonTable(out io.Writer, lessons schedule.Schedule) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "; Пара\tНачало\tОкончание\tСобрание относится к паре\tОпоздание с\tРанний уход до")
+	for _, lesson := range lessons.Lessons {
+		fmt.Fprintf(writer, "; %v\t%v\t%v\t%v-%v\t%v\t%v\n", report.Header{LessonNumber: lesson.Name}.LessonLabel(),
+			schedule.FormatClock(lesson.Start),
+			schedule.FormatClock(lesson.End), schedule.FormatClock(lesson.Start-lessons.ToleranceBefore),
+			schedule.FormatClock(lesson.End+lessons.ToleranceAfter),
+			schedule.FormatClock(lesson.Start+lessons.LateAfter(lesson)),
+			schedule.FormatClock(lesson.End-lessons.EarlyExitThreshold))
+	}
+	fmt.Fprintf(writer, "; %v\tиначе\t\t\t\t\n", schedule.Consultation)
+
+	return writer.Flush()
+}
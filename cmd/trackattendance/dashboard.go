@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/report"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// dashboard Структура веб-панели посещаемости над базой истории: список собраний, посещаемость групп, посещаемость
+// студента и выгрузка каждой страницы в .csv и .xlsx
+type dashboard struct {
+	store *history.Store
+	books map[string]string
+	//Задан ли пароль книг .xlsx: страницы выгружаются и отчёты раздаются только зашифрованными книгами .xlsx
+	encrypted bool
+}
+
+// dashboardPage Структура страницы веб-панели: заголовок, значения фильтра периода и группы и таблицы страницы
+type dashboardPage struct {
+	Title  string
+	Filter url.Values
+	Tables []dashboardTable
+	//Ссылки на выгрузку страницы в .csv (пустая, если выгрузка в .csv недоступна) и .xlsx
+	CSV, XLSX string
+}
+
+// dashboardTable Структура таблицы страницы веб-панели
+type dashboardTable struct {
+	Title   string
+	Columns []string
+	Rows    [][]dashboardCell
+}
+
+// dashboardCell Структура ячейки таблицы веб-панели: текст и необязательная ссылка
+type dashboardCell struct {
+	Text string
+	Link string
+}
+
+// errPageNotFound Ошибка запроса несуществующей страницы веб-панели
+var errPageNotFound = errors.New("страница не найдена")
+
+// dashboardTemplate Шаблон страницы веб-панели
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{"t": i18n.T}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 16px; }
+nav a, .downloads a { margin-right: 12px; }
+form { margin: 12px 0; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 24px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f5f5f5; }
+</style>
+</head>
+<body>
+<nav><a href="/">{{t "Собрания"}}</a></nav>
+<h1>{{.Title}}</h1>
+{{if .Filter}}<form method="get" action="/">
+<label>{{t "С"}} <input name="from" placeholder="ДД.ММ.ГГГГ" value="{{.Filter.Get "from"}}"></label>
+<label>{{t "по"}} <input name="to" placeholder="ДД.ММ.ГГГГ" value="{{.Filter.Get "to"}}"></label>
+<label>{{t "Группа"}} <input name="group" value="{{.Filter.Get "group"}}"></label>
+<button type="submit">{{t "Показать"}}</button>
+</form>
+{{end}}<div class="downloads">{{if .CSV}}<a href="{{.CSV}}">{{t "Скачать"}} .csv</a>{{end}}
+<a href="{{.XLSX}}">{{t "Скачать"}} .xlsx</a></div>
+{{range .Tables}}{{if .Title}}<h2>{{.Title}}</h2>
+{{end}}
+<table>
+{{if .Columns}}<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+{{end}}<tbody>
+{{range .Rows}}<tr>{{range .}}<td>{{if .Link}}<a href="{{.Link}}">{{.Text}}</a>{{else}}{{.Text}}{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{end}}</body>
+</html>
+`))
+
+/*====================================================================================================================*/
+
+// handler Функция, возвращающая обработчик страницы веб-панели: страница выводится в виде HTML или, с параметром
+// format=csv или format=xlsx, выгружается файлом
+func (board dashboard) handler(build func(r *http.Request) (dashboardPage, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := build(r)
+		if errors.Is(err, errPageNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Query().Get("format") {
+		//Выгрузка .csv не шифруется, поэтому при заданном пароле книг .xlsx недоступна
+		case "csv":
+			if board.encrypted {
+				http.Error(w, i18n.T("выгрузка .csv недоступна, так как задан пароль книг .xlsx"), http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="attendance.csv"`)
+			err = writeDashboardCSV(w, page)
+		case "xlsx":
+			w.Header().Set("Content-Type", xlsxContentType)
+			w.Header().Set("Content-Disposition", `attachment; filename="attendance.xlsx"`)
+			sheets := make([]report.Sheet, 0, len(page.Tables))
+			for _, table := range page.Tables {
+				sheets = append(sheets, report.Sheet{Name: table.Title, Rows: table.text()})
+			}
+			//Выгрузка содержит персональные данные, поэтому шифруется тем же паролем, что и итоговые отчёты .xlsx
+			err = report.WriteProtectedXLSX(w, sheets)
+		default:
+			//Ссылки на выгрузку - та же страница с параметром format
+			query := r.URL.Query()
+			if !board.encrypted {
+				query.Set("format", "csv")
+				page.CSV = r.URL.Path + "?" + query.Encode()
+			}
+			query.Set("format", "xlsx")
+			page.XLSX = r.URL.Path + "?" + query.Encode()
+
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			err = dashboardTemplate.Execute(w, page)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// index Функция, формирующая главную страницу веб-панели: собрания за период (последние - первыми) и посещаемость
+// групп за тот же период
+func (board dashboard) index(r *http.Request) (dashboardPage, error) {
+	if r.URL.Path != "/" {
+		return dashboardPage{}, errPageNotFound
+	}
+
+	filter := r.URL.Query()
+	from, to := time.Time{}, time.Now()
+	for name, date := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := filter.Get(name); value != "" {
+			parsed, err := history.ParseDate(value)
+			if err != nil {
+				return dashboardPage{}, err
+			}
+			*date = parsed
+		}
+	}
+	marks, err := board.store.Marks(r.Context(), from, to, filter.Get("group"))
+	if err != nil {
+		return dashboardPage{}, err
+	}
+
+	//Собрания и группы в порядке первого появления в отметках
+	var meetingIDs []int64
+	var groupNames []string
+	byMeeting := make(map[int64][]history.Mark)
+	byGroup := make(map[string][]history.Mark)
+	for _, mark := range marks {
+		if _, ok := byMeeting[mark.MeetingID]; !ok {
+			meetingIDs = append(meetingIDs, mark.MeetingID)
+		}
+		if _, ok := byGroup[mark.Group]; !ok {
+			groupNames = append(groupNames, mark.Group)
+		}
+		byMeeting[mark.MeetingID] = append(byMeeting[mark.MeetingID], mark)
+		byGroup[mark.Group] = append(byGroup[mark.Group], mark)
+	}
+	sort.Strings(groupNames)
+
+	meetings := dashboardTable{Title: i18n.T("Собрания"), Columns: []string{i18n.T("Дата проведения собрания"),
+		i18n.T("Номер пары"), i18n.T("Название собрания"), i18n.T("Ожидалось"), i18n.T("Присутствовали"),
+		i18n.T("Опоздали"), i18n.T("Отсутствовали"), i18n.T("Посещаемость, %")}}
+	for i := len(meetingIDs) - 1; i >= 0; i-- {
+		current := byMeeting[meetingIDs[i]]
+		first, counts := current[0], countMarks(current)
+		meetings.Rows = append(meetings.Rows, []dashboardCell{
+			{Text: first.Date.Format("02.01.2006")},
+			{Text: report.Header{LessonNumber: first.Lesson}.LessonLabel()},
+			{Text: first.Title, Link: "/meeting?id=" + strconv.FormatInt(first.MeetingID, 10)},
+			{Text: strconv.Itoa(counts["expected"])},
+			{Text: strconv.Itoa(counts["present"] + counts["partial"])},
+			{Text: strconv.Itoa(counts["late"])},
+			{Text: strconv.Itoa(counts["absent"])},
+			{Text: strconv.Itoa(attendanceRate(counts))},
+		})
+	}
+
+	groups := dashboardTable{Title: i18n.T("Посещаемость групп"), Columns: []string{i18n.T("Группа"),
+		i18n.T("Собраний"), i18n.T("Ожидалось"), i18n.T("Присутствовали"), i18n.T("Опоздали"),
+		i18n.T("Отсутствовали"), i18n.T("Посещаемость, %")}}
+	for _, name := range groupNames {
+		current := byGroup[name]
+		counts := countMarks(current)
+		meetingsCount := make(map[int64]bool)
+		for _, mark := range current {
+			meetingsCount[mark.MeetingID] = true
+		}
+
+		query := url.Values{"group": {name}, "from": {filter.Get("from")}, "to": {filter.Get("to")}}
+		groups.Rows = append(groups.Rows, []dashboardCell{
+			{Text: i18n.T(name), Link: "/?" + query.Encode()},
+			{Text: strconv.Itoa(len(meetingsCount))},
+			{Text: strconv.Itoa(counts["expected"])},
+			{Text: strconv.Itoa(counts["present"] + counts["partial"])},
+			{Text: strconv.Itoa(counts["late"])},
+			{Text: strconv.Itoa(counts["absent"])},
+			{Text: strconv.Itoa(attendanceRate(counts))},
+		})
+	}
+
+	//Форма фильтра выводится и с пустыми значениями
+	filter = url.Values{"from": {filter.Get("from")}, "to": {filter.Get("to")}, "group": {filter.Get("group")}}
+
+	return dashboardPage{Title: i18n.T("Посещаемость собраний"), Filter: filter, Tables: []dashboardTable{meetings,
+		groups}}, nil
+}
+
+// meeting Функция, формирующая страницу собрания: отметки всех его участников
+func (board dashboard) meeting(r *http.Request) (dashboardPage, error) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		return dashboardPage{}, fmt.Errorf("некорректный идентификатор собрания: %v", r.URL.Query().Get("id"))
+	}
+
+	marks, err := board.store.Marks(r.Context(), time.Time{}, time.Now(), "")
+	if err != nil {
+		return dashboardPage{}, err
+	}
+
+	var title string
+	table := dashboardTable{Title: i18n.T("Участники"), Columns: []string{i18n.T("Группа"), i18n.T("ФИО"),
+		i18n.T("Номер зачётки"), i18n.T("Присутствие"), i18n.T("Опоздание")}}
+	for _, mark := range marks {
+		if mark.MeetingID != id {
+			continue
+		}
+		title = mark.Title + ", " + mark.Date.Format("02.01.2006") + ", " +
+			report.Header{LessonNumber: mark.Lesson}.LessonLabel()
+		table.Rows = append(table.Rows, []dashboardCell{
+			{Text: i18n.T(mark.Group)},
+			{Text: mark.FullName, Link: "/student?" + url.Values{"name": {mark.FullName}}.Encode()},
+			{Text: board.books[mark.FullName]},
+			{Text: i18n.T(mark.Presence)},
+			{Text: i18n.T(mark.Delay)},
+		})
+	}
+	if title == "" {
+		return dashboardPage{}, fmt.Errorf("собрание %d не найдено в истории", id)
+	}
+
+	//Сформированные отчёты собрания, записанные в историю
+	reports, err := board.reports(r, id)
+	if err != nil {
+		return dashboardPage{}, err
+	}
+	if len(reports) == 0 {
+		return dashboardPage{Title: title, Tables: []dashboardTable{table}}, nil
+	}
+	files := dashboardTable{Title: i18n.T("Сформированные отчёты"), Columns: []string{i18n.T("Формат"),
+		i18n.T("Отчёт")}}
+	for i, current := range reports {
+		files.Rows = append(files.Rows, []dashboardCell{{Text: current.Format},
+			{Text: filepath.Base(current.Path), Link: fmt.Sprintf("/reports/%d/%d", id, i+1)}})
+	}
+
+	return dashboardPage{Title: title, Tables: []dashboardTable{table, files}}, nil
+}
+
+// reports Функция, возвращающая сформированные отчёты собрания, которые раздаются веб-панелью. Если задан пароль
+// книг .xlsx, раздаются только зашифрованные книги .xlsx
+func (board dashboard) reports(r *http.Request, id int64) ([]history.MeetingReport, error) {
+	reports, err := board.store.MeetingReports(r.Context(), id)
+	if err != nil || !board.encrypted {
+		return reports, err
+	}
+
+	var encrypted []history.MeetingReport
+	for _, current := range reports {
+		if current.Format == report.FormatXLSX {
+			encrypted = append(encrypted, current)
+		}
+	}
+
+	return encrypted, nil
+}
+
+// report Функция, раздающая сформированный отчёт собрания по адресу /reports/{id}/{номер}: номер - порядковый номер
+// отчёта на странице собрания. Раздаются только отчёты, записанные в историю, списки файлов папки отчётов не выводятся
+func (board dashboard) report(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/reports/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	id, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	reports, err := board.reports(r, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if number < 1 || number > len(reports) {
+		http.NotFound(w, r)
+		return
+	}
+
+	//Отчёт мог быть удалён из папки отчётов после записи в историю
+	path := reports[number-1].Path
+	file, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(path)))
+	http.ServeContent(w, r, filepath.Base(path), info.ModTime(), file)
+}
+
+// student Функция, формирующая страницу студента: накопленная посещаемость по семестрам и отметки на каждом
+// собрании
+func (board dashboard) student(r *http.Request) (dashboardPage, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return dashboardPage{}, fmt.Errorf("не указано ФИО студента")
+	}
+
+	stats, err := board.store.StudentStats(r.Context(), name)
+	if err != nil {
+		return dashboardPage{}, err
+	}
+	semesters := dashboardTable{Title: i18n.T("Посещаемость по семестрам"), Columns: []string{i18n.T("Семестр"),
+		i18n.T("Группа"), i18n.T("ФИО"), i18n.T("Занятий"), i18n.T("Присутствовал"), i18n.T("Не полностью"),
+		i18n.T("Опозданий"), i18n.T("Пропусков")}}
+	for _, current := range stats {
+		//Статистика ищется по началу ФИО, а на странице выводится только сам студент
+		if current.FullName != name {
+			continue
+		}
+		semesters.Rows = append(semesters.Rows, []dashboardCell{{Text: current.Semester}, {Text: i18n.T(current.Group)},
+			{Text: current.FullName}, {Text: strconv.Itoa(current.Lessons)}, {Text: strconv.Itoa(current.Present)},
+			{Text: strconv.Itoa(current.Partial)}, {Text: strconv.Itoa(current.Late)},
+			{Text: strconv.Itoa(current.Missed)}})
+	}
+
+	marks, err := board.store.Marks(r.Context(), time.Time{}, time.Now(), "")
+	if err != nil {
+		return dashboardPage{}, err
+	}
+	meetings := dashboardTable{Title: i18n.T("Собрания"), Columns: []string{i18n.T("Дата проведения собрания"),
+		i18n.T("Номер пары"), i18n.T("Название собрания"), i18n.T("ФИО"), i18n.T("Присутствие"), i18n.T("Опоздание")}}
+	for i := len(marks) - 1; i >= 0; i-- {
+		mark := marks[i]
+		if mark.FullName != name {
+			continue
+		}
+		meetings.Rows = append(meetings.Rows, []dashboardCell{
+			{Text: mark.Date.Format("02.01.2006")},
+			{Text: report.Header{LessonNumber: mark.Lesson}.LessonLabel()},
+			{Text: mark.Title, Link: "/meeting?id=" + strconv.FormatInt(mark.MeetingID, 10)},
+			{Text: mark.FullName},
+			{Text: i18n.T(mark.Presence)},
+			{Text: i18n.T(mark.Delay)},
+		})
+	}
+
+	return dashboardPage{Title: name, Tables: []dashboardTable{semesters, meetings}}, nil
+}
+
+/*====================================================================================================================*/
+
+// text Функция, возвращающая строки таблицы веб-панели вместе с "шапкой" в виде текста для выгрузки
+func (table dashboardTable) text() [][]string {
+	var rows [][]string
+	if len(table.Columns) > 0 {
+		rows = append(rows, table.Columns)
+	}
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, cell.Text)
+		}
+		rows = append(rows, cells)
+	}
+
+	return rows
+}
+
+// writeDashboardCSV Функция, выгружающая таблицы страницы веб-панели в .csv файл в том же виде, что и отчёты: UTF-8
+// с BOM и разделителем ";". Таблицы отделяются пустой строкой и начинаются с названия таблицы
+func writeDashboardCSV(w http.ResponseWriter, page dashboardPage) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return fmt.Errorf("ошибка записи строки с кодировкой: %w", err)
+	}
+
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = ';'
+	for i, table := range page.Tables {
+		if i > 0 {
+			if err := csvWriter.Write([]string{""}); err != nil {
+				return fmt.Errorf("ошибка записи пустой строки: %w", err)
+			}
+		}
+		if err := csvWriter.Write([]string{table.Title}); err != nil {
+			return fmt.Errorf("ошибка записи названия таблицы: %w", err)
+		}
+		if err := csvWriter.WriteAll(table.text()); err != nil {
+			return fmt.Errorf("ошибка записи таблицы: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// attendanceRate Вспомогательная функция, возвращающая долю отметок о присутствии (полном или неполном) в процентах
+func attendanceRate(counts map[string]int) int {
+	if counts["expected"] == 0 {
+		return 0
+	}
+
+	return (counts["present"] + counts["partial"]) * 100 / counts["expected"]
+}
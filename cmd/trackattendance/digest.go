@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/notify"
+	"mod.go/report"
+	"mod.go/roster"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// groupProgress Структура посещаемости группы за месяц для сводки целей: отметки за месяц и за прошлый месяц и
+// посещаемость по неделям месяца
+type groupProgress struct {
+	//Количество отметок (ожидавшихся студентов на занятиях) и отметок о присутствии за месяц
+	expected, attended int
+	//Количество отметок и отметок о присутствии за прошлый месяц
+	previousExpected, previousAttended int
+	//Количество отметок и отметок о присутствии по неделям месяца (ключ - понедельник недели)
+	weekExpected, weekAttended map[time.Time]int
+}
+
+// groupDigest Структура сводки посещаемости группы за месяц: посещаемость в процентах, цель посещаемости и
+// посещаемость за прошлый месяц (nil, если их нет) и посещаемость по неделям месяца
+type groupDigest struct {
+	Group        string `json:"group"`
+	Month        string `json:"month"`
+	Rate         int    `json:"attendance_rate"`
+	Attended     int    `json:"attended"`
+	Expected     int    `json:"expected"`
+	Target       *int   `json:"target,omitempty"`
+	PreviousRate *int   `json:"previous_rate,omitempty"`
+	Weeks        []int  `json:"weeks"`
+}
+
+/*====================================================================================================================*/
+
+// RunDigest Функция команды digest, формирующая сводку посещаемости групп за месяц с целями посещаемости из файла
+// целей: посещаемость группы, достигнута ли цель (или сколько до неё не хватает), изменение по сравнению с прошлым
+// месяцем и посещаемость по неделям. Сводка выводится в стандартный вывод и, с флагом --send, отправляется кураторам
+func RunDigest(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаги команды: месяц, группа, формат вывода и отправка кураторам
+	flags := flag.NewFlagSet("digest", flag.ContinueOnError)
+	monthFlag := flags.String("month", "", "месяц сводки (ММ.ГГГГ), по-умолчанию - текущий месяц")
+	group := flags.String("group", "", "группа, по которой формируется сводка (по-умолчанию - все группы)")
+	output := flags.String("output", "text", "формат вывода: text, csv, tsv или json")
+	send := flags.Bool("send", false, "отправить сводку кураторам групп настроенными способами оповещения")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if *output != "text" && checkFileFormat(*output) != nil {
+		return fmt.Errorf("неизвестный формат вывода: %v (допустимы text, csv, tsv, json)", *output)
+	}
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if *monthFlag != "" {
+		parsed, err := time.ParseInLocation("01.2006", *monthFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("некорректный месяц сводки: %v, ожидается вид ММ.ГГГГ", *monthFlag)
+		}
+		month = parsed
+	}
+	if *send && !configuration.Notify.Enabled() {
+		return fmt.Errorf("для отправки сводки необходимо настроить оповещения в секции [notify] cfg.ini")
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего считать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	goals, err := roster.LoadGoals(configuration.GoalsPath)
+	if err != nil {
+		return err
+	}
+
+	//Отметки за прошлый и текущий месяц
+	marks, err := store.Marks(ctx, month.AddDate(0, -1, 0), month.AddDate(0, 1, -1), *group)
+	if err != nil {
+		return err
+	}
+
+	//Машиночитаемые форматы выводятся и при пустом результате, чтобы их можно было передать другим программам
+	groups := digestGroups(marks, month, goals)
+	switch {
+	case *output != "text":
+		if err := WriteDigest(os.Stdout, *output, groups); err != nil {
+			return err
+		}
+	case len(groups) == 0:
+		fmt.Println(i18n.T("В истории нет записей о посещаемости по заданному условию"))
+		return nil
+	default:
+		for _, current := range groups {
+			fmt.Println(current.text())
+		}
+	}
+
+	//Кураторам сводки отправляются текстом при любом формате вывода
+	if *send && len(groups) > 0 {
+		digests := make(map[string]string, len(groups))
+		for _, current := range groups {
+			digests[current.Group] = current.text()
+		}
+		curators, err := roster.LoadCurators(configuration.CuratorsPath)
+		if err != nil {
+			return err
+		}
+		return notify.SendToCurators(ctx, configuration.Notify, curators, digests)
+	}
+
+	return nil
+}
+
+// WriteDigest Функция, выводящая сводки посещаемости групп в виде .csv (csv) или .tsv (tsv) файла или в виде массива
+// JSON (json). Посещаемость по неделям выводится в одной ячейке через пробел
+func WriteDigest(out io.Writer, format string, groups []groupDigest) error {
+	if format == "json" {
+		//Пустой результат выводится пустым массивом, а не null
+		if groups == nil {
+			groups = []groupDigest{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(groups)
+	}
+
+	csvWriter := csv.NewWriter(out)
+	if format == "tsv" {
+		csvWriter.Comma = '\t'
+	}
+
+	//Необязательные значения выводятся пустыми ячейками
+	optional := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+	rows := [][]string{{i18n.T("Группа"), i18n.T("Месяц"), i18n.T("Посещаемость, %"), i18n.T("Присутствовали"),
+		i18n.T("Ожидалось"), i18n.T("Цель, %"), i18n.T("Прошлый месяц, %"), i18n.T("По неделям, %")}}
+	for _, current := range groups {
+		weeks := make([]string, 0, len(current.Weeks))
+		for _, rate := range current.Weeks {
+			weeks = append(weeks, strconv.Itoa(rate))
+		}
+		rows = append(rows, []string{current.Group, current.Month, strconv.Itoa(current.Rate),
+			strconv.Itoa(current.Attended), strconv.Itoa(current.Expected), optional(current.Target),
+			optional(current.PreviousRate), strings.Join(weeks, " ")})
+	}
+
+	return csvWriter.WriteAll(rows)
+}
+
+// digestGroups Вспомогательная функция, подсчитывающая посещаемость групп за месяц по отметкам за этот и прошлый
+// месяц. Посещаемость - доля отметок о присутствии (полном или неполном) среди всех отметок студентов группы. Группы
+// без отметок за месяц и гости в сводку не попадают, группы возвращаются в алфавитном порядке
+func digestGroups(marks []history.Mark, month time.Time, goals roster.Goals) []groupDigest {
+	progress := make(map[string]*groupProgress)
+	for _, mark := range marks {
+		if mark.Group == roster.Guest {
+			continue
+		}
+
+		current, ok := progress[mark.Group]
+		if !ok {
+			current = &groupProgress{weekExpected: make(map[time.Time]int), weekAttended: make(map[time.Time]int)}
+			progress[mark.Group] = current
+		}
+
+		attended := 0
+		if mark.Presence == report.PresenceFull.String() || mark.Presence == report.PresencePartial.String() {
+			attended = 1
+		}
+
+		//Отметки прошлого месяца нужны только для сравнения
+		if mark.Date.Year() != month.Year() || mark.Date.Month() != month.Month() {
+			current.previousExpected++
+			current.previousAttended += attended
+			continue
+		}
+		current.expected++
+		current.attended += attended
+
+		//Неделя отметки определяется по её понедельнику
+		monday := mark.Date.AddDate(0, 0, -(int(mark.Date.Weekday())+6)%7)
+		current.weekExpected[monday]++
+		current.weekAttended[monday] += attended
+	}
+
+	var groups []groupDigest
+	for group, current := range progress {
+		if current.expected == 0 {
+			continue
+		}
+		digest := groupDigest{Group: group, Month: month.Format("01.2006"), Rate: current.attended * 100 /
+			current.expected, Attended: current.attended, Expected: current.expected}
+		if target, ok := goals.Target(group, month); ok {
+			digest.Target = &target
+		}
+		if current.previousExpected > 0 {
+			previous := current.previousAttended * 100 / current.previousExpected
+			digest.PreviousRate = &previous
+		}
+
+		//Посещаемость по неделям месяца в порядке недель
+		weeks := make([]time.Time, 0, len(current.weekExpected))
+		for monday := range current.weekExpected {
+			weeks = append(weeks, monday)
+		}
+		sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+		for _, monday := range weeks {
+			digest.Weeks = append(digest.Weeks, current.weekAttended[monday]*100/current.weekExpected[monday])
+		}
+
+		groups = append(groups, digest)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Group < groups[j].Group })
+
+	return groups
+}
+
+// text Функция, формирующая текст сводки посещаемости группы: посещаемость, достигнута ли цель (или сколько до неё
+// не хватает), изменение по сравнению с прошлым месяцем и посещаемость по неделям
+func (digest groupDigest) text() string {
+	lines := []string{i18n.Sprintf("Группа %v, %v: посещаемость %d%% (%d из %d отметок)", digest.Group, digest.Month,
+		digest.Rate, digest.Attended, digest.Expected)}
+
+	//Продвижение к цели посещаемости
+	if digest.Target != nil {
+		if target := *digest.Target; digest.Rate >= target {
+			lines = append(lines, i18n.Sprintf("Цель %d%% достигнута", target))
+		} else {
+			lines = append(lines, i18n.Sprintf("Цель %d%%: не хватает %d%%", target, target-digest.Rate))
+		}
+	}
+
+	//Изменение по сравнению с прошлым месяцем
+	if digest.PreviousRate != nil {
+		previous := *digest.PreviousRate
+		trend := "→"
+		if digest.Rate > previous {
+			trend = "↑"
+		} else if digest.Rate < previous {
+			trend = "↓"
+		}
+		lines = append(lines, i18n.Sprintf("Прошлый месяц: %d%% (%v %+d%%)", previous, trend, digest.Rate-previous))
+	}
+
+	//Посещаемость по неделям месяца
+	rates := make([]string, 0, len(digest.Weeks))
+	for _, rate := range digest.Weeks {
+		rates = append(rates, fmt.Sprintf("%d%%", rate))
+	}
+	lines = append(lines, i18n.Sprintf("По неделям: %v", strings.Join(rates, " → ")))
+
+	return strings.Join(lines, "\n") + "\n"
+}
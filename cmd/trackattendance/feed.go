@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// feedPageSize Количество отметок на странице ленты по-умолчанию и наибольшее количество отметок на странице
+const (
+	feedPageSize    = 1000
+	feedMaxPageSize = 10000
+)
+
+// marksFeed Структура ленты отметок для сторонних программ анализа (Power BI и подобных): отметки студентов из базы
+// истории постранично в виде плоской таблицы JSON
+type marksFeed struct {
+	store *history.Store
+	books map[string]string
+	salt  string
+}
+
+// feedMark Структура отметки студента в ленте. Дата указывается в виде ГГГГ-ММ-ДД, чтобы программы анализа
+// распознавали её без настройки
+type feedMark struct {
+	MeetingID  int64  `json:"meeting_id"`
+	Title      string `json:"title"`
+	Date       string `json:"date"`
+	Lesson     string `json:"lesson"`
+	FullName   string `json:"full_name"`
+	ID         string `json:"id"`
+	RecordBook string `json:"record_book,omitempty"`
+	Group      string `json:"group"`
+	Presence   string `json:"presence"`
+	Delay      string `json:"delay"`
+	IsPresent  bool   `json:"is_present"`
+	IsLate     bool   `json:"is_late"`
+}
+
+// feedPage Структура страницы ленты: отметки страницы и ссылка на следующую страницу (пустая на последней странице)
+type feedPage struct {
+	Value    []feedMark `json:"value"`
+	NextLink string     `json:"nextLink,omitempty"`
+}
+
+/*====================================================================================================================*/
+
+// ServeHTTP Функция, возвращающая страницу ленты отметок. Параметры запроса: from и to - период (ДД.ММ.ГГГГ),
+// group - группа, skip - количество пропускаемых отметок, top - количество отметок на странице
+func (feed marksFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, i18n.T("лента отметок запрашивается методом GET"), http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	from, to := time.Time{}, time.Now()
+	for name, date := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := query.Get(name); value != "" {
+			parsed, err := history.ParseDate(value)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*date = parsed
+		}
+	}
+	skip, top := 0, feedPageSize
+	for name, number := range map[string]*int{"skip": &skip, "top": &top} {
+		if value := query.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				http.Error(w, i18n.Sprintf("некорректное значение параметра %v: %v", name, value),
+					http.StatusBadRequest)
+				return
+			}
+			*number = parsed
+		}
+	}
+	top = min(max(top, 1), feedMaxPageSize)
+
+	//Отметки упорядочены по дате, паре и собранию, поэтому страницы не пересекаются, пока история не изменилась
+	marks, err := feed.store.Marks(r.Context(), from, to, query.Get("group"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	marks = marks[min(skip, len(marks)):]
+
+	page := feedPage{Value: make([]feedMark, 0, min(top, len(marks)))}
+	if len(marks) > top {
+		marks = marks[:top]
+		query.Set("skip", strconv.Itoa(skip+top))
+		query.Set("top", strconv.Itoa(top))
+		page.NextLink = r.URL.Path + "?" + query.Encode()
+	}
+	for _, mark := range marks {
+		page.Value = append(page.Value, feedMark{
+			MeetingID:  mark.MeetingID,
+			Title:      mark.Title,
+			Date:       mark.Date.Format("2006-01-02"),
+			Lesson:     report.Header{LessonNumber: mark.Lesson}.LessonLabel(),
+			FullName:   mark.FullName,
+			ID:         roster.StudentID(mark.FullName, feed.salt),
+			RecordBook: feed.books[mark.FullName],
+			Group:      i18n.T(mark.Group),
+			Presence:   i18n.T(mark.Presence),
+			Delay:      i18n.T(mark.Delay),
+			IsPresent: mark.Presence != report.PresenceAbsent.String() &&
+				mark.Presence != report.PresenceExcused.String(),
+			IsLate: mark.Delay == report.DelayLate.String(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
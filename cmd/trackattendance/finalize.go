@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"mod.go/audit"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"os"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunFinalize Функция команды finalize, закрывающая собрания за месяц или период в истории посещаемости, как
+// закрывается бумажный журнал в конце месяца: после закрытия отметки собрания и его отчёты не изменяются повторной
+// обработкой без флага --force, а каждое такое изменение записывается в журнал действий
+func RunFinalize(ctx context.Context, arguments []string, configuration config.Configuration, journal *audit.Log) error {
+	//Флаги команды: месяц или период и название собрания
+	flags := flag.NewFlagSet("finalize", flag.ContinueOnError)
+	monthFlag := flags.String("month", "", "закрываемый месяц (ММ.ГГГГ)")
+	from := flags.String("from", "", "дата начала закрываемого периода (ДД.ММ.ГГГГ)")
+	to := flags.String("to", "", "дата окончания закрываемого периода (ДД.ММ.ГГГГ), по-умолчанию - сегодня")
+	title := flags.String("title", "", "часть названия собрания (например, дисциплины), по которой отбираются собрания")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+
+	//Период закрытия: месяц целиком или период с --from по --to
+	var dateFrom, dateTo time.Time
+	switch {
+	case *monthFlag != "" && *from != "":
+		return fmt.Errorf("необходимо указать только один из флагов --month или --from")
+	case *monthFlag != "":
+		month, err := time.Parse("01.2006", *monthFlag)
+		if err != nil {
+			return fmt.Errorf("некорректный месяц: %v, ожидается вид ММ.ГГГГ", *monthFlag)
+		}
+		dateFrom, dateTo = month, month.AddDate(0, 1, -1)
+	case *from != "":
+		var err error
+		if dateFrom, err = history.ParseDate(*from); err != nil {
+			return err
+		}
+		dateTo = time.Now()
+		if *to != "" {
+			if dateTo, err = history.ParseDate(*to); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("необходимо указать закрываемый месяц флагом --month или период флагами --from и --to")
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего закрывать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	count, err := store.Finalize(ctx, dateFrom, dateTo, *title)
+	if err != nil {
+		return err
+	}
+
+	period := dateFrom.Format("02.01.2006") + "-" + dateTo.Format("02.01.2006")
+	if *title != "" {
+		period += ", " + *title
+	}
+	if err := journal.Finalize(period); err != nil {
+		return err
+	}
+	if err := journal.Write(configuration.History.Path); err != nil {
+		return err
+	}
+	fmt.Printf(i18n.T("Закрыто собраний: %d (%v)")+"\n", count, period)
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// journalStudent Структура строки журнала посещаемости: студент, его отметки по собраниям и итоги
+type journalStudent struct {
+	group      string
+	fullName   string
+	recordBook string
+	marks      map[int64]string
+	present    int
+	partial    int
+	late       int
+	missed     int
+}
+
+// journalJSON Структура журнала посещаемости в формате JSON: собрания и студенты с отметками по идентификаторам
+// собраний и итогами
+type journalJSON struct {
+	Meetings []journalMeetingJSON `json:"meetings"`
+	Students []journalStudentJSON `json:"students"`
+}
+
+// journalMeetingJSON Структура собрания журнала в формате JSON
+type journalMeetingJSON struct {
+	ID     int64  `json:"id"`
+	Date   string `json:"date"`
+	Lesson string `json:"lesson"`
+	Title  string `json:"title"`
+}
+
+// journalStudentJSON Структура строки журнала в формате JSON (ключ отметок - идентификатор собрания)
+type journalStudentJSON struct {
+	Group      string           `json:"group"`
+	FullName   string           `json:"full_name"`
+	RecordBook string           `json:"record_book,omitempty"`
+	Marks      map[int64]string `json:"marks"`
+	Present    int              `json:"present"`
+	Partial    int              `json:"partial"`
+	Late       int              `json:"late"`
+	Missed     int              `json:"missed"`
+}
+
+/*====================================================================================================================*/
+
+// RunJournal Функция команды journal, формирующая журнал посещаемости за период из базы истории: строки - студенты,
+// столбцы - собрания (дата и пара), в ячейках - отметки, в конце строки - итоги
+func RunJournal(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаги команды: период, группа, название собрания, файл и формат журнала
+	flags := flag.NewFlagSet("journal", flag.ContinueOnError)
+	from := flags.String("from", "", "дата начала периода (ДД.ММ.ГГГГ)")
+	to := flags.String("to", "", "дата окончания периода (ДД.ММ.ГГГГ), по-умолчанию - сегодня")
+	group := flags.String("group", "", "группа, по студентам которой формируется журнал (по-умолчанию - все группы)")
+	title := flags.String("title", "", "часть названия собрания (например, дисциплины), по которой отбираются собрания")
+	output := flags.String("output", "", "файл журнала или - для вывода в стандартный вывод")
+	format := flags.String("format", "csv", "формат журнала: csv, tsv или json")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if err := checkFileFormat(*format); err != nil {
+		return err
+	}
+
+	if *from == "" {
+		return fmt.Errorf("необходимо указать дату начала периода флагом --from")
+	}
+	dateFrom, err := history.ParseDate(*from)
+	if err != nil {
+		return err
+	}
+	dateTo := time.Now()
+	if *to != "" {
+		if dateTo, err = history.ParseDate(*to); err != nil {
+			return err
+		}
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего считать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	marks, err := store.Marks(ctx, dateFrom, dateTo, *group)
+	if err != nil {
+		return err
+	}
+
+	//Отбираем собрания по названию без учёта регистра
+	if *title != "" {
+		filtered := marks[:0]
+		for _, mark := range marks {
+			if strings.Contains(strings.ToLower(mark.Title), strings.ToLower(*title)) {
+				filtered = append(filtered, mark)
+			}
+		}
+		marks = filtered
+	}
+
+	//Номера зачёток выводятся в журнале, если они указаны в базе групп
+	books, err := roster.LoadRecordBooks(roster.BasePath)
+	if err != nil {
+		return err
+	}
+
+	//Журнал сохраняется в каталог итоговых отчётов, если файл не указан явно
+	path := *output
+	if path == "" {
+		path = configuration.ReportLocationPath + i18n.T("Журнал посещаемости_") + dateFrom.Format("02.01.2006") + "_" +
+			dateTo.Format("02.01.2006") + "." + *format
+	}
+	if path == "-" {
+		return WriteJournal(os.Stdout, *format, marks, books)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer file.Close()
+
+	//Таблица записывается в кодировке UTF-8 c BOM, как и отчёт о посещаемости, чтобы MS Excel корректно отображал
+	// кириллицу
+	if *format != "json" {
+		if _, err := file.WriteString("\xEF\xBB\xBF"); err != nil {
+			return fmt.Errorf("ошибка записи строки с кодировкой: %w", err)
+		}
+	}
+	if err := WriteJournal(file, *format, marks, books); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия файла журнала: %w", err)
+	}
+
+	fmt.Println(path)
+	return nil
+}
+
+// WriteJournal Функция, записывающая журнал посещаемости в виде .csv файла с разделителем ";" (csv), .tsv файла (tsv)
+// или объекта JSON (json). Первая строка "шапки" таблицы - даты и пары собраний, вторая - названия собраний. Отметки:
+// "+" - присутствовал, "±" - присутствовал не полностью, "оп" - опоздал, "н" - отсутствовал, пустая ячейка - студент
+// не ожидался на собрании. Если известен номер зачётки хотя бы одного студента журнала, после ФИО выводится столбец
+// номера зачётки
+func WriteJournal(out io.Writer, format string, marks []history.Mark, books map[string]string) error {
+	//Собрания в порядке даты и номера пары (порядок запроса) и студенты журнала
+	var meetings []history.Mark
+	seen := make(map[int64]bool)
+	students := make(map[string]*journalStudent)
+	for _, mark := range marks {
+		if !seen[mark.MeetingID] {
+			seen[mark.MeetingID] = true
+			meetings = append(meetings, mark)
+		}
+
+		key := mark.Group + "\x00" + mark.FullName
+		student, ok := students[key]
+		if !ok {
+			student = &journalStudent{group: mark.Group, fullName: mark.FullName, recordBook: books[mark.FullName],
+				marks: make(map[int64]string)}
+			students[key] = student
+		}
+
+		//Итоги считаются так же, как в команде stats: опоздания считаются отдельно от присутствия
+		switch mark.Presence {
+		case report.PresenceFull.String():
+			student.present++
+		case report.PresencePartial.String():
+			student.partial++
+		case report.PresenceAbsent.String():
+			student.missed++
+		}
+		if mark.Delay == report.DelayLate.String() {
+			student.late++
+		}
+
+		//Отметка в ячейке журнала
+		switch {
+		case mark.Presence == report.PresenceAbsent.String():
+			student.marks[mark.MeetingID] = i18n.T("н")
+		case mark.Presence == report.PresenceExcused.String():
+			student.marks[mark.MeetingID] = i18n.T("у")
+		case mark.Delay == report.DelayLate.String():
+			student.marks[mark.MeetingID] = i18n.T("оп")
+		case mark.Presence == report.PresencePartial.String():
+			student.marks[mark.MeetingID] = "±"
+		default:
+			student.marks[mark.MeetingID] = "+"
+		}
+	}
+
+	//Студенты выводятся по группе и ФИО
+	rows := make([]*journalStudent, 0, len(students))
+	recordBooks := false
+	for _, student := range students {
+		rows = append(rows, student)
+		recordBooks = recordBooks || student.recordBook != ""
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].group != rows[j].group {
+			return rows[i].group < rows[j].group
+		}
+		return rows[i].fullName < rows[j].fullName
+	})
+
+	if format == "json" {
+		data := journalJSON{Meetings: make([]journalMeetingJSON, 0, len(meetings)),
+			Students: make([]journalStudentJSON, 0, len(rows))}
+		for _, meeting := range meetings {
+			lesson := report.Header{LessonNumber: meeting.Lesson}.LessonLabel()
+			data.Meetings = append(data.Meetings, journalMeetingJSON{ID: meeting.MeetingID,
+				Date: meeting.Date.Format("02.01.2006"), Lesson: lesson, Title: meeting.Title})
+		}
+		for _, student := range rows {
+			data.Students = append(data.Students, journalStudentJSON{Group: student.group, FullName: student.fullName,
+				RecordBook: student.recordBook, Marks: student.marks, Present: student.present,
+				Partial: student.partial, Late: student.late, Missed: student.missed})
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	}
+
+	csvWriter := csv.NewWriter(out)
+	csvWriter.Comma = ';'
+	if format == "tsv" {
+		csvWriter.Comma = '\t'
+	}
+
+	//"Шапка" журнала: даты и пары, названия собраний и итоги
+	dates := []string{i18n.T("Группа"), i18n.T("ФИО")}
+	titles := []string{"", ""}
+	if recordBooks {
+		dates, titles = append(dates, i18n.T("Номер зачётки")), append(titles, "")
+	}
+	for _, meeting := range meetings {
+		lesson := report.Header{LessonNumber: meeting.Lesson}.LessonLabel()
+		dates = append(dates, meeting.Date.Format("02.01.2006")+" "+lesson)
+		titles = append(titles, meeting.Title)
+	}
+	dates = append(dates, i18n.T("Присутствовал"), i18n.T("Не полностью"), i18n.T("Опозданий"), i18n.T("Пропусков"))
+	if err := csvWriter.WriteAll([][]string{dates, titles}); err != nil {
+		return fmt.Errorf("ошибка записи журнала посещаемости: %w", err)
+	}
+
+	for _, student := range rows {
+		row := []string{student.group, student.fullName}
+		if recordBooks {
+			row = append(row, student.recordBook)
+		}
+		for _, meeting := range meetings {
+			row = append(row, student.marks[meeting.MeetingID])
+		}
+		row = append(row, strconv.Itoa(student.present), strconv.Itoa(student.partial), strconv.Itoa(student.late),
+			strconv.Itoa(student.missed))
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("ошибка записи журнала посещаемости: %w", err)
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("ошибка записи журнала посещаемости: %w", err)
+	}
+
+	return nil
+}
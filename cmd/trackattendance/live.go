@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"mod.go/config"
+	"mod.go/graph"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/teamsreport"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunLive Функция команды live, которая во время собрания периодически запрашивает в Microsoft Graph участников
+// текущего сеанса и выводит, кто из студентов групп собрания сейчас находится на нём, а кто отсутствует
+func RunLive(ctx context.Context, arguments []string, configuration config.Configuration, base roster.Base) error {
+	//Флаг команды: период обновления списка участников
+	flags := flag.NewFlagSet("live", flag.ContinueOnError)
+	interval := flags.Duration("interval", time.Minute, "период обновления списка участников собрания")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+
+	if !configuration.Graph.Enabled {
+		return fmt.Errorf("для наблюдения за собранием включите загрузку через Microsoft Graph в секции [graph] cfg.ini")
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("период обновления должен быть положительным: %v", *interval)
+	}
+
+	//Токен доступа запрашивается один раз на всё время наблюдения
+	token, err := graph.RequestToken(ctx, configuration.Graph)
+	if err != nil {
+		return err
+	}
+
+	//Освобождения от посещения пар не изменяются во время наблюдения и считываются один раз
+	exemptions, err := roster.LoadExemptions(configuration.ExemptionsPath)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	//Выводим список участников сразу и далее с заданным периодом, пока программа не будет прервана (Ctrl+C)
+	for {
+		if err := ShowLiveAttendance(ctx, configuration, base, exemptions, token); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ShowLiveAttendance Функция, выводящая таблицу присутствующих и отсутствующих студентов текущего сеанса собрания.
+// Студенты, освобождённые сегодня от посещения пар (exemptions), не считаются отсутствующими
+func ShowLiveAttendance(ctx context.Context, configuration config.Configuration, base roster.Base,
+	exemptions []roster.Exemption, token string) error {
+	meeting, records, err := graph.FetchLiveRecords(ctx, configuration.Graph, token)
+	if errors.Is(err, graph.ErrNoReports) {
+		fmt.Printf(i18n.T("%v: отчёт о посещаемости текущего собрания ещё не сформирован\n"), time.Now().Format("15:04:05"))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	//Массив участников, находящихся на собрании сейчас
+	var members []report.Member
+
+	for _, record := range records {
+		//Организатор собрания (преподаватель) и вышедшие с собрания участники пропускаются
+		if teamsreport.IsStaffRole(record.Role, teamsreport.English) || !record.Present() {
+			continue
+		}
+
+		//Приводим имя участника к виду ФИО с помощью функции ParseFullName() (или берём ФИО из базы групп по почте),
+		// преподаватели из базы групп пропускаются
+		fullName, group, ok := teamsreport.ParseFullName(record.Identity.DisplayName, teamsreport.Russian)
+		if baseName, found := roster.FindByEmail(record.EmailAddress); found {
+			fullName, group, ok = baseName, "", true
+		} else if baseName, found := roster.ResolveName(fullName); ok && found {
+			fullName, group = baseName, ""
+		}
+		if !ok || base.IsTeacher(fullName) {
+			continue
+		}
+
+		//Группа из имени, которой нет в базе групп (вероятно, опечатка), не учитывается
+		fullName, group, _ = teamsreport.CheckNameGroup(base, fullName, group)
+
+		//ФИО, указанное латиницей или без отчества, берётся из базы групп, если в ней есть подходящий человек
+		if group == "" {
+			if baseName, found := base.FindName(fullName); found {
+				fullName = baseName
+			}
+		}
+
+		//Если группа не указана в имени, устанавливаем её по базе групп
+		if group == "" {
+			group = base.SetGroup(fullName)
+		}
+
+		members = append(members, report.Member{Group: group, FullName: fullName, Email: record.EmailAddress,
+			Presence: report.PresenceFull})
+	}
+
+	//Применяем способ обработки гостей, гости, выводимые отдельно, выводятся в конце таблицы
+	members, guests := base.ApplyGuestPolicy(members, configuration.GuestPolicy, configuration.GuestMatchDistance)
+
+	//Дополняем список студентами групп собрания (и групп дисциплины собрания), которых сейчас нет на собрании
+	course, _ := roster.FindCourse(meeting.Subject, "")
+	if members, err = roster.FillLostMembers(ctx, base, members, time.Now(), course.Groups...); err != nil {
+		return err
+	}
+
+	//Студенты, освобождённые сегодня от посещения пар, не считаются отсутствующими
+	members = roster.ExcludeExempt(members, exemptions, time.Now())
+
+	report.SortMembers(members)
+	report.SortMembers(guests)
+	members = append(members, guests...)
+
+	//Выводим таблицу с выравниванием столбцов
+	fmt.Printf(i18n.T("\n%v, обновлено в %v\n"), meeting.Subject, time.Now().Format("15:04:05"))
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, i18n.T("Группа\tФИО\tСейчас"))
+
+	present, missing := 0, 0
+	for _, member := range members {
+		status := i18n.T("на собрании")
+		if member.Presence.IsAbsent() {
+			status = i18n.T("ОТСУТСТВУЕТ")
+			missing++
+		} else {
+			present++
+		}
+		fmt.Fprintf(writer, "%v\t%v\t%v\n", member.Group, member.FullName, status)
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf(i18n.T("На собрании: %d, отсутствуют: %d\n"), present, missing)
+
+	return nil
+}
@@ -0,0 +1,469 @@
+// Команда trackattendance формирует отчёт о посещаемости собрания MS Teams по последнему отчёту из директории загрузок
+// (или по отчётам, загруженным из Microsoft Graph, или по отчётам, указанным явно) и базе групп GroupsBase.csv
+//
+// Использование:
+//
+//	trackattendance [--config cfg.ini] [--output каталог|-] [--format csv,xlsx,json,html,template] [--signin явка.csv] [--lms-log журнал_moodle.csv] [--signup запись.csv] [--exam варианты.csv] [--only absent,late] [--only-present] [--report-to-stdout-summary] [--dry-run] [--verbose] [--quiet] [--force] [--input отчёт.csv] [отчёт.csv ...]
+//	trackattendance [--config cfg.ini] [--output каталог] merge отчёт1.csv отчёт2.csv [...]
+//	trackattendance [--config cfg.ini] stats --student "Иванов Иван" | --group МП-51 [--output table|csv|tsv|json]
+//	trackattendance [--config cfg.ini] [--output каталог] journal --from 01.09.2022 [--to 31.12.2022] [--group МП-51] [--title Математика] [--output файл|-] [--format csv|tsv|json]
+//	trackattendance [--config cfg.ini] [--output каталог] workload --from 01.09.2022 [--to 31.12.2022] [--teacher Петров] [--output файл|-] [--format csv|tsv|json]
+//	trackattendance [--config cfg.ini] digest [--month 04.2022] [--group МП-51] [--output text|csv|tsv|json] [--send]
+//	trackattendance [--config cfg.ini] live [--interval 1m]
+//	trackattendance [--config cfg.ini] aliases learn [--min-meetings 3] [--yes]
+//	trackattendance [--config cfg.ini] serve [--address 127.0.0.1:8080] [--port 8080]
+//	trackattendance [--config cfg.ini] [--output каталог] [--format csv,json] api [--address 127.0.0.1:8081] [--port 8081] [--token ключ] [--max-size 32]
+//	trackattendance [--config cfg.ini] [--output каталог] config show [--effective]
+//	trackattendance [--config cfg.ini] finalize --month 04.2022 | --from 01.04.2022 [--to 30.04.2022] [--title Математика]
+//	trackattendance [--config cfg.ini] semester new --name 2024-осень [--archive archive] [--schedule 08:30-10:00,...]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/fs"
+	"log/slog"
+	"mod.go/audit"
+	"mod.go/config"
+	"mod.go/graph"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/logging"
+	"mod.go/pipeline"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/teamsreport"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+func main() {
+	//Флаги командной строки: файл конфигураций, каталог итоговых отчётов и отчёт MS Teams для обработки
+	configPath := flag.String("config", "cfg.ini", "путь до файла конфигураций")
+	output := flag.String("output", "", "каталог, в который сохраняются итоговые отчёты (вместо report_location_folder)")
+	format := flag.String("format", "", "форматы итогового отчёта через запятую: csv, xlsx, json, html или template "+
+		"(вместо formats из конфигураций)")
+	signIn := flag.String("signin", "", "лист присутствия в аудитории (.csv с ФИО) для гибридного занятия")
+	lmsLog := flag.String("lms-log", "", "выгрузка журнала событий Moodle (.csv) для пометки отсутствовавших студентов, активных в СДО во время пары")
+	signUp := flag.String("signup", "", "список записавшихся на консультацию (.csv с ФИО) для сравнения с участниками консультации")
+	exam := flag.String("exam", "", "список вариантов экзамена (.csv: ФИО, вариант, ЧЧ:ММ-ЧЧ:ММ) для проверки присутствия студентов в назначенное время")
+	only := flag.String("only", "", "сформировать рядом с полным отчётом сокращённый отчёт только с участниками с пометками через запятую: absent, excused, late, partial, early, present (например, absent,late)")
+	onlyPresent := flag.Bool("only-present", false, "выводить в отчёте только участников собрания, без отсутствующих студентов")
+	stdoutSummary := flag.Bool("report-to-stdout-summary", false, "выводить краткую сводку каждого собрания в стандартный вывод (для писем cron)")
+	input := flag.String("input", "", "отчёт MS Teams для обработки (вместо последнего отчёта из директории загрузок)")
+	dryRun := flag.Bool("dry-run", false, "разобрать отчёт и вывести найденное (собрание, пару, группы, отсутствующих) без записи файлов")
+	verbose := flag.Bool("verbose", false, "выводить сведения о разборе каждой строки отчёта")
+	force := flag.Bool("force", false, "изменить собрание, закрытое командой finalize (изменение записывается в журнал действий)")
+	quiet := flag.Bool("quiet", false, "выводить в стандартный поток ошибок только предупреждения и ошибки (для запуска по расписанию)")
+	flag.Parse()
+
+	//При первом запуске из терминала (файла конфигураций ещё нет) задаём вопросы об основных настройках и создаём файл
+	// конфигураций, чтобы его не приходилось редактировать вручную
+	if _, err := os.Stat(*configPath); errors.Is(err, fs.ErrNotExist) && IsTerminal(os.Stdin) {
+		if err := RunWizard(*configPath, os.Stdin, os.Stdout); err != nil {
+			logging.Fatal("Ошибка создания файла конфигураций", "error", err)
+		}
+	}
+
+	//Считываем конфигурации путей до загрузок, пути сохранения отчёта, расписания и Microsoft Graph
+	configuration, err := config.Load(*configPath)
+	if err != nil {
+		logging.Fatal("Ошибка чтения конфигураций", "error", err)
+	}
+
+	//Сообщения программы записываются в журнал сообщений: в стандартный поток ошибок и в файл журнала из конфигураций.
+	// Разбор строк отчёта при подробном выводе записывается сообщениями уровня debug
+	if *verbose {
+		configuration.Log.Level = min(configuration.Log.Level, slog.LevelDebug)
+	}
+	if err := logging.Setup(configuration.Log, *quiet); err != nil {
+		logging.Fatal("Ошибка открытия журнала сообщений", "error", err)
+	}
+	defer logging.Close()
+
+	//Итоговые отчёты и сообщения программы выводятся на языке из конфигураций
+	i18n.Language = configuration.Language
+	report.ColumnLabels = configuration.Columns
+	report.LessonLabels = configuration.Schedule.LessonLabels()
+
+	//Группа выделяется из имени участника собрания по шаблонам групп из конфигураций
+	teamsreport.GroupPatterns = configuration.GroupPatterns
+	teamsreport.StrictParsing = configuration.StrictParsing
+	teamsreport.StaffRoles = configuration.StaffRoles
+	teamsreport.ReadLimits = configuration.Limits
+
+	//Прежние названия переименованных групп считаются той же группой в отчётах и истории
+	roster.GroupAliases = configuration.GroupAliases
+
+	//Группа из имени участника принимается, только если она есть в базе групп или в списке разрешённых групп
+	roster.MinGroupSize, roster.AllowedGroups = configuration.MinGroupSize, configuration.AllowedGroups
+
+	//Дисциплина собрания определяется по его названию
+	roster.Courses = configuration.Courses
+
+	//Отчёты из Microsoft Graph записываются в часовом поясе отчётов, чтобы при чтении время переводилось так же, как у
+	// загруженных вручную
+	switch {
+	case configuration.Schedule.ReportTimeZone != nil:
+		graph.TimeZone = configuration.Schedule.ReportTimeZone
+	case configuration.Schedule.TimeZone != nil:
+		graph.TimeZone = configuration.Schedule.TimeZone
+	}
+
+	//Отчёты загружаются с адресов Microsoft Graph из конфигураций, если они указаны (например, с имитации Microsoft Graph)
+	if configuration.Graph.Endpoint != "" {
+		graph.Endpoint = configuration.Graph.Endpoint
+	}
+	if configuration.Graph.LoginEndpoint != "" {
+		graph.LoginEndpoint = configuration.Graph.LoginEndpoint
+	}
+
+	//Лист присутствия в аудитории объединяется с отчётом MS Teams в один отчёт гибридного занятия
+	configuration.SignInPath = *signIn
+
+	//Отсутствовавшие студенты, активные в СДО во время пары, помечаются в отчёте (возможно, проблемы с MS Teams)
+	configuration.LMSLogPath = *lmsLog
+
+	//Участники консультации сравниваются со списком записавшихся: кто пришёл, кто не пришёл и кто пришёл без записи
+	configuration.SignUpPath = *signUp
+
+	//Присутствие студентов на экзамене проверяется по назначенному каждому студенту варианту и времени
+	configuration.ExamPath = *exam
+
+	//Краткая сводка собраний выводится в стандартный вывод, который cron отправляет администратору по почте
+	configuration.StdoutSummary = *stdoutSummary
+	if configuration.StdoutSummary && *output == "-" {
+		logging.Fatal(i18n.T("Ошибка чтения флагов: --output - и --report-to-stdout-summary выводят в стандартный вывод одновременно"))
+	}
+
+	//При пробном запуске не записывается ни один файл: журнал действий и история не ведутся, база групп не обновляется
+	// из источника, а отчёты не загружаются из Microsoft Graph (разбирается последний отчёт из директории загрузок)
+	configuration.DryRun, configuration.Verbose, configuration.Force = *dryRun, *verbose, *force
+	if configuration.DryRun {
+		configuration.Audit.Enabled, configuration.History.Enabled, configuration.Graph.Enabled = false, false, false
+		configuration.GroupsBaseSource = ""
+	}
+
+	//Для необязательных занятий (открытых лекций, мероприятий) отсутствующие студенты в отчёт не добавляются
+	if *onlyPresent {
+		configuration.OnlyPresent = true
+	}
+
+	//Сокращённый отчёт с проблемными студентами для кураторов формируется рядом с полным отчётом для архива
+	if *only != "" {
+		if configuration.Only, err = report.ParseStatusFilter(*only); err != nil {
+			logging.Fatal(i18n.T("Ошибка чтения пометок сокращённого отчёта"), "error", err)
+		}
+	}
+
+	//Форматы итогового отчёта из командной строки заменяют форматы из конфигураций
+	if *format != "" {
+		if configuration.Formats, err = report.ParseFormats(*format); err != nil {
+			logging.Fatal(i18n.T("Ошибка чтения формата отчёта"), "error", err)
+		}
+	}
+
+	//Для отчёта по шаблону считываем шаблон пользователя
+	if slices.Contains(configuration.Formats, report.FormatTemplate) {
+		if configuration.TemplatePath == "" {
+			logging.Fatal(i18n.T("Ошибка чтения шаблона отчёта: не указан template_path в секции [report]"))
+		}
+		if report.Template, report.TemplateExtension, err = report.LoadTemplate(configuration.TemplatePath); err != nil {
+			logging.Fatal(i18n.T("Ошибка чтения шаблона отчёта"), "error", err)
+		}
+	}
+
+	//Пароль книги .xlsx берётся из конфигураций или командой из хранилища паролей системы. Пароль считывается, даже если
+	// итоговый отчёт не формируется в виде книги .xlsx, так как книги выгружаются и из веб-панели
+	report.XLSXPassword, err = config.ReadSecret(context.Background(), configuration.XLSXPassword,
+		configuration.XLSXPasswordCommand)
+	if err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения пароля книги .xlsx"), "error", err)
+	}
+
+	//Каталог итоговых отчётов из командной строки заменяет каталог из конфигураций. Каталог "-" означает вывод отчёта
+	// в формате JSON в стандартный вывод, остальные файлы (.html страница, сводки, статистика) при этом не формируются
+	switch {
+	case *output == "-":
+		configuration.Formats = slices.DeleteFunc(configuration.Formats, func(format string) bool {
+			return format == report.FormatHTML
+		})
+		if !slices.Equal(configuration.Formats, []string{report.FormatJSON}) {
+			logging.Fatal(i18n.T("Ошибка чтения каталога отчётов: в стандартный вывод отчёт выводится только с --format json"))
+		}
+		configuration.ReportLocationPath = *output
+		configuration.Badge, configuration.PlatformStats = false, false
+		configuration.Email.SendReport, configuration.Notify.TelegramAttachReport = false, false
+	case *output != "":
+		configuration.ReportLocationPath = *output
+		if !strings.HasSuffix(*output, "/") && !strings.HasSuffix(*output, string(os.PathSeparator)) {
+			configuration.ReportLocationPath += string(os.PathSeparator)
+		}
+	}
+
+	//Открываем журнал действий и записываем в него запуск программы. Если программа завершится с ошибкой, запись
+	// о завершении в журнал не попадёт
+	journal, err := audit.Open(configuration.Audit, os.Args)
+	if err != nil {
+		logging.Fatal(i18n.T("Ошибка открытия журнала действий"), "error", err)
+	}
+	defer func() {
+		if err := journal.Close(); err != nil {
+			slog.Warn(i18n.T("Ошибка закрытия журнала действий"), "error", err)
+		}
+	}()
+
+	//Аргументы после флагов: команда (stats, live, merge) или отчёты для обработки
+	arguments := flag.Args()
+
+	//Контекст выполнения отменяется при прерывании программы (Ctrl+C), что позволяет корректно остановить обработку
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	//Команда stats выводит накопленную посещаемость из истории и не обрабатывает отчёты
+	if len(arguments) > 0 && arguments[0] == "stats" {
+		if err := RunStats(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды stats"), "error", err)
+		}
+		return
+	}
+
+	//Команда journal формирует журнал посещаемости за период из истории и не обрабатывает отчёты
+	if len(arguments) > 0 && arguments[0] == "journal" {
+		if err := RunJournal(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды journal"), "error", err)
+		}
+		return
+	}
+
+	//Команда workload формирует выгрузку нагрузки преподавателей по месяцам из истории и не обрабатывает отчёты
+	if len(arguments) > 0 && arguments[0] == "workload" {
+		if err := RunWorkload(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды workload"), "error", err)
+		}
+		return
+	}
+
+	//Команда digest формирует сводку посещаемости групп за месяц с продвижением к целям посещаемости
+	if len(arguments) > 0 && arguments[0] == "digest" {
+		if err := RunDigest(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды digest"), "error", err)
+		}
+		return
+	}
+
+	//Команда serve запускает веб-панель посещаемости и принимает запросы GraphQL к истории посещаемости
+	if len(arguments) > 0 && arguments[0] == "serve" {
+		if err := RunServe(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды serve"), "error", err)
+		}
+		return
+	}
+
+	//Команда config show выводит файл конфигураций или итоговые конфигурации с таблицей расписания пар
+	if len(arguments) > 0 && arguments[0] == "config" {
+		if err := RunConfig(arguments[1:], *configPath, configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды config"), "error", err)
+		}
+		return
+	}
+
+	//Команда finalize закрывает собрания за месяц или период: они не изменяются повторной обработкой без флага --force
+	if len(arguments) > 0 && arguments[0] == "finalize" {
+		if err := RunFinalize(ctx, arguments[1:], configuration, journal); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды finalize"), "error", err)
+		}
+		return
+	}
+
+	//Команда semester new переносит историю и файлы прошедшего семестра в архив и начинает новый семестр
+	if len(arguments) > 0 && arguments[0] == "semester" {
+		if err := RunSemester(ctx, arguments[1:], *configPath, configuration, journal); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды semester"), "error", err)
+		}
+		return
+	}
+
+	//Команда view открывает сформированный отчёт или базу истории для просмотра в браузере только для чтения
+	if len(arguments) > 0 && arguments[0] == "view" {
+		if err := RunView(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды view"), "error", err)
+		}
+		return
+	}
+
+	//Команда mock-graph запускает имитацию Microsoft Graph для проверки загрузки отчётов без доступа к тенанту
+	if len(arguments) > 0 && arguments[0] == "mock-graph" {
+		if err := RunMockGraph(ctx, arguments[1:], configuration); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды mock-graph"), "error", err)
+		}
+		return
+	}
+
+	//Обновляем базу групп из источника, указанного в конфигурациях. Если источник недоступен, используется сохранённая
+	// копия базы
+	if err := roster.Sync(ctx, configuration.GroupsBaseSource); err != nil {
+		if _, statErr := os.Stat(roster.BasePath); statErr != nil {
+			logging.Fatal(i18n.T("Ошибка обновления базы групп"), "error", err)
+		}
+		slog.Warn(i18n.T("Не удалось обновить базу групп, используется сохранённая копия"), "path", roster.BasePath,
+			"error", err)
+	} else if configuration.GroupsBaseSource != "" {
+		if err := journal.Write(roster.BasePath); err != nil {
+			logging.Fatal(i18n.T("Ошибка записи в журнал действий"), "error", err)
+		}
+	}
+
+	//Считываем базу групп один раз для всех отчётов
+	base, err := roster.LoadBase(roster.BasePath)
+	if err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения базы групп"), "error", err)
+	}
+	if roster.RecordBooks, err = roster.LoadRecordBooks(roster.BasePath); err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения базы групп"), "error", err)
+	}
+	if roster.Emails, err = roster.LoadEmails(roster.BasePath); err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения базы групп"), "error", err)
+	}
+	if roster.BaseMetadata, err = roster.LoadMetadata(roster.BasePath); err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения базы групп"), "error", err)
+	}
+	if roster.Staff, err = roster.LoadStaff(configuration.StaffPath); err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения файла преподавателей"), "error", err)
+	}
+	if roster.NameAliases, err = roster.LoadNameAliases(configuration.NameAliasesPath); err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения файла псевдонимов"), "error", err)
+	}
+	if roster.Excuses, err = roster.LoadExcuses(configuration.ExcusesPath); err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения файла уважительных причин"), "error", err)
+	}
+
+	//Команда aliases learn дописывает в файл псевдонимов гостей, которые в истории всегда сопоставлялись с одним
+	// студентом базы
+	if len(arguments) > 0 && arguments[0] == "aliases" {
+		if err := RunAliases(ctx, arguments[1:], configuration, base, journal); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды aliases"), "error", err)
+		}
+		return
+	}
+
+	//Команда live во время собрания выводит присутствующих и отсутствующих студентов, обновляя список каждую минуту
+	if len(arguments) > 0 && arguments[0] == "live" {
+		if err := RunLive(ctx, arguments[1:], configuration, base); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды live"), "error", err)
+		}
+		return
+	}
+
+	//Если включена история посещаемости, открываем её базу
+	var store *history.Store
+	if configuration.History.Enabled {
+		if store, err = history.Open(ctx, configuration.History.Path); err != nil {
+			logging.Fatal(i18n.T("Ошибка открытия истории посещаемости"), "error", err)
+		}
+		defer store.Close()
+	}
+
+	//Собрания сопоставляются с событиями календаря организатора, если это включено в конфигурациях
+	if configuration.Graph.Enabled && configuration.Graph.CalendarCheck {
+		configuration.Schedule.Calendar = CalendarLookup(ctx, configuration.Graph)
+	}
+
+	//Команда api принимает отчёты MS Teams по HTTP и возвращает итоговые отчёты (для портала факультета)
+	if len(arguments) > 0 && arguments[0] == "api" {
+		if err := RunAPI(ctx, arguments[1:], configuration, base, store, journal); err != nil {
+			logging.Fatal(i18n.T("Ошибка команды api"), "error", err)
+		}
+		return
+	}
+
+	//Общие данные обработки (освобождения и файлы, указанные флагами) считываются один раз для всех отчётов
+	runtime, err := pipeline.NewRuntime(configuration, base)
+	if err != nil {
+		logging.Fatal(i18n.T("Ошибка чтения данных обработки отчётов"), "error", err)
+	}
+
+	//Команда merge объединяет несколько отчётов одного собрания (например, пары, прерванной и продолженной в новом
+	// собрании) в один итоговый отчёт
+	if len(arguments) > 0 && arguments[0] == "merge" {
+		if len(arguments) < 3 {
+			logging.Fatal(i18n.T("Ошибка команды merge: необходимо указать не менее двух отчётов собрания"))
+		}
+		err := runtime.Process(ctx, arguments[1:], store, journal)
+		if errors.Is(err, pipeline.ErrTechnicalCall) || errors.Is(err, pipeline.ErrReportExists) ||
+			errors.Is(err, pipeline.ErrFinalized) || errors.Is(err, teamsreport.ErrLimitExceeded) {
+			slog.Info(i18n.T("Отчёт пропущен"), "report", strings.Join(arguments[1:], ", "), "reason", i18n.T(err.Error()))
+		} else if err != nil {
+			logging.Fatal(i18n.T("Ошибка объединения отчётов"), "reports", strings.Join(arguments[1:], ", "), "error", err)
+		}
+		if err := runtime.FlushNotifications(ctx); err != nil {
+			logging.Fatal(i18n.T("Ошибка отправки общей сводки оповещений"), "error", err)
+		}
+		return
+	}
+
+	//Массив отчётов, которые необходимо обработать: указанные в командной строке явно
+	reports := arguments
+	if *input != "" {
+		reports = append([]string{*input}, reports...)
+	}
+
+	//Если отчёты не указаны явно и включена загрузка через Microsoft Graph, загружаем отчёты о посещаемости в каталог
+	// загрузок, иначе обрабатываем последний отчёт, загруженный вручную
+	switch {
+	case len(reports) > 0:
+	case configuration.Graph.Enabled:
+		if reports, err = graph.FetchReports(ctx, configuration.Graph, configuration.DownloadFolderPath); err != nil {
+			logging.Fatal(i18n.T("Ошибка загрузки отчётов из Microsoft Graph"), "error", err)
+		}
+		for _, fetchedReport := range reports {
+			if err := journal.Write(fetchedReport); err != nil {
+				logging.Fatal(i18n.T("Ошибка записи в журнал действий"), "error", err)
+			}
+		}
+	default:
+		//Находим текущий отчёт с помощью функции FindCurrentReport()
+		currentReport, err := teamsreport.FindCurrentReport(ctx, configuration.DownloadFolderPath)
+		if err != nil {
+			logging.Fatal(i18n.T("Ошибка поиска отчёта"), "error", err)
+		}
+		reports = append(reports, currentReport)
+	}
+
+	//Отчёты пересозданных собраний (после обрыва связи) объединяются в одно собрание, если это включено в конфигурациях
+	meetings := make([][]string, 0, len(reports))
+	if configuration.MergeRecreated && len(reports) > 1 {
+		meetings = runtime.GroupRecreated(ctx, reports)
+	} else {
+		for _, currentReport := range reports {
+			meetings = append(meetings, []string{currentReport})
+		}
+	}
+
+	//Обрабатываем каждое собрание с помощью функции Process() с общими данными обработки
+	for _, paths := range meetings {
+		currentReport := strings.Join(paths, ", ")
+		if len(paths) > 1 {
+			slog.Info(i18n.T("Отчёты пересозданного собрания объединены"), "report", currentReport)
+		}
+		err := runtime.Process(ctx, paths, store, journal)
+		if errors.Is(err, pipeline.ErrTechnicalCall) || errors.Is(err, pipeline.ErrReportExists) ||
+			errors.Is(err, pipeline.ErrFinalized) || errors.Is(err, teamsreport.ErrLimitExceeded) {
+			slog.Info(i18n.T("Отчёт пропущен"), "report", currentReport, "reason", i18n.T(err.Error()))
+		} else if err != nil {
+			logging.Fatal(i18n.T("Ошибка обработки отчёта"), "report", currentReport, "error", err)
+		} else {
+			slog.Info(i18n.T("Отчёт обработан"), "report", currentReport)
+		}
+	}
+
+	//Оповещения всех обработанных собраний отправляются общей сводкой, если она включена в конфигурациях
+	if err := runtime.FlushNotifications(ctx); err != nil {
+		logging.Fatal(i18n.T("Ошибка отправки общей сводки оповещений"), "error", err)
+	}
+}
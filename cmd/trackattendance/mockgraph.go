@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"mod.go/config"
+	"mod.go/graph"
+	"mod.go/i18n"
+	"mod.go/roster"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunMockGraph Функция команды mock-graph, запускающая имитацию Microsoft Graph с заготовленными собраниями и отчётами
+// о посещаемости, чтобы администратор мог проверить загрузку отчётов через Microsoft Graph и всю их обработку до
+// получения доступа к настоящему тенанту. На имитации сегодня проходят первая пара расписания со студентами из базы
+// групп и консультация из двух сеансов. Вход любым способом авторизации подтверждается сразу. Сервер работает до
+// прерывания программы
+func RunMockGraph(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаги команды: адрес имитации и количество студентов из базы групп на заготовленной паре
+	flags := flag.NewFlagSet("mock-graph", flag.ContinueOnError)
+	address := flags.String("address", "127.0.0.1:8089", "адрес, на котором принимаются запросы")
+	count := flags.Int("students", 10, "количество студентов из базы групп на заготовленной паре")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if len(configuration.Schedule.Lessons) == 0 {
+		return fmt.Errorf("в файле конфигураций не указано расписание пар")
+	}
+
+	//Студенты заготовленной пары берутся из базы групп по алфавиту, чтобы отчёт сопоставлялся с группами
+	base, err := roster.LoadBase(roster.BasePath)
+	if err != nil {
+		return err
+	}
+	students := make([]string, 0, len(base))
+	for fullName := range base {
+		if !base.IsTeacher(fullName) {
+			students = append(students, fullName)
+		}
+	}
+	sort.Strings(students)
+	if len(students) > *count {
+		students = students[:*count]
+	}
+
+	//MS Teams выводит имена участников в виде ИОФ
+	for i, fullName := range students {
+		if words := strings.Fields(fullName); len(words) == 3 {
+			students[i] = words[1] + " " + words[2] + " " + words[0]
+		}
+	}
+
+	//Заготовленная пара проходит сегодня во время первой пары расписания в часовом поясе аудитории, а консультация -
+	// через час после окончания последней пары
+	location := time.Local
+	if configuration.Schedule.TimeZone != nil {
+		location = configuration.Schedule.TimeZone
+	}
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	lessons := configuration.Schedule.ForDate(today).Lessons
+	at := func(seconds int) time.Time { return today.Add(time.Duration(seconds) * time.Second) }
+	meetings := graph.MockMeetings(at(lessons[0].Start), at(lessons[0].End), at(lessons[len(lessons)-1].End+60*60),
+		students)
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия адреса %v: %w", *address, err)
+	}
+	server := &http.Server{Handler: graph.MockHandler(meetings), ReadHeaderTimeout: 10 * time.Second}
+
+	//Останавливаем сервер при прерывании программы
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	slog.Info(i18n.T("Имитация Microsoft Graph доступна"), "address", "http://"+listener.Addr().String()+"/")
+	fmt.Printf(i18n.T("Для проверки укажите в секции [graph] cfg.ini и запустите программу в другом окне:")+
+		"\n\nenabled=true\ntenant_id=mock\nclient_id=mock\nuser_id=organizer@example.com\ndate_from=%v\n"+
+		"endpoint=http://%v/v1.0/\nlogin_endpoint=http://%v/\n\n", today.Format("02.01.2006"), listener.Addr(),
+		listener.Addr())
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("ошибка работы сервера: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/audit"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/roster"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunSemester Функция команды semester new, переводящая программу на новый семестр: история посещаемости, файл
+// освобождений и журнал отправленных оповещений переносятся в архив прошедшего семестра, база групп, файл кураторов,
+// файл целей и файл конфигураций (с переименованиями групп) копируются в архив и остаются для нового семестра. Затем
+// создаётся пустая история, в базу групп записывается новый семестр и, если указано, заменяется расписание пар
+func RunSemester(ctx context.Context, arguments []string, configPath string, configuration config.Configuration,
+	journal *audit.Log) error {
+	if len(arguments) == 0 || arguments[0] != "new" {
+		return fmt.Errorf("неизвестная команда, используйте: semester new --name 2024-осень [--archive каталог] " +
+			"[--schedule 08:30-10:00,...]")
+	}
+
+	//Флаги команды: название нового семестра, каталог архива и новое расписание пар
+	flags := flag.NewFlagSet("semester new", flag.ContinueOnError)
+	name := flags.String("name", "", "название нового семестра (например, 2024-осень)")
+	archive := flags.String("archive", "archive", "каталог, в котором сохраняются архивы прошедших семестров")
+	lessons := flags.String("schedule", "", "новое расписание пар в виде ЧЧ:ММ-ЧЧ:ММ через запятую (по-умолчанию не меняется)")
+	if err := flags.Parse(arguments[1:]); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*name) == "" {
+		return fmt.Errorf("необходимо указать название нового семестра: semester new --name 2024-осень")
+	}
+	if *lessons != "" {
+		if err := config.CheckLessons(*lessons); err != nil {
+			return err
+		}
+	}
+
+	//Архив называется по семестру из базы групп, а если он не указан - по дате перехода на новый семестр
+	metadata, err := roster.LoadMetadata(roster.BasePath)
+	if err != nil {
+		return err
+	}
+	previous := metadata.Semester
+	if previous == "" {
+		previous = time.Now().Format("2006-01-02")
+	}
+	if previous == *name {
+		return fmt.Errorf("база групп уже относится к семестру %v", *name)
+	}
+	archivePath := filepath.Join(*archive, previous)
+	if _, err := os.Stat(archivePath); err == nil {
+		return fmt.Errorf("архив семестра %v уже существует: %v", previous, archivePath)
+	}
+	if err := os.MkdirAll(archivePath, 0755); err != nil {
+		return fmt.Errorf("ошибка создания каталога архива: %w", err)
+	}
+
+	//Файлы, которые остаются для нового семестра, копируются в архив
+	for _, path := range []string{configPath, roster.BasePath, configuration.CuratorsPath, configuration.GoalsPath} {
+		if err := archiveFile(path, archivePath, false); err != nil {
+			return err
+		}
+	}
+
+	//Файлы прошедшего семестра переносятся в архив (вместе со служебными файлами базы SQLite, если они есть)
+	for _, path := range []string{configuration.History.Path, configuration.History.Path + "-wal",
+		configuration.History.Path + "-shm", configuration.ExemptionsPath, configuration.ExcusesPath,
+		configuration.SentNotificationsPath} {
+		if err := archiveFile(path, archivePath, true); err != nil {
+			return err
+		}
+	}
+	fmt.Printf(i18n.T("Семестр %v перенесён в архив %v")+"\n", previous, archivePath)
+
+	//Создаём пустую историю посещаемости нового семестра
+	if configuration.History.Enabled {
+		store, err := history.Open(ctx, configuration.History.Path)
+		if err != nil {
+			return err
+		}
+		if err := store.Close(); err != nil {
+			return err
+		}
+		if err := journal.Write(configuration.History.Path); err != nil {
+			return err
+		}
+	}
+
+	//Записываем новый семестр в базу групп, чтобы он указывался в отчётах
+	if err := roster.SetSemester(roster.BasePath, *name); err != nil {
+		return err
+	}
+	if err := journal.Write(roster.BasePath); err != nil {
+		return err
+	}
+
+	//Заменяем расписание пар в файле конфигураций
+	if *lessons != "" {
+		if err := config.WriteLessons(configPath, *lessons); err != nil {
+			return err
+		}
+		if err := journal.Write(configPath); err != nil {
+			return err
+		}
+		fmt.Printf(i18n.T("Расписание пар заменено: %v")+"\n", *lessons)
+	}
+	fmt.Printf(i18n.T("Начат семестр %v")+"\n", *name)
+
+	return nil
+}
+
+// archiveFile Вспомогательная функция, копирующая или переносящая файл в каталог архива. Отсутствующий файл
+// пропускается
+func archiveFile(path, archivePath string, move bool) error {
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	target := filepath.Join(archivePath, filepath.Base(path))
+
+	//Файл переносится переименованием, а если каталог архива на другом диске - копированием с удалением
+	if move && os.Rename(path, target) == nil {
+		return nil
+	}
+	if err := copyFile(path, target); err != nil {
+		return err
+	}
+	if move {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("ошибка удаления файла %v после переноса в архив: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// copyFile Вспомогательная функция, копирующая содержимое файла
+func copyFile(path, target string) (err error) {
+	source, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла %v: %w", path, err)
+	}
+	defer source.Close()
+
+	file, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла архива: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка закрытия файла архива: %w", closeErr)
+		}
+	}()
+
+	if _, err := io.Copy(file, source); err != nil {
+		return fmt.Errorf("ошибка копирования файла %v в архив: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"mod.go/config"
+	"mod.go/graphql"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunServe Функция команды serve, запускающая HTTP сервер с веб-панелью посещаемости, запросами GraphQL к базе истории
+// (адрес /graphql) для сторонних интерфейсов и лентой отметок для Power BI. Сервер работает до прерывания программы.
+// Страницы веб-панели:
+//
+//	/ - собрания и посещаемость групп за период (параметры from, to, group)
+//	/meeting?id= - отметки участников собрания
+//	/student?name= - посещаемость студента по семестрам и отметки на собраниях
+//	/reports/{id}/{номер} - сформированные отчёты собрания, записанные в историю (ссылки выводятся на странице
+//	  собрания)
+//
+// Каждая страница выгружается в .csv или .xlsx с параметром format=csv или format=xlsx. Если задан пароль книг .xlsx,
+// выгружаются и раздаются только зашифрованные книги .xlsx. Если задан ключ доступа, все адреса требуют заголовок
+// Authorization: Bearer. Схема запросов GraphQL:
+//
+//	meetings(from: String, to: String, group: String, title: String): [Meeting] - собрания за период
+//	  Meeting: id, title, date, lesson, expected, present, partial, late, absent,
+//	    participants(group: String, absent: Boolean): [Participant]
+//	  Participant: fullName, id, recordBook, group, presence, delay, isPresent, isLate
+//	students(group: String, student: String): [Student] - накопленная посещаемость по семестрам
+//	  Student: semester, fullName, id, recordBook, group, lessons, present, partial, late, missed
+//	groups(from: String, to: String, group: String): [Group] - посещаемость групп за период
+//	  Group: name, meetings, expected, present, partial, late, absent, attendanceRate
+//
+// Лента отметок GET /feed?from=&to=&group=&skip=&top= возвращает отметки студентов постранично (по-умолчанию по 1000)
+// в виде {"value": [...], "nextLink": "..."}: meeting_id, title, date (ГГГГ-ММ-ДД), lesson, full_name, id,
+// record_book, group, presence, delay, is_present, is_late. На последней странице ссылки nextLink нет, поэтому Power
+// BI загружает ленту целиком, переходя по ссылкам (Web.Contents с заголовком Authorization, если задан ключ доступа).
+//
+// Даты указываются в виде ДД.ММ.ГГГГ, по-умолчанию период начинается с первого собрания и заканчивается сегодня
+func RunServe(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаги команды: адрес, на котором принимаются запросы, порт, заменяющий порт адреса, и ключ доступа
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	address := flags.String("address", "127.0.0.1:8080", "адрес, на котором принимаются запросы")
+	port := flags.Int("port", 0, "порт, заменяющий порт адреса")
+	token := flags.String("token", os.Getenv("TRACKATTENDANCE_API_TOKEN"), "ключ доступа, который передаётся в "+
+		"заголовке Authorization: Bearer (по-умолчанию - переменная окружения TRACKATTENDANCE_API_TOKEN)")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if *port != 0 {
+		host, _, err := net.SplitHostPort(*address)
+		if err != nil {
+			return fmt.Errorf("некорректный адрес сервера %v: %w", *address, err)
+		}
+		*address = net.JoinHostPort(host, strconv.Itoa(*port))
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего запрашивать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	//Номера зачёток и идентификаторы студентов позволяют сопоставлять ответы с данными других программ
+	books, err := roster.LoadRecordBooks(roster.BasePath)
+	if err != nil {
+		return err
+	}
+	schema := historySchema{store: store, books: books, salt: configuration.IDSalt}
+	board := dashboard{store: store, books: books, encrypted: report.XLSXPassword != ""}
+
+	//Папка отчётов (по-умолчанию рабочий стол) не раздаётся целиком: раздаются только отчёты, записанные в историю
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", graphql.Handler(graphql.ObjectFunc(schema.query)))
+	mux.Handle("/", board.handler(board.index))
+	mux.Handle("/meeting", board.handler(board.meeting))
+	mux.Handle("/student", board.handler(board.student))
+	mux.HandleFunc("/reports/", board.report)
+	mux.Handle("/feed", marksFeed{store: store, books: books, salt: configuration.IDSalt})
+	server := &http.Server{Addr: *address, Handler: requireToken(*token, mux), ReadHeaderTimeout: 10 * time.Second}
+
+	//Останавливаем сервер при прерывании программы
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	if *token == "" {
+		slog.Warn(i18n.T("Ключ доступа не задан, веб-панель доступна любому клиенту"), "address", *address)
+	}
+	slog.Info(i18n.T("Веб-панель посещаемости доступна"), "address", "http://"+*address+"/")
+	slog.Info(i18n.T("Запросы GraphQL принимаются"), "address", "http://"+*address+"/graphql")
+	slog.Info(i18n.T("Лента отметок для Power BI доступна"), "address", "http://"+*address+"/feed")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("ошибка работы сервера: %w", err)
+	}
+
+	return nil
+}
+
+// requireToken Вспомогательная функция, возвращающая обработчик, который пропускает к next только запросы с ключом
+// доступа token (пустой ключ - запросы принимаются без ключа)
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, i18n.T("неверный ключ доступа"), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+/*====================================================================================================================*/
+
+// historySchema Структура схемы GraphQL над базой истории посещаемости
+type historySchema struct {
+	store *history.Store
+	books map[string]string
+	salt  string
+}
+
+// query Функция, вычисляющая поля корневого объекта схемы
+func (schema historySchema) query(ctx context.Context, field graphql.Field) (interface{}, error) {
+	switch field.Name {
+	case "meetings":
+		marks, err := schema.marks(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+		title, err := stringArgument(field, "title")
+		if err != nil {
+			return nil, err
+		}
+
+		//Собрания в порядке даты и номера пары (порядок запроса отметок) с отметками их участников
+		var meetings []graphql.Object
+		byID := make(map[int64][]history.Mark)
+		for _, mark := range marks {
+			if title != "" && !strings.Contains(strings.ToLower(mark.Title), strings.ToLower(title)) {
+				continue
+			}
+			if _, ok := byID[mark.MeetingID]; !ok {
+				id := mark.MeetingID
+				meetings = append(meetings, graphql.ObjectFunc(func(ctx context.Context, field graphql.Field) (interface{}, error) {
+					return schema.meeting(byID[id], field)
+				}))
+			}
+			byID[mark.MeetingID] = append(byID[mark.MeetingID], mark)
+		}
+		return meetings, nil
+	case "students":
+		group, err := stringArgument(field, "group")
+		if err != nil {
+			return nil, err
+		}
+		student, err := stringArgument(field, "student")
+		if err != nil {
+			return nil, err
+		}
+		if (student == "") == (group == "") {
+			return nil, fmt.Errorf("для поля students необходимо указать ровно один из аргументов student или group")
+		}
+
+		var stats []history.Stats
+		if student != "" {
+			stats, err = schema.store.StudentStats(ctx, student)
+		} else {
+			stats, err = schema.store.GroupStats(ctx, group)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		students := make([]graphql.Object, 0, len(stats))
+		for _, current := range stats {
+			current := current
+			students = append(students, graphql.ObjectFunc(func(ctx context.Context, field graphql.Field) (interface{}, error) {
+				return schema.student(current, field)
+			}))
+		}
+		return students, nil
+	case "groups":
+		marks, err := schema.marks(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+
+		//Отметки по группам в порядке названий групп
+		var names []string
+		byGroup := make(map[string][]history.Mark)
+		for _, mark := range marks {
+			if _, ok := byGroup[mark.Group]; !ok {
+				names = append(names, mark.Group)
+			}
+			byGroup[mark.Group] = append(byGroup[mark.Group], mark)
+		}
+
+		groups := make([]graphql.Object, 0, len(names))
+		for _, name := range names {
+			name := name
+			groups = append(groups, graphql.ObjectFunc(func(ctx context.Context, field graphql.Field) (interface{}, error) {
+				return schema.group(name, byGroup[name], field)
+			}))
+		}
+		return groups, nil
+	default:
+		return nil, unknownField("Query", field)
+	}
+}
+
+// marks Вспомогательная функция, запрашивающая отметки студентов за период и по группе из аргументов поля
+func (schema historySchema) marks(ctx context.Context, field graphql.Field) ([]history.Mark, error) {
+	from, to := time.Time{}, time.Now()
+	for name, date := range map[string]*time.Time{"from": &from, "to": &to} {
+		value, err := stringArgument(field, name)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			continue
+		}
+		if *date, err = history.ParseDate(value); err != nil {
+			return nil, err
+		}
+	}
+
+	group, err := stringArgument(field, "group")
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.store.Marks(ctx, from, to, group)
+}
+
+// meeting Функция, вычисляющая поля собрания по отметкам его участников
+func (schema historySchema) meeting(marks []history.Mark, field graphql.Field) (interface{}, error) {
+	first := marks[0]
+	switch field.Name {
+	case "id":
+		return first.MeetingID, nil
+	case "title":
+		return first.Title, nil
+	case "date":
+		return first.Date.Format("02.01.2006"), nil
+	case "lesson":
+		return report.Header{LessonNumber: first.Lesson}.LessonLabel(), nil
+	case "expected", "present", "partial", "late", "absent":
+		return countMarks(marks)[field.Name], nil
+	case "participants":
+		group, err := stringArgument(field, "group")
+		if err != nil {
+			return nil, err
+		}
+		absent, filtered := field.Arguments["absent"].(bool)
+
+		var participants []graphql.Object
+		for _, mark := range marks {
+			if group != "" && roster.CanonicalGroup(group) != mark.Group {
+				continue
+			}
+			if filtered && absent != (mark.Presence == report.PresenceAbsent.String()) {
+				continue
+			}
+			mark := mark
+			participants = append(participants, graphql.ObjectFunc(func(ctx context.Context, field graphql.Field) (interface{}, error) {
+				return schema.participant(mark, field)
+			}))
+		}
+		return participants, nil
+	default:
+		return nil, unknownField("Meeting", field)
+	}
+}
+
+// participant Функция, вычисляющая поля отметки участника собрания
+func (schema historySchema) participant(mark history.Mark, field graphql.Field) (interface{}, error) {
+	switch field.Name {
+	case "fullName":
+		return mark.FullName, nil
+	case "id":
+		return roster.StudentID(mark.FullName, schema.salt), nil
+	case "recordBook":
+		return schema.books[mark.FullName], nil
+	case "group":
+		return i18n.T(mark.Group), nil
+	case "presence":
+		return i18n.T(mark.Presence), nil
+	case "delay":
+		return i18n.T(mark.Delay), nil
+	case "isPresent":
+		return mark.Presence != report.PresenceAbsent.String() && mark.Presence != report.PresenceExcused.String(), nil
+	case "isLate":
+		return mark.Delay == report.DelayLate.String(), nil
+	default:
+		return nil, unknownField("Participant", field)
+	}
+}
+
+// student Функция, вычисляющая поля накопленной посещаемости студента
+func (schema historySchema) student(stats history.Stats, field graphql.Field) (interface{}, error) {
+	switch field.Name {
+	case "semester":
+		return stats.Semester, nil
+	case "fullName":
+		return stats.FullName, nil
+	case "id":
+		return roster.StudentID(stats.FullName, schema.salt), nil
+	case "recordBook":
+		return schema.books[stats.FullName], nil
+	case "group":
+		return stats.Group, nil
+	case "lessons":
+		return stats.Lessons, nil
+	case "present":
+		return stats.Present, nil
+	case "partial":
+		return stats.Partial, nil
+	case "late":
+		return stats.Late, nil
+	case "missed":
+		return stats.Missed, nil
+	default:
+		return nil, unknownField("Student", field)
+	}
+}
+
+// group Функция, вычисляющая поля посещаемости группы за период
+func (schema historySchema) group(name string, marks []history.Mark, field graphql.Field) (interface{}, error) {
+	counts := countMarks(marks)
+	switch field.Name {
+	case "name":
+		return name, nil
+	case "meetings":
+		meetings := make(map[int64]bool)
+		for _, mark := range marks {
+			meetings[mark.MeetingID] = true
+		}
+		return len(meetings), nil
+	case "expected", "present", "partial", "late", "absent":
+		return counts[field.Name], nil
+	case "attendanceRate":
+		//Доля отметок о присутствии (полном или неполном) в процентах
+		if counts["expected"] == 0 {
+			return 0, nil
+		}
+		return (counts["present"] + counts["partial"]) * 100 / counts["expected"], nil
+	default:
+		return nil, unknownField("Group", field)
+	}
+}
+
+// countMarks Вспомогательная функция, подсчитывающая отметки: всего, присутствовали полностью и не полностью,
+// опоздали и отсутствовали
+func countMarks(marks []history.Mark) map[string]int {
+	counts := map[string]int{"expected": len(marks)}
+	for _, mark := range marks {
+		switch mark.Presence {
+		case report.PresenceFull.String():
+			counts["present"]++
+		case report.PresencePartial.String():
+			counts["partial"]++
+		case report.PresenceAbsent.String():
+			counts["absent"]++
+		}
+		if mark.Delay == report.DelayLate.String() {
+			counts["late"]++
+		}
+	}
+
+	return counts
+}
+
+// stringArgument Вспомогательная функция, возвращающая строковый аргумент поля (пустой, если аргумент не указан)
+func stringArgument(field graphql.Field, name string) (string, error) {
+	switch value := field.Arguments[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	default:
+		return "", fmt.Errorf("аргумент %v поля %v должен быть строкой", name, field.Name)
+	}
+}
+
+// unknownField Вспомогательная функция, возвращающая ошибку запроса несуществующего поля
+func unknownField(typeName string, field graphql.Field) error {
+	return fmt.Errorf("у типа %v нет поля %v", typeName, field.Name)
+}
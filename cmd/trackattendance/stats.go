@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/roster"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+/*====================================================================================================================*/
+
+// RunStats Функция команды stats, выводящая накопленную посещаемость студента или группы по семестрам из базы истории
+func RunStats(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаги команды: ФИО (или начало ФИО) студента или группа
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	student := flags.String("student", "", "ФИО студента (достаточно начала ФИО, например \"Иванов Иван\")")
+	group := flags.String("group", "", "группа, по всем студентам которой выводится посещаемость")
+	output := flags.String("output", "table", "формат вывода: table, csv, tsv или json")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+
+	switch *output {
+	case "table", "csv", "tsv", "json":
+	default:
+		return fmt.Errorf("неизвестный формат вывода: %v (допустимы table, csv, tsv, json)", *output)
+	}
+
+	if (*student == "") == (*group == "") {
+		return fmt.Errorf("необходимо указать ровно один из флагов --student или --group")
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего считать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	//Запрашиваем посещаемость студента или группы
+	var stats []history.Stats
+	if *student != "" {
+		stats, err = store.StudentStats(ctx, *student)
+	} else {
+		stats, err = store.GroupStats(ctx, *group)
+	}
+	if err != nil {
+		return err
+	}
+
+	//Идентификаторы студентов и номера зачёток позволяют сопоставлять посещаемость с данными других программ
+	books, err := roster.LoadRecordBooks(roster.BasePath)
+	if err != nil {
+		return err
+	}
+	for i := range stats {
+		stats[i].ID = roster.StudentID(stats[i].FullName, configuration.IDSalt)
+		stats[i].RecordBook = books[stats[i].FullName]
+	}
+
+	//Машиночитаемые форматы выводятся и при пустом результате, чтобы их можно было передать другим программам
+	if len(stats) == 0 && *output == "table" {
+		fmt.Println(i18n.T("В истории нет записей о посещаемости по заданному условию"))
+		return nil
+	}
+
+	return WriteStats(os.Stdout, *output, stats)
+}
+
+// checkFileFormat Вспомогательная функция, проверяющая машиночитаемый формат вывода команд по истории: csv, tsv
+// или json
+func checkFileFormat(format string) error {
+	switch format {
+	case "csv", "tsv", "json":
+		return nil
+	default:
+		return fmt.Errorf("неизвестный формат вывода: %v (допустимы csv, tsv, json)", format)
+	}
+}
+
+// WriteStats Функция, выводящая посещаемость в виде таблицы с выравниванием столбцов (table), в виде .csv (csv) или
+// .tsv (tsv) файла или в виде массива JSON (json). Столбец номера зачётки выводится, если номер известен хотя бы у одного
+// студента
+func WriteStats(out io.Writer, format string, stats []history.Stats) error {
+	recordBooks := false
+	for _, current := range stats {
+		if current.RecordBook != "" {
+			recordBooks = true
+		}
+	}
+
+	//"Шапка" таблицы посещаемости
+	columns := []string{i18n.T("Семестр"), i18n.T("Группа"), i18n.T("ФИО")}
+	if recordBooks {
+		columns = append(columns, i18n.T("Номер зачётки"))
+	}
+	columns = append(columns, i18n.T("Занятий"), i18n.T("Присутствовал"), i18n.T("Не полностью"), i18n.T("Опозданий"),
+		i18n.T("Пропусков"))
+
+	//Строка таблицы посещаемости студента
+	row := func(current history.Stats) []string {
+		cells := []string{current.Semester, current.Group, current.FullName}
+		if recordBooks {
+			cells = append(cells, current.RecordBook)
+		}
+		return append(cells, strconv.Itoa(current.Lessons), strconv.Itoa(current.Present),
+			strconv.Itoa(current.Partial), strconv.Itoa(current.Late), strconv.Itoa(current.Missed))
+	}
+
+	switch format {
+	case "json":
+		//Пустой результат выводится пустым массивом, а не null
+		if stats == nil {
+			stats = []history.Stats{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	case "csv", "tsv":
+		csvWriter := csv.NewWriter(out)
+		if format == "tsv" {
+			csvWriter.Comma = '\t'
+		}
+
+		rows := [][]string{columns}
+		for _, current := range stats {
+			rows = append(rows, row(current))
+		}
+
+		return csvWriter.WriteAll(rows)
+	default:
+		//Выводим таблицу посещаемости с выравниванием столбцов
+		writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(columns, "\t"))
+		for _, current := range stats {
+			fmt.Fprintln(writer, strings.Join(row(current), "\t"))
+		}
+
+		return writer.Flush()
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunView Функция команды view, открывающая для просмотра в браузере сформированный отчёт (.csv, .json или .html) или
+// базу истории посещаемости, чтобы кураторы, получившие файлы, могли посмотреть их без MS Excel. Данные открываются
+// только для чтения: отчёт не перезаписывается, а база истории не создаётся и не обновляется. Без аргумента
+// открывается база истории из конфигураций. Сервер работает до прерывания программы
+func RunView(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаг команды: адрес, на котором открывается просмотр (порт 0 - любой свободный порт)
+	flags := flag.NewFlagSet("view", flag.ContinueOnError)
+	address := flags.String("address", "127.0.0.1:0", "адрес, на котором открывается просмотр")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	path := configuration.History.Path
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("файл для просмотра не найден: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	switch strings.ToLower(filepath.Ext(path)) {
+	//Отчёт в виде .html страницы уже предназначен для просмотра и раздаётся как есть
+	case ".html", ".htm":
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, path)
+		})
+	//Отчёты в виде .csv файла и в формате JSON считываются заново при каждом открытии страницы
+	case ".csv", ".json":
+		mux.Handle("/", dashboard{}.handler(func(r *http.Request) (dashboardPage, error) {
+			if r.URL.Path != "/" {
+				return dashboardPage{}, errPageNotFound
+			}
+			return reportPage(path)
+		}))
+	//Всё остальное считается базой истории, которая открывается только для чтения
+	default:
+		store, err := history.OpenReadOnly(ctx, path)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		books, err := roster.LoadRecordBooks(roster.BasePath)
+		if err != nil {
+			return err
+		}
+		board := dashboard{store: store, books: books}
+		mux.Handle("/", board.handler(board.index))
+		mux.Handle("/meeting", board.handler(board.meeting))
+		mux.Handle("/student", board.handler(board.student))
+	}
+
+	//Адрес занимается заранее, чтобы вывести порт, выбранный системой
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия адреса %v: %w", *address, err)
+	}
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	//Останавливаем сервер при прерывании программы
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+
+	slog.Info(i18n.T("Просмотр только для чтения доступен"), "path", path, "address",
+		"http://"+listener.Addr().String()+"/")
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("ошибка работы сервера: %w", err)
+	}
+
+	return nil
+}
+
+// reportPage Функция, формирующая страницу просмотра отчёта в виде .csv файла или в формате JSON
+func reportPage(path string) (dashboardPage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return dashboardPage{}, fmt.Errorf("ошибка открытия отчёта: %w", err)
+	}
+	defer file.Close()
+
+	var sheets []report.Sheet
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		sheets, err = report.ReadJSONSheets(file)
+	} else {
+		sheets, err = readCSVSheets(file)
+	}
+	if err != nil {
+		return dashboardPage{}, err
+	}
+
+	page := dashboardPage{Title: filepath.Base(path)}
+	for _, sheet := range sheets {
+		table := dashboardTable{Title: sheet.Name}
+		rows := sheet.Rows
+		//Строка из трёх и более ячеек в начале таблицы считается её "шапкой"
+		if len(rows) > 1 && len(rows[0]) > 2 {
+			table.Columns, rows = rows[0], rows[1:]
+		}
+		for _, row := range rows {
+			cells := make([]dashboardCell, 0, len(row))
+			for _, text := range row {
+				cells = append(cells, dashboardCell{Text: text})
+			}
+			table.Rows = append(table.Rows, cells)
+		}
+		page.Tables = append(page.Tables, table)
+	}
+
+	return page, nil
+}
+
+// readCSVSheets Функция, разбивающая отчёт в виде .csv файла на таблицы по пустым строкам. Строка из одной ячейки в
+// начале таблицы ("Гости", "Преподаватели") считается её названием
+func readCSVSheets(in io.Reader) ([]report.Sheet, error) {
+	reader := csv.NewReader(in)
+	reader.Comma = ';'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var sheets []report.Sheet
+	var current report.Sheet
+	previousLine := 0
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения отчёта: %w", err)
+		}
+
+		//Убираем BOM, с которым записываются отчёты
+		if previousLine == 0 {
+			row[0] = strings.TrimPrefix(row[0], "\uFEFF")
+		}
+
+		//Пустые строки читатель .csv файлов пропускает, поэтому конец таблицы определяется по пропуску номера строки
+		line, _ := reader.FieldPos(0)
+		if line > previousLine+1 && len(current.Rows) > 0 {
+			sheets = append(sheets, current)
+			current = report.Sheet{}
+		}
+		previousLine = line
+
+		if len(current.Rows) == 0 && current.Name == "" && len(row) == 1 && len(sheets) > 0 {
+			current.Name = row[0]
+			continue
+		}
+		current.Rows = append(current.Rows, row)
+	}
+	if len(current.Rows) > 0 {
+		sheets = append(sheets, current)
+	}
+
+	return sheets, nil
+}
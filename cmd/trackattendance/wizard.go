@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mod.go/config"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// RunWizard Функция первого запуска, которая, если файла конфигураций нет, задаёт вопросы об основных настройках
+// (директория загрузок, директория отчётов, расписание пар и источник базы групп) и создаёт файл конфигураций, чтобы
+// его не приходилось редактировать вручную. Пустой ответ означает значение по-умолчанию
+func RunWizard(path string, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReader(in)
+	defaultDownloads, defaultReports := config.DefaultFolders()
+
+	fmt.Fprintf(out, "Файл конфигураций %v не найден. Ответьте на несколько вопросов, чтобы создать его "+
+		"(Enter - значение в скобках)\n\n", path)
+
+	var settings config.Initial
+	var err error
+
+	//Директории загрузок и отчётов должны существовать
+	if settings.DownloadFolderPath, err = askFolder(reader, out, "Директория загрузок, в которую сохраняются отчёты "+
+		"MS Teams", defaultDownloads); err != nil {
+		return err
+	}
+	if settings.ReportLocationPath, err = askFolder(reader, out, "Директория, в которую сохраняются отчёты о "+
+		"посещаемости", defaultReports); err != nil {
+		return err
+	}
+
+	//Расписание пар выбирается из готовых расписаний или вводится вручную
+	fmt.Fprintln(out, "Расписание пар:")
+	for i, preset := range config.SchedulePresets {
+		fmt.Fprintf(out, "  %d) %v: %v\n", i+1, preset.Name, preset.Lessons)
+	}
+	custom := len(config.SchedulePresets) + 1
+	fmt.Fprintf(out, "  %d) Своё расписание\n", custom)
+	for settings.Lessons == "" {
+		answer, err := ask(reader, out, "Номер расписания", "1")
+		if err != nil {
+			return err
+		}
+		number, err := strconv.Atoi(answer)
+		switch {
+		case err != nil || number < 1 || number > custom:
+			fmt.Fprintf(out, "Введите номер от 1 до %d\n", custom)
+		case number < custom:
+			settings.Lessons = config.SchedulePresets[number-1].Lessons
+		default:
+			lessons, err := ask(reader, out, "Время пар через запятую (например, 08:00-09:30,09:40-11:10)", "")
+			if err != nil {
+				return err
+			}
+			if err := config.CheckLessons(lessons); err != nil {
+				fmt.Fprintf(out, "Некорректное расписание: %v\n", err)
+				continue
+			}
+			settings.Lessons = lessons
+		}
+	}
+
+	//База групп загружается из .xlsx файла или таблицы Google Sheets, либо используется GroupsBase.csv
+	for {
+		answer, err := ask(reader, out, "Источник базы групп: файл .xlsx или ссылка на таблицу Google Sheets (Enter - "+
+			"GroupsBase.csv рядом с программой)", "")
+		if err != nil {
+			return err
+		}
+		if answer != "" && !strings.Contains(answer, "://") {
+			if _, err := os.Stat(config.ExpandPath(answer)); err != nil {
+				fmt.Fprintf(out, "Файл %v не найден\n", answer)
+				continue
+			}
+		}
+		settings.GroupsBase = answer
+		break
+	}
+
+	if err := config.Create(path, settings); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\nФайл конфигураций %v создан, остальные настройки можно изменить в нём позже\n\n", path)
+
+	return nil
+}
+
+// IsTerminal Функция, проверяющая, является ли файл терминалом (а не файлом или каналом), то есть можно ли задавать
+// вопросы пользователю
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// askFolder Вспомогательная функция, запрашивающая путь до существующей директории. Для значения по-умолчанию
+// возвращается пустая строка, чтобы в файле конфигураций осталось значение по-умолчанию
+func askFolder(reader *bufio.Reader, out io.Writer, question, defaultPath string) (string, error) {
+	for {
+		answer, err := ask(reader, out, question, defaultPath)
+		if err != nil {
+			return "", err
+		}
+		if answer == defaultPath {
+			return "", nil
+		}
+		if info, err := os.Stat(config.ExpandPath(answer)); err != nil || !info.IsDir() {
+			fmt.Fprintf(out, "Директория %v не найдена\n", answer)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// ask Вспомогательная функция, задающая вопрос и возвращающая ответ без пробелов по краям. Пустой ответ заменяется
+// значением по-умолчанию
+func ask(reader *bufio.Reader, out io.Writer, question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(out, "%v [%v]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(out, "%v: ", question)
+	}
+
+	answer, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", errors.New("ввод прерван, файл конфигураций не создан")
+	}
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return defaultValue, nil
+	}
+
+	return answer, nil
+}
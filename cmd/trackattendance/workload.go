@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/config"
+	"mod.go/history"
+	"mod.go/i18n"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// RunWorkload Функция команды workload, формирующая из базы истории выгрузку нагрузки преподавателей по месяцам для
+// таблицы учёта нагрузки: проведённые собрания, средняя посещаемость и студенто-часы
+func RunWorkload(ctx context.Context, arguments []string, configuration config.Configuration) error {
+	//Флаги команды: период, преподаватель, файл и формат выгрузки
+	flags := flag.NewFlagSet("workload", flag.ContinueOnError)
+	from := flags.String("from", "", "дата начала периода (ДД.ММ.ГГГГ)")
+	to := flags.String("to", "", "дата окончания периода (ДД.ММ.ГГГГ), по-умолчанию - сегодня")
+	teacher := flags.String("teacher", "", "часть ФИО преподавателя, по которой отбираются строки выгрузки")
+	output := flags.String("output", "", "файл выгрузки или - для вывода в стандартный вывод")
+	format := flags.String("format", "csv", "формат выгрузки: csv, tsv или json")
+	if err := flags.Parse(arguments); err != nil {
+		return err
+	}
+	if err := checkFileFormat(*format); err != nil {
+		return err
+	}
+
+	if *from == "" {
+		return fmt.Errorf("необходимо указать дату начала периода флагом --from")
+	}
+	dateFrom, err := history.ParseDate(*from)
+	if err != nil {
+		return err
+	}
+	dateTo := time.Now()
+	if *to != "" {
+		if dateTo, err = history.ParseDate(*to); err != nil {
+			return err
+		}
+	}
+
+	//База истории должна уже существовать, иначе в ней нечего считать
+	if _, err := os.Stat(configuration.History.Path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("база истории %v не найдена, включите запись истории в секции [history] cfg.ini",
+			configuration.History.Path)
+	}
+
+	store, err := history.Open(ctx, configuration.History.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	workloads, err := store.Workloads(ctx, dateFrom, dateTo)
+	if err != nil {
+		return err
+	}
+
+	//Отбираем строки по ФИО преподавателя без учёта регистра
+	if *teacher != "" {
+		filtered := workloads[:0]
+		for _, workload := range workloads {
+			if strings.Contains(strings.ToLower(workload.Teacher), strings.ToLower(*teacher)) {
+				filtered = append(filtered, workload)
+			}
+		}
+		workloads = filtered
+	}
+
+	//Выгрузка сохраняется в каталог итоговых отчётов, если файл не указан явно
+	path := *output
+	if path == "" {
+		path = configuration.ReportLocationPath + i18n.T("Нагрузка преподавателей_") + dateFrom.Format("02.01.2006") +
+			"_" + dateTo.Format("02.01.2006") + "." + *format
+	}
+	if path == "-" {
+		return WriteWorkload(os.Stdout, *format, workloads)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer file.Close()
+
+	//Таблица записывается в кодировке UTF-8 c BOM, как и журнал посещаемости, чтобы MS Excel корректно отображал
+	// кириллицу
+	if *format != "json" {
+		if _, err := file.WriteString("\xEF\xBB\xBF"); err != nil {
+			return fmt.Errorf("ошибка записи строки с кодировкой: %w", err)
+		}
+	}
+	if err := WriteWorkload(file, *format, workloads); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия файла выгрузки: %w", err)
+	}
+
+	fmt.Println(path)
+	return nil
+}
+
+// WriteWorkload Функция, записывающая нагрузку преподавателей в виде .csv файла с разделителем ";" (csv) или .tsv
+// файла (tsv): строка на каждый месяц и преподавателя, или в виде массива JSON (json). Средняя посещаемость - доля
+// отметок о присутствии (полном или неполном) среди всех отметок студентов на собраниях преподавателя, дробные числа
+// таблицы записываются с десятичной запятой
+func WriteWorkload(out io.Writer, format string, workloads []history.Workload) error {
+	if format == "json" {
+		//Пустой результат выводится пустым массивом, а не null
+		if workloads == nil {
+			workloads = []history.Workload{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(workloads)
+	}
+
+	csvWriter := csv.NewWriter(out)
+	csvWriter.Comma = ';'
+	if format == "tsv" {
+		csvWriter.Comma = '\t'
+	}
+
+	rows := [][]string{{i18n.T("Месяц"), i18n.T("Преподаватель"), i18n.T("Проведено собраний"),
+		i18n.T("Средняя посещаемость, %"), i18n.T("Студенто-часов")}}
+	for _, workload := range workloads {
+		teacher := workload.Teacher
+		if teacher == "" {
+			teacher = i18n.T("Преподаватель не указан")
+		}
+		rate := 0.0
+		if workload.Expected > 0 {
+			rate = float64(workload.Attended) * 100 / float64(workload.Expected)
+		}
+		rows = append(rows, []string{workload.Month.Format("01.2006"), teacher, strconv.Itoa(workload.Meetings),
+			decimalComma(rate), decimalComma(workload.StudentHours)})
+	}
+
+	return csvWriter.WriteAll(rows)
+}
+
+// decimalComma Вспомогательная функция, записывающая число с одним знаком после десятичной запятой
+func decimalComma(value float64) string {
+	return strings.Replace(strconv.FormatFloat(value, 'f', 1, 64), ".", ",", 1)
+}
@@ -0,0 +1,992 @@
+// Package config Пакет чтения файла конфигураций cfg.ini: пути до загрузок и отчётов, расписание пар, настройки
+// Microsoft Graph и истории посещаемости
+package config
+
+import (
+	"context"
+	"fmt"
+	"gopkg.in/ini.v1"
+	"mod.go/audit"
+	"mod.go/email"
+	"mod.go/graph"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/logging"
+	"mod.go/notify"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	"mod.go/sheets"
+	"mod.go/teamsreport"
+	"mod.go/xapi"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура всех конфигураций, считанных из файла cfg.ini
+type Configuration struct {
+	//Путь до директории загрузок
+	DownloadFolderPath string
+	//Путь до директории, в которую сохраняется сформированный отчёт
+	ReportLocationPath string
+	//Путь до файла кураторов групп, по которому отчёты и оповещения направляются кураторам
+	CuratorsPath string
+	//Путь до журнала отправленных оповещений, по которому повторная обработка отчёта не отправляет их повторно
+	SentNotificationsPath string
+	//Путь до файла освобождений студентов и групп от посещения пар
+	ExemptionsPath string
+	//Путь до файла уважительных причин отсутствия студентов
+	ExcusesPath string
+	//Путь до файла преподавателей и ассистентов, которые не попадают в список участников
+	StaffPath string
+	//Путь до файла псевдонимов участников (имён из MS Teams, которые всегда относятся к одному студенту базы)
+	NameAliasesPath string
+	//Путь до файла целей посещаемости групп по месяцам
+	GoalsPath string
+	//Источник базы групп (.xlsx файл или ссылка на Google Sheets), из которого обновляется GroupsBase.csv
+	GroupsBaseSource string
+	//Расписание пар
+	Schedule schedule.Schedule
+	//Настройки загрузки отчётов через Microsoft Graph
+	Graph graph.Configuration
+	//Настройки истории посещаемости
+	History history.Configuration
+	//Настройки оповещений кураторов
+	Notify notify.Configuration
+	//Настройки отправки отчётов по электронной почте
+	Email email.Configuration
+	//Настройки выгрузки таблицы посещаемости в Google Sheets
+	Sheets sheets.Configuration
+	//Настройки выгрузки посещаемости в хранилище учебных записей в виде утверждений xAPI
+	XAPI xapi.Configuration
+	//Ограничения чтения одного отчёта MS Teams
+	Limits teamsreport.Limits
+	//Настройки журнала действий
+	Audit audit.Configuration
+	//Настройки журнала сообщений программы
+	Log logging.Configuration
+	//Формировать ли статистику устройств, с которых участники присоединялись к собранию
+	PlatformStats bool
+	//Форматы итогового отчёта в порядке формирования: csv, xlsx, json, html, template или зарегистрированные функцией
+	// report.RegisterExporter(). Первый формат - основной: его путь проверяется при повторной обработке и
+	// отправляется по электронной почте
+	Formats []string
+	//Путь до шаблона отчёта (text/template) для формата template
+	TemplatePath string
+	//Пароль, которым шифруется отчёт в формате xlsx. Пустой пароль - книга не шифруется
+	XLSXPassword string
+	//Команда, выводящая пароль книги .xlsx из хранилища паролей системы (используется, если пароль не указан)
+	XLSXPasswordCommand string
+	//Формировать ли изображение со сводкой посещаемости собрания для чата группы
+	Badge bool
+	//Выводить ли в конце отчёта преподавателей и ассистентов из файла преподавателей со временем присоединения
+	StaffBlock bool
+	//Выводить ли после таблицы участников итоги посещаемости по группам
+	TotalsBlock bool
+	//Объединять ли отчёты собраний одного преподавателя с тем же названием на одной паре (собрание, созданное заново
+	// после обрыва связи) в одно собрание
+	MergeRecreated bool
+	//ФИО преподавателя для отчёта. Если не указано, берётся имя инициатора собрания
+	Lecturer string
+	//Название профиля конфигураций, с которым сформирован отчёт. Если не указано, берётся имя файла конфигураций
+	Profile string
+	//Способ обработки гостей собрания: include, drop, separate или match
+	GuestPolicy string
+	//Наибольшее количество отличающихся символов ФИО гостя и студента базы, при котором гость считается студентом
+	GuestMatchDistance int
+	//Доля присутствовавших студентов групп собрания в процентах, при которой занятие набирает кворум (0 - кворум не
+	// проверяется)
+	QuorumShare int
+	//Доля продолжительности собрания в процентах, которую студент должен находиться на собрании для учёта в кворуме
+	QuorumTimeShare int
+	//Допуск проверки экзамена в секундах: на сколько студент может подключиться позже или выйти раньше назначенного
+	// ему времени
+	ExamTolerance int
+	//Секрет, с которым вычисляются идентификаторы студентов в машиночитаемых форматах
+	IDSalt string
+	//Язык итоговых отчётов и сообщений программы: ru или en
+	Language string
+	//Выводить ли в отчёте только участников собрания, без отсутствующих студентов групп собрания (для необязательных
+	// занятий). Также задаётся флагом --only-present командной строки
+	OnlyPresent bool
+	//Пометки участников (absent, late и т.д.), которые выводятся в сокращённом отчёте, формируемом рядом с полным
+	// отчётом для отправки кураторам. Также задаётся флагом --only командной строки
+	Only report.StatusFilter
+	//Пропускать ли технические созвоны: отчёт не формируется и собрание не записывается в историю
+	SkipTechnicalCalls bool
+	//Прерывать ли обработку отчёта на первой некорректной строке участника (иначе строка пропускается с
+	// предупреждением в отчёте)
+	StrictParsing bool
+	//Роли участников собрания, которые являются преподавателями и не попадают в список участников
+	StaffRoles []string
+	//Способ обработки уже сформированного отчёта того же собрания: перезапись, пропуск собрания или новая версия
+	ExistingReports string
+	//Названия столбцов таблицы участников итогового отчёта
+	Columns report.Columns
+	//Шаблоны групп, по которым группа выделяется из имени участника собрания
+	GroupPatterns []*regexp.Regexp
+	//Прежние названия переименованных групп (ключ - прежнее название, значение - текущее)
+	GroupAliases map[string]string
+	//Дисциплины, к которым относятся собрания по названию
+	Courses []roster.Course
+	//Наименьшее количество студентов группы в базе групп, при котором группа из имени участника принимается
+	MinGroupSize int
+	//Группы, которые принимаются из имени участника собрания, даже если их нет в базе групп
+	AllowedGroups []string
+	//Путь до листа присутствия в аудитории для гибридного занятия. Задаётся флагом --signin командной строки
+	SignInPath string
+	//Путь до выгрузки журнала событий СДО Moodle, по которому отмечаются отсутствовавшие студенты, активные в СДО во
+	// время пары. Задаётся флагом --lms-log командной строки
+	LMSLogPath string
+	//Путь до списка записавшихся на консультацию, с которым сравниваются участники консультации. Задаётся флагом
+	// --signup командной строки
+	SignUpPath string
+	//Путь до списка вариантов экзамена, по которому проверяется присутствие студентов в назначенное время. Задаётся
+	// флагом --exam командной строки
+	ExamPath string
+	//Выводить ли краткую сводку каждого собрания в стандартный вывод. Задаётся флагом --report-to-stdout-summary
+	StdoutSummary bool
+	//Пробный запуск: отчёт разбирается и найденное выводится в стандартный вывод без записи файлов. Задаётся флагом
+	// --dry-run
+	DryRun bool
+	//Изменять ли собрания, закрытые командой finalize. Задаётся флагом --force
+	Force bool
+	//Выводить ли сведения о разборе каждой строки отчёта. Задаётся флагом --verbose
+	Verbose bool
+}
+
+// DefaultLessons Стандартное расписание пар
+const DefaultLessons = "08:00-09:30,09:40-11:10,11:20-12:50,13:20-14:50,15:00-16:30,16:40-18:10,18:20-19:50,20:00-21:30"
+
+/*====================================================================================================================*/
+
+// Load Функция, считывающая все конфигурации из .ini файла
+func Load(path string) (Configuration, error) {
+	//Переменная конфигураций
+	var configuration Configuration
+
+	//Открываем .ini файл
+	configurationFile, err := ini.Load(path)
+	if err != nil {
+		return configuration, fmt.Errorf("ошибка открытия файла конфигураций: %w", err)
+	}
+
+	//Считываем пути до загрузок и будущего расположения отчёта
+	configuration.DownloadFolderPath, configuration.ReportLocationPath = SetPaths(configurationFile.Section("paths"))
+
+	//Считываем путь до файла кураторов групп, по-умолчанию файл лежит рядом с базой групп
+	configuration.CuratorsPath = ExpandPath(configurationFile.Section("paths").Key("curators_path").
+		MustString("curators.csv"))
+
+	//Считываем источник базы групп, по-умолчанию используется GroupsBase.csv без обновления
+	configuration.GroupsBaseSource = configurationFile.Section("paths").Key("groups_base").String()
+	if !strings.Contains(configuration.GroupsBaseSource, "://") {
+		configuration.GroupsBaseSource = ExpandPath(configuration.GroupsBaseSource)
+	}
+
+	//Считываем путь до файла освобождений от посещения пар, по-умолчанию файл лежит рядом с базой групп
+	configuration.ExemptionsPath = ExpandPath(configurationFile.Section("paths").Key("exemptions_path").
+		MustString("exemptions.csv"))
+
+	//Считываем путь до файла уважительных причин отсутствия, по-умолчанию файл лежит рядом с базой групп
+	configuration.ExcusesPath = ExpandPath(configurationFile.Section("paths").Key("excuses_path").
+		MustString("excuses.csv"))
+
+	//Считываем путь до файла преподавателей и ассистентов, по-умолчанию файл лежит рядом с базой групп
+	configuration.StaffPath = ExpandPath(configurationFile.Section("paths").Key("staff_path").
+		MustString(roster.StaffPath))
+
+	//Считываем путь до файла псевдонимов участников, по-умолчанию файл лежит рядом с базой групп
+	configuration.NameAliasesPath = ExpandPath(configurationFile.Section("paths").Key("name_aliases_path").
+		MustString(roster.NameAliasesPath))
+
+	//Считываем путь до файла целей посещаемости групп, по-умолчанию файл лежит рядом с базой групп
+	configuration.GoalsPath = ExpandPath(configurationFile.Section("paths").Key("goals_path").MustString("goals.csv"))
+
+	//Считываем путь до журнала отправленных оповещений
+	configuration.SentNotificationsPath = ExpandPath(configurationFile.Section("paths").Key("sent_notifications_path").
+		MustString("sent_notifications.log"))
+
+	//Считываем расписание пар
+	if configuration.Schedule, err = SetSchedule(configurationFile.Section("schedule")); err != nil {
+		return configuration, err
+	}
+	configuration.SkipTechnicalCalls = configurationFile.Section("schedule").Key("skip_technical_calls").MustBool(false)
+
+	//Считываем настройки Microsoft Graph
+	if configuration.Graph, err = SetGraph(configurationFile.Section("graph")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем настройки истории посещаемости
+	configuration.History = SetHistory(configurationFile.Section("history"))
+
+	//Считываем настройки оповещений кураторов
+	configuration.Notify = SetNotify(configurationFile.Section("notify"))
+	configuration.Columns = SetColumns(configurationFile.Section("columns"))
+
+	//Задачи кураторам создаются в Microsoft To Do от имени приложения по серии пропусков из истории посещаемости
+	if configuration.Notify.FollowUpAbsences < 0 {
+		return configuration, fmt.Errorf("количество пропусков followup_absences не может быть отрицательным")
+	}
+	if configuration.Notify.FollowUpAbsences > 0 && (!configuration.History.Enabled || !configuration.Graph.Enabled ||
+		configuration.Graph.AuthFlow != "client_credentials") {
+		return configuration, fmt.Errorf("для задач кураторам (followup_absences) необходимо включить историю " +
+			"посещаемости и Microsoft Graph с авторизацией client_credentials")
+	}
+
+	//Считываем настройки отправки отчётов по электронной почте
+	if configuration.Email, err = SetEmail(configurationFile.Section("email")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем ограничения чтения отчётов
+	if configuration.Limits, err = SetLimits(configurationFile.Section("limits")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем настройки выгрузки в Google Sheets
+	if configuration.Sheets, err = SetSheets(configurationFile.Section("sheets")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем настройки выгрузки в хранилище учебных записей
+	if configuration.XAPI, err = SetXAPI(configurationFile.Section("xapi")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем настройки журнала действий
+	configuration.Audit = audit.Configuration{
+		Enabled: configurationFile.Section("audit").Key("enabled").MustBool(false),
+		Path:    ExpandPath(configurationFile.Section("audit").Key("log_path").MustString("audit.log")),
+	}
+
+	//Считываем настройки журнала сообщений
+	if configuration.Log, err = SetLog(configurationFile.Section("log")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем настройки итогового отчёта
+	configuration.PlatformStats = configurationFile.Section("report").Key("platform_stats").MustBool(false)
+	//Список форматов formats заменяет прежние ключи format и html (отчёт в виде .html страницы рядом с основным)
+	formats := configurationFile.Section("report").Key("formats").String()
+	if strings.TrimSpace(formats) == "" {
+		formats = configurationFile.Section("report").Key("format").MustString(report.FormatCSV)
+		if configurationFile.Section("report").Key("html").MustBool(false) {
+			formats += "," + report.FormatHTML
+		}
+	}
+	if configuration.Formats, err = report.ParseFormats(formats); err != nil {
+		return configuration, err
+	}
+	configuration.TemplatePath = ExpandPath(strings.TrimSpace(configurationFile.Section("report").Key("template_path").
+		String()))
+	configuration.XLSXPassword = configurationFile.Section("report").Key("xlsx_password").String()
+	configuration.XLSXPasswordCommand = strings.TrimSpace(configurationFile.Section("report").
+		Key("xlsx_password_command").String())
+	configuration.Badge = configurationFile.Section("report").Key("badge").MustBool(false)
+	configuration.StaffBlock = configurationFile.Section("report").Key("staff_block").MustBool(false)
+	configuration.TotalsBlock = configurationFile.Section("report").Key("totals_block").MustBool(false)
+	configuration.MergeRecreated = configurationFile.Section("report").Key("merge_recreated").MustBool(true)
+	configuration.Lecturer = strings.TrimSpace(configurationFile.Section("report").Key("lecturer").String())
+	configuration.Profile = strings.TrimSpace(configurationFile.Section("report").Key("profile").
+		MustString(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))))
+	if configuration.GuestPolicy, err = roster.ParseGuestPolicy(configurationFile.Section("report").Key("guest_policy").String()); err != nil {
+		return configuration, err
+	}
+	configuration.GuestMatchDistance = configurationFile.Section("report").Key("guest_match_distance").MustInt(2)
+	if configuration.Language, err = i18n.ParseLanguage(configurationFile.Section("report").Key("language").String()); err != nil {
+		return configuration, err
+	}
+	configuration.IDSalt = configurationFile.Section("report").Key("id_salt").String()
+	configuration.OnlyPresent = configurationFile.Section("report").Key("only_present").MustBool(false)
+	if configuration.Only, err = report.ParseStatusFilter(configurationFile.Section("report").Key("only").
+		String()); err != nil {
+		return configuration, err
+	}
+	configuration.StrictParsing = configurationFile.Section("report").Key("strict_parsing").MustBool(false)
+	configuration.StaffRoles = teamsreport.ParseStaffRoles(configurationFile.Section("report").Key("staff_roles").
+		MustString(teamsreport.DefaultStaffRoles))
+	if configuration.ExistingReports, err = report.ParseExistingPolicy(configurationFile.Section("report").Key("existing").String()); err != nil {
+		return configuration, err
+	}
+	configuration.QuorumShare = configurationFile.Section("report").Key("quorum_share").MustInt(0)
+	configuration.QuorumTimeShare = configurationFile.Section("report").Key("quorum_time_share").MustInt(50)
+	if configuration.QuorumShare < 0 || configuration.QuorumShare > 100 || configuration.QuorumTimeShare < 0 ||
+		configuration.QuorumTimeShare > 100 {
+		return configuration, fmt.Errorf("доли кворума должны быть указаны в процентах от 0 до 100")
+	}
+	configuration.ExamTolerance = configurationFile.Section("report").Key("exam_tolerance").MustInt(5) * 60
+	if configuration.ExamTolerance < 0 {
+		return configuration, fmt.Errorf("допуск проверки экзамена не может быть отрицательным")
+	}
+
+	//Считываем шаблоны групп, которые могут быть указаны в имени участника собрания
+	groupPatterns := configurationFile.Section("groups").Key("patterns").MustString(teamsreport.DefaultGroupPatterns)
+	if configuration.GroupPatterns, err = teamsreport.CompileGroupPatterns(groupPatterns); err != nil {
+		return configuration, err
+	}
+
+	//Считываем переименования групп
+	groupAliases := configurationFile.Section("groups").Key("aliases").String()
+	if configuration.GroupAliases, err = roster.ParseGroupAliases(groupAliases); err != nil {
+		return configuration, err
+	}
+
+	//Считываем дисциплины собраний
+	if configuration.Courses, err = SetCourses(configurationFile.Section("courses")); err != nil {
+		return configuration, err
+	}
+
+	//Считываем условия, при которых принимается группа, указанная в имени участника собрания
+	configuration.MinGroupSize = configurationFile.Section("groups").Key("min_size").MustInt(1)
+	if configuration.MinGroupSize < 1 {
+		return configuration, fmt.Errorf("наименьшее количество студентов группы min_size должно быть не меньше 1")
+	}
+	for _, group := range strings.Split(configurationFile.Section("groups").Key("allowed").String(), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			configuration.AllowedGroups = append(configuration.AllowedGroups, group)
+		}
+	}
+
+	return configuration, nil
+}
+
+// SetPaths Функция, считывающая конфигурации путей до загрузок и до директории будущего расположения отчёта
+func SetPaths(section *ini.Section) (string, string) {
+	//Определяем ОС пользователя
+	currentOS := runtime.GOOS
+
+	//Считываем из файла конфигураций пути до загрузок и будущего расположения отчёта
+	downloadFolderPath := ExpandPath(section.Key("download_folder_path").String())
+	reportLocationPath := ExpandPath(section.Key("report_location_folder").String())
+
+	//Если значения путей до загрузок и до будущего расположения отчёта не установлены, ставим значения по-умолчанию в
+	// зависимости от ОС пользователя
+	defaultDownloads, defaultReports := DefaultFolders()
+	if downloadFolderPath == "" {
+		downloadFolderPath = defaultDownloads
+	}
+	if reportLocationPath == "" {
+		reportLocationPath = defaultReports
+	}
+
+	//В зависимости от ОС возвращаем пути до каталогов загрузок и размещения с припиской корректных слэшей с целью
+	//предотвращения ошибок поиска пути
+	if currentOS == "windows" {
+		return downloadFolderPath + "\\", reportLocationPath + "\\"
+	} else {
+		return downloadFolderPath + "/", reportLocationPath + "/"
+	}
+}
+
+// DefaultFolders Функция, возвращающая пути по-умолчанию до директории загрузок и до директории расположения отчёта в
+// зависимости от ОС пользователя
+func DefaultFolders() (string, string) {
+	//Домашний каталог текущего пользователя (C:\Users\<пользователь> для Windows, /Users/<пользователь> для MacOS),
+	// если его не удалось определить, используется текущая директория
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = "."
+	}
+
+	switch runtime.GOOS {
+	//Для Windows и MacOS путями по-умолчанию являются загрузки и рабочий стол текущего пользователя
+	case "windows", "darwin":
+		return filepath.Join(home, "Downloads"), filepath.Join(home, "Desktop")
+	//Для Linux путём по-умолчанию является текущая директория "."
+	default:
+		return ".", "."
+	}
+}
+
+// windowsVariable Переменная окружения в пути в виде %ИМЯ% (как в Windows)
+var windowsVariable = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// ExpandPath Функция, подставляющая в путь из файла конфигураций домашний каталог пользователя вместо "~" в начале
+// пути и значения переменных окружения вместо %ИМЯ%, $ИМЯ и ${ИМЯ} (например, %USERPROFILE%\Downloads или
+// $HOME/Desktop). Неизвестные переменные окружения остаются в пути как есть
+func ExpandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+
+	path = windowsVariable.ReplaceAllStringFunc(path, func(variable string) string {
+		if value, ok := os.LookupEnv(strings.Trim(variable, "%")); ok {
+			return value
+		}
+		return variable
+	})
+
+	return os.Expand(path, func(name string) string {
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		return "$" + name
+	})
+}
+
+// ReadSecret Функция, возвращающая секрет из файла конфигураций, а если он не указан - вывод команды command (например,
+// "secret-tool lookup service trackattendance" или "security find-generic-password -s trackattendance -w"), которая
+// берёт секрет из хранилища паролей системы. Аргументы команды разделяются пробелами и, как в командной оболочке,
+// могут быть заключены в одинарные или двойные кавычки ("secret-tool lookup service 'track attendance'"). Конечный
+// перевод строки отбрасывается
+func ReadSecret(ctx context.Context, secret, command string) (string, error) {
+	if secret != "" || command == "" {
+		return secret, nil
+	}
+
+	args, err := SplitCommand(command)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+	output, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("ошибка выполнения команды %v: %w", args[0], err)
+	}
+
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+// SplitCommand Функция, разделяющая строку команды на аргументы так же, как командная оболочка: аргументы разделяются
+// пробелами, в одинарных и двойных кавычках пробелы сохраняются. Обратная косая черта экранирует только кавычку или
+// пробел, поэтому пути Windows ("C:\Tools\keepass.exe") указываются без удвоения косых черт
+func SplitCommand(command string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	//Начат ли аргумент (пустые кавычки - тоже аргумент) и открытая кавычка
+	started, quote := false, rune(0)
+
+	symbols := []rune(command)
+	for i := 0; i < len(symbols); i++ {
+		symbol := symbols[i]
+		switch {
+		//Экранированная кавычка или пробел записываются в аргумент как есть (в одинарных кавычках экранирования нет)
+		case symbol == '\\' && quote != '\'' && i+1 < len(symbols) && (symbols[i+1] == '"' ||
+			quote == 0 && (symbols[i+1] == '\'' || unicode.IsSpace(symbols[i+1]))):
+			i++
+			started = true
+			current.WriteRune(symbols[i])
+		case quote != 0 && symbol == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(symbol)
+		case symbol == '\'' || symbol == '"':
+			started, quote = true, symbol
+		case unicode.IsSpace(symbol):
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			started = true
+			current.WriteRune(symbol)
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("незакрытая кавычка в команде: %v", command)
+	}
+	if started {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// SetSchedule Функция, считывающая расписание пар, допуски и порог опоздания из секции расписания
+func SetSchedule(section *ini.Section) (schedule.Schedule, error) {
+	//Переменная расписания
+	var lessons schedule.Schedule
+
+	//Считываем из файла конфигураций список пар, допуски, порог опоздания и порог раннего ухода
+	lessonBounds := section.Key("lessons").String()
+	toleranceBefore := section.Key("tolerance_before").String()
+	toleranceAfter := section.Key("tolerance_after").String()
+	lessonNames := section.Key("lesson_names").String()
+	lateThreshold := section.Key("late_after_minutes").String()
+	graceMinutes := section.Key("grace_minutes").String()
+	earlyExitThreshold := section.Key("early_exit_threshold").String()
+	technicalCallThreshold := section.Key("technical_call_threshold").String()
+	clockDriftTolerance := section.Key("clock_drift_tolerance").String()
+
+	//Если значения не установлены, ставим стандартное расписание и значения по-умолчанию
+	if lessonBounds == "" {
+		lessonBounds = DefaultLessons
+	}
+	if toleranceBefore == "" {
+		toleranceBefore = "15"
+	}
+	if toleranceAfter == "" {
+		toleranceAfter = "15"
+	}
+	//Прежнее название порога опоздания (late_threshold) поддерживается для старых файлов конфигураций
+	if lateThreshold == "" {
+		lateThreshold = section.Key("late_threshold").MustString("5")
+	}
+	if graceMinutes == "" {
+		graceMinutes = "0"
+	}
+	if earlyExitThreshold == "" {
+		earlyExitThreshold = "5"
+	}
+	if technicalCallThreshold == "" {
+		technicalCallThreshold = "0"
+	}
+	if clockDriftTolerance == "" {
+		clockDriftTolerance = "2"
+	}
+
+	//Переводим допуски, порог опоздания и порог раннего ухода из минут в секунды с помощью вспомогательной функции ParseMinutes()
+	var err error
+	if lessons.ToleranceBefore, err = schedule.ParseMinutes(toleranceBefore); err != nil {
+		return lessons, err
+	}
+	if lessons.ToleranceAfter, err = schedule.ParseMinutes(toleranceAfter); err != nil {
+		return lessons, err
+	}
+	if lessons.GracePeriod, err = schedule.ParseMinutes(graceMinutes); err != nil {
+		return lessons, err
+	}
+
+	//Порог опоздания указывается одним значением для всех пар или через запятую для каждой пары по порядку номеров.
+	// Парам без своего порога назначается первый порог из списка
+	var lateThresholds []int
+	for _, threshold := range strings.Split(lateThreshold, ",") {
+		seconds, err := schedule.ParseMinutes(strings.TrimSpace(threshold))
+		if err != nil {
+			return lessons, err
+		}
+		lateThresholds = append(lateThresholds, seconds)
+	}
+	lessons.LateThreshold = lateThresholds[0]
+	if lessons.EarlyExitThreshold, err = schedule.ParseMinutes(earlyExitThreshold); err != nil {
+		return lessons, err
+	}
+	if lessons.TechnicalCallThreshold, err = schedule.ParseMinutes(technicalCallThreshold); err != nil {
+		return lessons, err
+	}
+	if lessons.ClockDriftTolerance, err = schedule.ParseMinutes(clockDriftTolerance); err != nil {
+		return lessons, err
+	}
+
+	//Считываем долю продолжительности собрания, начиная с которой участник считается присутствовавшим полностью
+	lessons.PresenceShare = section.Key("presence_share").MustInt(0)
+	if lessons.PresenceShare < 0 || lessons.PresenceShare > 100 {
+		return lessons, fmt.Errorf("доля присутствия должна быть указана в процентах от 0 до 100: %d", lessons.PresenceShare)
+	}
+
+	//Считываем часовой пояс аудитории и часовой пояс времени в отчётах MS Teams
+	if lessons.TimeZone, err = schedule.LoadTimeZone(section.Key("timezone").String()); err != nil {
+		return lessons, err
+	}
+	if lessons.ReportTimeZone, err = schedule.LoadTimeZone(section.Key("report_timezone").String()); err != nil {
+		return lessons, err
+	}
+
+	//Общий список пар и списки пар отдельных дней недели
+	if lessons.Lessons, err = parseLessons(lessonBounds, lateThresholds, lessonNames); err != nil {
+		return lessons, err
+	}
+	for weekday, key := range WeekdayKeys {
+		bounds := strings.TrimSpace(section.Key(key).String())
+		if bounds == "" {
+			continue
+		}
+		weekdayLessons, err := parseLessons(bounds, lateThresholds, lessonNames)
+		if err != nil {
+			return lessons, fmt.Errorf("ошибка в расписании %v: %w", key, err)
+		}
+		if lessons.Weekdays == nil {
+			lessons.Weekdays = make(map[time.Weekday][]schedule.Lesson)
+		}
+		lessons.Weekdays[weekday] = weekdayLessons
+	}
+
+	return lessons, nil
+}
+
+// WeekdayKeys Ключи секции schedule со списками пар отдельных дней недели
+var WeekdayKeys = map[time.Weekday]string{
+	time.Monday:    "lessons_mon",
+	time.Tuesday:   "lessons_tue",
+	time.Wednesday: "lessons_wed",
+	time.Thursday:  "lessons_thu",
+	time.Friday:    "lessons_fri",
+	time.Saturday:  "lessons_sat",
+	time.Sunday:    "lessons_sun",
+}
+
+// parseLessons Функция, считывающая список пар вида "08:00-09:30,09:40-11:10" с порогами опоздания и названиями пар
+// по порядку номеров
+func parseLessons(lessonBounds string, lateThresholds []int, lessonNames string) ([]schedule.Lesson, error) {
+	var lessons []schedule.Lesson
+
+	//Цикл по всем парам, перечисленным через запятую
+	for i, bounds := range strings.Split(lessonBounds, ",") {
+		//Разделяем строку пары на время начала и окончания
+		words := strings.Split(strings.TrimSpace(bounds), "-")
+		if len(words) != 2 {
+			return nil, fmt.Errorf("некорректный формат пары в расписании: %v", bounds)
+		}
+
+		//Время начала и окончания пары получаем с помощью функции ParseClock()
+		start, err := schedule.ParseClock(words[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := schedule.ParseClock(words[1])
+		if err != nil {
+			return nil, err
+		}
+
+		//Добавляем пару в расписание
+		lessons = append(lessons, schedule.Lesson{
+			Name:          "Пара " + strconv.Itoa(i+1),
+			Start:         start,
+			End:           end,
+			LateThreshold: lateThresholds[0],
+		})
+		if i < len(lateThresholds) {
+			lessons[i].LateThreshold = lateThresholds[i]
+		}
+	}
+
+	//Названия пар для вывода: шаблон с номером пары ("Занятие %d") или названия через запятую по порядку номеров
+	switch {
+	case lessonNames == "":
+	case strings.Contains(lessonNames, "%d"):
+		for i := range lessons {
+			lessons[i].Label = fmt.Sprintf(strings.TrimSpace(lessonNames), i+1)
+		}
+	default:
+		names := strings.Split(lessonNames, ",")
+		if len(names) > len(lessons) {
+			return nil, fmt.Errorf("названий пар (%d) больше, чем пар в расписании (%d)", len(names), len(lessons))
+		}
+		for i, name := range names {
+			lessons[i].Label = strings.TrimSpace(name)
+		}
+	}
+	if len(lateThresholds) > len(lessons) {
+		return nil, fmt.Errorf("порогов опоздания (%d) больше, чем пар в расписании (%d)", len(lateThresholds),
+			len(lessons))
+	}
+
+	return lessons, nil
+}
+
+// CheckLessons Функция, проверяющая расписание пар в виде значения ключа lessons ("08:00-09:30,09:40-11:10")
+func CheckLessons(lessonBounds string) error {
+	section, err := ini.Empty().NewSection("schedule")
+	if err != nil {
+		return err
+	}
+	if _, err := section.NewKey("lessons", lessonBounds); err != nil {
+		return err
+	}
+	_, err = SetSchedule(section)
+
+	return err
+}
+
+// WriteLessons Функция, заменяющая расписание пар (ключ lessons секции schedule) в файле конфигураций. Остальные строки
+// файла, включая комментарии, не изменяются. Расписание проверяется перед записью
+func WriteLessons(path, lessonBounds string) error {
+	if err := CheckLessons(lessonBounds); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла конфигураций: %w", err)
+	}
+
+	//Ищем ключ lessons в секции schedule, а если его нет - добавляем ключ после заголовка секции
+	lines := strings.Split(string(content), "\n")
+	current, header, replaced := "", -1, false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			current = strings.TrimSpace(strings.Trim(strings.SplitN(trimmed, "]", 2)[0], "["))
+			if current == "schedule" {
+				header = i
+			}
+			continue
+		}
+		if key, _, ok := strings.Cut(trimmed, "="); ok && current == "schedule" && strings.TrimSpace(key) == "lessons" {
+			lines[i], replaced = "lessons="+lessonBounds, true
+			break
+		}
+	}
+	switch {
+	case replaced:
+	case header >= 0:
+		lines = append(lines[:header+1], append([]string{"lessons=" + lessonBounds}, lines[header+1:]...)...)
+	default:
+		lines = append(lines, "", "[schedule]", "lessons="+lessonBounds)
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла конфигураций: %w", err)
+	}
+
+	return nil
+}
+
+// SetHistory Функция, считывающая настройки истории посещаемости из секции history
+func SetHistory(section *ini.Section) history.Configuration {
+	return history.Configuration{
+		Enabled: section.Key("enabled").MustBool(false),
+		Path:    ExpandPath(section.Key("database_path").MustString("history.db")),
+	}
+}
+
+// SetNotify Функция, считывающая настройки оповещений кураторов из секции notify
+func SetNotify(section *ini.Section) notify.Configuration {
+	return notify.Configuration{
+		WebhookURL:       section.Key("webhook_url").String(),
+		TwilioAccountSID: section.Key("twilio_account_sid").String(),
+		TwilioAuthToken:  section.Key("twilio_auth_token").String(),
+		TwilioFrom:       section.Key("twilio_from").String(),
+
+		TelegramBotToken:     section.Key("telegram_bot_token").String(),
+		TelegramChatID:       section.Key("telegram_chat_id").String(),
+		TelegramAttachReport: section.Key("telegram_attach_report").MustBool(false),
+
+		FollowUpAbsences: section.Key("followup_absences").MustInt(0),
+
+		Batch:        section.Key("batch").MustBool(false),
+		TelegramRate: section.Key("telegram_rate").MustInt(20),
+		WebhookRate:  section.Key("webhook_rate").MustInt(0),
+		TwilioRate:   section.Key("twilio_rate").MustInt(0),
+	}
+}
+
+// SetColumns Функция, считывающая названия столбцов таблицы участников итогового отчёта из секции columns
+func SetColumns(section *ini.Section) report.Columns {
+	return report.Columns{
+		Group:         strings.TrimSpace(section.Key("group").String()),
+		FullName:      strings.TrimSpace(section.Key("full_name").String()),
+		RecordBook:    strings.TrimSpace(section.Key("record_book").String()),
+		Presence:      strings.TrimSpace(section.Key("presence").String()),
+		Delay:         strings.TrimSpace(section.Key("delay").String()),
+		EarlyExit:     strings.TrimSpace(section.Key("early_exit").String()),
+		Participation: strings.TrimSpace(section.Key("participation").String()),
+		Booking:       strings.TrimSpace(section.Key("booking").String()),
+	}
+}
+
+// SetEmail Функция, считывающая настройки отправки отчётов по электронной почте из секции email
+func SetEmail(section *ini.Section) (email.Configuration, error) {
+	//Переменная настроек
+	var settings email.Configuration
+
+	//Если отправка отчётов не включена, остальные настройки не считываются
+	settings.SendReport = section.Key("send_report").MustBool(false)
+	if !settings.SendReport {
+		return settings, nil
+	}
+
+	settings.Host = section.Key("smtp_host").String()
+	settings.Port = section.Key("smtp_port").MustInt(587)
+	settings.Username = section.Key("username").String()
+	settings.Password = section.Key("password").String()
+	settings.From = section.Key("from").String()
+
+	var err error
+	if settings.Recipients, err = email.ParseRecipients(section.Key("recipients").String()); err != nil {
+		return settings, err
+	}
+
+	//Без сервера, отправителя и получателей отправка отчёта невозможна
+	if settings.Host == "" {
+		return settings, fmt.Errorf("в файле конфигураций не указан smtp_host для отправки отчётов")
+	}
+	if settings.From == "" && settings.Username == "" {
+		return settings, fmt.Errorf("в файле конфигураций не указан отправитель (from или username) для отправки отчётов")
+	}
+	if len(settings.Recipients) == 0 {
+		return settings, fmt.Errorf("в файле конфигураций не указаны получатели (recipients) для отправки отчётов")
+	}
+
+	return settings, nil
+}
+
+// SetLimits Функция, считывающая ограничения чтения одного отчёта MS Teams из секции limits
+func SetLimits(section *ini.Section) (teamsreport.Limits, error) {
+	limits := teamsreport.Limits{
+		MaxFileSize: section.Key("max_file_size").MustInt64(50) << 20,
+		MaxRows:     section.Key("max_rows").MustInt(100000),
+		FileTimeout: time.Duration(section.Key("file_timeout").MustInt(60)) * time.Second,
+	}
+	if limits.MaxFileSize < 0 || limits.MaxRows < 0 || limits.FileTimeout < 0 {
+		return limits, fmt.Errorf("ограничения чтения отчётов не могут быть отрицательными")
+	}
+
+	return limits, nil
+}
+
+// SetCourses Функция, считывающая дисциплины собраний из секции courses: ключ - шаблон названия собрания, значение -
+// "Дисциплина | группы через запятую | преподаватель". Дисциплины проверяются в порядке указания
+func SetCourses(section *ini.Section) ([]roster.Course, error) {
+	var courses []roster.Course
+	for _, key := range section.Keys() {
+		course, err := roster.ParseCourse(key.Name(), key.String())
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, course)
+	}
+
+	return courses, nil
+}
+
+// SetLog Функция, считывающая настройки журнала сообщений программы из секции log
+func SetLog(section *ini.Section) (logging.Configuration, error) {
+	settings := logging.Configuration{
+		Path:       ExpandPath(strings.TrimSpace(section.Key("path").String())),
+		MaxSize:    section.Key("max_size").MustInt64(10) << 20,
+		MaxBackups: section.Key("max_backups").MustInt(5),
+	}
+	if settings.MaxSize < 0 || settings.MaxBackups < 0 {
+		return settings, fmt.Errorf("размер и количество файлов журнала сообщений не могут быть отрицательными")
+	}
+
+	var err error
+	settings.Level, err = logging.ParseLevel(section.Key("level").String())
+
+	return settings, err
+}
+
+// SetSheets Функция, считывающая настройки выгрузки таблицы посещаемости в Google Sheets из секции sheets
+func SetSheets(section *ini.Section) (sheets.Configuration, error) {
+	//Переменная настроек
+	var settings sheets.Configuration
+
+	//Если выгрузка не включена, остальные настройки не считываются
+	settings.Enabled = section.Key("enabled").MustBool(false)
+	if !settings.Enabled {
+		return settings, nil
+	}
+
+	settings.SpreadsheetID = strings.TrimSpace(section.Key("spreadsheet_id").String())
+	settings.CredentialsPath = ExpandPath(section.Key("credentials_path").MustString("service_account.json"))
+
+	var err error
+	if settings.Layout, err = sheets.ParseLayout(section.Key("layout").String()); err != nil {
+		return settings, err
+	}
+
+	//Без таблицы выгрузка невозможна
+	if settings.SpreadsheetID == "" {
+		return settings, fmt.Errorf("в файле конфигураций не указан spreadsheet_id для выгрузки в Google Sheets")
+	}
+
+	return settings, nil
+}
+
+// SetXAPI Функция, считывающая настройки выгрузки посещаемости в хранилище учебных записей из секции xapi
+func SetXAPI(section *ini.Section) (xapi.Configuration, error) {
+	//Переменная настроек
+	var settings xapi.Configuration
+
+	//Если выгрузка не включена, остальные настройки не считываются
+	settings.Enabled = section.Key("enabled").MustBool(false)
+	if !settings.Enabled {
+		return settings, nil
+	}
+
+	settings.Endpoint = strings.TrimSpace(section.Key("endpoint").String())
+	settings.Username = section.Key("username").String()
+	settings.Password = section.Key("password").String()
+	settings.HomePage = strings.TrimSpace(section.Key("homepage").String())
+
+	//Без адреса хранилища и сайта университета выгрузка невозможна
+	if settings.Endpoint == "" || settings.HomePage == "" {
+		return settings, fmt.Errorf("в файле конфигураций не указаны endpoint и homepage для выгрузки xAPI")
+	}
+
+	return settings, nil
+}
+
+// SetGraph Функция, считывающая настройки подключения к Microsoft Graph из секции graph
+func SetGraph(section *ini.Section) (graph.Configuration, error) {
+	//Переменная настроек
+	var settings graph.Configuration
+
+	//Если загрузка через Microsoft Graph не включена, остальные настройки не считываются
+	settings.Enabled = section.Key("enabled").MustBool(false)
+	if !settings.Enabled {
+		return settings, nil
+	}
+
+	//Адреса вместо стандартных указываются для проверки загрузки на имитации Microsoft Graph (команда mock-graph)
+	settings.Endpoint = section.Key("endpoint").String()
+	settings.LoginEndpoint = section.Key("login_endpoint").String()
+	for _, endpoint := range []*string{&settings.Endpoint, &settings.LoginEndpoint} {
+		if *endpoint != "" && !strings.HasSuffix(*endpoint, "/") {
+			*endpoint += "/"
+		}
+	}
+
+	settings.AuthFlow = section.Key("auth_flow").String()
+	settings.TenantID = section.Key("tenant_id").String()
+	settings.ClientID = section.Key("client_id").String()
+	settings.ClientSecret = section.Key("client_secret").String()
+	settings.UserID = section.Key("user_id").String()
+	settings.MeetingID = section.Key("meeting_id").String()
+	settings.CalendarCheck = section.Key("calendar_check").MustBool(false)
+
+	//Способ авторизации по-умолчанию - от имени приложения
+	if settings.AuthFlow == "" {
+		settings.AuthFlow = "client_credentials"
+	}
+
+	//Без тенанта и приложения авторизация невозможна
+	if settings.TenantID == "" || settings.ClientID == "" {
+		return settings, fmt.Errorf("в файле конфигураций не указаны tenant_id и client_id для Microsoft Graph")
+	}
+
+	//При авторизации от имени приложения необходимо явно указать организатора собраний
+	if settings.AuthFlow == "client_credentials" && settings.UserID == "" {
+		return settings, fmt.Errorf("для авторизации client_credentials в файле конфигураций необходимо указать user_id организатора")
+	}
+
+	//Если конкретное собрание не указано, считываем период, за который загружаются все собрания
+	if settings.MeetingID == "" {
+		var err error
+
+		settings.DateFrom, err = time.ParseInLocation("02.01.2006", section.Key("date_from").String(), time.Local)
+		if err != nil {
+			return settings, fmt.Errorf("ошибка чтения начала периода загрузки собраний: %w", err)
+		}
+
+		//Если конец периода не указан, период состоит из одного дня
+		dateTo := section.Key("date_to").String()
+		if dateTo == "" {
+			settings.DateTo = settings.DateFrom
+		} else {
+			settings.DateTo, err = time.ParseInLocation("02.01.2006", dateTo, time.Local)
+			if err != nil {
+				return settings, fmt.Errorf("ошибка чтения конца периода загрузки собраний: %w", err)
+			}
+		}
+	}
+
+	return settings, nil
+}
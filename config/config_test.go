@@ -0,0 +1,39 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+/*====================================================================================================================*/
+
+// TestSplitCommand Проверка разделения команды чтения секрета на аргументы: кавычки, экранирование и пути Windows
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+	}{
+		{"secret-tool lookup service trackattendance", []string{"secret-tool", "lookup", "service", "trackattendance"}},
+		{`security find-generic-password -s "track attendance" -w`,
+			[]string{"security", "find-generic-password", "-s", "track attendance", "-w"}},
+		{`pass show 'Кафедра/отчёты xlsx'`, []string{"pass", "show", "Кафедра/отчёты xlsx"}},
+		{`C:\Tools\keepass.exe --entry "Отчёт \"xlsx\""`, []string{`C:\Tools\keepass.exe`, "--entry", `Отчёт "xlsx"`}},
+		{`echo a\ b '' "it's"`, []string{"echo", "a b", "", "it's"}},
+		{"  ", nil},
+	}
+
+	for _, test := range tests {
+		args, err := SplitCommand(test.command)
+		if err != nil {
+			t.Errorf("ошибка разделения команды %v: %v", test.command, err)
+			continue
+		}
+		if !slices.Equal(args, test.args) {
+			t.Errorf("команда %v разделена на %q, ожидалось %q", test.command, args, test.args)
+		}
+	}
+
+	if _, err := SplitCommand(`pass show "xlsx`); err == nil {
+		t.Errorf("незакрытая кавычка не обнаружена")
+	}
+}
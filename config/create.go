@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/ini.v1"
+)
+
+/*====================================================================================================================*/
+
+// SchedulePreset Структура готового расписания пар, которое выбирается при создании файла конфигураций
+type SchedulePreset struct {
+	//Название расписания
+	Name string
+	//Время начала и окончания пар в виде значения lessons секции schedule
+	Lessons string
+}
+
+// SchedulePresets Готовые расписания пар: стандартное и распространённые расписания с началом в 08:30 и в 09:00
+var SchedulePresets = []SchedulePreset{
+	{"Стандартное, с 08:00", DefaultLessons},
+	{"С 08:30", "08:30-10:00,10:10-11:40,11:50-13:20,14:00-15:30,15:40-17:10,17:20-18:50,19:00-20:30"},
+	{"С 09:00", "09:00-10:30,10:40-12:10,12:40-14:10,14:20-15:50,16:00-17:30,17:40-19:10,19:20-20:50"},
+}
+
+// Initial Структура основных настроек, которые запрашиваются при первом запуске программы. Пустые значения заменяются
+// значениями по-умолчанию при чтении файла конфигураций
+type Initial struct {
+	//Путь до директории загрузок
+	DownloadFolderPath string
+	//Путь до директории, в которую сохраняются отчёты
+	ReportLocationPath string
+	//Время начала и окончания пар через запятую
+	Lessons string
+	//Источник базы групп (.xlsx файл или ссылка на Google Sheets)
+	GroupsBase string
+}
+
+/*====================================================================================================================*/
+
+// Create Функция, создающая файл конфигураций с основными настройками первого запуска. Остальные настройки в файл не
+// записываются и принимают значения по-умолчанию, их описание приведено в cfg.ini из поставки программы
+func Create(path string, settings Initial) error {
+	if err := CheckLessons(settings.Lessons); err != nil {
+		return err
+	}
+
+	file := ini.Empty()
+	paths := file.Section("paths")
+	paths.Comment = "Файл конфигураций создан при первом запуске программы, описание остальных настроек приведено в " +
+		"cfg.ini из поставки программы"
+	paths.Key("download_folder_path").SetValue(settings.DownloadFolderPath)
+	paths.Key("report_location_folder").SetValue(settings.ReportLocationPath)
+	paths.Key("groups_base").SetValue(settings.GroupsBase)
+	file.Section("schedule").Key("lessons").SetValue(settings.Lessons)
+
+	if err := file.SaveTo(path); err != nil {
+		return fmt.Errorf("ошибка записи файла конфигураций: %w", err)
+	}
+
+	return nil
+}
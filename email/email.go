@@ -0,0 +1,233 @@
+// Package email Пакет отправки сформированных отчётов о посещаемости по электронной почте через SMTP
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mod.go/i18n"
+	"mod.go/report"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек отправки отчётов по электронной почте
+type Configuration struct {
+	//Отправлять ли сформированный отчёт автоматически
+	SendReport bool
+	//Адрес SMTP сервера
+	Host string
+	//Порт SMTP сервера (465 - TLS, 587 или 25 - STARTTLS, если сервер его поддерживает)
+	Port int
+	//Имя пользователя SMTP сервера
+	Username string
+	//Пароль пользователя SMTP сервера
+	Password string
+	//Адрес отправителя. Если не указан, используется имя пользователя
+	From string
+	//Адреса получателей отчёта (например, координатора курса)
+	Recipients []string
+}
+
+// attachmentTypes Типы содержимого вложения по расширению файла отчёта: отчёт отправляется в основном формате, который
+// выбран первым в formats секции [report] cfg.ini. Отчёты остальных форматов отправляются как двоичные файлы
+var attachmentTypes = map[string]string{
+	".csv":  "text/csv",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".json": "application/json",
+	".html": "text/html",
+	".htm":  "text/html",
+	".txt":  "text/plain",
+}
+
+/*====================================================================================================================*/
+
+// ParseRecipients Функция, разбирающая перечисленные через запятую адреса получателей
+func ParseRecipients(source string) ([]string, error) {
+	var recipients []string
+
+	for _, address := range strings.Split(source, ",") {
+		if strings.TrimSpace(address) == "" {
+			continue
+		}
+
+		parsed, err := mail.ParseAddress(strings.TrimSpace(address))
+		if err != nil {
+			return nil, fmt.Errorf("некорректный адрес получателя отчёта \"%v\": %w", address, err)
+		}
+		recipients = append(recipients, parsed.Address)
+	}
+
+	return recipients, nil
+}
+
+// SendReport Функция, отправляющая сформированный отчёт получателям вложением письма. В теме письма указываются
+// название и дата собрания
+func SendReport(ctx context.Context, settings Configuration, header report.Header, path string) error {
+	attachment, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения отчёта для отправки: %w", err)
+	}
+
+	from := settings.From
+	if from == "" {
+		from = settings.Username
+	}
+
+	subject := i18n.Sprintf("Отчёт о посещаемости: %v, %v", header.Title, header.Date)
+	message, err := FormMessage(from, settings.Recipients, subject, header, filepath.Base(path), attachment)
+	if err != nil {
+		return err
+	}
+
+	return send(ctx, settings, from, message)
+}
+
+// FormMessage Функция, формирующая письмо с отчётом во вложении в формате MIME
+func FormMessage(from string, recipients []string, subject string, header report.Header, name string,
+	attachment []byte) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	//Текст письма с оглавлением отчёта
+	text := fmt.Sprintf("%v: %v\r\n%v: %v\r\n%v: %v\r\n", i18n.T("Название собрания"), header.Title,
+		i18n.T("Дата проведения собрания"), header.Date, i18n.T("Номер пары"), header.LessonLabel())
+	if header.Course != "" {
+		text += i18n.T("Дисциплина") + ": " + header.Course + "\r\n"
+	}
+	if header.Lecturer != "" {
+		text += i18n.T("Преподаватель") + ": " + header.Lecturer + "\r\n"
+	}
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования текста письма: %w", err)
+	}
+	if _, err := part.Write([]byte(wrapBase64(text))); err != nil {
+		return nil, fmt.Errorf("ошибка формирования текста письма: %w", err)
+	}
+
+	//Вложение с отчётом, тип которого определяется по расширению файла отчёта
+	contentType, ok := attachmentTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		contentType = "application/octet-stream"
+	}
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	part, err = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {mime.FormatMediaType(contentType, map[string]string{"name": name})},
+		"Content-Disposition":       {disposition},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка формирования вложения письма: %w", err)
+	}
+	if _, err := part.Write([]byte(wrapBase64(string(attachment)))); err != nil {
+		return nil, fmt.Errorf("ошибка формирования вложения письма: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("ошибка формирования письма: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %v\r\n", from)
+	fmt.Fprintf(&message, "To: %v\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&message, "Subject: %v\r\n", mime.BEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
+
+// wrapBase64 Вспомогательная функция, кодирующая данные в base64 строками по 76 символов
+func wrapBase64(data string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(data))
+
+	var lines []string
+	for len(encoded) > 76 {
+		lines = append(lines, encoded[:76])
+		encoded = encoded[76:]
+	}
+	lines = append(lines, encoded)
+
+	return strings.Join(lines, "\r\n")
+}
+
+// send Вспомогательная функция, отправляющая письмо через SMTP сервер. На порту 465 соединение сразу устанавливается
+// по TLS, на остальных портах используется STARTTLS, если сервер его поддерживает
+func send(ctx context.Context, settings Configuration, from string, message []byte) error {
+	address := net.JoinHostPort(settings.Host, strconv.Itoa(settings.Port))
+
+	var dialer net.Dialer
+	connection, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к SMTP серверу: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		connection.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{ServerName: settings.Host}
+	if settings.Port == 465 {
+		connection = tls.Client(connection, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(connection, settings.Host)
+	if err != nil {
+		connection.Close()
+		return fmt.Errorf("ошибка подключения к SMTP серверу: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && settings.Port != 465 {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("ошибка установки защищённого соединения с SMTP сервером: %w", err)
+		}
+	}
+
+	if settings.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", settings.Username, settings.Password, settings.Host)); err != nil {
+			return fmt.Errorf("ошибка авторизации на SMTP сервере: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("ошибка указания отправителя письма: %w", err)
+	}
+	for _, recipient := range settings.Recipients {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("ошибка указания получателя письма %v: %w", recipient, err)
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		writer.Close()
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %w", err)
+	}
+
+	return client.Quit()
+}
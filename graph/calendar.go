@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+	"mod.go/schedule"
+	"net/url"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// EventWindow Промежуток до и после начала собрания, в котором событие календаря организатора может начинаться
+const EventWindow = time.Hour
+
+// calendarEvent Структура события календаря из ответа Microsoft Graph
+type calendarEvent struct {
+	Subject       string       `json:"subject"`
+	Start         dateTimeZone `json:"start"`
+	End           dateTimeZone `json:"end"`
+	OnlineMeeting *struct {
+		JoinURL string `json:"joinUrl"`
+	} `json:"onlineMeeting"`
+}
+
+// dateTimeZone Структура времени события календаря: время без смещения и часовой пояс, в котором оно указано
+type dateTimeZone struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+/*====================================================================================================================*/
+
+// FindEvent Функция, находящая в календаре организатора событие, с которым сопоставляется собрание, начавшееся в
+// start: событие со ссылкой на собрание Teams, плановое начало которого ближе всего к началу собрания (в пределах
+// EventWindow) и которое не закончилось к началу собрания. Если такого события нет, возвращается ложь
+func FindEvent(ctx context.Context, graph Configuration, token string, start time.Time) (schedule.Event, bool, error) {
+	query := url.Values{
+		"startDateTime": {start.Add(-EventWindow).Format(time.RFC3339)},
+		"endDateTime":   {start.Add(EventWindow).Format(time.RFC3339)},
+		"$select":       {"subject,start,end,onlineMeeting"},
+	}
+	events, err := getAll[calendarEvent](ctx, token, base(graph)+"calendarView?"+query.Encode())
+	if err != nil {
+		return schedule.Event{}, false, err
+	}
+
+	var found schedule.Event
+	var distance time.Duration
+	ok := false
+	for _, event := range events {
+		if event.OnlineMeeting == nil || event.OnlineMeeting.JoinURL == "" {
+			continue
+		}
+
+		//События с неразборчивым временем пропускаются
+		eventStart, err := event.Start.parse()
+		if err != nil {
+			continue
+		}
+		eventEnd, err := event.End.parse()
+		if err != nil || !eventEnd.After(start) {
+			continue
+		}
+
+		current := eventStart.Sub(start)
+		if current < 0 {
+			current = -current
+		}
+		if current <= EventWindow && (!ok || current < distance) {
+			found, distance, ok = schedule.Event{Subject: event.Subject, Start: eventStart, End: eventEnd}, current, true
+		}
+	}
+
+	return found, ok, nil
+}
+
+// parse Вспомогательная функция, переводящая время события календаря во время с учётом его часового пояса.
+// Microsoft Graph по-умолчанию указывает время событий в UTC
+func (source dateTimeZone) parse() (time.Time, error) {
+	location := time.UTC
+	if source.TimeZone != "" {
+		if loaded, err := time.LoadLocation(source.TimeZone); err == nil {
+			location = loaded
+		}
+	}
+
+	return time.ParseInLocation("2006-01-02T15:04:05.9999999", source.DateTime, location)
+}
@@ -0,0 +1,559 @@
+// Package graph Пакет загрузки отчётов о посещаемости собраний напрямую из Microsoft Graph
+package graph
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+	"mod.go/schedule"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек подключения к Microsoft Graph
+type Configuration struct {
+	//Включена ли загрузка отчётов через Microsoft Graph
+	Enabled bool
+	//Способ авторизации: "client_credentials" или "device_code"
+	AuthFlow string
+	//Идентификатор клиента (тенанта) Azure AD
+	TenantID string
+	//Идентификатор зарегистрированного приложения
+	ClientID string
+	//Секрет приложения (только для client_credentials)
+	ClientSecret string
+	//Идентификатор или адрес электронной почты организатора собраний
+	UserID string
+	//Идентификатор конкретного собрания
+	MeetingID string
+	//Начало периода, за который загружаются собрания
+	DateFrom time.Time
+	//Конец периода, за который загружаются собрания
+	DateTo time.Time
+	//Адреса Microsoft Graph и сервиса авторизации вместо стандартных (например, имитации Microsoft Graph)
+	Endpoint, LoginEndpoint string
+	//Сопоставляются ли собрания с событиями календаря организатора, плановое время которых используется вместо
+	// расписания пар для определения пары, опозданий и раннего ухода
+	CalendarCheck bool
+}
+
+// Meeting Структура собрания Teams из ответа Microsoft Graph. Ссылка на запись собрания запрашивается отдельно
+// функцией FindRecording()
+type Meeting struct {
+	ID           string `json:"id"`
+	Subject      string `json:"subject"`
+	JoinWebURL   string `json:"joinWebUrl"`
+	RecordingURL string `json:"-"`
+}
+
+// recording Структура записи собрания из ответа Microsoft Graph
+type recording struct {
+	ID                  string `json:"id"`
+	RecordingContentURL string `json:"recordingContentUrl"`
+}
+
+// AttendanceReport Структура отчёта о посещаемости собрания из ответа Microsoft Graph
+type AttendanceReport struct {
+	ID                   string `json:"id"`
+	MeetingStartDateTime string `json:"meetingStartDateTime"`
+	MeetingEndDateTime   string `json:"meetingEndDateTime"`
+}
+
+// AttendanceInterval Структура интервала нахождения участника на собрании
+type AttendanceInterval struct {
+	JoinDateTime  string `json:"joinDateTime"`
+	LeaveDateTime string `json:"leaveDateTime"`
+}
+
+// AttendanceRecord Структура записи об участнике собрания из ответа Microsoft Graph
+type AttendanceRecord struct {
+	EmailAddress             string `json:"emailAddress"`
+	TotalAttendanceInSeconds int    `json:"totalAttendanceInSeconds"`
+	Role                     string `json:"role"`
+	Identity                 struct {
+		DisplayName string `json:"displayName"`
+	} `json:"identity"`
+	AttendanceIntervals []AttendanceInterval `json:"attendanceIntervals"`
+}
+
+/*====================================================================================================================*/
+
+// Адрес Microsoft Graph и сервиса авторизации
+var (
+	Endpoint      = "https://graph.microsoft.com/v1.0/"
+	LoginEndpoint = "https://login.microsoftonline.com/"
+)
+
+// TimeZone Часовой пояс, в котором записывается время в отчётах, загруженных из Microsoft Graph. Устанавливается равным
+// часовому поясу отчётов из файла конфигураций
+var TimeZone = time.Local
+
+// ErrNoReports Ошибка, возвращаемая, если в Microsoft Graph не найдено ни одного отчёта о посещаемости
+var ErrNoReports = errors.New("в Microsoft Graph не найдено отчётов о посещаемости собраний")
+
+/*====================================================================================================================*/
+
+// RequestToken Функция, получающая токен доступа к Microsoft Graph выбранным способом авторизации
+func RequestToken(ctx context.Context, graph Configuration) (string, error) {
+	//Адрес получения токена для тенанта
+	tokenURL := LoginEndpoint + graph.TenantID + "/oauth2/v2.0/token"
+
+	//Авторизация от имени приложения по секрету
+	if graph.AuthFlow == "client_credentials" {
+		token, errorCode, err := postTokenForm(ctx, tokenURL, url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {graph.ClientID},
+			"client_secret": {graph.ClientSecret},
+			"scope":         {"https://graph.microsoft.com/.default"},
+		})
+		if err != nil {
+			return "", err
+		}
+		if token == "" {
+			return "", fmt.Errorf("не удалось получить токен доступа Microsoft Graph: %v", errorCode)
+		}
+		return token, nil
+	}
+
+	if graph.AuthFlow != "device_code" {
+		return "", fmt.Errorf("неизвестный способ авторизации Microsoft Graph: %v", graph.AuthFlow)
+	}
+
+	//Авторизация от имени пользователя по коду устройства: запрашиваем код и выводим инструкцию пользователю
+	response, err := postForm(ctx, LoginEndpoint+graph.TenantID+"/oauth2/v2.0/devicecode", url.Values{
+		"client_id": {graph.ClientID},
+		"scope":     {"OnlineMeetings.Read OnlineMeetingArtifact.Read.All Calendars.Read"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса кода устройства: %w", err)
+	}
+	defer response.Body.Close()
+
+	var deviceCode struct {
+		DeviceCode string `json:"device_code"`
+		Interval   int    `json:"interval"`
+		Message    string `json:"message"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&deviceCode); err != nil {
+		return "", fmt.Errorf("ошибка получения кода устройства: %w", err)
+	}
+	if deviceCode.DeviceCode == "" {
+		return "", fmt.Errorf("сервис авторизации не выдал код устройства")
+	}
+	fmt.Println(deviceCode.Message)
+
+	//Опрашиваем сервис авторизации, пока пользователь не подтвердит вход или не будет отменён контекст
+	//Если сервис авторизации не указал интервал опроса, используется стандартный интервал 5 с (RFC 8628)
+	interval := time.Duration(deviceCode.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		token, errorCode, err := postTokenForm(ctx, tokenURL, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"client_id":   {graph.ClientID},
+			"device_code": {deviceCode.DeviceCode},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		//Разбор ситуации. Пока вход не подтверждён, продолжаем опрос, иначе возвращаем токен или ошибку
+		switch {
+		case token != "":
+			return token, nil
+		case errorCode == "authorization_pending":
+		case errorCode == "slow_down":
+			interval += 5 * time.Second
+		default:
+			return "", fmt.Errorf("ошибка авторизации Microsoft Graph: %v", errorCode)
+		}
+	}
+}
+
+// postTokenForm Вспомогательная функция, отправляющая запрос на получение токена и возвращающая токен или код ошибки
+// сервиса авторизации
+func postTokenForm(ctx context.Context, tokenURL string, form url.Values) (string, string, error) {
+	response, err := postForm(ctx, tokenURL, form)
+	if err != nil {
+		return "", "", fmt.Errorf("ошибка запроса токена доступа: %w", err)
+	}
+	defer response.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", "", fmt.Errorf("ошибка чтения ответа сервиса авторизации: %w", err)
+	}
+
+	return token.AccessToken, token.Error, nil
+}
+
+// postForm Вспомогательная функция, отправляющая POST запрос с формой с учётом контекста
+func postForm(ctx context.Context, requestURL string, form url.Values) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return http.DefaultClient.Do(request)
+}
+
+// get Вспомогательная функция, выполняющая GET запрос к Microsoft Graph и разбирающая ответ в переменную out
+func get(ctx context.Context, token, requestURL string, out interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Microsoft Graph: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к Microsoft Graph: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("Microsoft Graph вернул ошибку %v: %s", response.Status, body)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return fmt.Errorf("ошибка чтения ответа Microsoft Graph: %w", err)
+	}
+
+	return nil
+}
+
+// getAll Вспомогательная функция, собирающая все страницы списка Microsoft Graph (по ссылке @odata.nextLink)
+func getAll[T any](ctx context.Context, token, requestURL string) ([]T, error) {
+	var values []T
+
+	for requestURL != "" {
+		var page struct {
+			Value    []T    `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := get(ctx, token, requestURL, &page); err != nil {
+			return nil, err
+		}
+
+		values = append(values, page.Value...)
+		requestURL = page.NextLink
+	}
+
+	return values, nil
+}
+
+// base Вспомогательная функция, возвращающая корень запросов: организатора из конфигураций или текущего
+// авторизованного пользователя
+func base(graph Configuration) string {
+	if graph.UserID != "" {
+		return Endpoint + "users/" + url.PathEscape(graph.UserID) + "/"
+	}
+
+	return Endpoint + "me/"
+}
+
+/*====================================================================================================================*/
+
+// FindMeetings Функция, возвращающая собрания организатора: указанное в конфигурациях или все собрания за период
+func FindMeetings(ctx context.Context, graph Configuration, token string) ([]Meeting, error) {
+	//Корень запросов: конкретный пользователь или текущий авторизованный пользователь
+	root := base(graph)
+
+	//Если указано конкретное собрание, возвращаем только его
+	if graph.MeetingID != "" {
+		var meeting Meeting
+		if err := get(ctx, token, root+"onlineMeetings/"+url.PathEscape(graph.MeetingID), &meeting); err != nil {
+			return nil, err
+		}
+		return []Meeting{meeting}, nil
+	}
+
+	//Иначе находим в календаре все онлайн собрания за период (включая последний день периода)
+	query := url.Values{
+		"startDateTime": {graph.DateFrom.Format(time.RFC3339)},
+		"endDateTime":   {graph.DateTo.AddDate(0, 0, 1).Format(time.RFC3339)},
+		"$select":       {"subject,onlineMeeting"},
+	}
+	events, err := getAll[struct {
+		OnlineMeeting *struct {
+			JoinURL string `json:"joinUrl"`
+		} `json:"onlineMeeting"`
+	}](ctx, token, root+"calendarView?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var meetings []Meeting
+
+	//Для каждого события календаря со ссылкой на собрание Teams находим само собрание по ссылке присоединения
+	for _, event := range events {
+		if event.OnlineMeeting == nil || event.OnlineMeeting.JoinURL == "" {
+			continue
+		}
+
+		//Одинарные кавычки в строке фильтра OData экранируются удвоением
+		joinURL := strings.ReplaceAll(event.OnlineMeeting.JoinURL, "'", "''")
+		filter := url.Values{"$filter": {"JoinWebUrl eq '" + joinURL + "'"}}
+		found, err := getAll[Meeting](ctx, token, root+"onlineMeetings?"+filter.Encode())
+		if err != nil {
+			return nil, err
+		}
+		meetings = append(meetings, found...)
+	}
+
+	return meetings, nil
+}
+
+// FetchReports Функция, загружающая отчёты о посещаемости собраний из Microsoft Graph в каталог загрузок в формате
+// .csv файлов MS Teams и возвращающая пути до них
+func FetchReports(ctx context.Context, graph Configuration, downloadPath string) ([]string, error) {
+	//Получаем токен доступа
+	token, err := RequestToken(ctx, graph)
+	if err != nil {
+		return nil, err
+	}
+
+	//Находим собрания организатора
+	meetings, err := FindMeetings(ctx, graph, token)
+	if err != nil {
+		return nil, err
+	}
+
+	//Массив путей до сохранённых отчётов
+	var reports []string
+
+	//Цикл по всем найденным собраниям
+	for _, meeting := range meetings {
+		meeting.RecordingURL = FindRecording(ctx, graph, token, meeting.ID)
+		meetingURL := base(graph) + "onlineMeetings/" + url.PathEscape(meeting.ID) + "/attendanceReports"
+
+		//У одного собрания может быть несколько отчётов (по одному на каждый сеанс собрания)
+		attendanceReports, err := getAll[AttendanceReport](ctx, token, meetingURL)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, attendanceReport := range attendanceReports {
+			records, err := getAll[AttendanceRecord](ctx, token,
+				meetingURL+"/"+url.PathEscape(attendanceReport.ID)+"/attendanceRecords")
+			if err != nil {
+				return nil, err
+			}
+
+			//Сохраняем отчёт в каталог загрузок с помощью функции WriteReport()
+			report := downloadPath + "meetingAttendanceReport_" + attendanceReport.ID + ".csv"
+			if err := WriteReport(report, meeting, attendanceReport, records); err != nil {
+				return nil, err
+			}
+
+			reports = append(reports, report)
+		}
+	}
+
+	if len(reports) == 0 {
+		return nil, ErrNoReports
+	}
+
+	return reports, nil
+}
+
+// FindRecording Функция, возвращающая ссылку на последнюю запись собрания. Для чтения записей приложению нужно
+// разрешение OnlineMeetingRecording.Read.All, поэтому если записей нет или доступа к ним нет, возвращается пустая
+// ссылка, а отчёты загружаются как обычно
+func FindRecording(ctx context.Context, graph Configuration, token, meetingID string) string {
+	recordings, err := getAll[recording](ctx, token, base(graph)+"onlineMeetings/"+url.PathEscape(meetingID)+
+		"/recordings")
+	if err != nil || len(recordings) == 0 {
+		return ""
+	}
+
+	return recordings[len(recordings)-1].RecordingContentURL
+}
+
+// FetchLiveRecords Функция, возвращающая записи об участниках текущего сеанса собрания: последнего отчёта о
+// посещаемости последнего найденного собрания организатора. Используется для наблюдения за собранием во время пары
+func FetchLiveRecords(ctx context.Context, graph Configuration, token string) (Meeting, []AttendanceRecord, error) {
+	//Находим собрания организатора
+	meetings, err := FindMeetings(ctx, graph, token)
+	if err != nil {
+		return Meeting{}, nil, err
+	}
+	if len(meetings) == 0 {
+		return Meeting{}, nil, ErrNoReports
+	}
+
+	//Текущим считается последнее найденное собрание
+	meeting := meetings[len(meetings)-1]
+	meetingURL := base(graph) + "onlineMeetings/" + url.PathEscape(meeting.ID) + "/attendanceReports"
+
+	attendanceReports, err := getAll[AttendanceReport](ctx, token, meetingURL)
+	if err != nil {
+		return meeting, nil, err
+	}
+	if len(attendanceReports) == 0 {
+		return meeting, nil, ErrNoReports
+	}
+
+	//Текущим сеансом считается последний отчёт о посещаемости собрания
+	attendanceReport := attendanceReports[len(attendanceReports)-1]
+	records, err := getAll[AttendanceRecord](ctx, token,
+		meetingURL+"/"+url.PathEscape(attendanceReport.ID)+"/attendanceRecords")
+	if err != nil {
+		return meeting, nil, err
+	}
+
+	return meeting, records, nil
+}
+
+// Present Функция, проверяющая, находится ли участник на собрании сейчас: у последнего интервала его нахождения на
+// собрании ещё нет времени выхода
+func (record AttendanceRecord) Present() bool {
+	if len(record.AttendanceIntervals) == 0 {
+		return false
+	}
+
+	return record.AttendanceIntervals[len(record.AttendanceIntervals)-1].LeaveDateTime == ""
+}
+
+/*====================================================================================================================*/
+
+// WriteReport Функция, записывающая отчёт Microsoft Graph в .csv файл того же вида, что и отчёт, загруженный из
+// MS Teams вручную (UTF-16 Little-Endian, разделитель - табуляция), чтобы его можно было обработать тем же путём
+func WriteReport(report string, meeting Meeting, attendanceReport AttendanceReport, records []AttendanceRecord) (err error) {
+	//Время начала и окончания собрания в виде отчёта MS Teams
+	start, err := FormatTime(attendanceReport.MeetingStartDateTime)
+	if err != nil {
+		return err
+	}
+	end, err := FormatTime(attendanceReport.MeetingEndDateTime)
+	if err != nil {
+		return err
+	}
+	duration, err := DurationSeconds(attendanceReport)
+	if err != nil {
+		return err
+	}
+
+	//Оглавление отчёта повторяет первые 8 непустых строк отчёта MS Teams. Ссылки на собрание и его запись, которых нет
+	// в отчётах MS Teams, добавляются перед "шапкой" таблицы участников, если они известны
+	rows := [][]string{
+		{"Сводка собрания"},
+		{"Общее число участников", strconv.Itoa(len(records))},
+		{"Название собрания", meeting.Subject},
+		{"Время начала собрания", start},
+		{"Время окончания собрания", end},
+		{"Идентификатор собрания", meeting.ID},
+		{"Продолжительность собрания", schedule.FormatDuration(duration)},
+	}
+	if meeting.JoinWebURL != "" {
+		rows = append(rows, []string{"Ссылка на собрание", meeting.JoinWebURL})
+	}
+	if meeting.RecordingURL != "" {
+		rows = append(rows, []string{"Запись собрания", meeting.RecordingURL})
+	}
+	rows = append(rows, []string{"Полное имя", "Время присоединения", "Время выхода", "Продолжительность",
+		"Адрес электронной почты", "Роль", "Идентификатор участника (UPN)"})
+
+	//Цикл по всем участникам собрания
+	for _, record := range records {
+		//Время присоединения - начало первого интервала, время выхода - конец последнего
+		var join, leave string
+		if len(record.AttendanceIntervals) > 0 {
+			if join, err = FormatTime(record.AttendanceIntervals[0].JoinDateTime); err != nil {
+				return err
+			}
+			if leave, err = FormatTime(record.AttendanceIntervals[len(record.AttendanceIntervals)-1].LeaveDateTime); err != nil {
+				return err
+			}
+		}
+
+		//Организатор и выступающие собрания в отчётах MS Teams помечаются как инициатор и выступающие
+		role := "Участник"
+		switch record.Role {
+		case "Organizer":
+			role = "Инициатор"
+		case "Presenter":
+			role = "Выступающий"
+		}
+
+		rows = append(rows, []string{record.Identity.DisplayName, join, leave,
+			schedule.FormatDuration(record.TotalAttendanceInSeconds), record.EmailAddress, role, record.EmailAddress})
+	}
+
+	//Создаём файл отчёта
+	file, err := os.Create(report)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка закрытия файла отчёта: %w", closeErr)
+		}
+	}()
+
+	//Генерируем кодировщик в UTF-16 Little-Endian с BOM
+	enc := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder()
+	utf16w := transform.NewWriter(file, enc)
+
+	//Создаём писец .csv файлов с разделителем табуляцией
+	csvWriter := csv.NewWriter(utf16w)
+	csvWriter.Comma = '\t'
+
+	if err := csvWriter.WriteAll(rows); err != nil {
+		return fmt.Errorf("ошибка записи отчёта Microsoft Graph: %w", err)
+	}
+	if err := utf16w.Close(); err != nil {
+		return fmt.Errorf("ошибка записи отчёта Microsoft Graph: %w", err)
+	}
+
+	return nil
+}
+
+// FormatTime Вспомогательная функция, переводящая время Microsoft Graph (UTC) в часовой пояс TimeZone вида отчёта
+// MS Teams
+func FormatTime(source string) (string, error) {
+	parsed, err := time.Parse(time.RFC3339Nano, source)
+	if err != nil {
+		return "", fmt.Errorf("ошибка перевода времени Microsoft Graph: %w", err)
+	}
+
+	return parsed.In(TimeZone).Format("02.01.2006, 15:04:05"), nil
+}
+
+// DurationSeconds Вспомогательная функция, возвращающая продолжительность собрания в секундах
+func DurationSeconds(attendanceReport AttendanceReport) (int, error) {
+	start, err := time.Parse(time.RFC3339Nano, attendanceReport.MeetingStartDateTime)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка перевода времени Microsoft Graph: %w", err)
+	}
+
+	end, err := time.Parse(time.RFC3339Nano, attendanceReport.MeetingEndDateTime)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка перевода времени Microsoft Graph: %w", err)
+	}
+
+	return int(end.Sub(start).Seconds()), nil
+}
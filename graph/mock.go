@@ -0,0 +1,273 @@
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// MockToken Токен доступа, который выдаёт и принимает имитация Microsoft Graph
+const MockToken = "mock-access-token"
+
+// mockPageSize Количество записей об участниках на одной странице ответа имитации. Ответ нарочно разбивается на
+// страницы, чтобы проверить загрузку по ссылке @odata.nextLink
+const mockPageSize = 3
+
+// MockMeeting Структура заготовленного собрания имитации Microsoft Graph: собрание, ссылка присоединения из события
+// календаря, ссылка на запись собрания (пустая, если собрание не записывалось) и отчёты о посещаемости сеансов собрания
+type MockMeeting struct {
+	Meeting      Meeting
+	JoinURL      string
+	RecordingURL string
+	Reports      []MockReport
+}
+
+// MockReport Структура заготовленного отчёта о посещаемости сеанса собрания с записями об участниках
+type MockReport struct {
+	Report  AttendanceReport
+	Records []AttendanceRecord
+}
+
+/*====================================================================================================================*/
+
+// MockMeetings Функция, формирующая заготовленные собрания имитации: пару с start по end, на которой есть организатор,
+// гость и студенты из students (присутствовал, опоздал, ушёл раньше, переподключался, отсутствовал), и консультацию из
+// двух сеансов, начинающуюся в consultation. Имена студентов указываются так, как их выводит MS Teams (ИОФ)
+func MockMeetings(start, end, consultation time.Time, students []string) []MockMeeting {
+	at := func(moment time.Time) string { return moment.UTC().Format(time.RFC3339) }
+	record := func(name, role string, intervals ...[2]time.Time) AttendanceRecord {
+		current := AttendanceRecord{EmailAddress: strings.ReplaceAll(strings.ToLower(name), " ", ".") + "@example.com",
+			Role: role}
+		current.Identity.DisplayName = name
+		for _, interval := range intervals {
+			current.AttendanceIntervals = append(current.AttendanceIntervals, AttendanceInterval{at(interval[0]),
+				at(interval[1])})
+			current.TotalAttendanceInSeconds += int(interval[1].Sub(interval[0]).Seconds())
+		}
+		return current
+	}
+	minutes := func(moment time.Time, count int) time.Time { return moment.Add(time.Duration(count) * time.Minute) }
+
+	//Пара: организатор и гость на всём собрании, студенты с разными отметками, каждый пятый студент отсутствует
+	lesson := []AttendanceRecord{
+		record("Иван Петрович Проверочный", "Organizer", [2]time.Time{minutes(start, -5), end}),
+		record("Ольга Сергеевна Внешняя (Guest)", "Attendee", [2]time.Time{start, end}),
+	}
+	for i, student := range students {
+		switch i % 5 {
+		case 0:
+			lesson = append(lesson, record(student, "Attendee", [2]time.Time{minutes(start, -2), end}))
+		case 1:
+			lesson = append(lesson, record(student, "Attendee", [2]time.Time{minutes(start, 20), end}))
+		case 2:
+			lesson = append(lesson, record(student, "Attendee", [2]time.Time{start, minutes(end, -40)}))
+		case 3:
+			lesson = append(lesson, record(student, "Attendee", [2]time.Time{start, minutes(start, 30)},
+				[2]time.Time{minutes(start, 35), end}))
+		}
+	}
+
+	//Консультация из двух получасовых сеансов: первые студенты присутствуют на обоих
+	duration := 30 * time.Minute
+	firstSession := []AttendanceRecord{record("Иван Петрович Проверочный", "Organizer",
+		[2]time.Time{consultation, consultation.Add(duration)})}
+	secondSession := []AttendanceRecord{record("Иван Петрович Проверочный", "Organizer",
+		[2]time.Time{consultation.Add(duration), consultation.Add(2 * duration)})}
+	for i, student := range students {
+		if i >= 2 {
+			break
+		}
+		firstSession = append(firstSession, record(student, "Attendee", [2]time.Time{consultation,
+			consultation.Add(duration)}))
+		secondSession = append(secondSession, record(student, "Attendee", [2]time.Time{consultation.Add(duration),
+			consultation.Add(2 * duration)}))
+	}
+
+	//Пара записывалась, консультация - нет
+	lessonMeeting := Meeting{ID: "mock-meeting-lesson", Subject: "Проверка Microsoft Graph: пара",
+		JoinWebURL: "https://teams.example.com/l/meetup-join/lesson"}
+	consultationMeeting := Meeting{ID: "mock-meeting-consultation", Subject: "Проверка Microsoft Graph: консультация",
+		JoinWebURL: "https://teams.example.com/l/meetup-join/consultation"}
+	return []MockMeeting{
+		{lessonMeeting, lessonMeeting.JoinWebURL, "https://graph.example.com/recordings/mock-meeting-lesson/content",
+			[]MockReport{{AttendanceReport{"mock-report-lesson", at(start), at(end)}, lesson}}},
+		{consultationMeeting, consultationMeeting.JoinWebURL, "",
+			[]MockReport{
+				{AttendanceReport{"mock-report-consultation-1", at(consultation), at(consultation.Add(duration))},
+					firstSession},
+				{AttendanceReport{"mock-report-consultation-2", at(consultation.Add(duration)),
+					at(consultation.Add(2 * duration))}, secondSession},
+			}},
+	}
+}
+
+// MockHandler Функция, возвращающая обработчик имитации Microsoft Graph и сервиса авторизации для проверки загрузки
+// отчётов без доступа к настоящему тенанту. Запросы Microsoft Graph принимаются по адресу /v1.0/ (как
+// https://graph.microsoft.com/v1.0/), запросы авторизации - по адресу /{tenant}/oauth2/v2.0/ (как
+// https://login.microsoftonline.com/). Вход любым способом подтверждается сразу, токен всегда равен MockToken
+func MockHandler(meetings []MockMeeting) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/v1.0/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+MockToken {
+			mockError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token is empty or invalid.")
+			return
+		}
+
+		//Корень запросов: организатор (users/{id}) или текущий пользователь (me)
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1.0/"), "/"), "/")
+		switch {
+		case len(parts) > 1 && parts[0] == "users":
+			parts = parts[2:]
+		case len(parts) > 0 && parts[0] == "me":
+			parts = parts[1:]
+		default:
+			mockError(w, http.StatusNotFound, "ResourceNotFound", "Unknown resource.")
+			return
+		}
+
+		switch {
+		//События календаря со ссылками на собрания
+		case len(parts) == 1 && parts[0] == "calendarView":
+			events := make([]interface{}, 0, len(meetings))
+			for _, meeting := range meetings {
+				//Плановое время события - от начала первого до окончания последнего сеанса собрания
+				event := map[string]interface{}{"subject": meeting.Meeting.Subject,
+					"onlineMeeting": map[string]string{"joinUrl": meeting.JoinURL}}
+				if len(meeting.Reports) > 0 {
+					event["start"] = mockDateTime(meeting.Reports[0].Report.MeetingStartDateTime)
+					event["end"] = mockDateTime(meeting.Reports[len(meeting.Reports)-1].Report.MeetingEndDateTime)
+				}
+				events = append(events, event)
+			}
+			mockPage(w, r, events)
+		//Поиск собрания по ссылке присоединения
+		case len(parts) == 1 && parts[0] == "onlineMeetings":
+			found := make([]interface{}, 0, 1)
+			for _, meeting := range meetings {
+				if strings.Contains(r.URL.Query().Get("$filter"), "'"+meeting.JoinURL+"'") {
+					found = append(found, meeting.Meeting)
+				}
+			}
+			mockPage(w, r, found)
+		case len(parts) >= 2 && parts[0] == "onlineMeetings":
+			meeting, ok := findMockMeeting(meetings, parts[1])
+			if !ok {
+				mockError(w, http.StatusNotFound, "NotFound", "Meeting not found.")
+				return
+			}
+			switch {
+			case len(parts) == 2:
+				mockJSON(w, meeting.Meeting)
+			//Записи собрания: ссылка на содержимое записи, если собрание записывалось
+			case len(parts) == 3 && parts[2] == "recordings":
+				recordings := make([]interface{}, 0, 1)
+				if meeting.RecordingURL != "" {
+					recordings = append(recordings, recording{ID: meeting.Meeting.ID + "-recording",
+						RecordingContentURL: meeting.RecordingURL})
+				}
+				mockPage(w, r, recordings)
+			case len(parts) == 3 && parts[2] == "attendanceReports":
+				reports := make([]interface{}, 0, len(meeting.Reports))
+				for _, report := range meeting.Reports {
+					reports = append(reports, report.Report)
+				}
+				mockPage(w, r, reports)
+			case len(parts) == 5 && parts[2] == "attendanceReports" && parts[4] == "attendanceRecords":
+				for _, report := range meeting.Reports {
+					if report.Report.ID == parts[3] {
+						records := make([]interface{}, 0, len(report.Records))
+						for _, record := range report.Records {
+							records = append(records, record)
+						}
+						mockPage(w, r, records)
+						return
+					}
+				}
+				mockError(w, http.StatusNotFound, "NotFound", "Attendance report not found.")
+			default:
+				mockError(w, http.StatusNotFound, "ResourceNotFound", "Unknown resource.")
+			}
+		default:
+			mockError(w, http.StatusNotFound, "ResourceNotFound", "Unknown resource.")
+		}
+	})
+
+	//Сервис авторизации: код устройства выдаётся и подтверждается сразу
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token"):
+			mockJSON(w, map[string]interface{}{"access_token": MockToken, "token_type": "Bearer", "expires_in": 3600})
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/devicecode"):
+			mockJSON(w, map[string]interface{}{"device_code": "mock-device-code", "interval": 1,
+				"message": "Имитация Microsoft Graph: вход по коду устройства подтверждён автоматически"})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	return mux
+}
+
+// mockDateTime Вспомогательная функция, переводящая время RFC 3339 во время события календаря Microsoft Graph (время
+// в UTC без смещения и часовой пояс)
+func mockDateTime(source string) map[string]string {
+	parsed, err := time.Parse(time.RFC3339Nano, source)
+	if err != nil {
+		return map[string]string{"dateTime": source, "timeZone": "UTC"}
+	}
+
+	return map[string]string{"dateTime": parsed.UTC().Format("2006-01-02T15:04:05.0000000"), "timeZone": "UTC"}
+}
+
+// findMockMeeting Вспомогательная функция, находящая заготовленное собрание по идентификатору
+func findMockMeeting(meetings []MockMeeting, id string) (MockMeeting, bool) {
+	for _, meeting := range meetings {
+		if meeting.Meeting.ID == id {
+			return meeting, true
+		}
+	}
+
+	return MockMeeting{}, false
+}
+
+// mockPage Вспомогательная функция, отвечающая страницей списка Microsoft Graph (параметр page - номер страницы) со
+// ссылкой @odata.nextLink на следующую страницу
+func mockPage(w http.ResponseWriter, r *http.Request, values []interface{}) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	from := page * mockPageSize
+	if from > len(values) {
+		from = len(values)
+	}
+	to := from + mockPageSize
+	if to > len(values) {
+		to = len(values)
+	}
+
+	response := map[string]interface{}{"value": values[from:to]}
+	if to < len(values) {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(page+1))
+		next := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+		response["@odata.nextLink"] = next.String()
+	}
+	mockJSON(w, response)
+}
+
+// mockError Вспомогательная функция, отвечающая ошибкой в виде ошибок Microsoft Graph
+func mockError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"code": code, "message": message}})
+}
+
+// mockJSON Вспомогательная функция, отвечающая значением в формате JSON
+func mockJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(value)
+}
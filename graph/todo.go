@@ -0,0 +1,82 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+/*====================================================================================================================*/
+
+// todoList Структура списка задач Microsoft To Do из ответа Microsoft Graph
+type todoList struct {
+	ID                string `json:"id"`
+	WellknownListName string `json:"wellknownListName"`
+}
+
+// todoTask Структура задачи Microsoft To Do, создаваемой в Microsoft Graph
+type todoTask struct {
+	Title string `json:"title"`
+	Body  struct {
+		Content     string `json:"content"`
+		ContentType string `json:"contentType"`
+	} `json:"body"`
+	Importance string `json:"importance"`
+}
+
+/*====================================================================================================================*/
+
+// CreateTask Функция, создающая задачу в списке задач Microsoft To Do "Задачи" пользователя с адресом электронной почты
+// userEmail (например, задачу куратору группы связаться со студентом). Для создания задач от имени приложения оно должно
+// иметь разрешение Tasks.ReadWrite.All
+func CreateTask(ctx context.Context, token, userEmail, title, text string) error {
+	root := Endpoint + "users/" + url.PathEscape(userEmail) + "/todo/lists"
+
+	//Находим список задач пользователя по-умолчанию
+	lists, err := getAll[todoList](ctx, token, root)
+	if err != nil {
+		return err
+	}
+	listID := ""
+	for _, list := range lists {
+		if list.WellknownListName == "defaultList" {
+			listID = list.ID
+			break
+		}
+	}
+	if listID == "" {
+		return fmt.Errorf("у пользователя %v не найден список задач Microsoft To Do по-умолчанию", userEmail)
+	}
+
+	task := todoTask{Title: title, Importance: "high"}
+	task.Body.Content, task.Body.ContentType = text, "text"
+	body, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования задачи Microsoft To Do: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, root+"/"+url.PathEscape(listID)+"/tasks",
+		bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Microsoft Graph: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к Microsoft Graph: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
+		answer, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("Microsoft Graph вернул ошибку при создании задачи %v: %s", response.Status, answer)
+	}
+
+	return nil
+}
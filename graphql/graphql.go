@@ -0,0 +1,529 @@
+// Package graphql Пакет выполнения запросов GraphQL для панелей посещаемости и сторонних интерфейсов. Поддерживается
+// подмножество языка, достаточное для запросов чтения: операция query (с именем и переменными или без них), поля,
+// псевдонимы полей, аргументы (строки, числа, логические значения, null, перечисления, списки, объекты и переменные)
+// и вложенные наборы полей. Фрагменты, директивы, мутации и подписки не поддерживаются
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*====================================================================================================================*/
+
+// Ограничения запроса GraphQL, защищающие сервер от слишком больших запросов и от переполнения стека при разборе
+// глубоко вложенных наборов полей и значений
+const (
+	//Наибольший размер тела запроса POST в байтах
+	MaxRequestSize = 1 << 20
+	//Наибольшая глубина вложенности наборов полей, списков и объектов в запросе
+	MaxDepth = 32
+)
+
+// Field Структура запрошенного поля: имя в ответе (псевдоним или имя поля), имя поля, значения аргументов (с
+// подставленными переменными) и вложенные поля
+type Field struct {
+	Alias     string
+	Name      string
+	Arguments map[string]interface{}
+	Selection []Field
+}
+
+// Object Интерфейс объекта схемы, значения полей которого вычисляются по запросу. Значение поля - скаляр (строка,
+// число, логическое значение), Object, список объектов []Object или nil
+type Object interface {
+	Resolve(ctx context.Context, field Field) (interface{}, error)
+}
+
+// ObjectFunc Функция, вычисляющая значения полей объекта схемы
+type ObjectFunc func(ctx context.Context, field Field) (interface{}, error)
+
+// Resolve Функция, вычисляющая значение поля объекта схемы
+func (object ObjectFunc) Resolve(ctx context.Context, field Field) (interface{}, error) {
+	return object(ctx, field)
+}
+
+// request Структура запроса GraphQL по HTTP
+type request struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// responseError Структура ошибки в ответе GraphQL
+type responseError struct {
+	Message string `json:"message"`
+}
+
+// response Структура ответа GraphQL
+type response struct {
+	Data   interface{}     `json:"data"`
+	Errors []responseError `json:"errors,omitempty"`
+}
+
+/*====================================================================================================================*/
+
+// Handler Функция, возвращающая обработчик HTTP запросов GraphQL: запрос передаётся методом POST в теле в формате
+// JSON ({"query": "...", "variables": {...}}) или методом GET в параметрах query и variables
+func Handler(root Object) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, httpRequest *http.Request) {
+		var current request
+		switch httpRequest.Method {
+		case http.MethodGet:
+			current.Query = httpRequest.URL.Query().Get("query")
+			if variables := httpRequest.URL.Query().Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &current.Variables); err != nil {
+					http.Error(writer, "некорректные переменные запроса", http.StatusBadRequest)
+					return
+				}
+			}
+		case http.MethodPost:
+			var tooLarge *http.MaxBytesError
+			err := json.NewDecoder(http.MaxBytesReader(writer, httpRequest.Body, MaxRequestSize)).Decode(&current)
+			switch {
+			case errors.As(err, &tooLarge):
+				http.Error(writer, "запрос GraphQL превышает наибольший размер", http.StatusRequestEntityTooLarge)
+				return
+			case err != nil:
+				http.Error(writer, "некорректный запрос GraphQL", http.StatusBadRequest)
+				return
+			}
+		default:
+			http.Error(writer, "запрос GraphQL передаётся методом GET или POST", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var result response
+		data, err := Execute(httpRequest.Context(), root, current.Query, current.Variables)
+		if err != nil {
+			result.Errors = []responseError{{Message: err.Error()}}
+		} else {
+			result.Data = data
+		}
+
+		writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		if err := json.NewEncoder(writer).Encode(result); err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Execute Функция, разбирающая запрос GraphQL и вычисляющая запрошенные поля корневого объекта схемы
+func Execute(ctx context.Context, root Object, query string, variables map[string]interface{}) (map[string]interface{}, error) {
+	fields, err := Parse(query, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveObject(ctx, root, fields)
+}
+
+// resolveObject Вспомогательная функция, вычисляющая запрошенные поля объекта
+func resolveObject(ctx context.Context, object Object, fields []Field) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		value, err := object.Resolve(ctx, field)
+		if err != nil {
+			return nil, err
+		}
+		if result[field.Alias], err = complete(ctx, field, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// complete Вспомогательная функция, приводящая значение поля к значению ответа: у объектов и списков объектов
+// вычисляются вложенные поля, скаляры возвращаются как есть
+func complete(ctx context.Context, field Field, value interface{}) (interface{}, error) {
+	switch current := value.(type) {
+	case Object:
+		if len(field.Selection) == 0 {
+			return nil, fmt.Errorf("для поля %v необходимо указать вложенные поля", field.Name)
+		}
+		return resolveObject(ctx, current, field.Selection)
+	case []Object:
+		if len(field.Selection) == 0 {
+			return nil, fmt.Errorf("для поля %v необходимо указать вложенные поля", field.Name)
+		}
+		list := make([]interface{}, 0, len(current))
+		for _, item := range current {
+			resolved, err := resolveObject(ctx, item, field.Selection)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, resolved)
+		}
+		return list, nil
+	default:
+		if len(field.Selection) > 0 {
+			return nil, fmt.Errorf("у поля %v нет вложенных полей", field.Name)
+		}
+		return value, nil
+	}
+}
+
+/*====================================================================================================================*/
+
+// parser Структура разбора запроса GraphQL: лексемы запроса, текущая позиция, глубина вложенности и значения
+// переменных
+type parser struct {
+	tokens    []string
+	position  int
+	depth     int
+	variables map[string]interface{}
+}
+
+// Parse Функция, разбирающая запрос GraphQL в поля корневого объекта. Переменные запроса подставляются в аргументы,
+// для переменных без значения используются значения по-умолчанию из объявления операции
+func Parse(query string, variables map[string]interface{}) ([]Field, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	current := parser{tokens: tokens, variables: make(map[string]interface{})}
+	for name, value := range variables {
+		current.variables[name] = value
+	}
+
+	//Краткая форма запроса ({ ... }) или операция query с необязательными именем и объявлением переменных
+	if current.peek() != "{" {
+		operation := current.next()
+		if operation != "query" {
+			return nil, fmt.Errorf("поддерживаются только запросы query, а не %v", operation)
+		}
+		if isName(current.peek()) {
+			current.next()
+		}
+		if current.peek() == "(" {
+			if err := current.variableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fields, err := current.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	if current.peek() != "" {
+		return nil, fmt.Errorf("лишняя часть запроса после набора полей: %v", current.peek())
+	}
+
+	return fields, nil
+}
+
+// peek Вспомогательная функция, возвращающая текущую лексему без перехода к следующей (пустую в конце запроса)
+func (current *parser) peek() string {
+	if current.position >= len(current.tokens) {
+		return ""
+	}
+
+	return current.tokens[current.position]
+}
+
+// next Вспомогательная функция, возвращающая текущую лексему и переходящая к следующей
+func (current *parser) next() string {
+	token := current.peek()
+	if token != "" {
+		current.position++
+	}
+
+	return token
+}
+
+// enter Вспомогательная функция, переходящая на следующий уровень вложенности запроса. Разбор запроса, вложенность
+// которого превышает MaxDepth, прерывается
+func (current *parser) enter() error {
+	if current.depth++; current.depth > MaxDepth {
+		return fmt.Errorf("вложенность запроса превышает %d уровня", MaxDepth)
+	}
+
+	return nil
+}
+
+// leave Вспомогательная функция, возвращающаяся на предыдущий уровень вложенности запроса
+func (current *parser) leave() {
+	current.depth--
+}
+
+// expect Вспомогательная функция, проверяющая, что текущая лексема совпадает с ожидаемой
+func (current *parser) expect(token string) error {
+	if found := current.next(); found != token {
+		if found == "" {
+			found = "конец запроса"
+		}
+		return fmt.Errorf("ожидалось \"%v\", найдено \"%v\"", token, found)
+	}
+
+	return nil
+}
+
+// variableDefinitions Вспомогательная функция, разбирающая объявление переменных операции. Типы переменных не
+// проверяются, запоминаются только значения по-умолчанию
+func (current *parser) variableDefinitions() error {
+	if err := current.expect("("); err != nil {
+		return err
+	}
+
+	for current.peek() != ")" {
+		if err := current.expect("$"); err != nil {
+			return err
+		}
+		name := current.next()
+		if !isName(name) {
+			return fmt.Errorf("некорректное имя переменной: %v", name)
+		}
+		if err := current.expect(":"); err != nil {
+			return err
+		}
+		if err := current.skipType(); err != nil {
+			return err
+		}
+
+		if current.peek() == "=" {
+			current.next()
+			value, err := current.value()
+			if err != nil {
+				return err
+			}
+			if _, ok := current.variables[name]; !ok {
+				current.variables[name] = value
+			}
+		}
+	}
+
+	return current.expect(")")
+}
+
+// skipType Вспомогательная функция, пропускающая тип переменной (String, Int!, [String!]!)
+func (current *parser) skipType() error {
+	if current.peek() == "[" {
+		current.next()
+		if err := current.enter(); err != nil {
+			return err
+		}
+		defer current.leave()
+		if err := current.skipType(); err != nil {
+			return err
+		}
+		if err := current.expect("]"); err != nil {
+			return err
+		}
+	} else if name := current.next(); !isName(name) {
+		return fmt.Errorf("некорректный тип переменной: %v", name)
+	}
+
+	if current.peek() == "!" {
+		current.next()
+	}
+
+	return nil
+}
+
+// selectionSet Вспомогательная функция, разбирающая набор полей в фигурных скобках
+func (current *parser) selectionSet() ([]Field, error) {
+	if err := current.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := current.enter(); err != nil {
+		return nil, err
+	}
+	defer current.leave()
+
+	var fields []Field
+	for current.peek() != "}" {
+		field, err := current.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("пустой набор полей")
+	}
+
+	return fields, current.expect("}")
+}
+
+// field Вспомогательная функция, разбирающая поле: псевдоним, имя, аргументы и вложенные поля
+func (current *parser) field() (Field, error) {
+	name := current.next()
+	if !isName(name) {
+		return Field{}, fmt.Errorf("некорректное имя поля: %v", name)
+	}
+
+	field := Field{Alias: name, Name: name, Arguments: make(map[string]interface{})}
+	if current.peek() == ":" {
+		current.next()
+		if field.Name = current.next(); !isName(field.Name) {
+			return Field{}, fmt.Errorf("некорректное имя поля: %v", field.Name)
+		}
+	}
+
+	if current.peek() == "(" {
+		current.next()
+		for current.peek() != ")" {
+			argument := current.next()
+			if !isName(argument) {
+				return Field{}, fmt.Errorf("некорректное имя аргумента поля %v: %v", field.Name, argument)
+			}
+			if err := current.expect(":"); err != nil {
+				return Field{}, err
+			}
+			value, err := current.value()
+			if err != nil {
+				return Field{}, err
+			}
+			field.Arguments[argument] = value
+		}
+		if err := current.expect(")"); err != nil {
+			return Field{}, err
+		}
+	}
+
+	if current.peek() == "{" {
+		var err error
+		if field.Selection, err = current.selectionSet(); err != nil {
+			return Field{}, err
+		}
+	}
+
+	return field, nil
+}
+
+// value Вспомогательная функция, разбирающая значение аргумента
+func (current *parser) value() (interface{}, error) {
+	token := current.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("ожидалось значение, найден конец запроса")
+	case token == "$":
+		name := current.next()
+		value, ok := current.variables[name]
+		if !ok {
+			return nil, fmt.Errorf("не передано значение переменной $%v", name)
+		}
+		return value, nil
+	case token[0] == '"':
+		value, err := strconv.Unquote(token)
+		if err != nil {
+			return nil, fmt.Errorf("некорректная строка %v: %w", token, err)
+		}
+		return value, nil
+	case token == "true", token == "false":
+		return token == "true", nil
+	case token == "null":
+		return nil, nil
+	case token == "[":
+		if err := current.enter(); err != nil {
+			return nil, err
+		}
+		defer current.leave()
+		list := []interface{}{}
+		for current.peek() != "]" {
+			item, err := current.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, current.expect("]")
+	case token == "{":
+		if err := current.enter(); err != nil {
+			return nil, err
+		}
+		defer current.leave()
+		object := make(map[string]interface{})
+		for current.peek() != "}" {
+			name := current.next()
+			if err := current.expect(":"); err != nil {
+				return nil, err
+			}
+			item, err := current.value()
+			if err != nil {
+				return nil, err
+			}
+			object[name] = item
+		}
+		return object, current.expect("}")
+	case token[0] == '-' || unicode.IsDigit(rune(token[0])):
+		if value, err := strconv.Atoi(token); err == nil {
+			return value, nil
+		}
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное число %v", token)
+		}
+		return value, nil
+	case isName(token):
+		//Значение перечисления передаётся как строка
+		return token, nil
+	default:
+		return nil, fmt.Errorf("некорректное значение аргумента: %v", token)
+	}
+}
+
+// tokenize Вспомогательная функция, разбивающая запрос на лексемы: знаки пунктуации, имена, числа и строки (в
+// кавычках). Запятые, пробелы и комментарии (от # до конца строки) пропускаются
+func tokenize(query string) ([]string, error) {
+	var tokens []string
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		symbol := runes[i]
+		switch {
+		case unicode.IsSpace(symbol) || symbol == ',' || symbol == '\uFEFF':
+			i++
+		case symbol == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case strings.ContainsRune("{}()[]:$!=", symbol):
+			tokens = append(tokens, string(symbol))
+			i++
+		case symbol == '"':
+			start := i
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("незакрытая строка в запросе")
+			}
+			i++
+			tokens = append(tokens, string(runes[start:i]))
+		case symbol == '-' || symbol == '_' || unicode.IsLetter(symbol) || unicode.IsDigit(symbol):
+			start := i
+			for i++; i < len(runes) && (runes[i] == '_' || runes[i] == '.' || unicode.IsLetter(runes[i]) ||
+				unicode.IsDigit(runes[i])); i++ {
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			return nil, fmt.Errorf("недопустимый символ в запросе: %q", symbol)
+		}
+	}
+
+	return tokens, nil
+}
+
+// isName Вспомогательная функция, проверяющая, является ли лексема именем (поля, аргумента, типа или переменной)
+func isName(token string) bool {
+	if token == "" || !(token[0] == '_' || unicode.IsLetter(rune(token[0]))) {
+		return false
+	}
+
+	return !strings.Contains(token, ".")
+}
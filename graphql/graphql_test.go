@@ -0,0 +1,202 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+/*====================================================================================================================*/
+
+// testRoot Корневой объект схемы тестов: поле hello возвращает приветствие по аргументу name, поле groups - список
+// групп с полями name и size, поле echo - значение аргумента value как есть
+var testRoot = ObjectFunc(func(ctx context.Context, field Field) (interface{}, error) {
+	switch field.Name {
+	case "hello":
+		name, _ := field.Arguments["name"].(string)
+		if name == "" {
+			name = "мир"
+		}
+		return "Привет, " + name, nil
+	case "groups":
+		var groups []Object
+		for _, name := range []string{"МП-51", "МП-52"} {
+			name := name
+			groups = append(groups, ObjectFunc(func(ctx context.Context, field Field) (interface{}, error) {
+				switch field.Name {
+				case "name":
+					return name, nil
+				case "size":
+					return 25, nil
+				default:
+					return nil, fmt.Errorf("у типа Group нет поля %v", field.Name)
+				}
+			}))
+		}
+		return groups, nil
+	case "echo":
+		return field.Arguments["value"], nil
+	default:
+		return nil, fmt.Errorf("у типа Query нет поля %v", field.Name)
+	}
+})
+
+/*====================================================================================================================*/
+
+// TestParse Проверка разбора запросов: краткая форма и операция query, псевдонимы, аргументы всех видов, переменные и
+// их значения по-умолчанию, комментарии и запятые
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		want      []Field
+	}{
+		{"краткая форма", `{ hello }`, nil,
+			[]Field{{Alias: "hello", Name: "hello", Arguments: map[string]interface{}{}}}},
+		{"операция с именем и псевдонимом", `query Greeting { greeting: hello(name: "Мария") }`, nil,
+			[]Field{{Alias: "greeting", Name: "hello", Arguments: map[string]interface{}{"name": "Мария"}}}},
+		{"аргументы всех видов", `{ echo(s: "a\"b", i: -5, f: 1.5, b: true, n: null, e: ABSENT, l: [1, 2],
+			o: {from: "01.09.2026"}) }`, nil,
+			[]Field{{Alias: "echo", Name: "echo", Arguments: map[string]interface{}{"s": `a"b`, "i": -5, "f": 1.5,
+				"b": true, "n": nil, "e": "ABSENT", "l": []interface{}{1, 2},
+				"o": map[string]interface{}{"from": "01.09.2026"}}}}},
+		{"переменные и значения по-умолчанию", `query ($group: String!, $late: [Boolean] = [false]) {
+			echo(group: $group, late: $late) }`, map[string]interface{}{"group": "МП-51"},
+			[]Field{{Alias: "echo", Name: "echo", Arguments: map[string]interface{}{"group": "МП-51",
+				"late": []interface{}{false}}}}},
+		{"переданная переменная заменяет значение по-умолчанию", `query ($n: Int = 1) { echo(value: $n) }`,
+			map[string]interface{}{"n": 7.0},
+			[]Field{{Alias: "echo", Name: "echo", Arguments: map[string]interface{}{"value": 7.0}}}},
+		{"вложенные поля и комментарии", "{\n  # группы\n  groups { name, size }\n}", nil,
+			[]Field{{Alias: "groups", Name: "groups", Arguments: map[string]interface{}{}, Selection: []Field{
+				{Alias: "name", Name: "name", Arguments: map[string]interface{}{}},
+				{Alias: "size", Name: "size", Arguments: map[string]interface{}{}},
+			}}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fields, err := Parse(test.query, test.variables)
+			if err != nil {
+				t.Fatalf("ошибка разбора запроса: %v", err)
+			}
+			if !reflect.DeepEqual(fields, test.want) {
+				t.Errorf("разобрано %#v, ожидалось %#v", fields, test.want)
+			}
+		})
+	}
+}
+
+// TestParseErrors Проверка ошибок разбора некорректных и слишком глубоко вложенных запросов
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name, query, want string
+	}{
+		{"мутация", `mutation { hello }`, "поддерживаются только запросы query"},
+		{"незакрытая строка", `{ hello(name: "Мария) }`, "незакрытая строка"},
+		{"незакрытый набор полей", `{ hello`, "некорректное имя поля"},
+		{"пустой набор полей", `{ }`, "пустой набор полей"},
+		{"лишняя часть запроса", `{ hello } }`, "лишняя часть запроса"},
+		{"неизвестная переменная", `{ hello(name: $name) }`, "не передано значение переменной $name"},
+		{"недопустимый символ", `{ hello @skip }`, "недопустимый символ"},
+		{"значение без конца", `{ echo(value: [1, 2 }`, "некорректное значение аргумента"},
+		{"глубокий набор полей", strings.Repeat("{ a ", MaxDepth+1) + strings.Repeat("}", MaxDepth+1),
+			"вложенность запроса превышает"},
+		{"глубокий список", `{ echo(value: ` + strings.Repeat("[", 100000) + `) }`, "вложенность запроса превышает"},
+		{"глубокий объект", `{ echo(value: ` + strings.Repeat("{a: ", 100000) + `) }`,
+			"вложенность запроса превышает"},
+		{"глубокий тип переменной", `query ($v: ` + strings.Repeat("[", 100000) + `) { hello }`,
+			"вложенность запроса превышает"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.query, nil)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("ошибка разбора %v, ожидалась ошибка с текстом %q", err, test.want)
+			}
+		})
+	}
+}
+
+// TestExecute Проверка вычисления полей объекта схемы: псевдонимы, списки объектов и ошибки вычисления
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name, query string
+		want        string
+		err         string
+	}{
+		{"скаляры и псевдонимы", `{ hello a: hello(name: "Мария") }`,
+			`{"a":"Привет, Мария","hello":"Привет, мир"}`, ""},
+		{"список объектов", `{ groups { name size } }`,
+			`{"groups":[{"name":"МП-51","size":25},{"name":"МП-52","size":25}]}`, ""},
+		{"неизвестное поле", `{ missing }`, "", "у типа Query нет поля missing"},
+		{"объект без вложенных полей", `{ groups }`, "", "необходимо указать вложенные поля"},
+		{"скаляр с вложенными полями", `{ hello { name } }`, "", "у поля hello нет вложенных полей"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := Execute(context.Background(), testRoot, test.query, nil)
+			if test.err != "" {
+				if err == nil || !strings.Contains(err.Error(), test.err) {
+					t.Errorf("ошибка %v, ожидалась ошибка с текстом %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ошибка выполнения запроса: %v", err)
+			}
+			got, err := json.Marshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("ответ %s, ожидался %s", got, test.want)
+			}
+		})
+	}
+}
+
+// TestHandler Проверка обработчика HTTP запросов: запросы GET и POST, переменные и ограничение размера тела запроса
+func TestHandler(t *testing.T) {
+	handler := Handler(testRoot)
+	tests := []struct {
+		name, method, target, body string
+		status                     int
+		want                       string
+	}{
+		{"GET с переменными", http.MethodGet,
+			`/graphql?query=query($n:String){hello(name:$n)}&variables={"n":"Иван"}`, "", http.StatusOK,
+			`{"data":{"hello":"Привет, Иван"}}`},
+		{"POST", http.MethodPost, "/graphql", `{"query": "{ hello }"}`, http.StatusOK,
+			`{"data":{"hello":"Привет, мир"}}`},
+		{"ошибка запроса", http.MethodPost, "/graphql", `{"query": "{ missing }"}`, http.StatusOK,
+			`{"data":null,"errors":[{"message":"у типа Query нет поля missing"}]}`},
+		{"некорректный JSON", http.MethodPost, "/graphql", `{"query": `, http.StatusBadRequest, ""},
+		{"слишком большой запрос", http.MethodPost, "/graphql",
+			`{"query": "{ hello }", "variables": {"x": "` + strings.Repeat("x", MaxRequestSize) + `"}}`,
+			http.StatusRequestEntityTooLarge, ""},
+		{"неподдерживаемый метод", http.MethodDelete, "/graphql", "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			target := strings.NewReplacer(`"`, "%22", "{", "%7B", "}", "%7D", "$", "%24").Replace(test.target)
+			handler.ServeHTTP(recorder, httptest.NewRequest(test.method, target, strings.NewReader(test.body)))
+			if recorder.Code != test.status {
+				t.Fatalf("код ответа %d, ожидался %d: %s", recorder.Code, test.status, recorder.Body)
+			}
+			if test.want != "" && strings.TrimSpace(recorder.Body.String()) != test.want {
+				t.Errorf("ответ %s, ожидался %s", recorder.Body, test.want)
+			}
+		})
+	}
+}
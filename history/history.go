@@ -0,0 +1,951 @@
+// Package history Пакет истории посещаемости: хранение всех обработанных собраний в локальной базе SQLite и подсчёт
+// накопленной посещаемости студентов по семестрам
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	_ "modernc.org/sqlite"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек истории посещаемости
+type Configuration struct {
+	//Записываются ли обработанные собрания в историю
+	Enabled bool
+	//Путь до файла базы истории
+	Path string
+}
+
+// Store Структура хранилища истории посещаемости
+type Store struct {
+	//Соединение с базой SQLite
+	db *sql.DB
+}
+
+// Stats Структура накопленной посещаемости студента за семестр
+type Stats struct {
+	//Семестр (например, "2022-весна")
+	Semester string `json:"semester"`
+	//ФИО студента
+	FullName string `json:"full_name"`
+	//Стабильный идентификатор студента (хэш ФИО)
+	ID string `json:"id,omitempty"`
+	//Номер зачётной книжки студента из базы групп
+	RecordBook string `json:"record_book,omitempty"`
+	//Группа студента
+	Group string `json:"group"`
+	//Количество занятий, на которых ожидался студент
+	Lessons int `json:"lessons"`
+	//Количество занятий, на которых студент присутствовал полностью
+	Present int `json:"present"`
+	//Количество занятий, на которых студент присутствовал не полностью
+	Partial int `json:"partial"`
+	//Количество опозданий
+	Late int `json:"late"`
+	//Количество пропущенных занятий
+	Missed int `json:"missed"`
+}
+
+// schema Схема базы истории: собрания и отметки участников собраний, ключом которых являются студент и дата
+const schema = `
+CREATE TABLE IF NOT EXISTS meetings (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	title         TEXT NOT NULL,
+	date          TEXT NOT NULL,
+	lesson        TEXT NOT NULL,
+	semester      TEXT NOT NULL,
+	processed_at  TEXT NOT NULL,
+	quorum        INTEGER,
+	source_hash   TEXT,
+	finalized_at  TEXT,
+	lecturer      TEXT,
+	duration      INTEGER,
+	join_url      TEXT,
+	recording_url TEXT
+);
+CREATE TABLE IF NOT EXISTS attendance (
+	meeting_id    INTEGER NOT NULL REFERENCES meetings(id),
+	student       TEXT NOT NULL,
+	student_group TEXT NOT NULL,
+	date          TEXT NOT NULL,
+	semester      TEXT NOT NULL,
+	presence      TEXT NOT NULL,
+	delay         TEXT NOT NULL,
+	early_exit    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS name_matches (
+	meeting_id INTEGER NOT NULL REFERENCES meetings(id),
+	name       TEXT NOT NULL,
+	student    TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS merged_sources (
+	meeting_id  INTEGER NOT NULL REFERENCES meetings(id),
+	source_hash TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS meeting_reports (
+	meeting_id INTEGER NOT NULL REFERENCES meetings(id),
+	format     TEXT NOT NULL,
+	path       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS attendance_student_date ON attendance (student, date);
+CREATE INDEX IF NOT EXISTS attendance_group_date ON attendance (student_group, date);
+`
+
+/*====================================================================================================================*/
+
+// Open Функция, открывающая (или создающая) базу истории посещаемости по указанному пути
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы истории: %w", err)
+	}
+
+	//Создаём таблицы, если база открыта впервые
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка создания таблиц базы истории: %w", err)
+	}
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// migrate Вспомогательная функция, добавляющая в базу истории, созданную прежними версиями программы, недостающие
+// столбцы
+func migrate(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_info('meetings')`)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения схемы базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("ошибка чтения схемы базы истории: %w", err)
+		}
+		columns[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка чтения схемы базы истории: %w", err)
+	}
+
+	//Кворум собрания (NULL, если кворум не проверялся)
+	if !columns["quorum"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN quorum INTEGER`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+
+	//Хэш содержимого отчётов собрания (NULL для собраний, записанных прежними версиями программы)
+	if !columns["source_hash"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN source_hash TEXT`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+
+	//Время закрытия собрания (NULL, если собрание не закрыто командой finalize)
+	if !columns["finalized_at"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN finalized_at TEXT`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+
+	//Преподаватель и продолжительность собрания в секундах (NULL, если неизвестны или собрание записано прежними
+	// версиями программы)
+	if !columns["lecturer"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN lecturer TEXT`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+	if !columns["duration"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN duration INTEGER`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+
+	//Ссылки на собрание и на его запись (NULL, если отчёт загружен не из Microsoft Graph)
+	if !columns["join_url"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN join_url TEXT`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+	if !columns["recording_url"] {
+		if _, err := db.ExecContext(ctx, `ALTER TABLE meetings ADD COLUMN recording_url TEXT`); err != nil {
+			return fmt.Errorf("ошибка обновления схемы базы истории: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// OpenReadOnly Функция, открывающая существующую базу истории посещаемости только для чтения (для просмотра истории
+// без права изменения). Таблицы не создаются, а база, созданная прежними версиями программы, не обновляется
+func OpenReadOnly(ctx context.Context, path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы истории: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы истории: %w", err)
+	}
+	//Проверяем, что файл является базой истории
+	if _, err := db.ExecContext(ctx, "SELECT 1 FROM meetings LIMIT 1"); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("файл %v не является базой истории: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close Функция, закрывающая базу истории
+func (store *Store) Close() error {
+	return store.db.Close()
+}
+
+// ParseDate Вспомогательная функция, переводящая дату из оглавления отчёта (ДД.ММ.ГГГГ) во время
+func ParseDate(date string) (time.Time, error) {
+	parsed, err := time.Parse("02.01.2006", date)
+	if err != nil {
+		return parsed, fmt.Errorf("ошибка перевода даты собрания: %w", err)
+	}
+
+	return parsed, nil
+}
+
+// Semester Функция, возвращающая учебный семестр даты: с сентября по январь - осенний, с февраля по август - весенний.
+// Январь относится к осеннему семестру предыдущего года
+func Semester(date time.Time) string {
+	switch {
+	case date.Month() >= time.September:
+		return fmt.Sprintf("%d-осень", date.Year())
+	case date.Month() == time.January:
+		return fmt.Sprintf("%d-осень", date.Year()-1)
+	default:
+		return fmt.Sprintf("%d-весна", date.Year())
+	}
+}
+
+/*====================================================================================================================*/
+
+// AppendSession Функция, добавляющая обработанное собрание и отметки всех его участников в историю. Собрание, уже
+// записанное по тем же отчётам (с тем же хэшем содержимого), заменяется, поэтому повторная обработка не дублирует
+// отметки. Заменённое закрытое собрание остаётся закрытым. Если на той же паре того же дня у тех же групп уже записано
+// другое собрание (например, преподаватель перезапустил звонок), отметки объединяются с ним, а не дублируются в
+// статистике, и возвращается true. Пути сформированных отчётов собрания (ключ - формат) запоминаются для веб-панели
+func (store *Store) AppendSession(ctx context.Context, header report.Header, members []report.Member,
+	reports map[string]string) (bool, error) {
+	//Переводим дату собрания в формат ГГГГ-ММ-ДД, чтобы записи в базе сортировались по дате
+	date, err := ParseDate(header.Date)
+	if err != nil {
+		return false, err
+	}
+	semester := Semester(date)
+
+	//Собрание и отметки участников записываются одной транзакцией
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("ошибка записи в базу истории: %w", err)
+	}
+	defer tx.Rollback()
+
+	//Собрание той же пары, записанное по другим отчётам, дополняется отметками обрабатываемого собрания
+	conflict, err := findConflict(ctx, tx, header, date, members)
+	if err != nil {
+		return false, err
+	}
+	if conflict != 0 {
+		if err := mergeSession(ctx, tx, conflict, header, members, date, semester); err != nil {
+			return false, err
+		}
+		if err := insertReports(ctx, tx, conflict, reports); err != nil {
+			return false, err
+		}
+		if err := tx.Commit(); err != nil {
+			return false, fmt.Errorf("ошибка записи в базу истории: %w", err)
+		}
+		return true, nil
+	}
+
+	//Кворум записывается, только если он проверялся
+	var quorum sql.NullBool
+	if header.Quorum.Checked {
+		quorum = sql.NullBool{Bool: header.Quorum.Met, Valid: true}
+	}
+
+	//Закрытое собрание, изменяемое с флагом --force, остаётся закрытым
+	var finalizedAt sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT MAX(finalized_at) FROM meetings WHERE `+finalizedCondition,
+		header.SourceHash, header.Title, date.Format("2006-01-02"), header.LessonNumber).Scan(&finalizedAt); err != nil {
+		return false, fmt.Errorf("ошибка чтения собрания из базы истории: %w", err)
+	}
+
+	//Удаляем собрание, записанное при прежней обработке тех же отчётов
+	if header.SourceHash != "" {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM merged_sources WHERE meeting_id IN
+			(SELECT id FROM meetings WHERE source_hash = ?)`, header.SourceHash); err != nil {
+			return false, fmt.Errorf("ошибка удаления повторно обработанного собрания из базы истории: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM meeting_reports WHERE meeting_id IN
+			(SELECT id FROM meetings WHERE source_hash = ?)`, header.SourceHash); err != nil {
+			return false, fmt.Errorf("ошибка удаления повторно обработанного собрания из базы истории: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM attendance WHERE meeting_id IN
+			(SELECT id FROM meetings WHERE source_hash = ?)`, header.SourceHash); err != nil {
+			return false, fmt.Errorf("ошибка удаления повторно обработанного собрания из базы истории: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM name_matches WHERE meeting_id IN
+			(SELECT id FROM meetings WHERE source_hash = ?)`, header.SourceHash); err != nil {
+			return false, fmt.Errorf("ошибка удаления повторно обработанного собрания из базы истории: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM meetings WHERE source_hash = ?`, header.SourceHash); err != nil {
+			return false, fmt.Errorf("ошибка удаления повторно обработанного собрания из базы истории: %w", err)
+		}
+	}
+
+	//Хэш содержимого записывается, только если он известен
+	var sourceHash sql.NullString
+	if header.SourceHash != "" {
+		sourceHash = sql.NullString{String: header.SourceHash, Valid: true}
+	}
+
+	//Преподаватель и продолжительность собрания записываются, только если они известны
+	var lecturer sql.NullString
+	if header.Lecturer != "" {
+		lecturer = sql.NullString{String: header.Lecturer, Valid: true}
+	}
+	var duration sql.NullInt64
+	if header.Duration > 0 {
+		duration = sql.NullInt64{Int64: int64(header.Duration), Valid: true}
+	}
+
+	//Ссылки на собрание и на его запись известны только для отчётов, загруженных из Microsoft Graph
+	var joinURL, recordingURL sql.NullString
+	if header.JoinURL != "" {
+		joinURL = sql.NullString{String: header.JoinURL, Valid: true}
+	}
+	if header.RecordingURL != "" {
+		recordingURL = sql.NullString{String: header.RecordingURL, Valid: true}
+	}
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO meetings (title, date, lesson, semester, processed_at, quorum,
+		source_hash, finalized_at, lecturer, duration, join_url, recording_url) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		?)`, header.Title, date.Format("2006-01-02"), header.LessonNumber, semester, time.Now().Format(time.RFC3339),
+		quorum, sourceHash, finalizedAt, lecturer, duration, joinURL, recordingURL)
+	if err != nil {
+		return false, fmt.Errorf("ошибка записи собрания в базу истории: %w", err)
+	}
+	meetingID, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("ошибка записи собрания в базу истории: %w", err)
+	}
+
+	//Цикл по всем участникам собрания
+	for _, member := range members {
+		if member.FullName == "" {
+			continue
+		}
+		if err := insertMark(ctx, tx, meetingID, member, date, semester); err != nil {
+			return false, err
+		}
+	}
+	if err := insertReports(ctx, tx, meetingID, reports); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("ошибка записи в базу истории: %w", err)
+	}
+
+	return false, nil
+}
+
+// insertMark Вспомогательная функция, записывающая отметку участника собрания и его сопоставление со студентом по
+// похожему ФИО
+func insertMark(ctx context.Context, tx *sql.Tx, meetingID int64, member report.Member, date time.Time,
+	semester string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO attendance (meeting_id, student, student_group, date, semester, presence,
+		delay, early_exit) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, meetingID, member.FullName, member.Group,
+		date.Format("2006-01-02"), semester, member.Presence.String(), member.Delay.String(), member.EarlyExit.String())
+	if err != nil {
+		return fmt.Errorf("ошибка записи участника собрания в базу истории: %w", err)
+	}
+
+	//Сопоставление гостя со студентом по похожему ФИО запоминается для поиска постоянных псевдонимов
+	if member.MatchedFrom != "" {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO name_matches (meeting_id, name, student) VALUES (?, ?, ?)`,
+			meetingID, member.MatchedFrom, member.FullName); err != nil {
+			return fmt.Errorf("ошибка записи сопоставления участника в базу истории: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// insertReports Вспомогательная функция, запоминающая пути сформированных отчётов собрания в порядке названий
+// форматов. Уже записанные пути не повторяются
+func insertReports(ctx context.Context, tx *sql.Tx, meetingID int64, reports map[string]string) error {
+	formats := make([]string, 0, len(reports))
+	for format := range reports {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	for _, format := range formats {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO meeting_reports (meeting_id, format, path) SELECT ?, ?, ?
+			WHERE NOT EXISTS (SELECT 1 FROM meeting_reports WHERE meeting_id = ? AND path = ?)`, meetingID, format,
+			reports[format], meetingID, reports[format]); err != nil {
+			return fmt.Errorf("ошибка записи отчёта собрания в базу истории: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// findConflict Вспомогательная функция, возвращающая идентификатор собрания, записанного на той же паре того же дня
+// у тех же групп по другим отчётам (0, если такого собрания нет). Собрание, записанное по тем же отчётам без
+// объединения с другими, не считается конфликтом и заменяется. Консультации и технические созвоны не объединяются
+func findConflict(ctx context.Context, tx *sql.Tx, header report.Header, date time.Time,
+	members []report.Member) (int64, error) {
+	if header.LessonNumber == schedule.Consultation || header.LessonNumber == schedule.TechnicalCall {
+		return 0, nil
+	}
+
+	//Группы собрания без гостей и преподавателей
+	var groups []interface{}
+	seen := make(map[string]bool)
+	for _, member := range members {
+		if member.FullName == "" || member.Group == "" || member.Group == roster.Guest ||
+			member.Group == roster.Teacher || seen[member.Group] {
+			continue
+		}
+		seen[member.Group] = true
+		groups = append(groups, member.Group)
+	}
+	if len(groups) == 0 {
+		return 0, nil
+	}
+
+	arguments := append([]interface{}{date.Format("2006-01-02"), header.LessonNumber, header.SourceHash}, groups...)
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT meetings.id
+		FROM meetings JOIN attendance ON attendance.meeting_id = meetings.id
+		WHERE meetings.date = ? AND meetings.lesson = ? AND NOT (meetings.source_hash IS NOT NULL AND
+			meetings.source_hash = ? AND NOT EXISTS (SELECT 1 FROM merged_sources WHERE meeting_id = meetings.id))
+		AND attendance.student_group IN (?`+strings.Repeat(", ?", len(groups)-1)+`)
+		ORDER BY meetings.id LIMIT 1`, arguments...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка поиска собрания той же пары в базе истории: %w", err)
+	}
+
+	return id, nil
+}
+
+// mergeSession Вспомогательная функция, объединяющая собрание с собранием той же пары, уже записанным в историю:
+// каждому студенту остаётся лучшая из двух отметок, продолжительности собраний суммируются, а известные только
+// обрабатываемому собранию преподаватель и ссылки дополняют записанное собрание. Повторное объединение тех же отчётов
+// не меняет продолжительность
+func mergeSession(ctx context.Context, tx *sql.Tx, meetingID int64, header report.Header, members []report.Member,
+	date time.Time, semester string) error {
+	//Отчёты, уже объединённые с собранием, узнаются по хэшу содержимого
+	var merged bool
+	if header.SourceHash != "" {
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM meetings WHERE id = ? AND source_hash = ?) OR
+			EXISTS (SELECT 1 FROM merged_sources WHERE meeting_id = ? AND source_hash = ?)`, meetingID,
+			header.SourceHash, meetingID, header.SourceHash).Scan(&merged); err != nil {
+			return fmt.Errorf("ошибка чтения собрания из базы истории: %w", err)
+		}
+	}
+
+	//Цикл по всем участникам собрания
+	for _, member := range members {
+		if member.FullName == "" {
+			continue
+		}
+
+		var presence string
+		err := tx.QueryRowContext(ctx, `SELECT presence FROM attendance WHERE meeting_id = ? AND student = ?`,
+			meetingID, member.FullName).Scan(&presence)
+		switch {
+		//Участник не был записан на собрании той же пары
+		case errors.Is(err, sql.ErrNoRows):
+			if err := insertMark(ctx, tx, meetingID, member, date, semester); err != nil {
+				return err
+			}
+		case err != nil:
+			return fmt.Errorf("ошибка чтения отметки участника из базы истории: %w", err)
+		//Отметка заменяется, только если на обрабатываемом собрании участник присутствовал дольше
+		case presenceRank(member.Presence.String()) > presenceRank(presence):
+			if _, err := tx.ExecContext(ctx, `UPDATE attendance SET student_group = ?, presence = ?, delay = ?,
+				early_exit = ? WHERE meeting_id = ? AND student = ?`, member.Group, member.Presence.String(),
+				member.Delay.String(), member.EarlyExit.String(), meetingID, member.FullName); err != nil {
+				return fmt.Errorf("ошибка записи участника собрания в базу истории: %w", err)
+			}
+		}
+	}
+	if merged {
+		return nil
+	}
+
+	//Дополняем записанное собрание сведениями обрабатываемого собрания
+	var lecturer, joinURL, recordingURL sql.NullString
+	if header.Lecturer != "" {
+		lecturer = sql.NullString{String: header.Lecturer, Valid: true}
+	}
+	if header.JoinURL != "" {
+		joinURL = sql.NullString{String: header.JoinURL, Valid: true}
+	}
+	if header.RecordingURL != "" {
+		recordingURL = sql.NullString{String: header.RecordingURL, Valid: true}
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE meetings SET duration = COALESCE(duration, 0) + ?,
+		lecturer = COALESCE(lecturer, ?), join_url = COALESCE(join_url, ?), recording_url = COALESCE(recording_url, ?)
+		WHERE id = ?`, header.Duration, lecturer, joinURL, recordingURL, meetingID); err != nil {
+		return fmt.Errorf("ошибка объединения собрания в базе истории: %w", err)
+	}
+	if header.SourceHash != "" {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO merged_sources (meeting_id, source_hash) VALUES (?, ?)`,
+			meetingID, header.SourceHash); err != nil {
+			return fmt.Errorf("ошибка объединения собрания в базе истории: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// presenceRank Вспомогательная функция, возвращающая порядок пометки о присутствии при объединении собраний одной
+// пары: чем дольше участник присутствовал, тем больше порядок
+func presenceRank(presence string) int {
+	switch presence {
+	case report.PresenceFull.String():
+		return 3
+	case report.PresencePartial.String():
+		return 2
+	case report.PresenceExcused.String():
+		return 1
+	case report.PresenceAbsent.String():
+		return 0
+	default:
+		return -1
+	}
+}
+
+// finalizedCondition Условие отбора закрытых собраний, к которым относится обрабатываемое собрание: собрание с тем же
+// хэшем содержимого отчётов или с тем же названием, датой и номером пары
+const finalizedCondition = `finalized_at IS NOT NULL AND ((source_hash IS NOT NULL AND source_hash = ?) OR
+	(title = ? AND date = ? AND lesson = ?))`
+
+// IsFinalized Функция, проверяющая, закрыто ли собрание командой finalize: такое собрание и его отчёты не изменяются
+// без флага --force
+func (store *Store) IsFinalized(ctx context.Context, header report.Header) (bool, error) {
+	date, err := ParseDate(header.Date)
+	if err != nil {
+		return false, err
+	}
+
+	var finalized bool
+	if err := store.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM meetings WHERE `+finalizedCondition+`)`,
+		header.SourceHash, header.Title, date.Format("2006-01-02"), header.LessonNumber).Scan(&finalized); err != nil {
+		return false, fmt.Errorf("ошибка чтения собрания из базы истории: %w", err)
+	}
+
+	return finalized, nil
+}
+
+// Finalize Функция, закрывающая собрания с from по to включительно (как закрывается бумажный журнал в конце месяца).
+// Если указано название, закрываются только собрания, в названии которых оно встречается (без учёта регистра).
+// Возвращает количество закрытых собраний, уже закрытые собрания не учитываются
+func (store *Store) Finalize(ctx context.Context, from, to time.Time, title string) (int, error) {
+	tx, err := store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка записи в базу истории: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, title FROM meetings WHERE finalized_at IS NULL AND date BETWEEN ? AND ?`,
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса собраний из базы истории: %w", err)
+	}
+
+	//Название отбирается без учёта регистра на стороне программы, так как SQLite не меняет регистр кириллицы
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var meetingTitle string
+		if err := rows.Scan(&id, &meetingTitle); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("ошибка чтения собраний из базы истории: %w", err)
+		}
+		if title == "" || strings.Contains(strings.ToLower(meetingTitle), strings.ToLower(title)) {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка чтения собраний из базы истории: %w", err)
+	}
+
+	finalizedAt := time.Now().Format(time.RFC3339)
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE meetings SET finalized_at = ? WHERE id = ?`, finalizedAt, id); err != nil {
+			return 0, fmt.Errorf("ошибка закрытия собрания в базе истории: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("ошибка записи в базу истории: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// MeetingReport Структура сформированного отчёта собрания из истории посещаемости
+type MeetingReport struct {
+	//Формат отчёта (csv, xlsx, json, ...)
+	Format string
+	//Полный путь до отчёта
+	Path string
+}
+
+// MeetingReports Функция, возвращающая сформированные отчёты собрания в порядке записи. У собрания, объединённого с
+// собранием той же пары, возвращаются отчёты обоих собраний
+func (store *Store) MeetingReports(ctx context.Context, meetingID int64) ([]MeetingReport, error) {
+	rows, err := store.db.QueryContext(ctx, `SELECT format, path FROM meeting_reports WHERE meeting_id = ?
+		ORDER BY rowid`, meetingID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса отчётов собрания из базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []MeetingReport
+	for rows.Next() {
+		var current MeetingReport
+		if err := rows.Scan(&current.Format, &current.Path); err != nil {
+			return nil, fmt.Errorf("ошибка чтения отчётов собрания из базы истории: %w", err)
+		}
+		reports = append(reports, current)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения отчётов собрания из базы истории: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Mark Структура отметки студента на собрании из истории посещаемости
+type Mark struct {
+	//Идентификатор собрания в истории
+	MeetingID int64
+	//Название собрания
+	Title string
+	//Дата собрания
+	Date time.Time
+	//Номер пары
+	Lesson string
+	//ФИО студента
+	FullName string
+	//Группа студента (текущее название, если группа переименована)
+	Group string
+	//Пометки о присутствии и опоздании
+	Presence string
+	Delay    string
+}
+
+// marksQuery Запрос отметок студентов на собраниях за период, условие отбора по группе подставляется в запрос
+const marksQuery = `
+SELECT meetings.id, meetings.title, meetings.date, meetings.lesson, attendance.student, attendance.student_group,
+	attendance.presence, attendance.delay
+FROM attendance JOIN meetings ON meetings.id = attendance.meeting_id
+WHERE attendance.date BETWEEN ? AND ?%s
+ORDER BY meetings.date, meetings.lesson, meetings.id, attendance.rowid`
+
+// Marks Функция, возвращающая отметки студентов на собраниях с from по to включительно. Если группа указана,
+// возвращаются отметки только её студентов, включая записи под прежними названиями группы
+func (store *Store) Marks(ctx context.Context, from, to time.Time, group string) ([]Mark, error) {
+	condition := ""
+	arguments := []interface{}{from.Format("2006-01-02"), to.Format("2006-01-02")}
+	if group != "" {
+		names := roster.GroupNames(group)
+		condition = " AND attendance.student_group IN (?" + strings.Repeat(", ?", len(names)-1) + ")"
+		for _, name := range names {
+			arguments = append(arguments, name)
+		}
+	}
+
+	rows, err := store.db.QueryContext(ctx, fmt.Sprintf(marksQuery, condition), arguments...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса отметок из базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	var marks []Mark
+	for rows.Next() {
+		var current Mark
+		var date string
+		if err := rows.Scan(&current.MeetingID, &current.Title, &date, &current.Lesson, &current.FullName,
+			&current.Group, &current.Presence, &current.Delay); err != nil {
+			return nil, fmt.Errorf("ошибка чтения отметок из базы истории: %w", err)
+		}
+		if current.Date, err = time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("ошибка чтения даты собрания из базы истории: %w", err)
+		}
+		current.Group = roster.CanonicalGroup(current.Group)
+		marks = append(marks, current)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения отметок из базы истории: %w", err)
+	}
+
+	return marks, nil
+}
+
+// AbsenceStreak Функция, возвращающая количество последних собраний подряд, которые студент пропустил. Пропуски по
+// уважительной причине не учитываются и не прерывают серию пропусков
+func (store *Store) AbsenceStreak(ctx context.Context, fullName string) (int, error) {
+	rows, err := store.db.QueryContext(ctx, `SELECT attendance.presence
+FROM attendance JOIN meetings ON meetings.id = attendance.meeting_id
+WHERE attendance.student = ?
+ORDER BY meetings.date DESC, meetings.lesson DESC, meetings.id DESC`, fullName)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка запроса отметок из базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	streak := 0
+	for rows.Next() {
+		var presence string
+		if err := rows.Scan(&presence); err != nil {
+			return 0, fmt.Errorf("ошибка чтения отметок из базы истории: %w", err)
+		}
+		if presence == report.PresenceExcused.String() {
+			continue
+		}
+		if presence != report.PresenceAbsent.String() {
+			break
+		}
+		streak++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("ошибка чтения отметок из базы истории: %w", err)
+	}
+
+	return streak, nil
+}
+
+// Workload Структура нагрузки преподавателя за месяц по истории посещаемости
+type Workload struct {
+	//Месяц (первое число месяца)
+	Month time.Time `json:"month"`
+	//ФИО преподавателя (пустое, если преподаватель собрания неизвестен)
+	Teacher string `json:"teacher"`
+	//Количество проведённых собраний
+	Meetings int `json:"meetings"`
+	//Количество отметок студентов на собраниях (ожидавшихся студентов)
+	Expected int `json:"expected"`
+	//Количество отметок о присутствии (полном или неполном)
+	Attended int `json:"attended"`
+	//Студенто-часы: сумма продолжительностей собраний, умноженных на количество присутствовавших студентов
+	StudentHours float64 `json:"student_hours"`
+}
+
+// workloadQuery Запрос нагрузки преподавателей по месяцам: сначала отметки считаются по каждому собранию, затем
+// собрания объединяются по месяцу и преподавателю
+const workloadQuery = `
+SELECT substr(date, 1, 7) AS month, COALESCE(lecturer, '') AS teacher, COUNT(*), SUM(expected), SUM(attended),
+	SUM(attended * COALESCE(duration, 0))
+FROM (SELECT meetings.date, meetings.lecturer, meetings.duration,
+		COALESCE(SUM(attendance.student_group != ?), 0) AS expected,
+		COALESCE(SUM(attendance.student_group != ? AND attendance.presence IN (?, ?)), 0) AS attended
+	FROM meetings LEFT JOIN attendance ON attendance.meeting_id = meetings.id
+	WHERE meetings.date BETWEEN ? AND ? AND meetings.lesson != ?
+	GROUP BY meetings.id)
+GROUP BY month, teacher
+ORDER BY month, teacher`
+
+// Workloads Функция, возвращающая нагрузку преподавателей по месяцам для собраний с from по to включительно.
+// Технические созвоны и гости не учитываются, собрания с неизвестной продолжительностью не входят в студенто-часы
+func (store *Store) Workloads(ctx context.Context, from, to time.Time) ([]Workload, error) {
+	rows, err := store.db.QueryContext(ctx, workloadQuery, roster.Guest, roster.Guest, report.PresenceFull.String(),
+		report.PresencePartial.String(), from.Format("2006-01-02"), to.Format("2006-01-02"), schedule.TechnicalCall)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса нагрузки преподавателей из базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	var workloads []Workload
+	for rows.Next() {
+		var current Workload
+		var month string
+		var seconds int64
+		if err := rows.Scan(&month, &current.Teacher, &current.Meetings, &current.Expected, &current.Attended,
+			&seconds); err != nil {
+			return nil, fmt.Errorf("ошибка чтения нагрузки преподавателей из базы истории: %w", err)
+		}
+		if current.Month, err = time.Parse("2006-01", month); err != nil {
+			return nil, fmt.Errorf("ошибка чтения даты собрания из базы истории: %w", err)
+		}
+		current.StudentHours = float64(seconds) / 3600
+		workloads = append(workloads, current)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения нагрузки преподавателей из базы истории: %w", err)
+	}
+
+	return workloads, nil
+}
+
+/*====================================================================================================================*/
+
+// statsQuery Запрос накопленной посещаемости по семестрам, условие отбора подставляется в запрос. Пометки
+// присутствия и опоздания передаются параметрами перед параметрами условия
+const statsQuery = `
+SELECT semester, student, student_group, COUNT(*),
+	SUM(presence = ?), SUM(presence = ?), SUM(delay = ?), SUM(presence = ?)
+FROM attendance
+WHERE %s
+GROUP BY semester, student, student_group
+ORDER BY semester, student_group, student`
+
+// StudentStats Функция, возвращающая накопленную посещаемость студентов, ФИО которых начинается с fullName. Символы
+// шаблона LIKE ("%" и "_") в fullName экранируются и сравниваются как есть
+func (store *Store) StudentStats(ctx context.Context, fullName string) ([]Stats, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(fullName)
+
+	return store.stats(ctx, `student LIKE ? || '%' ESCAPE '\'`, escaped)
+}
+
+// GroupStats Функция, возвращающая накопленную посещаемость всех студентов группы, включая записи под прежними
+// названиями группы
+func (store *Store) GroupStats(ctx context.Context, group string) ([]Stats, error) {
+	names := roster.GroupNames(group)
+
+	//Условие отбора по всем названиям группы
+	arguments := make([]interface{}, len(names))
+	for i, name := range names {
+		arguments[i] = name
+	}
+	condition := "student_group IN (?" + strings.Repeat(", ?", len(names)-1) + ")"
+
+	return store.stats(ctx, condition, arguments...)
+}
+
+// stats Вспомогательная функция, выполняющая запрос накопленной посещаемости с заданным условием. Записи под
+// прежними названиями групп объединяются с записями под текущими
+func (store *Store) stats(ctx context.Context, condition string, arguments ...interface{}) ([]Stats, error) {
+	//Пометки, по которым подсчитывается посещаемость, - те же подписи, что записываются в историю
+	statuses := []interface{}{report.PresenceFull.String(), report.PresencePartial.String(), report.DelayLate.String(),
+		report.PresenceAbsent.String()}
+	rows, err := store.db.QueryContext(ctx, fmt.Sprintf(statsQuery, condition), append(statuses, arguments...)...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса посещаемости из базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []Stats
+	for rows.Next() {
+		var current Stats
+		if err := rows.Scan(&current.Semester, &current.FullName, &current.Group, &current.Lessons, &current.Present,
+			&current.Partial, &current.Late, &current.Missed); err != nil {
+			return nil, fmt.Errorf("ошибка чтения посещаемости из базы истории: %w", err)
+		}
+		stats = append(stats, current)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения посещаемости из базы истории: %w", err)
+	}
+
+	return mergeGroupAliases(stats), nil
+}
+
+// mergeGroupAliases Вспомогательная функция, объединяющая посещаемость студента за семестр, записанную под прежним и
+// текущим названиями группы
+func mergeGroupAliases(stats []Stats) []Stats {
+	var merged []Stats
+	indexes := make(map[Stats]int)
+
+	for _, current := range stats {
+		current.Group = roster.CanonicalGroup(current.Group)
+		key := Stats{Semester: current.Semester, FullName: current.FullName, Group: current.Group}
+
+		if index, ok := indexes[key]; ok {
+			merged[index].Lessons += current.Lessons
+			merged[index].Present += current.Present
+			merged[index].Partial += current.Partial
+			merged[index].Late += current.Late
+			merged[index].Missed += current.Missed
+			continue
+		}
+
+		indexes[key] = len(merged)
+		merged = append(merged, current)
+	}
+
+	//Сохраняем порядок запроса: по семестру, группе и ФИО
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Semester != merged[j].Semester {
+			return merged[i].Semester < merged[j].Semester
+		}
+		if merged[i].Group != merged[j].Group {
+			return merged[i].Group < merged[j].Group
+		}
+		return merged[i].FullName < merged[j].FullName
+	})
+
+	return merged
+}
+
+// NameMatch Структура сопоставления гостя со студентом базы по похожему ФИО из истории посещаемости
+type NameMatch struct {
+	//ФИО гостя из отчёта MS Teams
+	Name string
+	//ФИО студента из базы групп, с которым сопоставлен гость
+	FullName string
+	//Количество собраний, на которых гость сопоставлен с этим студентом
+	Meetings int
+}
+
+// NameMatches Функция, возвращающая сопоставления гостей со студентами из истории посещаемости с количеством собраний
+// каждого сопоставления, в порядке ФИО гостя
+func (store *Store) NameMatches(ctx context.Context) ([]NameMatch, error) {
+	rows, err := store.db.QueryContext(ctx, `SELECT name, student, COUNT(DISTINCT meeting_id) FROM name_matches
+		GROUP BY name, student ORDER BY name, student`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса сопоставлений из базы истории: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []NameMatch
+	for rows.Next() {
+		var current NameMatch
+		if err := rows.Scan(&current.Name, &current.FullName, &current.Meetings); err != nil {
+			return nil, fmt.Errorf("ошибка чтения сопоставлений из базы истории: %w", err)
+		}
+		matches = append(matches, current)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения сопоставлений из базы истории: %w", err)
+	}
+
+	return matches, nil
+}
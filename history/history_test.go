@@ -0,0 +1,244 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"mod.go/report"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// testMember Вспомогательная функция, возвращающая участника собрания группы МП-51 с пометкой о присутствии
+func testMember(fullName string, presence report.PresenceStatus) report.Member {
+	member := report.Member{Group: "МП-51", FullName: fullName, Presence: presence}
+	if presence == report.PresenceFull || presence == report.PresencePartial {
+		member.Delay, member.EarlyExit = report.DelayNone, report.Exit{Status: report.ExitFull}
+	}
+
+	return member
+}
+
+// testSession Вспомогательная функция, возвращающая оглавление собрания первой пары 15.10.2026 продолжительностью
+// duration минут, записанного по отчётам с хэшем содержимого hash
+func testSession(title, hash string, duration int) report.Header {
+	return report.Header{Title: title, Date: "15.10.2026", LessonNumber: "Пара 1", Lecturer: "Лекторов Пётр Сергеевич",
+		Duration: duration * 60, SourceHash: hash}
+}
+
+// appendRun Вспомогательная функция, записывающая собрание в историю отдельным запуском программы: база открывается
+// заново и закрывается после записи. Возвращает, было ли собрание объединено с собранием той же пары
+func appendRun(t *testing.T, path string, header report.Header, members ...report.Member) bool {
+	t.Helper()
+
+	store, err := Open(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ошибка открытия базы истории: %v", err)
+	}
+	defer store.Close()
+
+	merged, err := store.AppendSession(context.Background(), header, members, nil)
+	if err != nil {
+		t.Fatalf("ошибка записи собрания в историю: %v", err)
+	}
+
+	return merged
+}
+
+/*====================================================================================================================*/
+
+// TestAppendSessionMergesSlot Проверка объединения собраний одной пары из разных запусков программы: преподаватель
+// перезапустил звонок, и отчёты двух собраний обработаны по-отдельности. Занятие учитывается в статистике один раз,
+// студенту остаётся лучшая отметка, а повторная обработка отчётов не меняет продолжительность собрания
+func TestAppendSessionMergesSlot(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	if appendRun(t, path, testSession("Математический анализ", "first", 30),
+		testMember("Иванов Иван Иванович", report.PresenceFull),
+		testMember("Петрова Мария Петровна", report.PresenceAbsent)) {
+		t.Errorf("первое собрание пары объединено с несуществующим собранием")
+	}
+	second := testSession("Математический анализ (продолжение)", "second", 50)
+	for run := 0; run < 2; run++ {
+		if !appendRun(t, path, second, testMember("Иванов Иван Иванович", report.PresenceAbsent),
+			testMember("Петрова Мария Петровна", report.PresencePartial),
+			testMember("Сидоров Алексей Викторович", report.PresenceFull)) {
+			t.Errorf("запуск %d: собрание той же пары не объединено с записанным собранием", run+1)
+		}
+	}
+
+	store, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	stats, err := store.GroupStats(ctx, "МП-51")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][2]int{
+		"Иванов Иван Иванович":       {1, 0},
+		"Петрова Мария Петровна":     {0, 1},
+		"Сидоров Алексей Викторович": {1, 0},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("в статистике %d студентов, ожидалось %d: %+v", len(stats), len(want), stats)
+	}
+	for _, current := range stats {
+		if current.Lessons != 1 || [2]int{current.Present, current.Partial} != want[current.FullName] {
+			t.Errorf("%v: занятий %d, полностью %d, не полностью %d", current.FullName, current.Lessons,
+				current.Present, current.Partial)
+		}
+	}
+
+	day := time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+	workloads, err := store.Workloads(ctx, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(workloads) != 1 || workloads[0].Meetings != 1 || workloads[0].StudentHours != 3*80.0/60 {
+		t.Errorf("нагрузка после объединения собраний: %+v, ожидалось 1 собрание и 4 студенто-часа", workloads)
+	}
+}
+
+// TestAppendSessionOtherGroup Проверка, что собрания разных групп на одной паре не объединяются
+func TestAppendSessionOtherGroup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	appendRun(t, path, testSession("Математический анализ", "first", 90),
+		testMember("Иванов Иван Иванович", report.PresenceFull))
+	other := testMember("Смирнова Анна Павловна", report.PresenceFull)
+	other.Group = "МП-52"
+	if appendRun(t, path, testSession("Физика", "second", 90), other) {
+		t.Errorf("собрание другой группы объединено с собранием той же пары")
+	}
+}
+
+// TestAppendSessionSameSource Проверка повторной обработки тех же отчётов: собрание с тем же хэшем содержимого
+// заменяется, а не объединяется и не дублируется, и в истории остаются отметки последней обработки
+func TestAppendSessionSameSource(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	header := testSession("Математический анализ", "same", 90)
+	appendRun(t, path, header, testMember("Иванов Иван Иванович", report.PresenceAbsent))
+	if appendRun(t, path, header, testMember("Иванов Иван Иванович", report.PresenceFull),
+		testMember("Петрова Мария Петровна", report.PresenceFull)) {
+		t.Errorf("повторно обработанное собрание объединено, а не заменено")
+	}
+
+	store, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	day := time.Date(2026, time.October, 15, 0, 0, 0, 0, time.UTC)
+	marks, err := store.Marks(ctx, day, day, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(marks) != 2 {
+		t.Fatalf("в истории %d отметок, ожидалось 2: %+v", len(marks), marks)
+	}
+	for _, mark := range marks {
+		if mark.MeetingID != marks[0].MeetingID || mark.Presence != report.PresenceFull.String() {
+			t.Errorf("отметка %+v, ожидалось присутствие на одном собрании", mark)
+		}
+	}
+}
+
+// TestStats Проверка накопленной посещаемости студента и группы: занятия, полное и неполное присутствие, опоздания и
+// пропуски. Пропуск по уважительной причине учитывается как занятие, но не как пропуск. Начало ФИО студента не
+// является шаблоном LIKE
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	late := testMember("Иванов Иван Иванович", report.PresencePartial)
+	late.Delay = report.DelayLate
+	for i, member := range []report.Member{
+		testMember("Иванов Иван Иванович", report.PresenceFull),
+		late,
+		testMember("Иванов Иван Иванович", report.PresenceAbsent),
+		testMember("Иванов Иван Иванович", report.PresenceExcused),
+	} {
+		header := testSession("Математический анализ", fmt.Sprint("lesson", i), 90)
+		header.Date = fmt.Sprintf("%02d.10.2026", 12+i)
+		appendRun(t, path, header, member, testMember("Иванова Анна Сергеевна", report.PresenceFull))
+	}
+
+	store, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	stats, err := store.StudentStats(ctx, "Иванов Иван")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Stats{Semester: "2026-осень", FullName: "Иванов Иван Иванович", Group: "МП-51", Lessons: 4, Present: 1,
+		Partial: 1, Late: 1, Missed: 1}
+	if len(stats) != 1 || stats[0] != want {
+		t.Errorf("посещаемость студента %+v, ожидалось %+v", stats, want)
+	}
+
+	stats, err = store.GroupStats(ctx, "МП-51")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || stats[0].FullName != "Иванов Иван Иванович" || stats[1].Present != 4 {
+		t.Errorf("посещаемость группы %+v, ожидалось 2 студента", stats)
+	}
+
+	//Символы шаблона LIKE в ФИО сравниваются как есть: "Иванов_" не находит "Иванова", а "%" - всех студентов
+	for _, prefix := range []string{"%", "Иванов_", "Иван%Иванович", `Иванов\`} {
+		stats, err := store.StudentStats(ctx, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stats) != 0 {
+			t.Errorf("по началу ФИО %q найдено %+v, ожидалось ничего", prefix, stats)
+		}
+	}
+}
+
+// TestAbsenceStreak Проверка серии пропусков: считаются последние собрания подряд, пропуски по уважительной причине
+// не прерывают серию, а присутствие прерывает
+func TestAbsenceStreak(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+
+	for i, presence := range []report.PresenceStatus{report.PresenceAbsent, report.PresenceFull,
+		report.PresenceAbsent, report.PresenceExcused, report.PresenceAbsent} {
+		header := testSession("Математический анализ", fmt.Sprint("lesson", i), 90)
+		header.Date = fmt.Sprintf("%02d.10.2026", 12+i)
+		appendRun(t, path, header, testMember("Иванов Иван Иванович", presence),
+			testMember("Петрова Мария Петровна", report.PresenceFull))
+	}
+
+	store, err := Open(ctx, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for fullName, want := range map[string]int{
+		"Иванов Иван Иванович":       2,
+		"Петрова Мария Петровна":     0,
+		"Сидоров Алексей Викторович": 0,
+	} {
+		streak, err := store.AbsenceStreak(ctx, fullName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if streak != want {
+			t.Errorf("%v: серия пропусков %d, ожидалось %d", fullName, streak, want)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package i18n Пакет перевода итоговых отчётов и сообщений программы. Тексты в коде пишутся на русском языке и служат
+// ключами каталогов сообщений, для других языков текст заменяется переводом из каталога
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Языки итоговых отчётов и сообщений программы
+const (
+	//Русский язык - язык текстов в коде, каталог для него не нужен
+	Russian = "ru"
+	//Английский язык
+	English = "en"
+)
+
+// Language Язык итоговых отчётов и сообщений программы. Устанавливается из файла конфигураций
+var Language = Russian
+
+// catalogs Каталоги сообщений: для каждого языка, кроме русского, - перевод русских текстов. Тексты с пометками
+// формата (%v, %d) переводятся до подстановки значений
+var catalogs = map[string]map[string]string{
+	English: {
+		//Оглавление и таблица участников отчёта
+		"Название собрания":        "Meeting title",
+		"Дата проведения собрания": "Meeting date",
+		"Номер пары":               "Lesson",
+		"Кворум":                   "Quorum",
+		"Группа":                   "Group",
+		"ФИО":                      "Full name",
+		"База групп":               "Groups base",
+		"версия %v":                "version %v",
+		"обновлена %v":             "updated %v",
+		"семестр %v":               "semester %v",
+		"Номер зачётки":            "Record book",
+		"Присутствие":              "Attendance",
+		"Опоздание":                "Lateness",
+		"Время нахождения на собрании": "Time in meeting",
+		"Формат участия":               "Participation",
+		"Запись на консультацию":       "Consultation sign-up",
+		"Гость":              "Guest",
+		"Гости":              "Guests",
+		"Преподаватели":      "Teaching staff",
+		"Роль":               "Role",
+		"Присоединение":      "Joined",
+		"Выход":              "Left",
+		"Минут на собрании":  "Minutes in meeting",
+		"Преподаватель":      "Lecturer",
+		"Пара %d":            "Lesson %d",
+		"Консультация":       "Consultation",
+		"Технический созвон": "Technical call",
+		"Есть (%d из %d)":    "Met (%d of %d)",
+		"Нет (%d из %d)":     "Not met (%d of %d)",
+
+		//Пометки участников
+		"Присутствовал":                       "Present",
+		"Присутствовал не полностью":          "Partially present",
+		"Отсутствовал (уважительная причина)": "Absent (excused)",
+		"Отсутствовал":                        "Absent",
+		"Без опоздания":                       "On time",
+		"Опоздал":                             "Late",
+		"Опоздал на %d мин":                   "%d min late",
+		"Полное присутствие на паре":          "Full attendance",
+		"Малое присутствие на паре":           "Under a minute",
+		"Малое нахождение на паре":            "Under half an hour",
+		"Ушёл раньше на %d мин":               "Left %d min early",
+		"онлайн":                              "online",
+		"очно":                                "in person",
+		"записан":                             "signed up",
+		"без записи":                          "walk-in",
+		"%v (возможно, проблемы с Teams)":     "%v (possible Teams issues)",
+
+		//Названия файлов, сводка и статистика устройств
+		"Отчёт о проведение собрания_": "Attendance report_",
+		"Сводка посещаемости_":         "Attendance summary_",
+		"Статистика устройств_":        "Device statistics_",
+		"Присутствовали":               "Present",
+		"Опоздали":                     "Late",
+		"Отсутствовали":                "Absent",
+		"Устройство":                   "Device",
+		"Участников":                   "Members",
+		"Доля, %":                      "Share, %",
+		"Отчёт":                        "Report",
+		"Журнал посещаемости_":         "Attendance journal_",
+		"н":                            "a",
+		"у":                            "e",
+		"оп":                           "l",
+		"Присутствовали: %d, опоздали: %d, отсутствовали: %d": "Present: %d, late: %d, absent: %d",
+		"Всего": "Total",
+		"Фильтр по группе, ФИО или отметке": "Filter by group, name or mark",
+		"преподаватель":                     "lecturer",
+		"кворум":                            "quorum",
+		"Предупреждения разбора":            "Parse warnings",
+
+		//Письма и оповещения
+		"Группа %v, %v: посещаемость %d%% (%d из %d отметок)": "Group %v, %v: attendance %d%% (%d of %d marks)",
+		"Цель %d%% достигнута":                                "Goal of %d%% reached",
+		"Цель %d%%: не хватает %d%%":                          "Goal of %d%%: %d%% short",
+		"Прошлый месяц: %d%% (%v %+d%%)":                      "Previous month: %d%% (%v %+d%%)",
+		"По неделям: %v":                                      "By week: %v",
+		"Отчёт о посещаемости: %v, %v":                        "Attendance report: %v, %v",
+		"%v, %v, %v. Группа %v, отсутствовали (%d):\n%v":      "%v, %v, %v. Group %v, absent (%d):\n%v",
+
+		//Посещаемость из истории
+		"Семестр":      "Semester",
+		"Занятий":      "Lessons",
+		"Не полностью": "Partial",
+		"Опозданий":    "Late",
+		"Пропусков":    "Missed",
+
+		//Сообщения программы
+		"Ошибка чтения формата отчёта":                                            "Error reading report format",
+		"Ошибка чтения шаблона отчёта":                                            "Error reading report template",
+		"Ошибка чтения шаблона отчёта: не указан template_path в секции [report]": "Error reading report template: template_path is not set in the [report] section",
+		"Ошибка чтения каталога отчётов: в стандартный вывод отчёт выводится только с --format json": "" +
+			"Error reading report folder: only --format json can be written to standard output",
+		"Ошибка открытия журнала действий":     "Error opening audit log",
+		"Ошибка закрытия журнала действий: %v": "Error closing audit log: %v",
+		"Ошибка записи в журнал действий":      "Error writing audit log",
+		"Ошибка команды stats":                 "stats command error",
+		"Ошибка команды config":                "config command error",
+		"Ошибка команды journal":               "journal command error",
+		"Ошибка команды live":                  "live command error",
+		"Ошибка команды serve":                 "serve command error",
+		"Ошибка команды digest":                "digest command error",
+		"Ошибка команды semester":              "semester command error",
+		"Ошибка команды view":                  "view command error",
+		"Ошибка команды mock-graph":            "mock-graph command error",
+		"Имитация Microsoft Graph доступна":    "Mock Microsoft Graph is available",
+		"Для проверки укажите в секции [graph] cfg.ini и запустите программу в другом окне:": "To test, set the following in the [graph] section of cfg.ini and run the program in another window:",
+		"Просмотр только для чтения доступен":                                                "Read-only view is available",
+		"Собрание": "Meeting",
+		"Семестр %v перенесён в архив %v":  "Semester %v archived to %v",
+		"Расписание пар заменено: %v":      "Lesson schedule replaced: %v",
+		"Начат семестр %v":                 "Semester %v started",
+		"Запросы GraphQL принимаются":      "GraphQL queries are accepted",
+		"Веб-панель посещаемости доступна": "Attendance dashboard is available",
+		"Версия программы":                 "Tool version",
+		"Профиль конфигураций":             "Config profile",
+		"профиль конфигураций":             "config profile",
+		"Сформирован":                      "Generated",
+		"сформирован":                      "generated",
+		"Собрания":                         "Meetings",
+		"Сформированные отчёты":            "Generated reports",
+		"С":                  "From",
+		"по":                 "to",
+		"Показать":           "Show",
+		"Скачать":            "Download",
+		"Ожидалось":          "Expected",
+		"Посещаемость, %":    "Attendance, %",
+		"Посещаемость групп": "Group attendance",
+		"Собраний":           "Meetings",
+		"Посещаемость собраний":                       "Meeting attendance",
+		"Участники":                                   "Participants",
+		"Посещаемость по семестрам":                   "Attendance by semester",
+		"Ошибка обновления базы групп":                "Error updating groups base",
+		"Ошибка чтения файла преподавателей":          "Staff file read error",
+		"Ошибка чтения базы групп":                    "Error reading groups base",
+		"Ошибка открытия истории посещаемости":        "Error opening attendance history",
+		"Ошибка загрузки отчётов из Microsoft Graph":  "Error downloading reports from Microsoft Graph",
+		"Отчёт обработан":                             "Report processed",
+		"Дисциплина":                                  "Course",
+		"Ошибка чтения файла уважительных причин":     "Error reading excuses file",
+		"Ошибка чтения файла псевдонимов":             "Error reading aliases file",
+		"Ошибка команды aliases":                      "aliases command error",
+		"Новых псевдонимов не найдено":                "No new aliases found",
+		"Имя в MS Teams":                              "Name in MS Teams",
+		"ФИО в базе групп":                            "Full name in groups base",
+		"Дописать псевдонимы (%d) в файл %v? [y/N]: ": "Append aliases (%d) to %v? [y/N]: ",
+		"Псевдонимы не записаны":                      "Aliases were not written",
+		"Псевдонимы (%d) дописаны в файл %v\n":        "Aliases (%d) appended to %v\n",
+		"Сравнение групп":                             "Group comparison",
+		"Студентов":                                   "Students",
+		"Изменено закрытое собрание":                  "Finalized meeting changed",
+		"Ошибка команды finalize":                     "finalize command error",
+		"Закрыто собраний: %d (%v)":                   "Meetings finalized: %d (%v)",
+		"собрание закрыто командой finalize, для изменения укажите флаг --force": "meeting is finalized, use --force to change it",
+		"Проверка экзамена":                  "Exam check",
+		"Вариант":                            "Variant",
+		"Назначенное время":                  "Assigned time",
+		"Результат":                          "Result",
+		"в назначенное время":                "on time",
+		"не подключался":                     "did not join",
+		"нет в списке вариантов":             "not in the assignments list",
+		"подключился позже начала на %d мин": "joined %d min after the start",
+		"вышел раньше окончания на %d мин":   "left %d min before the end",
+		"отключался во время экзамена (на собрании %d мин из %d)":                    "disconnected during the exam (%d of %d min in the meeting)",
+		"несоответствий %d из %d":                                                    "%d of %d mismatched",
+		"Ошибка чтения данных обработки отчётов":                                     "Error reading report processing data",
+		"Связаться со студентом %v (%v), пропусков подряд: %d":                       "Follow up with student %v (%v), absences in a row: %d",
+		"Студент %v группы %v пропустил подряд %d собраний, последнее - %v, %v, %v.": "Student %v of group %v missed %d meetings in a row, the last one - %v, %v, %v.",
+		"Не указан адрес куратора группы, задача не создана":                         "Group curator email is not set, task not created",
+		"Итоги посещаемости":                                                         "Attendance totals",
+		"Присутствовали не полностью":                                                "Partially present",
+		"Ошибка команды workload":                                                    "workload command error",
+		"Нагрузка преподавателей_":                                                   "Teacher workload_",
+		"Месяц":                   "Month",
+		"Проведено собраний":      "Meetings held",
+		"Средняя посещаемость, %": "Average attendance, %",
+		"Студенто-часов":          "Student-hours",
+		"Преподаватель не указан": "Teacher not specified",
+		"Ошибка поиска события календаря организатора": "Organizer calendar event lookup error",
+		"Собрание сопоставлено с событием календаря":   "Meeting matched to calendar event",
+		"Время по календарю":                           "Calendar time",
+		"по календарю":                                 "per calendar",
+		"Отчёты пересозданного собрания объединены":    "Reports of the re-created meeting merged",
+		"Ошибка отправки общей сводки оповещений":      "Error sending the combined notification digest",
+		"Обработано собраний: %d":                      "Meetings processed: %d",
+		"Время собрания":                               "Meeting time",
+		"время собрания":                               "meeting time",
+		"Продолжительность собрания":                   "Meeting duration",
+		"%d мин":      "%d min",
+		"%d ч %d мин": "%d h %d min",
+		"Ошибка чтения пароля книги .xlsx":          "Error reading .xlsx workbook password",
+		"Ошибка чтения пометок сокращённого отчёта": "Error reading short report statuses",
+		"отсутствующие":                             "absent",
+		"уважительные":                              "excused",
+		"опоздавшие":                                "late",
+		"неполные":                                  "partial",
+		"ушедшие":                                   "left early",
+		"присутствующие":                            "present",
+		"Ошибка команды api":                        "api command error",
+		"Ключ доступа не задан, отчёты принимаются от любого клиента":     "Access token is not set, reports are accepted from any client",
+		"Отчёты MS Teams принимаются":                                     "MS Teams reports are accepted",
+		"Ошибка отправки отчёта":                                          "Error sending report",
+		"Ссылка на собрание":                                              "Meeting link",
+		"Запись собрания":                                                 "Meeting recording",
+		"Запись собрания: %v":                                             "Meeting recording: %v",
+		"отчёт передаётся методом POST":                                   "the report must be sent with the POST method",
+		"неверный ключ доступа":                                           "invalid access key",
+		"неизвестный формат ответа: %v (допустимы json, csv)":             "unknown response format: %v (allowed: json, csv)",
+		"некорректная форма запроса":                                      "malformed request form",
+		"в форме запроса нет файлов report":                               "the request form has no report files",
+		"Собрание той же пары уже записано в историю, отметки объединены": "A meeting in the same slot is already in history, marks merged",
+		"Ключ доступа не задан, веб-панель доступна любому клиенту":       "Access key not set, the dashboard is open to any client",
+		"выгрузка .csv недоступна, так как задан пароль книг .xlsx":       ".csv export is unavailable because an .xlsx password is set",
+		"Формат": "Format",
+		"Лента отметок для Power BI доступна":     "Power BI marks feed is available",
+		"лента отметок запрашивается методом GET": "the marks feed is requested with the GET method",
+		"некорректное значение параметра %v: %v":  "invalid value of parameter %v: %v",
+		"Цель, %":          "Target, %",
+		"Прошлый месяц, %": "Previous month, %",
+		"По неделям, %":    "By week, %",
+		"Отчёт пропущен":   "Report skipped",
+		"собрание является техническим созвоном, отчёт не формируется": "" +
+			"the meeting is a technical call, no report is generated",
+		"отчёт этого собрания уже сформирован": "a report for this meeting has already been generated",
+		"Ошибка чтения флагов: --output - и --report-to-stdout-summary выводят в стандартный вывод одновременно": "" +
+			"Error reading flags: --output - and --report-to-stdout-summary both write to standard output",
+		"Ошибка поиска отчёта":       "Error finding report",
+		"Ошибка обработки отчёта":    "Error processing report",
+		"Ошибка объединения отчётов": "Error merging reports",
+		"Ошибка команды merge: необходимо указать не менее двух отчётов собрания": "" +
+			"merge command error: at least two meeting reports are required",
+		"Не удалось обновить базу групп, используется сохранённая копия": "Could not update the groups base, using the saved copy" +
+			"Could not update groups base, using saved copy %v: %v",
+		"%v: отчёт о посещаемости текущего собрания ещё не сформирован\n": "" +
+			"%v: attendance report of the current meeting is not ready yet\n",
+		"\n%v, обновлено в %v\n":             "\n%v, updated at %v\n",
+		"Группа\tФИО\tСейчас":                "Group\tFull name\tNow",
+		"на собрании":                        "in meeting",
+		"ОТСУТСТВУЕТ":                        "ABSENT",
+		"На собрании: %d, отсутствуют: %d\n": "In meeting: %d, absent: %d\n",
+		"В истории нет записей о посещаемости по заданному условию": "" +
+			"No attendance records match the condition",
+
+		//Пробный запуск и подробный вывод
+		"Пробный запуск, файлы не записываются": "Dry run, no files are written",
+		"Сопоставлено с группами: %d, гостей: %d, будут отмечены отсутствующими: %d": "" +
+			"Matched to groups: %d, guests: %d, to be marked absent: %d",
+		"Не удалось выделить ФИО (%d): %v":          "Could not parse names (%d): %v",
+		"Разбор строк отчёта":                       "Report rows",
+		"Отметки участников":                        "Member marks",
+		"из имени":                                  "from name",
+		"из базы групп":                             "from groups base",
+		"нет в базе групп":                          "not in groups base",
+		"переподключение":                           "reconnect",
+		"время устройства вне времени собрания":     "device time outside the meeting",
+		"не удалось выделить ФИО, строка пропущена": "could not parse the name, row skipped",
+		"инициатор собрания, строка пропущена":      "meeting organizer, row skipped",
+		"Разбор строки участника":                   "Participant row parsed",
+	},
+}
+
+/*====================================================================================================================*/
+
+// ParseLanguage Функция, проверяющая язык итоговых отчётов и сообщений программы. По-умолчанию - русский
+func ParseLanguage(source string) (string, error) {
+	language := strings.ToLower(strings.TrimSpace(source))
+	if language == "" || language == Russian {
+		return Russian, nil
+	}
+	if _, ok := catalogs[language]; !ok {
+		return "", fmt.Errorf("неизвестный язык: %v (допустимы ru, en)", source)
+	}
+
+	return language, nil
+}
+
+// T Функция, возвращающая перевод русского текста на выбранный язык. Текст, которого нет в каталоге, возвращается
+// без изменений
+func T(message string) string {
+	if translated, ok := catalogs[Language][message]; ok {
+		return translated
+	}
+
+	return message
+}
+
+// Sprintf Функция, переводящая текст с пометками формата и подставляющая в него значения
+func Sprintf(format string, arguments ...interface{}) string {
+	return fmt.Sprintf(T(format), arguments...)
+}
@@ -0,0 +1,226 @@
+// Package logging Пакет журнала сообщений программы (slog): уровни сообщений, тихий режим для запуска по расписанию и
+// необязательный файл журнала с ротацией по размеру, чтобы ночная обработка оставляла след, а не завершалась молча
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек журнала сообщений
+type Configuration struct {
+	//Путь до файла журнала (пустой - сообщения выводятся только в стандартный поток ошибок)
+	Path string
+	//Наименьший уровень сообщений, записываемых в журнал
+	Level slog.Level
+	//Размер файла журнала в байтах, после которого файл переименовывается и начинается новый
+	MaxSize int64
+	//Количество сохраняемых прежних файлов журнала (журнал.log.1, журнал.log.2, ...)
+	MaxBackups int
+}
+
+// file Файл журнала, открытый функцией Setup()
+var file *rotatingFile
+
+/*====================================================================================================================*/
+
+// ParseLevel Функция, проверяющая уровень сообщений из файла конфигураций: debug, info, warn или error. По-умолчанию
+// записываются сообщения уровня info и выше
+func ParseLevel(source string) (slog.Level, error) {
+	switch level := strings.ToLower(strings.TrimSpace(source)); level {
+	case "":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("неизвестный уровень сообщений журнала: %v (допустимы debug, info, warn, error)",
+			source)
+	}
+}
+
+// Setup Функция, устанавливающая журнал сообщений программы по-умолчанию: сообщения выводятся в стандартный поток
+// ошибок (в тихом режиме - только предупреждения и ошибки) и, если указан путь, записываются в файл журнала в формате
+// JSON. Сообщения пакета log также попадают в журнал. Файл журнала закрывается функцией Close()
+func Setup(settings Configuration, quiet bool) error {
+	consoleLevel := settings.Level
+	if quiet && consoleLevel < slog.LevelWarn {
+		consoleLevel = slog.LevelWarn
+	}
+	handlers := []slog.Handler{slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: consoleLevel})}
+
+	if settings.Path != "" {
+		opened, err := openRotating(settings.Path, settings.MaxSize, settings.MaxBackups)
+		if err != nil {
+			return err
+		}
+		file = opened
+		handlers = append(handlers, slog.NewJSONHandler(file, &slog.HandlerOptions{Level: settings.Level}))
+	}
+
+	slog.SetDefault(slog.New(fanout(handlers)))
+
+	return nil
+}
+
+// Close Функция, закрывающая файл журнала, если он открыт
+func Close() error {
+	if file == nil {
+		return nil
+	}
+
+	return file.Close()
+}
+
+// Fatal Функция, записывающая в журнал сообщение об ошибке, закрывающая файл журнала и завершающая программу с кодом 1
+func Fatal(message string, args ...interface{}) {
+	slog.Error(message, args...)
+	Close()
+	os.Exit(1)
+}
+
+/*====================================================================================================================*/
+
+// fanout Обработчик сообщений, передающий каждое сообщение нескольким обработчикам (стандартному потоку ошибок и файлу)
+type fanout []slog.Handler
+
+// Enabled Функция, проверяющая, записывает ли сообщение уровня level хотя бы один обработчик
+func (handlers fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handle Функция, передающая сообщение обработчикам, которые записывают сообщения его уровня
+func (handlers fanout) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range handlers {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs Функция, возвращающая обработчик с атрибутами attrs у всех обработчиков
+func (handlers fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	result := make(fanout, 0, len(handlers))
+	for _, handler := range handlers {
+		result = append(result, handler.WithAttrs(attrs))
+	}
+
+	return result
+}
+
+// WithGroup Функция, возвращающая обработчик с группой атрибутов name у всех обработчиков
+func (handlers fanout) WithGroup(name string) slog.Handler {
+	result := make(fanout, 0, len(handlers))
+	for _, handler := range handlers {
+		result = append(result, handler.WithGroup(name))
+	}
+
+	return result
+}
+
+/*====================================================================================================================*/
+
+// rotatingFile Структура файла журнала, который при превышении размера переименовывается в журнал.log.1 (прежние
+// файлы сдвигаются, самый старый удаляется), а запись продолжается в новый файл
+type rotatingFile struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// openRotating Функция, открывающая файл журнала для дописывания
+func openRotating(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	current := &rotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := current.open(); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// open Функция, открывающая файл журнала и запоминающая его размер
+func (current *rotatingFile) open() error {
+	opened, err := os.OpenFile(current.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла журнала сообщений: %w", err)
+	}
+	info, err := opened.Stat()
+	if err != nil {
+		opened.Close()
+		return fmt.Errorf("ошибка открытия файла журнала сообщений: %w", err)
+	}
+	current.file, current.size = opened, info.Size()
+
+	return nil
+}
+
+// Write Функция, дописывающая сообщение в файл журнала и начинающая новый файл при превышении размера
+func (current *rotatingFile) Write(message []byte) (int, error) {
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+
+	if current.maxSize > 0 && current.size > 0 && current.size+int64(len(message)) > current.maxSize {
+		if err := current.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	written, err := current.file.Write(message)
+	current.size += int64(written)
+
+	return written, err
+}
+
+// rotate Функция, сдвигающая прежние файлы журнала и начинающая новый файл
+func (current *rotatingFile) rotate() error {
+	if err := current.file.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия файла журнала сообщений: %w", err)
+	}
+
+	//Самый старый файл удаляется, остальные сдвигаются на один номер
+	if current.maxBackups > 0 {
+		os.Remove(fmt.Sprintf("%v.%d", current.path, current.maxBackups))
+		for i := current.maxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%v.%d", current.path, i), fmt.Sprintf("%v.%d", current.path, i+1))
+		}
+		if err := os.Rename(current.path, current.path+".1"); err != nil {
+			return fmt.Errorf("ошибка ротации файла журнала сообщений: %w", err)
+		}
+	} else if err := os.Remove(current.path); err != nil {
+		return fmt.Errorf("ошибка ротации файла журнала сообщений: %w", err)
+	}
+
+	return current.open()
+}
+
+// Close Функция, закрывающая файл журнала
+func (current *rotatingFile) Close() error {
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+
+	return current.file.Close()
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"context"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Batch Структура оповещений, собираемых за один запуск программы: при обработке накопившихся отчётов в каждый канал
+// отправляется одна общая сводка всех собраний вместо сообщения о каждом собрании
+type Batch struct {
+	mutex sync.Mutex
+	//Сводки собраний для чата Telegram
+	telegram []string
+	//Итоговые отчёты, отправляемые в чат Telegram файлами
+	attachments []string
+	//Сводки отсутствующих студентов по группам (ключ - группа)
+	curators map[string][]string
+}
+
+// Время следующей допустимой отправки по каждому каналу для ограничения частоты сообщений
+var (
+	throttleMutex sync.Mutex
+	nextSend      = make(map[string]time.Time)
+)
+
+/*====================================================================================================================*/
+
+// AddTelegram Функция, добавляющая в общую сводку чата Telegram сводку собрания и итоговый отчёт
+func (batch *Batch) AddTelegram(header report.Header, members []report.Member, reportPath string) {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	batch.telegram = append(batch.telegram, TelegramSummary(header, members))
+	batch.attachments = append(batch.attachments, reportPath)
+}
+
+// AddCurators Функция, добавляющая в общие сводки кураторов тексты по группам (ключ - группа)
+func (batch *Batch) AddCurators(texts map[string]string) {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	if batch.curators == nil {
+		batch.curators = make(map[string][]string)
+	}
+	for group, text := range texts {
+		batch.curators[group] = append(batch.curators[group], text)
+	}
+}
+
+// Empty Функция, проверяющая, что в общие сводки не добавлено ни одного оповещения
+func (batch *Batch) Empty() bool {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	return len(batch.telegram) == 0 && len(batch.curators) == 0
+}
+
+// Flush Функция, отправляющая собранные оповещения: одно сообщение со сводками всех собраний в чат Telegram (длинная
+// сводка делится на несколько сообщений по границам собраний) и одно сообщение каждому куратору со сводками всех
+// собраний его группы. Отправленные оповещения удаляются из общих сводок
+func (batch *Batch) Flush(ctx context.Context, settings Configuration, curators map[string]roster.Curator) error {
+	batch.mutex.Lock()
+	defer batch.mutex.Unlock()
+
+	if len(batch.telegram) > 0 {
+		title := i18n.Sprintf("Обработано собраний: %d", len(batch.telegram))
+		for _, text := range joinMessages(title, batch.telegram, telegramLimit) {
+			if err := sendTelegramMessage(ctx, settings, text); err != nil {
+				return err
+			}
+		}
+		if settings.TelegramAttachReport {
+			for _, path := range batch.attachments {
+				if err := sendTelegramDocument(ctx, settings, path); err != nil {
+					return err
+				}
+			}
+		}
+		batch.telegram, batch.attachments = nil, nil
+	}
+
+	if len(batch.curators) > 0 {
+		texts := make(map[string]string, len(batch.curators))
+		for group, summaries := range batch.curators {
+			texts[group] = strings.Join(summaries, "\n\n")
+		}
+		if err := SendToCurators(ctx, settings, curators, texts); err != nil {
+			return err
+		}
+		batch.curators = nil
+	}
+
+	return nil
+}
+
+/*====================================================================================================================*/
+
+// throttle Вспомогательная функция, ожидающая, пока отправка ещё одного сообщения в канал channel не превысит rate
+// сообщений в минуту. При rate = 0 частота не ограничивается
+func throttle(ctx context.Context, channel string, rate int) error {
+	if rate <= 0 {
+		return nil
+	}
+
+	throttleMutex.Lock()
+	wait := max(time.Until(nextSend[channel]), 0)
+	nextSend[channel] = time.Now().Add(wait + time.Minute/time.Duration(rate))
+	throttleMutex.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// joinMessages Вспомогательная функция, объединяющая заголовок и сводки в сообщения длиной не больше limit символов.
+// Сводки не разрываются между сообщениями
+func joinMessages(title string, texts []string, limit int) []string {
+	var messages []string
+	current := title
+
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if len([]rune(current))+2+len([]rune(text)) > limit {
+			messages = append(messages, current)
+			current = text
+			continue
+		}
+		current += "\n\n" + text
+	}
+
+	return append(messages, current)
+}
@@ -0,0 +1,178 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"mod.go/report"
+	"mod.go/roster"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// testChannels Структура оповещений, полученных тестовым сервером: сообщения и файлы Telegram и оповещения вебхука
+type testChannels struct {
+	mutex     sync.Mutex
+	messages  []string
+	documents []string
+	webhook   []Message
+}
+
+// testServer Вспомогательная функция, запускающая тестовый сервер, который принимает запросы к API бота Telegram и
+// вебхуку, и направляющая на него оповещения Telegram
+func testServer(t *testing.T) (*testChannels, string) {
+	t.Helper()
+
+	channels := &testChannels{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		channels.mutex.Lock()
+		defer channels.mutex.Unlock()
+
+		switch r.URL.Path {
+		case "/bottoken/sendMessage":
+			channels.messages = append(channels.messages, r.FormValue("text"))
+		case "/bottoken/sendDocument":
+			_, header, err := r.FormFile("document")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			channels.documents = append(channels.documents, header.Filename)
+		case "/webhook":
+			var message Message
+			if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			channels.webhook = append(channels.webhook, message)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	endpoint := TelegramEndpoint
+	TelegramEndpoint = server.URL + "/"
+	t.Cleanup(func() { TelegramEndpoint = endpoint })
+
+	return channels, server.URL + "/webhook"
+}
+
+/*====================================================================================================================*/
+
+// TestBatchFlush Проверка общей сводки за запуск: в чат Telegram отправляется одно сообщение со сводками всех
+// собраний и итоговые отчёты, каждому куратору - одно оповещение со сводками всех собраний группы, а отправленные
+// оповещения удаляются из сводок
+func TestBatchFlush(t *testing.T) {
+	channels, webhook := testServer(t)
+	settings := Configuration{TelegramBotToken: "token", TelegramChatID: "-100", TelegramAttachReport: true,
+		WebhookURL: webhook}
+	curators := map[string]roster.Curator{"МП-51": {Group: "МП-51", FullName: "Кураторова Елена Ивановна"}}
+
+	dir := t.TempDir()
+	var batch Batch
+	if !batch.Empty() {
+		t.Fatalf("новая сводка не пуста")
+	}
+	for _, title := range []string{"Математический анализ", "Физика"} {
+		path := filepath.Join(dir, title+".csv")
+		if err := os.WriteFile(path, []byte("отчёт"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		header := report.Header{Title: title, Date: "15.10.2026", LessonNumber: "Пара 1"}
+		members := []report.Member{{Group: "МП-51", FullName: "Иванов Иван Иванович", Presence: report.PresenceAbsent}}
+		batch.AddTelegram(header, members, path)
+		batch.AddCurators(map[string]string{"МП-51": title + ": отсутствовал Иванов Иван Иванович",
+			"МП-52": title + ": все присутствовали"})
+	}
+
+	if err := batch.Flush(context.Background(), settings, curators); err != nil {
+		t.Fatalf("ошибка отправки сводки: %v", err)
+	}
+	if len(channels.messages) != 1 || !strings.HasPrefix(channels.messages[0], "Обработано собраний: 2") ||
+		!strings.Contains(channels.messages[0], "Математический анализ") ||
+		!strings.Contains(channels.messages[0], "Физика") {
+		t.Errorf("сообщения Telegram %q, ожидалось одно сообщение со сводками двух собраний", channels.messages)
+	}
+	if want := []string{"Математический анализ.csv", "Физика.csv"}; !reflect.DeepEqual(channels.documents, want) {
+		t.Errorf("файлы Telegram %v, ожидалось %v", channels.documents, want)
+	}
+	want := []Message{
+		{Group: "МП-51", Curator: "Кураторова Елена Ивановна", Text: "Математический анализ: отсутствовал Иванов " +
+			"Иван Иванович\n\nФизика: отсутствовал Иванов Иван Иванович"},
+		{Group: "МП-52", Text: "Математический анализ: все присутствовали\n\nФизика: все присутствовали"},
+	}
+	if !reflect.DeepEqual(channels.webhook, want) {
+		t.Errorf("оповещения кураторов %+v, ожидалось %+v", channels.webhook, want)
+	}
+
+	//Повторная отправка пустой сводки ничего не отправляет
+	if !batch.Empty() {
+		t.Errorf("сводка не очищена после отправки")
+	}
+	if err := batch.Flush(context.Background(), settings, curators); err != nil {
+		t.Fatal(err)
+	}
+	if len(channels.messages) != 1 || len(channels.webhook) != 2 {
+		t.Errorf("пустая сводка отправлена повторно")
+	}
+}
+
+// TestJoinMessages Проверка деления общей сводки на сообщения: сводки не разрываются, а сообщения не длиннее
+// ограничения
+func TestJoinMessages(t *testing.T) {
+	texts := []string{strings.Repeat("а", 40), strings.Repeat("б", 40), "  " + strings.Repeat("в", 40) + "\n"}
+	last := strings.Repeat("в", 40)
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []string
+	}{
+		{"одно сообщение", 200, []string{"Сводка\n\n" + texts[0] + "\n\n" + texts[1] + "\n\n" + last}},
+		{"по сводке в сообщении", 60, []string{"Сводка\n\n" + texts[0], texts[1], last}},
+		{"по две сводки", 85, []string{"Сводка\n\n" + texts[0], texts[1] + "\n\n" + last}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := joinMessages("Сводка", texts, test.limit)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("сообщения %q, ожидалось %q", got, test.want)
+			}
+		})
+	}
+}
+
+// TestThrottle Проверка ограничения частоты сообщений: первое сообщение отправляется сразу, следующее - через
+// интервал, а ожидание прерывается отменой контекста
+func TestThrottle(t *testing.T) {
+	ctx := context.Background()
+	if err := throttle(ctx, "test", 0); err != nil {
+		t.Fatalf("ограничение частоты без ограничения: %v", err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if err := throttle(ctx, "test", 600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("второе сообщение отправлено через %v, ожидалось не раньше 100 мс", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := throttle(cancelled, "test", 600); err != context.Canceled {
+		t.Errorf("ошибка %v, ожидалась отмена контекста", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package notify Пакет оповещений кураторов об отсутствующих студентах: сводка отсутствующих по группам и её отправка
+// через мессенджеры (вебхук или Twilio для WhatsApp, Viber и SMS), сводка собрания в чат Telegram
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек оповещений
+type Configuration struct {
+	//Адрес вебхука, на который отправляется сводка (например, шлюз в чат группы WhatsApp или Viber)
+	WebhookURL string
+	//Идентификатор аккаунта Twilio
+	TwilioAccountSID string
+	//Токен аккаунта Twilio
+	TwilioAuthToken string
+	//Номер отправителя Twilio (для WhatsApp - "whatsapp:+14155238886")
+	TwilioFrom string
+	//Токен бота Telegram, от имени которого отправляется сводка собрания
+	TelegramBotToken string
+	//Идентификатор чата Telegram (например, "-1001234567890" или "@kafedra_attendance")
+	TelegramChatID string
+	//Отправлять ли в чат Telegram итоговый отчёт в виде файла
+	TelegramAttachReport bool
+	//Количество пропущенных подряд собраний, после которого куратору группы создаётся задача в Microsoft To Do (0 -
+	// задачи не создаются)
+	FollowUpAbsences int
+	//Отправлять ли оповещения одной общей сводкой за запуск программы по каждому каналу, а не после каждого собрания
+	Batch bool
+	//Наибольшее количество сообщений в минуту по каждому каналу: Telegram, вебхук и Twilio (0 - без ограничения)
+	TelegramRate int
+	WebhookRate  int
+	TwilioRate   int
+}
+
+// Message Структура оповещения куратора группы, отправляемая на вебхук в формате JSON
+type Message struct {
+	Group   string `json:"group"`
+	Curator string `json:"curator"`
+	Phone   string `json:"phone"`
+	Text    string `json:"text"`
+}
+
+// TwilioEndpoint Адрес API Twilio
+var TwilioEndpoint = "https://api.twilio.com/2010-04-01/"
+
+/*====================================================================================================================*/
+
+// Enabled Функция, проверяющая, настроен ли хотя бы один способ оповещения
+func (settings Configuration) Enabled() bool {
+	return settings.WebhookURL != "" || settings.TwilioAccountSID != ""
+}
+
+// FollowUpTask Функция, возвращающая название и текст задачи куратору группы о студенте, пропустившем подряд absences
+// собраний
+func FollowUpTask(fullName, group string, absences int, lastMeeting report.Header) (string, string) {
+	title := i18n.Sprintf("Связаться со студентом %v (%v), пропусков подряд: %d", fullName, i18n.T(group), absences)
+	text := i18n.Sprintf("Студент %v группы %v пропустил подряд %d собраний, последнее - %v, %v, %v.", fullName,
+		i18n.T(group), absences, lastMeeting.Title, lastMeeting.Date, lastMeeting.LessonLabel())
+
+	return title, text
+}
+
+// AbsenteeSummary Функция, формирующая сводку отсутствующих студентов по группам. Группы, в которых отсутствующих
+// нет, в сводку не попадают
+func AbsenteeSummary(header report.Header, members []report.Member) map[string]string {
+	//Отсутствующие студенты по группам
+	absentees := make(map[string][]string)
+	for _, member := range members {
+		if member.Presence.IsAbsent() && member.FullName != "" {
+			absentees[member.Group] = append(absentees[member.Group], member.FullName)
+		}
+	}
+
+	summaries := make(map[string]string)
+	for group, fullNames := range absentees {
+		sort.Strings(fullNames)
+		summaries[group] = i18n.Sprintf("%v, %v, %v. Группа %v, отсутствовали (%d):\n%v", header.Title, header.Date,
+			header.LessonLabel(), group, len(fullNames), strings.Join(fullNames, "\n"))
+		//Ссылка на запись собрания помогает отсутствовавшим студентам изучить пропущенный материал
+		if header.RecordingURL != "" {
+			summaries[group] += "\n" + i18n.Sprintf("Запись собрания: %v", header.RecordingURL)
+		}
+	}
+
+	return summaries
+}
+
+// TelegramEnabled Функция, проверяющая, настроена ли отправка сводки собрания в чат Telegram
+func (settings Configuration) TelegramEnabled() bool {
+	return settings.TelegramBotToken != "" && settings.TelegramChatID != ""
+}
+
+// SendAbsentees Функция, отправляющая сводку отсутствующих студентов кураторам групп всеми настроенными способами.
+// Через Twilio сводка отправляется только кураторам, у которых указан номер телефона
+func SendAbsentees(ctx context.Context, settings Configuration, curators map[string]roster.Curator,
+	header report.Header, members []report.Member) error {
+	return SendToCurators(ctx, settings, curators, AbsenteeSummary(header, members))
+}
+
+// SendToCurators Функция, отправляющая тексты по группам (ключ - группа) кураторам групп всеми настроенными способами.
+// Через Twilio текст отправляется только кураторам, у которых указан номер телефона. Частота сообщений ограничивается
+// отдельно для вебхука и Twilio
+func SendToCurators(ctx context.Context, settings Configuration, curators map[string]roster.Curator,
+	texts map[string]string) error {
+	//Группы отправляются в алфавитном порядке
+	groups := make([]string, 0, len(texts))
+	for group := range texts {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		curator := curators[group]
+		message := Message{Group: group, Curator: curator.FullName, Phone: curator.Phone, Text: texts[group]}
+
+		if settings.WebhookURL != "" {
+			if err := throttle(ctx, "webhook", settings.WebhookRate); err != nil {
+				return err
+			}
+			if err := SendWebhook(ctx, settings.WebhookURL, message); err != nil {
+				return err
+			}
+		}
+		if settings.TwilioAccountSID != "" && curator.Phone != "" {
+			if err := throttle(ctx, "twilio", settings.TwilioRate); err != nil {
+				return err
+			}
+			if err := SendTwilio(ctx, settings, message); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+/*====================================================================================================================*/
+
+// SendWebhook Функция, отправляющая оповещение на вебхук в формате JSON
+func SendWebhook(ctx context.Context, webhookURL string, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования оповещения: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к вебхуку: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	return send(request, "вебхук")
+}
+
+// SendTwilio Функция, отправляющая оповещение через API Twilio. Если номер отправителя относится к WhatsApp, номер
+// получателя также переводится в WhatsApp
+func SendTwilio(ctx context.Context, settings Configuration, message Message) error {
+	to := message.Phone
+	if strings.HasPrefix(settings.TwilioFrom, "whatsapp:") && !strings.HasPrefix(to, "whatsapp:") {
+		to = "whatsapp:" + to
+	}
+
+	form := url.Values{"From": {settings.TwilioFrom}, "To": {to}, "Body": {message.Text}}
+	requestURL := TwilioEndpoint + "Accounts/" + url.PathEscape(settings.TwilioAccountSID) + "/Messages.json"
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Twilio: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(settings.TwilioAccountSID, settings.TwilioAuthToken)
+
+	return send(request, "Twilio")
+}
+
+// send Вспомогательная функция, выполняющая запрос отправки оповещения и проверяющая ответ
+func send(request *http.Request, channel string) error {
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("ошибка отправки оповещения через %v: %w", channel, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("%v вернул ошибку %v: %s", channel, response.Status, body)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"mod.go/i18n"
+	"mod.go/report"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// TelegramEndpoint Адрес API ботов Telegram
+var TelegramEndpoint = "https://api.telegram.org/"
+
+// telegramLimit Наибольшая длина сообщения Telegram в символах
+const telegramLimit = 4096
+
+/*====================================================================================================================*/
+
+// TelegramSummary Функция, формирующая сводку собрания для чата Telegram: количество присутствовавших, опоздавших и
+// отсутствовавших и список отсутствовавших студентов по группам. Слишком длинная сводка обрезается
+func TelegramSummary(header report.Header, members []report.Member) string {
+	summary := report.Summarize(members)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%v, %v, %v\n", header.Title, header.Date, header.LessonLabel())
+	fmt.Fprintf(&text, "%v\n", i18n.Sprintf("Присутствовали: %d, опоздали: %d, отсутствовали: %d", summary.Present,
+		summary.Late, summary.Absent))
+
+	//Участники уже отсортированы по группе и ФИО
+	if summary.Absent > 0 {
+		fmt.Fprintf(&text, "\n%v:\n", i18n.T("Отсутствовали"))
+		for _, member := range members {
+			if member.FullName != "" && member.Presence.IsAbsent() {
+				fmt.Fprintf(&text, "%v %v\n", member.Group, member.FullName)
+			}
+		}
+	}
+
+	runes := []rune(text.String())
+	if len(runes) > telegramLimit {
+		return string(runes[:telegramLimit-1]) + "…"
+	}
+
+	return text.String()
+}
+
+// SendTelegram Функция, отправляющая сводку собрания в чат Telegram и, если это включено в конфигурациях, итоговый
+// отчёт в виде файла
+func SendTelegram(ctx context.Context, settings Configuration, header report.Header, members []report.Member,
+	reportPath string) error {
+	if err := sendTelegramMessage(ctx, settings, TelegramSummary(header, members)); err != nil {
+		return err
+	}
+
+	if !settings.TelegramAttachReport {
+		return nil
+	}
+
+	return sendTelegramDocument(ctx, settings, reportPath)
+}
+
+// sendTelegramMessage Вспомогательная функция, отправляющая текстовое сообщение в чат Telegram с ограничением частоты
+// сообщений
+func sendTelegramMessage(ctx context.Context, settings Configuration, text string) error {
+	if err := throttle(ctx, "telegram", settings.TelegramRate); err != nil {
+		return err
+	}
+
+	form := url.Values{"chat_id": {settings.TelegramChatID}, "text": {text}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramMethod(settings, "sendMessage"),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Telegram: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return send(request, "Telegram")
+}
+
+// sendTelegramDocument Вспомогательная функция, отправляющая файл отчёта в чат Telegram
+func sendTelegramDocument(ctx context.Context, settings Configuration, path string) error {
+	if err := throttle(ctx, "telegram", settings.TelegramRate); err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия отчёта для отправки: %w", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("chat_id", settings.TelegramChatID); err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Telegram: %w", err)
+	}
+	part, err := writer.CreateFormFile("document", filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Telegram: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("ошибка чтения отчёта для отправки: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Telegram: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramMethod(settings, "sendDocument"), &body)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Telegram: %w", err)
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return send(request, "Telegram")
+}
+
+// telegramMethod Вспомогательная функция, возвращающая адрес метода API бота Telegram
+func telegramMethod(settings Configuration, method string) string {
+	return TelegramEndpoint + "bot" + settings.TelegramBotToken + "/" + method
+}
@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/teamsreport"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// WriteDryRun Функция, выводящая результат пробного запуска: оглавление собрания, количество участников,
+// сопоставленных с группами, гостей и студентов, которые будут отмечены отсутствующими, и имена, из которых не удалось
+// выделить ФИО. При подробном выводе добавляются разбор каждой строки отчёта и отметки каждого участника
+func WriteDryRun(out io.Writer, paths []string, header report.Header, members, guests []report.Member,
+	diagnostics teamsreport.Diagnostics, verbose bool) error {
+	//Участники, сопоставленные с группами, гости и отсутствующие студенты
+	var matched, guestCount, absent int
+	for _, member := range append(append([]report.Member{}, members...), guests...) {
+		switch {
+		case member.FullName == "" || member.Presence.IsExcused():
+		case member.Presence.IsAbsent():
+			absent++
+		case member.Group == roster.Guest:
+			guestCount++
+		default:
+			matched++
+		}
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%v\n", i18n.T("Пробный запуск, файлы не записываются"))
+	fmt.Fprintf(&text, "%v: %v\n", i18n.T("Отчёт"), strings.Join(paths, ", "))
+	fmt.Fprintf(&text, "%v: %v\n", i18n.T("Название собрания"), header.Title)
+	if header.Course != "" {
+		fmt.Fprintf(&text, "%v: %v\n", i18n.T("Дисциплина"), header.Course)
+	}
+	fmt.Fprintf(&text, "%v: %v\n", i18n.T("Дата проведения собрания"), header.Date)
+	fmt.Fprintf(&text, "%v: %v\n", i18n.T("Номер пары"), header.LessonLabel())
+	if held := header.Held(); held != "" {
+		fmt.Fprintf(&text, "%v: %v\n", i18n.T("Время собрания"), held)
+	}
+	if header.Duration > 0 {
+		fmt.Fprintf(&text, "%v: %v\n", i18n.T("Продолжительность собрания"), header.DurationLabel())
+	}
+	if planned := header.Planned(); planned != "" {
+		fmt.Fprintf(&text, "%v: %v\n", i18n.T("Время по календарю"), planned)
+	}
+	if header.Quorum.Checked {
+		fmt.Fprintf(&text, "%v: %v\n", i18n.T("Кворум"), header.Quorum)
+	}
+	fmt.Fprintf(&text, "%v\n", i18n.Sprintf("Сопоставлено с группами: %d, гостей: %d, будут отмечены отсутствующими: %d",
+		matched, guestCount, absent))
+	if len(diagnostics.Unparsed) > 0 {
+		fmt.Fprintf(&text, "%v\n", i18n.Sprintf("Не удалось выделить ФИО (%d): %v", len(diagnostics.Unparsed),
+			strings.Join(diagnostics.Unparsed, "; ")))
+	}
+
+	if len(header.Warnings) > 0 {
+		fmt.Fprintf(&text, "%v:\n", i18n.T("Предупреждения разбора"))
+		for _, warning := range header.Warnings {
+			fmt.Fprintf(&text, "  %v\n", warning)
+		}
+	}
+
+	//Разбор строк отчёта и итоговые отметки участников помогают найти причину неверного определения группы или отметок
+	if verbose {
+		fmt.Fprintf(&text, "\n%v:\n", i18n.T("Разбор строк отчёта"))
+		for _, row := range diagnostics.Rows {
+			fmt.Fprintf(&text, "  %v\n", row)
+		}
+
+		fmt.Fprintf(&text, "\n%v:\n", i18n.T("Отметки участников"))
+		for _, member := range append(append([]report.Member{}, members...), guests...) {
+			if member.FullName != "" {
+				fmt.Fprintf(&text, "  %v; %v; %v; %v; %v\n", i18n.T(member.Group), member.FullName,
+					member.PresenceLabel(), member.DelayLabel(), member.EarlyExit.Label())
+			}
+		}
+	}
+
+	if _, err := io.WriteString(out, text.String()); err != nil {
+		return fmt.Errorf("ошибка записи результата пробного запуска: %w", err)
+	}
+
+	return nil
+}
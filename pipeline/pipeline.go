@@ -0,0 +1,658 @@
+// Package pipeline Пакет обработки отчёта MS Teams от чтения .csv файла до формирования итогового отчёта, истории и
+// оповещений. Программы, встраивающие обработку, могут дополнять и фильтровать данные собственными функциями (хуками),
+// зарегистрированными до вызова ProcessReport():
+//
+//	pipeline.AfterParse(func(ctx context.Context, header *report.Header, members []report.Member) ([]report.Member, error) {
+//		//Например, дополнение участников данными из внешней системы учёта студентов
+//		return members, nil
+//	})
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mod.go/audit"
+	"mod.go/config"
+	"mod.go/email"
+	"mod.go/graph"
+	"mod.go/history"
+	"mod.go/i18n"
+	"mod.go/notify"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	"mod.go/sheets"
+	"mod.go/teamsreport"
+	"mod.go/xapi"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Hook Функция, вызываемая на одном из этапов обработки отчёта. Получает оглавление отчёта (которое можно изменить) и
+// участников собрания, возвращает участников, передаваемые следующим этапам. Ошибка хука прерывает обработку отчёта
+type Hook func(ctx context.Context, header *report.Header, members []report.Member) ([]report.Member, error)
+
+// Зарегистрированные хуки этапов обработки отчёта
+var (
+	hooksMutex  sync.RWMutex
+	afterParse  []Hook
+	afterMatch  []Hook
+	beforeWrite []Hook
+)
+
+// ErrTechnicalCall Ошибка, возвращаемая для технического созвона, если технические созвоны пропускаются: отчёт не
+// формируется, собрание не записывается в историю
+var ErrTechnicalCall = errors.New("собрание является техническим созвоном, отчёт не формируется")
+
+// ErrReportExists Ошибка, возвращаемая, если отчёт собрания уже сформирован и существующие отчёты пропускаются: отчёт
+// не формируется и собрание не записывается в историю
+var ErrReportExists = errors.New("отчёт этого собрания уже сформирован")
+
+// ErrFinalized Ошибка, возвращаемая, если собрание закрыто командой finalize и флаг --force не указан: отчёт не
+// формируется и собрание в истории не изменяется
+var ErrFinalized = errors.New("собрание закрыто командой finalize, для изменения укажите флаг --force")
+
+/*====================================================================================================================*/
+
+// AfterParse Функция, регистрирующая хук, вызываемый после чтения отчёта MS Teams: участники уже объединены по ФИО и
+// им назначены группы, но гости ещё не обработаны и отсутствующие студенты не добавлены
+func AfterParse(hook Hook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	afterParse = append(afterParse, hook)
+}
+
+// AfterMatch Функция, регистрирующая хук, вызываемый после сопоставления участников с базой групп: гости обработаны,
+// отсутствующие студенты добавлены, освобождённые от посещения исключены
+func AfterMatch(hook Hook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	afterMatch = append(afterMatch, hook)
+}
+
+// BeforeWrite Функция, регистрирующая хук, вызываемый перед записью итогового отчёта, после сортировки участников.
+// Гости, выводимые отдельным списком, в хук не передаются
+func BeforeWrite(hook Hook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	beforeWrite = append(beforeWrite, hook)
+}
+
+// runHooks Вспомогательная функция, вызывающая хуки этапа в порядке регистрации
+func runHooks(ctx context.Context, stage *[]Hook, header *report.Header, members []report.Member) ([]report.Member, error) {
+	hooksMutex.RLock()
+	hooks := append([]Hook(nil), *stage...)
+	hooksMutex.RUnlock()
+
+	for _, hook := range hooks {
+		var err error
+		if members, err = hook(ctx, header, members); err != nil {
+			return nil, fmt.Errorf("ошибка хука обработки отчёта: %w", err)
+		}
+	}
+
+	return members, nil
+}
+
+// Runtime Структура общих данных обработки отчётов: конфигурации, база групп и файлы, указанные флагами командной
+// строки (освобождения, лист присутствия, журнал СДО, список записавшихся, варианты экзамена). Данные считываются один
+// раз функцией NewRuntime() и не изменяются при обработке, поэтому при обработке нескольких отчётов подряд файлы не
+// считываются заново для каждого отчёта
+type Runtime struct {
+	//Конфигурации программы
+	Configuration config.Configuration
+	//База групп
+	Base roster.Base
+	//Освобождения от посещения пар
+	Exemptions []roster.Exemption
+	//ФИО из листа присутствия в аудитории (пустой, если флаг --signin не указан)
+	SignIn []string
+	//Активность студентов в СДО (пустая, если флаг --lms-log не указан)
+	LMSActivity roster.LMSActivity
+	//ФИО из списка записавшихся на консультацию (пустой, если флаг --signup не указан)
+	SignUp []string
+	//Варианты экзамена (пустой, если флаг --exam не указан)
+	Assignments []roster.Assignment
+	//Итоговые отчёты последнего собрания, сформированные функцией Process() (ключ - формат, значение - путь до отчёта)
+	Reports map[string]string
+	//Оповещения, отправляемые общей сводкой после обработки всех отчётов (nil, если сводка выключена)
+	batch *notify.Batch
+	//Оповещения общей сводки, которые записываются в журнал отправленных оповещений после её отправки
+	batched [][2]string
+}
+
+// NewRuntime Функция, считывающая общие данные обработки отчётов для всех отчётов, обрабатываемых программой
+func NewRuntime(configuration config.Configuration, base roster.Base) (*Runtime, error) {
+	runtime := &Runtime{Configuration: configuration, Base: base}
+	if configuration.Notify.Batch {
+		runtime.batch = &notify.Batch{}
+	}
+
+	var err error
+	if runtime.Exemptions, err = roster.LoadExemptions(configuration.ExemptionsPath); err != nil {
+		return nil, err
+	}
+	if configuration.SignInPath != "" {
+		if runtime.SignIn, err = roster.LoadSignIn(configuration.SignInPath); err != nil {
+			return nil, err
+		}
+	}
+	if configuration.LMSLogPath != "" {
+		if runtime.LMSActivity, err = roster.LoadLMSActivity(configuration.LMSLogPath); err != nil {
+			return nil, err
+		}
+	}
+	if configuration.SignUpPath != "" {
+		if runtime.SignUp, err = roster.LoadSignUp(configuration.SignUpPath); err != nil {
+			return nil, err
+		}
+	}
+	if configuration.ExamPath != "" {
+		if runtime.Assignments, err = roster.LoadAssignments(configuration.ExamPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return runtime, nil
+}
+
+// ProcessReport Функция, обрабатывающая отчёт MS Teams (или несколько отчётов одного собрания, объединяемых в один):
+// от чтения .csv файлов до формирования итогового отчёта
+// Если передано хранилище истории, собрание и отметки участников добавляются в историю посещаемости. Прочитанные и
+// записанные файлы записываются в журнал действий (если он ведётся). Для обработки нескольких отчётов подряд общие
+// данные лучше считать один раз функцией NewRuntime() и обрабатывать отчёты функцией Process()
+func ProcessReport(ctx context.Context, paths []string, configuration config.Configuration, base roster.Base,
+	store *history.Store, journal *audit.Log) error {
+	runtime, err := NewRuntime(configuration, base)
+	if err != nil {
+		return err
+	}
+	if err := runtime.Process(ctx, paths, store, journal); err != nil {
+		return err
+	}
+
+	return runtime.FlushNotifications(ctx)
+}
+
+// Process Функция, обрабатывающая отчёт MS Teams (или несколько отчётов одного собрания, объединяемых в один) с общими
+// данными, считанными функцией NewRuntime()
+func (runtime *Runtime) Process(ctx context.Context, paths []string, store *history.Store, journal *audit.Log) error {
+	configuration, base := runtime.Configuration, runtime.Base
+	runtime.Reports = nil
+
+	//Формируем оглавление и список участников собрания с помощью функции DiagnoseCSVReports(), которая при пробном
+	// запуске и подробном выводе дополнительно возвращает сведения о разборе отчёта
+	header, members, diagnostics, err := teamsreport.DiagnoseCSVReports(ctx, paths, configuration.Schedule, base,
+		configuration.Verbose)
+	if err != nil {
+		return err
+	}
+	if configuration.Verbose && !configuration.DryRun {
+		for _, row := range diagnostics.Rows {
+			slog.Debug(i18n.T("Разбор строки участника"), row.Attrs()...)
+		}
+	}
+	for _, path := range paths {
+		if err := journal.Read(path); err != nil {
+			return err
+		}
+	}
+	if header.LessonNumber == schedule.TechnicalCall && configuration.SkipTechnicalCalls {
+		return ErrTechnicalCall
+	}
+
+	//Для гибридного занятия объединяем участников собрания со студентами, отметившимися в аудитории
+	if configuration.SignInPath != "" {
+		if err := journal.Read(configuration.SignInPath); err != nil {
+			return err
+		}
+		members = base.MergeSignIn(members, runtime.SignIn, configuration.GuestMatchDistance)
+	}
+
+	//Хэш входных файлов определяет собрание при повторной обработке тех же отчётов
+	inputs := append([]string{}, paths...)
+	if configuration.SignInPath != "" {
+		inputs = append(inputs, configuration.SignInPath)
+	}
+	if configuration.LMSLogPath != "" {
+		inputs = append(inputs, configuration.LMSLogPath)
+	}
+	if configuration.SignUpPath != "" {
+		inputs = append(inputs, configuration.SignUpPath)
+	}
+	if configuration.ExamPath != "" {
+		inputs = append(inputs, configuration.ExamPath)
+	}
+	if header.SourceHash, err = contentHash(inputs...); err != nil {
+		return err
+	}
+
+	if members, err = runHooks(ctx, &afterParse, &header, members); err != nil {
+		return err
+	}
+
+	//Дисциплина определяется по названию собрания (или по инициатору не переименованного собрания): её название
+	// выводится в оглавлении, а студенты её групп отмечаются отсутствующими, даже если никто из группы не пришёл
+	course, _ := roster.FindCourse(header.Title, header.Lecturer)
+	header.Course = course.Name
+
+	//ФИО преподавателя из конфигураций (или преподавателя дисциплины) заменяет имя инициатора собрания (например, если
+	// собрание создано с общей учётной записи кафедры)
+	if configuration.Lecturer != "" {
+		header.Lecturer = configuration.Lecturer
+	}
+	if course.Teacher != "" {
+		header.Lecturer = course.Teacher
+	}
+	header.Roster = roster.BaseMetadata.String()
+	header.ToolVersion, header.Profile, header.GeneratedAt = report.ToolVersion, configuration.Profile, time.Now()
+
+	//Отделяем преподавателей и ассистентов из файла преподавателей, чтобы они не попали в гости или в список участников
+	members, staff := roster.SeparateStaff(members)
+	if configuration.StaffBlock {
+		header.Staff = staff
+	}
+
+	//Применяем способ обработки гостей: гости убираются, выводятся отдельно или сопоставляются со студентами базы
+	members, guests := base.ApplyGuestPolicy(members, configuration.GuestPolicy, configuration.GuestMatchDistance)
+
+	//Пара собрания ищется в расписании дня недели собрания
+	date, err := time.Parse("2.1.2006", header.Date)
+	if err != nil {
+		return fmt.Errorf("ошибка разбора даты собрания \"%v\": %w", header.Date, err)
+	}
+
+	//Заполняем массив участников собрания людьми, которых не было на собрании с помощью функции FillLostMembers(),
+	// если собрание было парой (а не консультацией или техническим созвоном) и в отчёт выводятся не только участники
+	lesson, isLesson := schedule.FindLesson(header.LessonNumber, configuration.Schedule.ForDate(date))
+	//Пара, сопоставленная с событием календаря организатора, проходит в плановое время события
+	if isLesson && !header.PlannedStart.IsZero() {
+		day := time.Date(header.PlannedStart.Year(), header.PlannedStart.Month(), header.PlannedStart.Day(), 0, 0, 0, 0,
+			header.PlannedStart.Location())
+		lesson.Start, lesson.End = int(header.PlannedStart.Sub(day).Seconds()), int(header.PlannedEnd.Sub(day).Seconds())
+	}
+	if isLesson && !configuration.OnlyPresent {
+		if members, err = roster.FillLostMembers(ctx, base, members, date, course.Groups...); err != nil {
+			return err
+		}
+
+		//Убираем из отсутствующих студентов, освобождённых от посещения пар в день собрания
+		members = roster.ExcludeExempt(members, runtime.Exemptions, date)
+
+		//Помечаем отсутствовавших студентов, которые во время пары были активны в СДО
+		if configuration.LMSLogPath != "" {
+			if err := journal.Read(configuration.LMSLogPath); err != nil {
+				return err
+			}
+			roster.FlagLMSActive(members, runtime.LMSActivity, date.Add(time.Duration(lesson.Start)*time.Second),
+				date.Add(time.Duration(lesson.End)*time.Second))
+		}
+	}
+
+	//Для консультации отсутствующие студенты групп не добавляются: участники сравниваются со списком записавшихся,
+	// если он указан
+	if header.LessonNumber == schedule.Consultation && configuration.SignUpPath != "" {
+		if err := journal.Read(configuration.SignUpPath); err != nil {
+			return err
+		}
+		members = base.CompareSignUp(members, runtime.SignUp, configuration.GuestMatchDistance)
+	}
+	if members, err = runHooks(ctx, &afterMatch, &header, members); err != nil {
+		return err
+	}
+
+	//Проверяем кворум занятия, если он включён в конфигурациях, собрание было парой и известны отсутствующие студенты
+	if configuration.QuorumShare > 0 && isLesson && !configuration.OnlyPresent {
+		header.Quorum = roster.CheckQuorum(members, header.Duration, configuration.QuorumShare,
+			configuration.QuorumTimeShare)
+	}
+
+	//Для потоковой лекции (пары нескольких групп) сравниваем посещаемость групп
+	if isLesson && !configuration.OnlyPresent {
+		header.Groups = roster.CompareGroups(members)
+	}
+
+	//Проверяем присутствие студентов на экзамене в назначенное каждому студенту время
+	if configuration.ExamPath != "" {
+		if err := journal.Read(configuration.ExamPath); err != nil {
+			return err
+		}
+		header.Exam = base.CheckExam(members, runtime.Assignments, configuration.ExamTolerance, configuration.GuestMatchDistance)
+	}
+
+	//Подсчитываем итоги посещаемости по группам для блока итогов после таблицы участников
+	if configuration.TotalsBlock {
+		header.Totals = report.SummarizeGroups(members, roster.Guest)
+	}
+
+	//Сортируем список участников собрания с помощью функции SortMembers()
+	report.SortMembers(members)
+	report.SortMembers(guests)
+	roster.AssignIDs(members, configuration.IDSalt)
+	roster.AssignIDs(guests, configuration.IDSalt)
+	roster.AssignRecordBooks(members)
+
+	//При пробном запуске выводим найденное в отчёте и не записываем ни отчётов, ни истории, ни оповещений
+	if configuration.DryRun {
+		return WriteDryRun(os.Stdout, paths, header, members, guests, diagnostics, configuration.Verbose)
+	}
+
+	if members, err = runHooks(ctx, &beforeWrite, &header, members); err != nil {
+		return err
+	}
+
+	//Закрытое собрание (и его отчёты) изменяется только с флагом --force, изменение записывается в журнал действий
+	if store != nil {
+		finalized, err := store.IsFinalized(ctx, header)
+		if err != nil {
+			return err
+		}
+		if finalized {
+			if !configuration.Force {
+				return ErrFinalized
+			}
+			if !journal.Enabled() {
+				return fmt.Errorf("изменение закрытого собрания с флагом --force возможно только при включённом журнале " +
+					"действий (секция [audit] cfg.ini)")
+			}
+			meeting := fmt.Sprintf("%v, %v, %v", header.Title, header.Date, header.LessonNumber)
+			if err := journal.Force(meeting); err != nil {
+				return err
+			}
+			slog.Warn(i18n.T("Изменено закрытое собрание"), "meeting", meeting)
+		}
+	}
+
+	//Форматы итогового отчёта, выбранные в конфигурациях. Первый формат - основной
+	exporters, err := report.Exporters(configuration.Formats, configuration.ReportLocationPath)
+	if err != nil {
+		return err
+	}
+	if len(exporters) == 0 {
+		return fmt.Errorf("не выбран ни один формат итогового отчёта (formats в секции [report] cfg.ini)")
+	}
+
+	//Отчёт этого собрания мог быть уже сформирован при предыдущем запуске: он пропускается, перезаписывается или
+	// формируется новая версия отчёта. Наличие отчёта проверяется по основному формату
+	if configuration.ReportLocationPath != "-" && report.ResolveExisting(&header, configuration.ExistingReports,
+		exporters[0].Path) {
+		return ErrReportExists
+	}
+
+	//Формируем и заполняем отчёт в каждом выбранном формате
+	reportPath := exporters[0].Path(header)
+	runtime.Reports = make(map[string]string, len(exporters))
+	for i, exporter := range exporters {
+		if err := exporter.Write(ctx, header, members, guests); err != nil {
+			return err
+		}
+		runtime.Reports[configuration.Formats[i]] = exporter.Path(header)
+		if configuration.ReportLocationPath != "-" {
+			if err := journal.Write(exporter.Path(header)); err != nil {
+				return err
+			}
+		}
+	}
+
+	//Формируем сокращённый отчёт с участниками с выбранными пометками (--only): полный отчёт остаётся в архиве, а
+	// кураторам по почте и в чат Telegram отправляется сокращённый
+	forwardPath := reportPath
+	if len(configuration.Only) > 0 && configuration.ReportLocationPath != "-" {
+		short := header
+		short.Only = configuration.Only
+		for _, exporter := range exporters {
+			if err := exporter.Write(ctx, short, configuration.Only.Apply(members),
+				configuration.Only.Apply(guests)); err != nil {
+				return err
+			}
+			if err := journal.Write(exporter.Path(short)); err != nil {
+				return err
+			}
+		}
+		forwardPath = exporters[0].Path(short)
+	}
+
+	//Выводим краткую сводку собрания в стандартный вывод (для писем cron с выводом ночной обработки отчётов)
+	if configuration.StdoutSummary {
+		if err := report.WriteSummary(os.Stdout, header, append(append([]report.Member{}, members...), guests...),
+			reportPath); err != nil {
+			return err
+		}
+	}
+
+	//Оповещения, уже отправленные при прежней обработке того же отчёта, повторно не отправляются
+	ledger, err := openSentLog(configuration.SentNotificationsPath)
+	if err != nil {
+		return err
+	}
+
+	//Отправляем сформированный отчёт по электронной почте, если отправка включена в конфигурациях
+	if configuration.Email.SendReport && !ledger.Sent(header.SourceHash, "email") {
+		if err := email.SendReport(ctx, configuration.Email, header, forwardPath); err != nil {
+			return err
+		}
+		if err := ledger.Mark(header.SourceHash, "email"); err != nil {
+			return err
+		}
+	}
+
+	//Отправляем сводку собрания (и отчёт, если включено) в чат Telegram
+	if configuration.Notify.TelegramEnabled() && !ledger.Sent(header.SourceHash, "telegram") {
+		all := append(append([]report.Member{}, members...), guests...)
+		if runtime.batch != nil {
+			runtime.batch.AddTelegram(header, all, forwardPath)
+			runtime.batched = append(runtime.batched, [2]string{header.SourceHash, "telegram"})
+		} else {
+			if err := notify.SendTelegram(ctx, configuration.Notify, header, all, forwardPath); err != nil {
+				return err
+			}
+			if err := ledger.Mark(header.SourceHash, "telegram"); err != nil {
+				return err
+			}
+		}
+	}
+
+	//Дописываем таблицу посещаемости в Google Sheets кафедры
+	if configuration.Sheets.Enabled && !ledger.Sent(header.SourceHash, "sheets") {
+		if err := sheets.Export(ctx, configuration.Sheets, header, append(append([]report.Member{}, members...),
+			guests...)); err != nil {
+			return err
+		}
+		if err := ledger.Mark(header.SourceHash, "sheets"); err != nil {
+			return err
+		}
+	}
+
+	//Выгружаем посещаемость студентов в хранилище учебных записей платформы учебной аналитики
+	if configuration.XAPI.Enabled && !ledger.Sent(header.SourceHash, "xapi") {
+		if err := xapi.Export(ctx, configuration.XAPI, header, members); err != nil {
+			return err
+		}
+		if err := ledger.Mark(header.SourceHash, "xapi"); err != nil {
+			return err
+		}
+	}
+
+	//Статистика и история ведутся по всем участникам, включая гостей, выведенных отдельно
+	members = append(members, guests...)
+
+	//Формируем статистику устройств участников, если она включена в конфигурациях
+	if configuration.PlatformStats {
+		if err := report.FormPlatformStats(ctx, header, members, configuration.ReportLocationPath); err != nil {
+			return err
+		}
+
+		//Статистика не формируется, если в отчёте MS Teams нет сведений об устройствах
+		statsPath := report.PlatformStatsPath(header, configuration.ReportLocationPath)
+		if _, err := os.Stat(statsPath); err == nil {
+			if err := journal.Write(statsPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	//Формируем изображение со сводкой посещаемости, если оно включено в конфигурациях
+	if configuration.Badge {
+		if err := report.FormBadge(ctx, header, members, configuration.ReportLocationPath); err != nil {
+			return err
+		}
+		if err := journal.Write(report.BadgePath(header, configuration.ReportLocationPath)); err != nil {
+			return err
+		}
+	}
+
+	//Добавляем собрание в историю посещаемости
+	if store != nil {
+		//Отчёты, выведенные в стандартный вывод, не сохраняются, поэтому их пути не запоминаются
+		var reports map[string]string
+		if configuration.ReportLocationPath != "-" {
+			reports = runtime.Reports
+		}
+		merged, err := store.AppendSession(ctx, header, members, reports)
+		if err != nil {
+			return err
+		}
+		if merged {
+			slog.Warn(i18n.T("Собрание той же пары уже записано в историю, отметки объединены"), "title", header.Title,
+				"date", header.Date, "lesson", header.LessonNumber)
+		}
+		if err := journal.Write(configuration.History.Path); err != nil {
+			return err
+		}
+	}
+
+	//Создаём кураторам задачи в Microsoft To Do о студентах, пропустивших подряд заданное количество собраний
+	if store != nil && configuration.Notify.FollowUpAbsences > 0 && isLesson {
+		if err := createFollowUps(ctx, configuration, header, members, store, ledger); err != nil {
+			return err
+		}
+	}
+
+	//Отправляем кураторам сводку отсутствующих студентов, если оповещения настроены и собрание не было консультацией
+	if configuration.Notify.Enabled() && isLesson {
+		curators, err := roster.LoadCurators(configuration.CuratorsPath)
+		if err != nil {
+			return err
+		}
+
+		//Сводка отправляется по группам, чтобы при сбое отправки повторная обработка отправила только оставшиеся
+		summaries := notify.AbsenteeSummary(header, members)
+		groups := make([]string, 0, len(summaries))
+		for group := range summaries {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			channel := "curators " + group
+			if ledger.Sent(header.SourceHash, channel) {
+				continue
+			}
+			if runtime.batch != nil {
+				runtime.batch.AddCurators(map[string]string{group: summaries[group]})
+				runtime.batched = append(runtime.batched, [2]string{header.SourceHash, channel})
+				continue
+			}
+			if err := notify.SendToCurators(ctx, configuration.Notify, curators,
+				map[string]string{group: summaries[group]}); err != nil {
+				return err
+			}
+			if err := ledger.Mark(header.SourceHash, channel); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// FlushNotifications Функция, отправляющая оповещения, собранные в общую сводку при обработке отчётов функцией
+// Process(), если сводка включена в конфигурациях (batch в секции [notify] cfg.ini). Вызывается один раз после
+// обработки всех отчётов запуска. Отправленные оповещения записываются в журнал отправленных оповещений, поэтому при
+// сбое отправки следующий запуск отправит их снова
+func (runtime *Runtime) FlushNotifications(ctx context.Context) error {
+	if runtime.batch == nil || runtime.batch.Empty() {
+		return nil
+	}
+
+	//Файл кураторов нужен, только если сводки отправляются кураторам
+	var curators map[string]roster.Curator
+	if runtime.Configuration.Notify.Enabled() {
+		var err error
+		if curators, err = roster.LoadCurators(runtime.Configuration.CuratorsPath); err != nil {
+			return err
+		}
+	}
+	if err := runtime.batch.Flush(ctx, runtime.Configuration.Notify, curators); err != nil {
+		return err
+	}
+
+	ledger, err := openSentLog(runtime.Configuration.SentNotificationsPath)
+	if err != nil {
+		return err
+	}
+	for _, sent := range runtime.batched {
+		if err := ledger.Mark(sent[0], sent[1]); err != nil {
+			return err
+		}
+	}
+	runtime.batched = nil
+
+	return nil
+}
+
+// createFollowUps Функция, создающая кураторам задачи в Microsoft To Do о студентах, серия пропусков которых на этом
+// собрании достигла порога. Задача создаётся один раз на серию: при следующих пропусках серия уже больше порога
+func createFollowUps(ctx context.Context, configuration config.Configuration, header report.Header,
+	members []report.Member, store *history.Store, ledger *sentLog) error {
+	curators, err := roster.LoadCurators(configuration.CuratorsPath)
+	if err != nil {
+		return err
+	}
+
+	//Токен доступа запрашивается только при первой создаваемой задаче
+	token := ""
+	for _, member := range members {
+		if !member.Presence.IsAbsent() || member.Group == roster.Guest {
+			continue
+		}
+		channel := "followup " + member.FullName
+		if ledger.Sent(header.SourceHash, channel) {
+			continue
+		}
+
+		streak, err := store.AbsenceStreak(ctx, member.FullName)
+		if err != nil {
+			return err
+		}
+		if streak != configuration.Notify.FollowUpAbsences {
+			continue
+		}
+		curator := curators[member.Group]
+		if curator.Email == "" {
+			slog.Warn(i18n.T("Не указан адрес куратора группы, задача не создана"), "group", member.Group,
+				"student", member.FullName)
+			continue
+		}
+
+		if token == "" {
+			if token, err = graph.RequestToken(ctx, configuration.Graph); err != nil {
+				return err
+			}
+		}
+		title, text := notify.FollowUpTask(member.FullName, member.Group, streak, header)
+		if err := graph.CreateTask(ctx, token, curator.Email, title, text); err != nil {
+			return err
+		}
+		if err := ledger.Mark(header.SourceHash, channel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"mod.go/config"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+/*====================================================================================================================*/
+
+// benchmarkReport Отчёт MS Teams, который обрабатывается в тестах производительности
+var benchmarkReport = filepath.Join("..", "teamsreport", "testdata", "ru_utf16.csv")
+
+// benchmarkBase База групп тестов производительности
+var benchmarkBase = roster.Base{
+	"Лекторов Пётр Сергеевич":    roster.Teacher,
+	"Иванов Иван Иванович":       "МП-51",
+	"Петрова Мария Петровна":     "МП-51",
+	"Сидоров Алексей Викторович": "МП-51",
+	"Кузнецов Дмитрий Олегович":  "МП-51",
+}
+
+// benchmarkConfiguration Вспомогательная функция, возвращающая конфигурации тестов производительности: расписание
+// пар, папку отчётов и файлы освобождений и журнала СДО, которые считываются функцией NewRuntime()
+func benchmarkConfiguration(b *testing.B) config.Configuration {
+	b.Helper()
+	directory := b.TempDir()
+
+	var exemptions, lmsLog strings.Builder
+	lmsLog.WriteString("Время,Полное имя пользователя\n")
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&exemptions, "Студент%d Иван Иванович,01.09.2026-31.12.2026,практика\n", i)
+		fmt.Fprintf(&lmsLog, "\"15.10.2026, 08:%02d\",Студент%d Иван Иванович\n", i%60, i)
+	}
+	configuration := config.Configuration{
+		Schedule: schedule.Schedule{
+			Lessons: []schedule.Lesson{
+				{Name: "Пара 1", Start: 8 * 3600, End: 9*3600 + 30*60, LateThreshold: 10 * 60},
+				{Name: "Пара 2", Start: 9*3600 + 40*60, End: 11*3600 + 10*60, LateThreshold: 10 * 60},
+			},
+			ToleranceBefore: 15 * 60, ToleranceAfter: 15 * 60, LateThreshold: 10 * 60, EarlyExitThreshold: 10 * 60,
+		},
+		Formats:            []string{report.FormatCSV},
+		ReportLocationPath: directory,
+		ExistingReports:    report.ExistingOverwrite,
+		GuestPolicy:        roster.GuestSeparate,
+		ExemptionsPath:     filepath.Join(directory, "exemptions.csv"),
+		LMSLogPath:         filepath.Join(directory, "lms.csv"),
+	}
+	if err := os.WriteFile(configuration.ExemptionsPath, []byte(exemptions.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(configuration.LMSLogPath, []byte(lmsLog.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	return configuration
+}
+
+/*====================================================================================================================*/
+
+// BenchmarkProcessReport Обработка отчётов без общих данных: функция ProcessReport() считывает файлы, указанные
+// флагами, заново для каждого отчёта
+func BenchmarkProcessReport(b *testing.B) {
+	configuration := benchmarkConfiguration(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := ProcessReport(context.Background(), []string{benchmarkReport}, configuration, benchmarkBase, nil,
+			nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRuntimeProcess Обработка отчётов с общими данными, считанными один раз функцией NewRuntime(), как при
+// обработке нескольких отчётов подряд и в команде api
+func BenchmarkRuntimeProcess(b *testing.B) {
+	runtime, err := NewRuntime(benchmarkConfiguration(b), benchmarkBase)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := runtime.Process(context.Background(), []string{benchmarkReport}, nil, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := runtime.FlushNotifications(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkNewRuntime Считывание общих данных обработки отчётов, которое выполняется один раз за запуск программы
+func BenchmarkNewRuntime(b *testing.B) {
+	configuration := benchmarkConfiguration(b)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewRuntime(configuration, benchmarkBase); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
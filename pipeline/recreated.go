@@ -0,0 +1,39 @@
+package pipeline
+
+import (
+	"context"
+	"mod.go/schedule"
+	"mod.go/teamsreport"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// GroupRecreated Функция, разбивающая отчёты на собрания: отчёты собраний одного преподавателя с тем же названием на
+// одной паре одного дня (собрание, созданное заново после обрыва связи) относятся к одному собранию и обрабатываются
+// вместе, остальные отчёты обрабатываются по-отдельности. Порядок собраний совпадает с порядком первых отчётов.
+// Отчёты, которые не удалось прочитать, и отчёты консультаций и технических созвонов не объединяются
+func (runtime *Runtime) GroupRecreated(ctx context.Context, paths []string) [][]string {
+	var groups [][]string
+	slots := make(map[string]int)
+
+	for _, path := range paths {
+		header, _, err := teamsreport.ReadCSVReport(ctx, path, runtime.Configuration.Schedule, runtime.Base)
+		if err != nil || header.LessonNumber == schedule.Consultation || header.LessonNumber == schedule.TechnicalCall {
+			groups = append(groups, []string{path})
+			continue
+		}
+
+		//Пара собрания определяется датой, номером пары, названием собрания и преподавателем
+		slot := strings.Join([]string{header.Date, header.LessonNumber, strings.ToLower(strings.TrimSpace(header.Title)),
+			strings.ToLower(header.Lecturer)}, "\x00")
+		if i, ok := slots[slot]; ok {
+			groups[i] = append(groups[i], path)
+			continue
+		}
+		slots[slot] = len(groups)
+		groups = append(groups, []string{path})
+	}
+
+	return groups
+}
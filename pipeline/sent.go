@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// sentLog Структура журнала отправленных оповещений: каждая отправка записывается строкой "хэш отчёта<TAB>способ",
+// поэтому повторная обработка того же отчёта (например, при перезапуске прерванного задания по расписанию) не
+// отправляет оповещения повторно
+type sentLog struct {
+	//Путь до файла журнала
+	path string
+	//Множество уже отправленных оповещений
+	sent map[string]bool
+}
+
+/*====================================================================================================================*/
+
+// contentHash Функция, возвращающая хэш SHA-256 содержимого входных файлов собрания в порядке их перечисления. Хэш
+// определяет собрание при повторной обработке тех же отчётов
+func contentHash(paths ...string) (string, error) {
+	hash := sha256.New()
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return "", fmt.Errorf("ошибка открытия файла для подсчёта хэша: %w", err)
+		}
+		_, err = io.Copy(hash, file)
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("ошибка чтения файла для подсчёта хэша: %w", err)
+		}
+
+		//Разделитель не даёт разным наборам файлов с одинаковым общим содержимым получить один хэш
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// openSentLog Функция, считывающая журнал отправленных оповещений. Если журнала нет, он создаётся при первой отправке
+func openSentLog(path string) (*sentLog, error) {
+	ledger := &sentLog{path: path, sent: make(map[string]bool)}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия журнала отправленных оповещений: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			ledger.sent[line] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения журнала отправленных оповещений: %w", err)
+	}
+
+	return ledger, nil
+}
+
+// Sent Функция, проверяющая, отправлялось ли уже оповещение отчёта указанным способом
+func (ledger *sentLog) Sent(hash, channel string) bool {
+	return ledger.sent[hash+"\t"+channel]
+}
+
+// Mark Функция, дописывающая в журнал отправку оповещения отчёта указанным способом
+func (ledger *sentLog) Mark(hash, channel string) error {
+	file, err := os.OpenFile(ledger.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия журнала отправленных оповещений: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := fmt.Fprintf(file, "%v\t%v\n", hash, channel); err != nil {
+		return fmt.Errorf("ошибка записи в журнал отправленных оповещений: %w", err)
+	}
+	ledger.sent[hash+"\t"+channel] = true
+
+	return nil
+}
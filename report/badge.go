@@ -0,0 +1,121 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"math"
+	"mod.go/i18n"
+	"os"
+)
+
+/*====================================================================================================================*/
+
+// Summary Структура сводки посещаемости собрания
+type Summary struct {
+	//Присутствовали без опоздания (полностью или не полностью)
+	Present int
+	//Присутствовали, но опоздали
+	Late int
+	//Отсутствовали
+	Absent int
+}
+
+// badgeSegment Структура сегмента кольцевой диаграммы сводки
+type badgeSegment struct {
+	label string
+	count int
+	color string
+}
+
+/*====================================================================================================================*/
+
+// Summarize Функция, подсчитывающая присутствовавших, опоздавших и отсутствовавших участников собрания
+func Summarize(members []Member) Summary {
+	var summary Summary
+
+	for _, member := range members {
+		switch {
+		case member.FullName == "" || member.Presence.IsExcused():
+			continue
+		case member.Presence.IsAbsent():
+			summary.Absent++
+		case member.Delay == DelayLate:
+			summary.Late++
+		default:
+			summary.Present++
+		}
+	}
+
+	return summary
+}
+
+// Total Функция, возвращающая общее количество участников в сводке
+func (summary Summary) Total() int {
+	return summary.Present + summary.Late + summary.Absent
+}
+
+// BadgePath Функция, возвращающая полный путь до изображения со сводкой посещаемости, сформированного функцией
+// FormBadge()
+func BadgePath(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Сводка посещаемости_") + header.FileName() + ".svg"
+}
+
+// FormBadge Функция, формирующая изображение .svg со сводкой посещаемости собрания (кольцевая диаграмма
+// присутствовавших, опоздавших и отсутствовавших), которое можно отправить в чат группы
+func FormBadge(ctx context.Context, header Header, members []Member, reportLocationPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	summary := Summarize(members)
+	segments := []badgeSegment{
+		{i18n.T("Присутствовали"), summary.Present, "#2e7d32"},
+		{i18n.T("Опоздали"), summary.Late, "#f9a825"},
+		{i18n.T("Отсутствовали"), summary.Absent, "#c62828"},
+	}
+
+	var image bytes.Buffer
+	fmt.Fprintf(&image, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"420\" height=\"170\" viewBox=\"0 0 420 170\" "+
+		"font-family=\"Segoe UI, Arial, sans-serif\">\n")
+	fmt.Fprintf(&image, "<rect width=\"420\" height=\"170\" rx=\"12\" fill=\"#ffffff\" stroke=\"#d0d0d0\"/>\n")
+
+	//Кольцевая диаграмма из окружностей с пунктиром: длина штриха - доля сегмента от длины окружности
+	const radius = 50.0
+	circumference := 2 * math.Pi * radius
+	fmt.Fprintf(&image, "<circle cx=\"85\" cy=\"85\" r=\"%.0f\" fill=\"none\" stroke=\"#eeeeee\" stroke-width=\"22\"/>\n", radius)
+	offset := 0.0
+	for _, segment := range segments {
+		if segment.count == 0 {
+			continue
+		}
+		length := circumference * float64(segment.count) / float64(summary.Total())
+		fmt.Fprintf(&image, "<circle cx=\"85\" cy=\"85\" r=\"%.0f\" fill=\"none\" stroke=\"%v\" stroke-width=\"22\" "+
+			"stroke-dasharray=\"%.2f %.2f\" stroke-dashoffset=\"%.2f\" transform=\"rotate(-90 85 85)\"/>\n",
+			radius, segment.color, length, circumference-length, -offset)
+		offset += length
+	}
+	fmt.Fprintf(&image, "<text x=\"85\" y=\"92\" text-anchor=\"middle\" font-size=\"22\" font-weight=\"bold\" "+
+		"fill=\"#333333\">%d</text>\n", summary.Total())
+
+	//Оглавление и легенда с количеством участников
+	fmt.Fprintf(&image, "<text x=\"165\" y=\"36\" font-size=\"16\" font-weight=\"bold\" fill=\"#333333\">%v</text>\n",
+		html.EscapeString(header.Title))
+	fmt.Fprintf(&image, "<text x=\"165\" y=\"58\" font-size=\"13\" fill=\"#666666\">%v, %v</text>\n",
+		html.EscapeString(header.Date), html.EscapeString(header.LessonLabel()))
+	for i, segment := range segments {
+		y := 88 + i*24
+		fmt.Fprintf(&image, "<rect x=\"165\" y=\"%d\" width=\"14\" height=\"14\" rx=\"3\" fill=\"%v\"/>\n", y-12, segment.color)
+		fmt.Fprintf(&image, "<text x=\"187\" y=\"%d\" font-size=\"14\" fill=\"#333333\">%v: %d</text>\n",
+			y, segment.label, segment.count)
+	}
+	fmt.Fprintf(&image, "</svg>\n")
+
+	//Сохраняем изображение рядом с отчётом о посещаемости
+	if err := os.WriteFile(BadgePath(header, reportLocationPath), image.Bytes(), 0644); err != nil {
+		return fmt.Errorf("ошибка записи сводки посещаемости: %w", err)
+	}
+
+	return nil
+}
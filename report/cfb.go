@@ -0,0 +1,283 @@
+package report
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+/*====================================================================================================================*/
+
+// Размеры и особые номера секторов составного файла (Compound File Binary, версия 3)
+const (
+	cfbSectorSize     = 512
+	cfbMiniSectorSize = 64
+	cfbMiniCutoff     = 4096
+	cfbDIFATInHeader  = 109
+	cfbFreeSector     = 0xFFFFFFFF
+	cfbEndOfChain     = 0xFFFFFFFE
+	cfbFATSector      = 0xFFFFFFFD
+	cfbDIFATSector    = 0xFFFFFFFC
+	cfbNoStream       = 0xFFFFFFFF
+)
+
+// cfbEntry Структура элемента составного файла: хранилище с вложенными элементами или поток с данными
+type cfbEntry struct {
+	name     string
+	data     []byte
+	children []*cfbEntry
+	storage  bool
+}
+
+// cfbDirectory Структура записи каталога составного файла при его сборке
+type cfbDirectory struct {
+	entry              *cfbEntry
+	kind               byte
+	left, right, child uint32
+	start              uint32
+	size               int
+}
+
+/*====================================================================================================================*/
+
+// writeCompoundFile Вспомогательная функция, собирающая составной файл (в таком контейнере MS Office хранит
+// зашифрованные книги) из элементов корневого хранилища. Потоки меньше 4096 байт хранятся в мини-потоке
+func writeCompoundFile(children []*cfbEntry) []byte {
+	//Записи каталога: корневое хранилище и все вложенные элементы
+	directory := []*cfbDirectory{{entry: &cfbEntry{name: "Root Entry", children: children, storage: true}, kind: 5,
+		left: cfbNoStream, right: cfbNoStream}}
+	for i := 0; i < len(directory); i++ {
+		current := directory[i]
+		if !current.entry.storage {
+			continue
+		}
+		ids := make([]uint32, 0, len(current.entry.children))
+		for _, child := range sortedCFBEntries(current.entry.children) {
+			kind := byte(2)
+			if child.storage {
+				kind = 1
+			}
+			ids = append(ids, uint32(len(directory)))
+			directory = append(directory, &cfbDirectory{entry: child, kind: kind})
+		}
+		current.child = cfbTree(directory, ids)
+	}
+
+	//Мини-поток с небольшими потоками и цепочки его мини-секторов
+	var miniStream []byte
+	var miniFAT []uint32
+	var large []*cfbDirectory
+	for _, current := range directory {
+		current.start, current.size = cfbEndOfChain, len(current.entry.data)
+		switch {
+		case current.kind != 2:
+			current.start = 0
+		case current.size == 0:
+		case current.size < cfbMiniCutoff:
+			current.start = uint32(len(miniFAT))
+			count := (current.size + cfbMiniSectorSize - 1) / cfbMiniSectorSize
+			for i := 0; i < count; i++ {
+				miniFAT = append(miniFAT, uint32(len(miniFAT)+1))
+			}
+			miniFAT[len(miniFAT)-1] = cfbEndOfChain
+			miniStream = append(miniStream, padBytes(current.entry.data, cfbMiniSectorSize)...)
+		default:
+			large = append(large, current)
+		}
+	}
+
+	sectors := func(size, unit int) int { return (size + unit - 1) / unit }
+	directorySectors := sectors(len(directory)*128, cfbSectorSize)
+	miniFATSectors := sectors(len(miniFAT)*4, cfbSectorSize)
+	miniStreamSectors := sectors(len(miniStream), cfbSectorSize)
+	dataSectors := directorySectors + miniFATSectors + miniStreamSectors
+	for _, current := range large {
+		dataSectors += sectors(current.size, cfbSectorSize)
+	}
+
+	//Количество секторов таблицы размещения зависит от общего количества секторов, включая её саму
+	fatSectors, difatSectors := 0, 0
+	for {
+		total := dataSectors + fatSectors + difatSectors
+		neededFAT := sectors(total*4, cfbSectorSize)
+		neededDIFAT := 0
+		if neededFAT > cfbDIFATInHeader {
+			neededDIFAT = sectors(neededFAT-cfbDIFATInHeader, cfbSectorSize/4-1)
+		}
+		if neededFAT == fatSectors && neededDIFAT == difatSectors {
+			break
+		}
+		fatSectors, difatSectors = neededFAT, neededDIFAT
+	}
+
+	//Таблица размещения: сектора таблицы, сектора продолжения её списка, затем цепочки остальных секторов
+	fat := make([]uint32, fatSectors*cfbSectorSize/4)
+	for i := range fat {
+		fat[i] = cfbFreeSector
+	}
+	next := 0
+	chain := func(count int) uint32 {
+		if count == 0 {
+			return cfbEndOfChain
+		}
+		start := next
+		for i := 0; i < count; i++ {
+			fat[next] = uint32(next + 1)
+			next++
+		}
+		fat[next-1] = cfbEndOfChain
+		return uint32(start)
+	}
+	for i := 0; i < fatSectors; i++ {
+		fat[next] = cfbFATSector
+		next++
+	}
+	firstDIFAT := uint32(cfbEndOfChain)
+	if difatSectors > 0 {
+		firstDIFAT = uint32(next)
+	}
+	for i := 0; i < difatSectors; i++ {
+		fat[next] = cfbDIFATSector
+		next++
+	}
+	firstDirectory := chain(directorySectors)
+	firstMiniFAT := chain(miniFATSectors)
+	directory[0].start, directory[0].size = chain(miniStreamSectors), len(miniStream)
+	for _, current := range large {
+		current.start = chain(sectors(current.size, cfbSectorSize))
+	}
+
+	//Заголовок составного файла
+	header := make([]byte, cfbSectorSize)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	binary.LittleEndian.PutUint16(header[24:], 0x003E)
+	binary.LittleEndian.PutUint16(header[26:], 0x0003)
+	binary.LittleEndian.PutUint16(header[28:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[30:], 9)
+	binary.LittleEndian.PutUint16(header[32:], 6)
+	binary.LittleEndian.PutUint32(header[44:], uint32(fatSectors))
+	binary.LittleEndian.PutUint32(header[48:], firstDirectory)
+	binary.LittleEndian.PutUint32(header[56:], cfbMiniCutoff)
+	binary.LittleEndian.PutUint32(header[60:], firstMiniFAT)
+	binary.LittleEndian.PutUint32(header[64:], uint32(miniFATSectors))
+	binary.LittleEndian.PutUint32(header[68:], firstDIFAT)
+	binary.LittleEndian.PutUint32(header[72:], uint32(difatSectors))
+	difat := make([]uint32, cfbDIFATInHeader+difatSectors*(cfbSectorSize/4-1))
+	for i := range difat {
+		difat[i] = cfbFreeSector
+		if i < fatSectors {
+			difat[i] = uint32(i)
+		}
+	}
+	for i := 0; i < cfbDIFATInHeader; i++ {
+		binary.LittleEndian.PutUint32(header[76+i*4:], difat[i])
+	}
+
+	out := header
+	out = append(out, uint32Bytes(fat)...)
+
+	//Сектора продолжения списка секторов таблицы размещения: 127 номеров и номер следующего сектора
+	for i := 0; i < difatSectors; i++ {
+		entries := difat[cfbDIFATInHeader+i*(cfbSectorSize/4-1) : cfbDIFATInHeader+(i+1)*(cfbSectorSize/4-1)]
+		following := uint32(cfbEndOfChain)
+		if i < difatSectors-1 {
+			following = firstDIFAT + uint32(i+1)
+		}
+		out = append(out, uint32Bytes(append(append([]uint32{}, entries...), following))...)
+	}
+
+	//Каталог составного файла
+	var records []byte
+	for _, current := range directory {
+		records = append(records, cfbRecord(current)...)
+	}
+	for len(records)%cfbSectorSize != 0 {
+		records = append(records, cfbRecord(&cfbDirectory{entry: &cfbEntry{}, left: cfbNoStream,
+			right: cfbNoStream, child: cfbNoStream})...)
+	}
+	out = append(out, records...)
+
+	for len(miniFAT)%(cfbSectorSize/4) != 0 {
+		miniFAT = append(miniFAT, cfbFreeSector)
+	}
+	out = append(out, uint32Bytes(miniFAT)...)
+	out = append(out, padBytes(miniStream, cfbSectorSize)...)
+	for _, current := range large {
+		out = append(out, padBytes(current.entry.data, cfbSectorSize)...)
+	}
+
+	return out
+}
+
+// sortedCFBEntries Вспомогательная функция, упорядочивающая элементы хранилища так, как этого требует формат: по длине
+// названия, затем по названию в верхнем регистре
+func sortedCFBEntries(entries []*cfbEntry) []*cfbEntry {
+	sorted := append([]*cfbEntry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		first, second := utf16.Encode([]rune(sorted[i].name)), utf16.Encode([]rune(sorted[j].name))
+		if len(first) != len(second) {
+			return len(first) < len(second)
+		}
+		return strings.ToUpper(sorted[i].name) < strings.ToUpper(sorted[j].name)
+	})
+
+	return sorted
+}
+
+// cfbTree Вспомогательная функция, связывающая упорядоченные элементы хранилища в сбалансированное дерево и
+// возвращающая номер его корня
+func cfbTree(directory []*cfbDirectory, ids []uint32) uint32 {
+	if len(ids) == 0 {
+		return cfbNoStream
+	}
+
+	middle := len(ids) / 2
+	root := directory[ids[middle]]
+	root.left, root.right = cfbTree(directory, ids[:middle]), cfbTree(directory, ids[middle+1:])
+	if !root.entry.storage {
+		root.child = cfbNoStream
+	}
+
+	return ids[middle]
+}
+
+// cfbRecord Вспомогательная функция, записывающая запись каталога составного файла (128 байт)
+func cfbRecord(current *cfbDirectory) []byte {
+	record := make([]byte, 128)
+	name := utf16.Encode([]rune(current.entry.name))
+	for i, unit := range name {
+		binary.LittleEndian.PutUint16(record[i*2:], unit)
+	}
+	if current.kind != 0 {
+		binary.LittleEndian.PutUint16(record[64:], uint16((len(name)+1)*2))
+		record[67] = 1
+	}
+	record[66] = current.kind
+	binary.LittleEndian.PutUint32(record[68:], current.left)
+	binary.LittleEndian.PutUint32(record[72:], current.right)
+	binary.LittleEndian.PutUint32(record[76:], current.child)
+	binary.LittleEndian.PutUint32(record[116:], current.start)
+	binary.LittleEndian.PutUint32(record[120:], uint32(current.size))
+
+	return record
+}
+
+// uint32Bytes Вспомогательная функция, записывающая числа в байты в порядке little-endian
+func uint32Bytes(values []uint32) []byte {
+	result := make([]byte, len(values)*4)
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(result[i*4:], value)
+	}
+
+	return result
+}
+
+// padBytes Вспомогательная функция, дополняющая данные нулями до размера, кратного size
+func padBytes(data []byte, size int) []byte {
+	if len(data)%size == 0 {
+		return data
+	}
+
+	return append(append([]byte{}, data...), make([]byte, size-len(data)%size)...)
+}
@@ -0,0 +1,198 @@
+package report
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+/*====================================================================================================================*/
+
+// XLSXPassword Пароль, которым шифруется книга .xlsx (xlsx_password в секции [report] cfg.ini). При пустом пароле
+// книга не шифруется
+var XLSXPassword string
+
+// Параметры шифрования книги (ECMA-376 Agile Encryption, AES-256 и SHA-512, как в MS Office 2013 и новее)
+const (
+	encryptionSpinCount   = 100000
+	encryptionSegmentSize = 4096
+	encryptionKeyBytes    = 32
+	encryptionSaltBytes   = 16
+)
+
+// Ключи блоков, из которых вместе с хешем пароля получаются ключи шифрования
+var (
+	verifierInputBlockKey = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	verifierValueBlockKey = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	keyValueBlockKey      = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+	hmacKeyBlockKey       = []byte{0x5f, 0xb2, 0xad, 0x01, 0x0c, 0xb9, 0xe1, 0xf6}
+	hmacValueBlockKey     = []byte{0xa0, 0x67, 0x7f, 0x02, 0xb2, 0x2c, 0x84, 0x33}
+)
+
+// encryptionInfoXML Описание шифрования книги в потоке EncryptionInfo
+const encryptionInfoXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\r\n" +
+	`<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" ` +
+	`xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password">` +
+	`<keyData saltSize="16" blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" ` +
+	`cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s"/>` +
+	`<dataIntegrity encryptedHmacKey="%s" encryptedHmacValue="%s"/>` +
+	`<keyEncryptors><keyEncryptor uri="http://schemas.microsoft.com/office/2006/keyEncryptor/password">` +
+	`<p:encryptedKey spinCount="100000" saltSize="16" blockSize="16" keyBits="256" hashSize="64" ` +
+	`cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s" ` +
+	`encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/>` +
+	`</keyEncryptor></keyEncryptors></encryption>`
+
+/*====================================================================================================================*/
+
+// EncryptXLSX Функция, шифрующая книгу .xlsx паролем так, как это делает MS Office: книга помещается в составной файл
+// с потоками EncryptionInfo и EncryptedPackage. Такую книгу Excel и LibreOffice открывают только после ввода пароля
+func EncryptXLSX(workbook []byte, password string) ([]byte, error) {
+	random := make([]byte, encryptionSaltBytes*3+encryptionKeyBytes+sha512.Size)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("ошибка получения случайных данных для шифрования: %w", err)
+	}
+	keyDataSalt, passwordSalt := random[:16], random[16:32]
+	verifierInput, secretKey := random[32:48], random[48:80]
+	hmacSalt := random[80:]
+
+	//Хеш пароля многократно хешируется, чтобы подбор пароля был медленным
+	hash := sha512.Sum512(append(append([]byte{}, passwordSalt...), utf16LE(password)...))
+	iteration := make([]byte, 4)
+	for i := 0; i < encryptionSpinCount; i++ {
+		binary.LittleEndian.PutUint32(iteration, uint32(i))
+		hash = sha512.Sum512(append(append([]byte{}, iteration...), hash[:]...))
+	}
+	passwordKey := func(blockKey []byte) []byte {
+		key := sha512.Sum512(append(append([]byte{}, hash[:]...), blockKey...))
+		return key[:encryptionKeyBytes]
+	}
+
+	//Проверочные данные пароля и ключ шифрования книги, зашифрованный ключом из пароля
+	verifierHash := sha512.Sum512(verifierInput)
+	encryptedVerifierInput, err := encryptAES(passwordKey(verifierInputBlockKey), passwordSalt, verifierInput)
+	if err != nil {
+		return nil, err
+	}
+	encryptedVerifierHash, err := encryptAES(passwordKey(verifierValueBlockKey), passwordSalt, verifierHash[:])
+	if err != nil {
+		return nil, err
+	}
+	encryptedKey, err := encryptAES(passwordKey(keyValueBlockKey), passwordSalt, secretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	//Книга шифруется частями по 4096 байт, перед ними записывается размер книги
+	encryptedPackage := binary.LittleEndian.AppendUint64(nil, uint64(len(workbook)))
+	for i := 0; i*encryptionSegmentSize < len(workbook); i++ {
+		segment := workbook[i*encryptionSegmentSize : min((i+1)*encryptionSegmentSize, len(workbook))]
+		encrypted, err := encryptAES(secretKey, blockIV(keyDataSalt, binary.LittleEndian.AppendUint32(nil, uint32(i))),
+			segment)
+		if err != nil {
+			return nil, err
+		}
+		encryptedPackage = append(encryptedPackage, encrypted...)
+	}
+
+	//Подпись зашифрованной книги, по которой проверяется, что книга не изменена
+	mac := hmac.New(sha512.New, hmacSalt)
+	mac.Write(encryptedPackage)
+	encryptedHMACKey, err := encryptAES(secretKey, blockIV(keyDataSalt, hmacKeyBlockKey), hmacSalt)
+	if err != nil {
+		return nil, err
+	}
+	encryptedHMACValue, err := encryptAES(secretKey, blockIV(keyDataSalt, hmacValueBlockKey), mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	encode := base64.StdEncoding.EncodeToString
+	info := binary.LittleEndian.AppendUint16(nil, 4)
+	info = binary.LittleEndian.AppendUint16(info, 4)
+	info = binary.LittleEndian.AppendUint32(info, 0x40)
+	info = append(info, fmt.Sprintf(encryptionInfoXML, encode(keyDataSalt), encode(encryptedHMACKey),
+		encode(encryptedHMACValue), encode(passwordSalt), encode(encryptedVerifierInput),
+		encode(encryptedVerifierHash), encode(encryptedKey))...)
+
+	return writeCompoundFile([]*cfbEntry{
+		{name: "EncryptionInfo", data: info},
+		{name: "EncryptedPackage", data: encryptedPackage},
+		dataSpaces(),
+	}), nil
+}
+
+// dataSpaces Вспомогательная функция, возвращающая хранилище \x06DataSpaces составного файла, в котором указано, что
+// поток EncryptedPackage зашифрован
+func dataSpaces() *cfbEntry {
+	//Версии 1.0 записываются парами 16-битных чисел, то есть числом 1
+	version := append(lengthPrefixed("Microsoft.Container.DataSpaces"), uint32Bytes([]uint32{1, 1, 1})...)
+
+	entry := append(uint32Bytes([]uint32{1, 0}), lengthPrefixed("EncryptedPackage")...)
+	entry = append(entry, lengthPrefixed("StrongEncryptionDataSpace")...)
+	dataSpaceMap := append(uint32Bytes([]uint32{8, 1, uint32(len(entry) + 4)}), entry...)
+
+	dataSpaceInfo := append(uint32Bytes([]uint32{8, 1}), lengthPrefixed("StrongEncryptionTransform")...)
+
+	transformID := lengthPrefixed("{FF9A3F03-56EF-4613-BDD5-5A41C1D07246}")
+	primary := append(uint32Bytes([]uint32{uint32(8 + len(transformID)), 1}), transformID...)
+	primary = append(primary, lengthPrefixed("Microsoft.Container.EncryptionTransform")...)
+	primary = append(primary, uint32Bytes([]uint32{1, 1, 1, 0, 0, 0, 4})...)
+
+	return &cfbEntry{name: "\x06DataSpaces", storage: true, children: []*cfbEntry{
+		{name: "Version", data: version},
+		{name: "DataSpaceMap", data: dataSpaceMap},
+		{name: "DataSpaceInfo", storage: true, children: []*cfbEntry{
+			{name: "StrongEncryptionDataSpace", data: dataSpaceInfo},
+		}},
+		{name: "TransformInfo", storage: true, children: []*cfbEntry{
+			{name: "StrongEncryptionTransform", storage: true, children: []*cfbEntry{
+				{name: "\x06Primary", data: primary},
+			}},
+		}},
+	}}
+}
+
+/*====================================================================================================================*/
+
+// encryptAES Вспомогательная функция, шифрующая данные AES-CBC, предварительно дополнив их нулями до размера блока
+func encryptAES(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования отчёта: %w", err)
+	}
+
+	result := append([]byte{}, padBytes(data, aes.BlockSize)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(result, result)
+
+	return result, nil
+}
+
+// blockIV Вспомогательная функция, возвращающая вектор инициализации AES из соли и ключа блока
+func blockIV(salt, blockKey []byte) []byte {
+	hash := sha512.Sum512(append(append([]byte{}, salt...), blockKey...))
+	return hash[:aes.BlockSize]
+}
+
+// utf16LE Вспомогательная функция, записывающая строку в кодировке UTF-16LE
+func utf16LE(text string) []byte {
+	var buffer bytes.Buffer
+	for _, unit := range utf16.Encode([]rune(text)) {
+		buffer.Write(binary.LittleEndian.AppendUint16(nil, unit))
+	}
+
+	return buffer.Bytes()
+}
+
+// lengthPrefixed Вспомогательная функция, записывающая строку UTF-16LE с длиной в байтах перед ней и дополнением
+// нулями до 4 байт
+func lengthPrefixed(text string) []byte {
+	encoded := utf16LE(text)
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(encoded))), padBytes(encoded, 4)...)
+}
@@ -0,0 +1,376 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"testing"
+	"unicode/utf16"
+)
+
+/*====================================================================================================================*/
+
+// Ключи блоков ECMA-376 Agile Encryption (MS-OFFCRYPTO 2.3.4.11-2.3.4.14), заданные в тесте независимо от шифрования
+var (
+	testVerifierInputBlockKey = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	testVerifierValueBlockKey = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	testKeyValueBlockKey      = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+	testHMACKeyBlockKey       = []byte{0x5f, 0xb2, 0xad, 0x01, 0x0c, 0xb9, 0xe1, 0xf6}
+	testHMACValueBlockKey     = []byte{0xa0, 0x67, 0x7f, 0x02, 0xb2, 0x2c, 0x84, 0x33}
+)
+
+// testEncryption Структура описания шифрования из потока EncryptionInfo
+type testEncryption struct {
+	KeyData struct {
+		SaltValue string `xml:"saltValue,attr"`
+	} `xml:"keyData"`
+	DataIntegrity struct {
+		EncryptedHMACKey   string `xml:"encryptedHmacKey,attr"`
+		EncryptedHMACValue string `xml:"encryptedHmacValue,attr"`
+	} `xml:"dataIntegrity"`
+	EncryptedKey struct {
+		SpinCount                  int    `xml:"spinCount,attr"`
+		SaltValue                  string `xml:"saltValue,attr"`
+		EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+		EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+		EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+	} `xml:"keyEncryptors>keyEncryptor>encryptedKey"`
+}
+
+/*====================================================================================================================*/
+
+// TestEncryptXLSX Проверка шифрования книги: составной файл разбирается, ключ получается из пароля, проверяются хеш
+// проверочных данных и подпись HMAC, и книга расшифровывается обратно в исходный архив. Книги меньше и больше 4096
+// байт проверяют мини-поток и шифрование частями, а книга больше 7 МБ - продолжение списка секторов таблицы
+// размещения (DIFAT)
+func TestEncryptXLSX(t *testing.T) {
+	var report bytes.Buffer
+	if err := WriteXLSX(&report, []Sheet{{Name: "Отчёт", Rows: [][]string{{"ФИО", "Присутствие"},
+		{"Иванов Иван Иванович", "Присутствовал"}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		workbook []byte
+		difat    bool
+	}{
+		{"итоговый отчёт", report.Bytes(), false},
+		{"книга больше 4096 байт", testArchive(t, 3*encryptionSegmentSize+100), false},
+		{"книга больше 7 МБ", testArchive(t, 8<<20), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encrypted, err := EncryptXLSX(test.workbook, "пароль 1")
+			if err != nil {
+				t.Fatalf("ошибка шифрования книги: %v", err)
+			}
+			if difat := binary.LittleEndian.Uint32(encrypted[72:]) > 0; difat != test.difat {
+				t.Errorf("продолжение списка секторов таблицы размещения: %v, ожидалось %v", difat, test.difat)
+			}
+
+			decrypted, err := testDecrypt(encrypted, "пароль 1")
+			if err != nil {
+				t.Fatalf("ошибка расшифровки книги: %v", err)
+			}
+			if !bytes.Equal(decrypted, test.workbook) {
+				t.Fatalf("расшифрованная книга (%d байт) не совпадает с исходной (%d байт)", len(decrypted),
+					len(test.workbook))
+			}
+			if _, err := zip.NewReader(bytes.NewReader(decrypted), int64(len(decrypted))); err != nil {
+				t.Errorf("расшифрованная книга не открывается как архив: %v", err)
+			}
+
+			if _, err := testDecrypt(encrypted, "пароль 2"); err == nil {
+				t.Errorf("книга расшифрована неверным паролем")
+			}
+		})
+	}
+}
+
+/*====================================================================================================================*/
+
+// testArchive Вспомогательная функция, возвращающая архив не меньше size байт со случайным несжатым содержимым
+func testArchive(t *testing.T, size int) []byte {
+	t.Helper()
+
+	content := make([]byte, size)
+	if _, err := rand.Read(content); err != nil {
+		t.Fatal(err)
+	}
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	file, err := writer.CreateHeader(&zip.FileHeader{Name: "xl/media/image1.bin", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return archive.Bytes()
+}
+
+// testDecrypt Вспомогательная функция, расшифровывающая книгу по MS-OFFCRYPTO: потоки составного файла, ключ из
+// пароля, проверочные данные, подпись HMAC и части книги
+func testDecrypt(file []byte, password string) ([]byte, error) {
+	streams, err := testReadCompoundFile(file)
+	if err != nil {
+		return nil, err
+	}
+	info, pack := streams["EncryptionInfo"], streams["EncryptedPackage"]
+	if len(info) < 8 || binary.LittleEndian.Uint16(info) != 4 || binary.LittleEndian.Uint16(info[2:]) != 4 {
+		return nil, fmt.Errorf("поток EncryptionInfo не описывает шифрование Agile")
+	}
+	if _, ok := streams["\x06DataSpaces/TransformInfo/StrongEncryptionTransform/\x06Primary"]; !ok {
+		return nil, fmt.Errorf("в составном файле нет описания преобразования \\x06Primary")
+	}
+
+	var encryption testEncryption
+	if err := xml.Unmarshal(info[8:], &encryption); err != nil {
+		return nil, err
+	}
+	decode := func(value string) []byte {
+		decoded, _ := base64.StdEncoding.DecodeString(value)
+		return decoded
+	}
+	keyDataSalt, passwordSalt := decode(encryption.KeyData.SaltValue), decode(encryption.EncryptedKey.SaltValue)
+
+	//Ключ из пароля: хеш соли и пароля, многократно хешированный с номером итерации, и хеш с ключом блока
+	hash := sha512.Sum512(append(append([]byte{}, passwordSalt...), testUTF16LE(password)...))
+	for i := 0; i < encryption.EncryptedKey.SpinCount; i++ {
+		hash = sha512.Sum512(append(binary.LittleEndian.AppendUint32(nil, uint32(i)), hash[:]...))
+	}
+	passwordKey := func(blockKey []byte) []byte {
+		key := sha512.Sum512(append(append([]byte{}, hash[:]...), blockKey...))
+		return key[:32]
+	}
+
+	verifierInput, err := testDecryptAES(passwordKey(testVerifierInputBlockKey), passwordSalt,
+		decode(encryption.EncryptedKey.EncryptedVerifierHashInput))
+	if err != nil {
+		return nil, err
+	}
+	verifierHash, err := testDecryptAES(passwordKey(testVerifierValueBlockKey), passwordSalt,
+		decode(encryption.EncryptedKey.EncryptedVerifierHashValue))
+	if err != nil {
+		return nil, err
+	}
+	expected := sha512.Sum512(verifierInput[:16])
+	if !bytes.Equal(verifierHash[:sha512.Size], expected[:]) {
+		return nil, fmt.Errorf("неверный пароль: хеш проверочных данных не совпадает")
+	}
+
+	secretKey, err := testDecryptAES(passwordKey(testKeyValueBlockKey), passwordSalt,
+		decode(encryption.EncryptedKey.EncryptedKeyValue))
+	if err != nil {
+		return nil, err
+	}
+	secretKey = secretKey[:32]
+	iv := func(blockKey []byte) []byte {
+		hash := sha512.Sum512(append(append([]byte{}, keyDataSalt...), blockKey...))
+		return hash[:aes.BlockSize]
+	}
+
+	//Подпись HMAC всего потока EncryptedPackage
+	hmacKey, err := testDecryptAES(secretKey, iv(testHMACKeyBlockKey),
+		decode(encryption.DataIntegrity.EncryptedHMACKey))
+	if err != nil {
+		return nil, err
+	}
+	hmacValue, err := testDecryptAES(secretKey, iv(testHMACValueBlockKey),
+		decode(encryption.DataIntegrity.EncryptedHMACValue))
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha512.New, hmacKey[:sha512.Size])
+	mac.Write(pack)
+	if !hmac.Equal(mac.Sum(nil), hmacValue[:sha512.Size]) {
+		return nil, fmt.Errorf("подпись HMAC зашифрованной книги не совпадает")
+	}
+
+	//Книга расшифровывается частями по 4096 байт, вектор инициализации части - хеш соли и номера части
+	size := binary.LittleEndian.Uint64(pack)
+	var workbook []byte
+	for i, data := 0, pack[8:]; len(data) > 0; i++ {
+		segment := data[:min(4096, len(data))]
+		data = data[len(segment):]
+		decrypted, err := testDecryptAES(secretKey, iv(binary.LittleEndian.AppendUint32(nil, uint32(i))), segment)
+		if err != nil {
+			return nil, err
+		}
+		workbook = append(workbook, decrypted...)
+	}
+	if uint64(len(workbook)) < size {
+		return nil, fmt.Errorf("расшифровано %d байт, в заголовке указано %d", len(workbook), size)
+	}
+
+	return workbook[:size], nil
+}
+
+// testReadCompoundFile Вспомогательная функция, читающая потоки составного файла версии 3 по таблицам размещения
+// (с продолжением списка секторов DIFAT) и дереву каталога. Ключ - путь потока через "/"
+func testReadCompoundFile(file []byte) (map[string][]byte, error) {
+	if len(file) < 512 || !bytes.Equal(file[:8], []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}) {
+		return nil, fmt.Errorf("файл не является составным файлом")
+	}
+	le32 := binary.LittleEndian.Uint32
+	sector := func(number uint32) ([]byte, error) {
+		offset := 512 * (int(number) + 1)
+		if offset+512 > len(file) {
+			return nil, fmt.Errorf("сектор %d за пределами файла", number)
+		}
+		return file[offset : offset+512], nil
+	}
+
+	//Список секторов таблицы размещения: 109 номеров в заголовке, остальные - в цепочке секторов DIFAT
+	fatCount := int(le32(file[44:]))
+	var fatSectors []uint32
+	for i := 0; i < 109; i++ {
+		fatSectors = append(fatSectors, le32(file[76+i*4:]))
+	}
+	for next, count := le32(file[68:]), le32(file[72:]); count > 0; count-- {
+		data, err := sector(next)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < 127; i++ {
+			fatSectors = append(fatSectors, le32(data[i*4:]))
+		}
+		next = le32(data[508:])
+	}
+	var fat []uint32
+	for _, number := range fatSectors[:fatCount] {
+		data, err := sector(number)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < 128; i++ {
+			fat = append(fat, le32(data[i*4:]))
+		}
+	}
+	readChain := func(table []uint32, start uint32, read func(uint32) ([]byte, error)) ([]byte, error) {
+		var result []byte
+		for number, steps := start, 0; number != 0xFFFFFFFE; number, steps = table[number], steps+1 {
+			if int(number) >= len(table) || steps > len(table) {
+				return nil, fmt.Errorf("некорректная цепочка секторов")
+			}
+			data, err := read(number)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, data...)
+		}
+		return result, nil
+	}
+
+	directory, err := readChain(fat, le32(file[48:]), sector)
+	if err != nil {
+		return nil, err
+	}
+	record := func(id uint32) []byte { return directory[id*128 : (id+1)*128] }
+	miniFAT := []uint32{}
+	if le32(file[64:]) > 0 {
+		data, err := readChain(fat, le32(file[60:]), sector)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(data); i += 4 {
+			miniFAT = append(miniFAT, le32(data[i:]))
+		}
+	}
+	miniStream, err := readChain(fat, le32(record(0)[116:]), sector)
+	if err != nil {
+		return nil, err
+	}
+	miniSector := func(number uint32) ([]byte, error) {
+		if int(number+1)*64 > len(miniStream) {
+			return nil, fmt.Errorf("мини-сектор %d за пределами мини-потока", number)
+		}
+		return miniStream[number*64 : (number+1)*64], nil
+	}
+
+	//Обход дерева каталога: левый и правый соседи и вложенные элементы хранилищ
+	streams := make(map[string][]byte)
+	var walk func(id uint32, prefix string) error
+	walk = func(id uint32, prefix string) error {
+		if id == 0xFFFFFFFF {
+			return nil
+		}
+		current := record(id)
+		units := make([]uint16, 0, 32)
+		for i := 0; i+2 < int(binary.LittleEndian.Uint16(current[64:])); i += 2 {
+			units = append(units, binary.LittleEndian.Uint16(current[i:]))
+		}
+		name := prefix + string(utf16.Decode(units))
+		if err := walk(le32(current[68:]), prefix); err != nil {
+			return err
+		}
+		if err := walk(le32(current[72:]), prefix); err != nil {
+			return err
+		}
+
+		switch current[66] {
+		case 1:
+			return walk(le32(current[76:]), name+"/")
+		case 2:
+			size := int(le32(current[120:]))
+			var data []byte
+			var err error
+			switch {
+			case size == 0:
+			case size < 4096:
+				data, err = readChain(miniFAT, le32(current[116:]), miniSector)
+			default:
+				data, err = readChain(fat, le32(current[116:]), sector)
+			}
+			if err != nil {
+				return err
+			}
+			if len(data) < size {
+				return fmt.Errorf("поток %v короче указанного размера", name)
+			}
+			streams[name] = data[:size]
+		}
+		return nil
+	}
+
+	return streams, walk(le32(record(0)[76:]), "")
+}
+
+// testDecryptAES Вспомогательная функция, расшифровывающая данные AES-CBC
+func testDecryptAES(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("размер зашифрованных данных не кратен размеру блока")
+	}
+	result := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(result, data)
+
+	return result, nil
+}
+
+// testUTF16LE Вспомогательная функция, записывающая строку в кодировке UTF-16LE
+func testUTF16LE(text string) []byte {
+	var result []byte
+	for _, unit := range utf16.Encode([]rune(text)) {
+		result = binary.LittleEndian.AppendUint16(result, unit)
+	}
+
+	return result
+}
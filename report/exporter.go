@@ -0,0 +1,124 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/*====================================================================================================================*/
+
+// Exporter Интерфейс формата итогового отчёта. Форматы регистрируются функцией RegisterExporter() и выбираются в
+// cfg.ini (formats в секции [report]), поэтому новый формат добавляется без изменения обработки отчётов
+type Exporter interface {
+	//Полный путь до отчёта собрания в этом формате
+	Path(header Header) string
+	//Запись отчёта собрания: оглавление, участники собрания и гости, выводимые отдельным списком
+	Write(ctx context.Context, header Header, members, guests []Member) error
+}
+
+// ExporterFactory Функция, создающая формат итогового отчёта для каталога итоговых отчётов reportLocationPath
+type ExporterFactory func(reportLocationPath string) Exporter
+
+// formExporter Структура формата, записывающего отчёт функцией вида FormReport() по пути функции вида Path()
+type formExporter struct {
+	path               func(header Header, reportLocationPath string) string
+	form               func(ctx context.Context, header Header, members, guests []Member, reportLocationPath string) error
+	reportLocationPath string
+}
+
+// Зарегистрированные форматы итогового отчёта
+var (
+	exportersMutex sync.RWMutex
+	exporters      = map[string]ExporterFactory{
+		FormatCSV:      formExporterFactory(Path, FormReport),
+		FormatXLSX:     formExporterFactory(XLSXPath, FormXLSXReport),
+		FormatJSON:     formExporterFactory(JSONPath, FormJSONReport),
+		FormatHTML:     formExporterFactory(HTMLPath, FormHTMLReport),
+		FormatTemplate: formExporterFactory(TemplatePath, FormTemplateReport),
+	}
+)
+
+/*====================================================================================================================*/
+
+// RegisterExporter Функция, регистрирующая формат итогового отчёта под названием name (без учёта регистра), которое
+// указывается в formats секции [report] cfg.ini. Формат с уже зарегистрированным названием заменяется
+func RegisterExporter(name string, factory ExporterFactory) {
+	exportersMutex.Lock()
+	defer exportersMutex.Unlock()
+	exporters[strings.ToLower(strings.TrimSpace(name))] = factory
+}
+
+// Exporters Функция, возвращающая форматы итогового отчёта с названиями formats в том же порядке
+func Exporters(formats []string, reportLocationPath string) ([]Exporter, error) {
+	exportersMutex.RLock()
+	defer exportersMutex.RUnlock()
+
+	result := make([]Exporter, 0, len(formats))
+	for _, format := range formats {
+		factory, ok := exporters[format]
+		if !ok {
+			return nil, unknownFormat(format)
+		}
+		result = append(result, factory(reportLocationPath))
+	}
+
+	return result, nil
+}
+
+// ParseFormats Функция, проверяющая список форматов итогового отчёта через запятую. Повторы пропускаются, по-умолчанию
+// отчёт формируется в виде .csv файла
+func ParseFormats(source string) ([]string, error) {
+	var formats []string
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(source, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		format, err := ParseFormat(part)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[format] {
+			seen[format] = true
+			formats = append(formats, format)
+		}
+	}
+	if len(formats) == 0 {
+		formats = []string{FormatCSV}
+	}
+
+	return formats, nil
+}
+
+// formExporterFactory Вспомогательная функция, создающая встроенный формат из функций пути и формирования отчёта
+func formExporterFactory(path func(Header, string) string,
+	form func(context.Context, Header, []Member, []Member, string) error) ExporterFactory {
+	return func(reportLocationPath string) Exporter {
+		return formExporter{path, form, reportLocationPath}
+	}
+}
+
+// Path Метод, возвращающий полный путь до отчёта собрания в формате встроенного формата
+func (exporter formExporter) Path(header Header) string {
+	return exporter.path(header, exporter.reportLocationPath)
+}
+
+// Write Метод, формирующий отчёт собрания во встроенном формате
+func (exporter formExporter) Write(ctx context.Context, header Header, members, guests []Member) error {
+	return exporter.form(ctx, header, members, guests, exporter.reportLocationPath)
+}
+
+// unknownFormat Вспомогательная функция, возвращающая ошибку неизвестного формата со списком допустимых форматов
+func unknownFormat(format string) error {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return fmt.Errorf("неизвестный формат отчёта: %v (допустимы %v)", format, strings.Join(names, ", "))
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Способы обработки уже сформированного отчёта того же собрания (например, при повторном запуске программы)
+const (
+	//Отчёт перезаписывается
+	ExistingOverwrite = "overwrite"
+	//Собрание пропускается с сообщением, отчёт не формируется
+	ExistingSkip = "skip"
+	//Отчёт формируется в файле с номером версии: "_v2", "_v3" и т.д.
+	ExistingVersion = "version"
+)
+
+// illegalFileChars Символы, недопустимые в названиях файлов Windows (и "/" - в названиях файлов Linux и macOS)
+const illegalFileChars = `<>:"/\|?*`
+
+/*====================================================================================================================*/
+
+// ParseExistingPolicy Функция, проверяющая способ обработки уже сформированного отчёта собрания из файла конфигураций.
+// По-умолчанию отчёт перезаписывается
+func ParseExistingPolicy(source string) (string, error) {
+	switch policy := strings.ToLower(strings.TrimSpace(source)); policy {
+	case "":
+		return ExistingOverwrite, nil
+	case ExistingOverwrite, ExistingSkip, ExistingVersion:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("неизвестный способ обработки существующего отчёта: %v (допустимы overwrite, skip, "+
+			"version)", source)
+	}
+}
+
+// FileName Функция, возвращающая часть названия файлов отчёта, общую для всех файлов собрания: название и дата
+// собрания, номер версии и пометки сокращённого отчёта. Символы, недопустимые в названиях файлов, заменяются на "_"
+func (header Header) FileName() string {
+	name := header.Title + "_" + header.Date
+	if header.Version > 1 {
+		name += fmt.Sprintf("_v%d", header.Version)
+	}
+	if len(header.Only) > 0 {
+		name += "_" + header.Only.FileLabel()
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7F || strings.ContainsRune(illegalFileChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+
+	//Windows не допускает пробелы и точки в конце названия файла
+	return strings.TrimRight(name, " .")
+}
+
+// ResolveExisting Функция, проверяющая, сформирован ли уже отчёт собрания по пути, который возвращает функция path.
+// Для способа version подбирает первый свободный номер версии отчёта. Возвращает true, если отчёт существует и
+// собрание нужно пропустить (способ skip)
+func ResolveExisting(header *Header, policy string, path func(Header) string) bool {
+	exists := func() bool {
+		_, err := os.Stat(path(*header))
+		return err == nil
+	}
+
+	switch policy {
+	case ExistingSkip:
+		return exists()
+	case ExistingVersion:
+		header.Version = 1
+		for exists() {
+			header.Version++
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"fmt"
+	"mod.go/i18n"
+	"slices"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Пометки участников, по которым отбираются строки сокращённого отчёта (флаг --only командной строки)
+const (
+	//Студент отсутствовал без уважительной причины
+	StatusAbsent = "absent"
+	//Студент отсутствовал по уважительной причине
+	StatusExcused = "excused"
+	//Участник опоздал на пару
+	StatusLate = "late"
+	//Участник присутствовал на паре не полностью
+	StatusPartial = "partial"
+	//Участник ушёл раньше окончания пары
+	StatusEarly = "early"
+	//Участник присутствовал на паре полностью
+	StatusPresent = "present"
+)
+
+// statusLabels Подписи пометок сокращённого отчёта, добавляемые к названию его файлов, в порядке проверки
+var statusLabels = [][2]string{
+	{StatusAbsent, "отсутствующие"},
+	{StatusExcused, "уважительные"},
+	{StatusLate, "опоздавшие"},
+	{StatusPartial, "неполные"},
+	{StatusEarly, "ушедшие"},
+	{StatusPresent, "присутствующие"},
+}
+
+// StatusFilter Пометки участников, которые выводятся в сокращённом отчёте. Пустой список - отчёт не сокращается
+type StatusFilter []string
+
+/*====================================================================================================================*/
+
+// ParseStatusFilter Функция, проверяющая список пометок через запятую (например, "absent,late"). Повторы пропускаются
+func ParseStatusFilter(source string) (StatusFilter, error) {
+	var filter StatusFilter
+
+	for _, part := range strings.Split(source, ",") {
+		status := strings.ToLower(strings.TrimSpace(part))
+		if status == "" || slices.Contains(filter, status) {
+			continue
+		}
+		known := false
+		for _, label := range statusLabels {
+			known = known || label[0] == status
+		}
+		if !known {
+			return nil, fmt.Errorf("неизвестная пометка участника: %v (допустимы absent, excused, late, partial, "+
+				"early, present)", part)
+		}
+		filter = append(filter, status)
+	}
+
+	return filter, nil
+}
+
+// Match Функция, проверяющая, есть ли у участника хотя бы одна из пометок сокращённого отчёта
+func (filter StatusFilter) Match(member Member) bool {
+	for _, status := range filter {
+		switch {
+		case status == StatusAbsent && member.Presence.IsAbsent(),
+			status == StatusExcused && member.Presence.IsExcused(),
+			status == StatusLate && member.Delay == DelayLate,
+			status == StatusPartial && member.Presence == PresencePartial,
+			status == StatusEarly && member.EarlyExit.Status == ExitEarly,
+			status == StatusPresent && member.Presence == PresenceFull:
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply Функция, возвращающая участников с пометками сокращённого отчёта в том же порядке
+func (filter StatusFilter) Apply(members []Member) []Member {
+	result := make([]Member, 0, len(members))
+	for _, member := range members {
+		if filter.Match(member) {
+			result = append(result, member)
+		}
+	}
+
+	return result
+}
+
+// FileLabel Функция, возвращающая подпись пометок сокращённого отчёта на выбранном языке для названия его файлов
+// ("отсутствующие-опоздавшие")
+func (filter StatusFilter) FileLabel() string {
+	labels := make([]string, 0, len(filter))
+	for _, label := range statusLabels {
+		if slices.Contains(filter, label[0]) {
+			labels = append(labels, i18n.T(label[1]))
+		}
+	}
+
+	return strings.Join(labels, "-")
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"mod.go/i18n"
+	"os"
+)
+
+/*====================================================================================================================*/
+
+// htmlReport Шаблон отчёта о посещаемости в виде .html страницы с сортируемой и фильтруемой таблицей участников
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{"t": i18n.T}).Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Header.Title}} {{.Header.Date}}</title>
+<style>
+body { font-family: "Segoe UI", Arial, sans-serif; margin: 12px; color: #333; }
+h1 { font-size: 1.3em; margin: 0 0 4px; }
+.meta { color: #666; margin-bottom: 12px; }
+.summary { display: flex; flex-wrap: wrap; gap: 8px; margin-bottom: 12px; }
+.summary div { padding: 6px 12px; border-radius: 6px; color: #fff; }
+.present { background: #2e7d32; } .late { background: #f9a825; } .absent { background: #c62828; }
+input { width: 100%; box-sizing: border-box; padding: 8px; margin-bottom: 8px; font-size: 1em; }
+table { width: 100%; border-collapse: collapse; font-size: 0.95em; }
+th, td { padding: 6px; border-bottom: 1px solid #ddd; text-align: left; }
+th { cursor: pointer; background: #f5f5f5; position: sticky; top: 0; }
+tr.ok { background: #e8f5e9; } tr.missed { background: #ffebee; }
+</style>
+</head>
+<body>
+<h1>{{.Header.Title}}</h1>{{if .Header.Course}}
+<div class="meta">{{t "Дисциплина"}}: {{.Header.Course}}</div>{{end}}
+<div class="meta">{{.Header.Date}}, {{.Header.LessonLabel}}{{if .Header.Lecturer}}, {{t "преподаватель"}}: {{.Header.Lecturer}}{{end}}{{if .Header.Held}}, {{t "время собрания"}}: {{.Header.Held}}{{end}}{{if .Header.DurationLabel}} ({{.Header.DurationLabel}}){{end}}{{if .Header.Planned}}, {{t "по календарю"}}: {{.Header.Planned}}{{end}}{{if .Header.Quorum.Checked}}, {{t "кворум"}}: {{.Header.Quorum}}{{end}}</div>
+<div class="summary">
+<div class="present">{{t "Присутствовали"}}: {{.Summary.Present}}</div>
+<div class="late">{{t "Опоздали"}}: {{.Summary.Late}}</div>
+<div class="absent">{{t "Отсутствовали"}}: {{.Summary.Absent}}</div>
+</div>
+{{if .Header.Groups}}<h1>{{t "Сравнение групп"}}</h1>
+<table>
+<thead><tr><th>{{t "Группа"}}</th><th>{{t "Присутствовали"}}</th><th>{{t "Студентов"}}</th><th>{{t "Посещаемость, %"}}</th></tr></thead>
+<tbody>
+{{range .Header.Groups}}<tr><td>{{t .Group}}</td><td>{{.Present}}</td><td>{{.Expected}}</td><td>{{.Percent}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{if .Header.Exam}}<h1>{{t "Проверка экзамена"}}</h1>
+<table>
+<thead><tr><th>{{t "ФИО"}}</th><th>{{t "Вариант"}}</th><th>{{t "Назначенное время"}}</th><th>{{t "Результат"}}</th></tr></thead>
+<tbody>
+{{range .Header.Exam}}<tr class="{{if .Mismatch}}missed{{else}}ok{{end}}"><td>{{.FullName}}</td><td>{{.Variant}}</td><td>{{.Window}}</td><td>{{.Result}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}<input id="filter" type="search" placeholder="{{t "Фильтр по группе, ФИО или отметке"}}">
+<table id="members">
+<thead><tr>{{range .Columns}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range .Members}}{{if .FullName}}<tr class="{{if .Presence.IsAbsent}}missed{{else}}ok{{end}}"><td>{{t .Group}}</td><td>{{.FullName}}</td>{{if $.RecordBooks}}<td>{{.RecordBook}}</td>{{end}}<td>{{.PresenceLabel}}</td><td>{{.DelayLabel}}</td><td>{{.EarlyExit.Label}}</td></tr>
+{{end}}{{end}}</tbody>
+</table>
+{{if .Header.Totals}}<h1>{{t "Итоги посещаемости"}}</h1>
+<table>
+<thead><tr><th>{{t "Группа"}}</th><th>{{t "Студентов"}}</th><th>{{t "Присутствовали"}}</th><th>{{t "Присутствовали не полностью"}}</th><th>{{t "Опоздали"}}</th><th>{{t "Отсутствовали"}}</th><th>{{t "Посещаемость, %"}}</th></tr></thead>
+<tbody>
+{{range .Header.Totals}}<tr><td>{{t .Group}}</td><td>{{.Invited}}</td><td>{{.Present}}</td><td>{{.Partial}}</td><td>{{.Late}}</td><td>{{.Absent}}</td><td>{{.Percent}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{if .Guests}}<h1>{{t "Гости"}}</h1>
+<table>
+<tbody>
+{{range .Guests}}<tr><td>{{t .Group}}</td><td>{{.FullName}}</td>{{if $.RecordBooks}}<td>{{.RecordBook}}</td>{{end}}<td>{{.PresenceLabel}}</td><td>{{.DelayLabel}}</td><td>{{.EarlyExit.Label}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}{{if .Header.Roster}}<div class="meta">{{t "База групп"}}: {{.Header.Roster}}</div>
+{{end}}{{if .Header.ToolVersion}}<div class="meta">{{t "Версия программы"}}: {{.Header.ToolVersion}}{{if .Header.Profile}}, {{t "профиль конфигураций"}}: {{.Header.Profile}}{{end}}{{if .Header.Generated}}, {{t "сформирован"}}: {{.Header.Generated}}{{end}}</div>
+{{end}}{{if .Header.Warnings}}<h1>{{t "Предупреждения разбора"}}</h1>
+<ul>
+{{range .Header.Warnings}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}<script>
+var table = document.getElementById("members");
+var body = table.tBodies[0];
+document.getElementById("filter").addEventListener("input", function () {
+	var query = this.value.toLowerCase();
+	Array.prototype.forEach.call(body.rows, function (row) {
+		row.style.display = row.textContent.toLowerCase().indexOf(query) === -1 ? "none" : "";
+	});
+});
+Array.prototype.forEach.call(table.tHead.rows[0].cells, function (cell, column) {
+	var ascending = true;
+	cell.addEventListener("click", function () {
+		var rows = Array.prototype.slice.call(body.rows);
+		rows.sort(function (a, b) {
+			var result = a.cells[column].textContent.localeCompare(b.cells[column].textContent, "{{.Language}}");
+			return ascending ? result : -result;
+		});
+		rows.forEach(function (row) { body.appendChild(row); });
+		ascending = !ascending;
+	});
+});
+</script>
+</body>
+</html>
+`))
+
+/*====================================================================================================================*/
+
+// HTMLPath Функция, возвращающая полный путь до отчёта в виде .html страницы, сформированного функцией FormHTMLReport()
+func HTMLPath(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Отчёт о проведение собрания_") + header.FileName() + ".html"
+}
+
+// FormHTMLReport Функция, формирующая отчёт в виде .html страницы: сводка посещаемости и таблица участников с
+// сортировкой по столбцам и фильтром, удобная для просмотра с телефона
+func FormHTMLReport(ctx context.Context, header Header, members, guests []Member, reportLocationPath string) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	//Создаём файл рядом с отчётом в виде .csv файла
+	file, err := os.Create(HTMLPath(header, reportLocationPath))
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка закрытия файла отчёта: %w", closeErr)
+		}
+	}()
+
+	//Столбец номера зачётки выводится, если номер указан хотя бы у одного участника
+	recordBooks := HasRecordBooks(members) || HasRecordBooks(guests)
+
+	data := struct {
+		Language    string
+		Columns     []string
+		RecordBooks bool
+		Header      Header
+		Summary     Summary
+		Members     []Member
+		Guests      []Member
+	}{i18n.Language, ColumnLabels.Header(false, recordBooks, false), recordBooks, header,
+		Summarize(append(append([]Member{}, members...), guests...)), members, guests}
+
+	if err := htmlReport.Execute(file, data); err != nil {
+		return fmt.Errorf("ошибка записи отчёта в виде .html страницы: %w", err)
+	}
+
+	return nil
+}
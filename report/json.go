@@ -0,0 +1,377 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"os"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Форматы итогового отчёта
+const (
+	//Отчёт в виде .csv файла для MS Excel
+	FormatCSV = "csv"
+	//Отчёт в виде книги .xlsx (листы те же, что при просмотре отчёта в формате JSON командой view)
+	FormatXLSX = "xlsx"
+	//Отчёт в формате JSON для обработки другими программами
+	FormatJSON = "json"
+	//Отчёт в виде .html страницы с сортируемой таблицей и сводкой посещаемости
+	FormatHTML = "html"
+	//Отчёт по шаблону пользователя (template_path в секции [report] cfg.ini)
+	FormatTemplate = "template"
+)
+
+// jsonTimeLayout Формат времени присоединения и выхода в отчёте в формате JSON (местное время собрания)
+const jsonTimeLayout = "2006-01-02T15:04:05"
+
+// jsonHeader Структура оглавления отчёта в формате JSON
+type jsonHeader struct {
+	Title        string       `json:"title"`
+	Course       string       `json:"course,omitempty"`
+	Date         string       `json:"date"`
+	LessonNumber string       `json:"lesson_number"`
+	Lecturer     string       `json:"lecturer,omitempty"`
+	StartTime    string       `json:"start_time,omitempty"`
+	EndTime      string       `json:"end_time,omitempty"`
+	Duration     int          `json:"duration_seconds,omitempty"`
+	Planned      string       `json:"planned,omitempty"`
+	JoinURL      string       `json:"join_url,omitempty"`
+	RecordingURL string       `json:"recording_url,omitempty"`
+	Quorum       *jsonQuorum  `json:"quorum,omitempty"`
+	Groups       []jsonGroup  `json:"groups,omitempty"`
+	Exam         []jsonExam   `json:"exam,omitempty"`
+	Totals       []jsonTotals `json:"totals,omitempty"`
+	Roster       string       `json:"roster,omitempty"`
+	ToolVersion  string       `json:"tool_version,omitempty"`
+	Profile      string       `json:"profile,omitempty"`
+	GeneratedAt  string       `json:"generated_at,omitempty"`
+	Warnings     []string     `json:"parse_warnings,omitempty"`
+}
+
+// jsonQuorum Структура кворума занятия в формате JSON
+type jsonQuorum struct {
+	Met      bool `json:"met"`
+	Present  int  `json:"present"`
+	Expected int  `json:"expected"`
+}
+
+// jsonGroup Структура посещаемости группы потоковой лекции в формате JSON
+type jsonGroup struct {
+	Group    string `json:"group"`
+	Present  int    `json:"present"`
+	Expected int    `json:"expected"`
+	Percent  int    `json:"percent"`
+}
+
+// jsonTotals Структура итогов посещаемости группы в формате JSON
+type jsonTotals struct {
+	Group   string `json:"group"`
+	Invited int    `json:"invited"`
+	Present int    `json:"present"`
+	Partial int    `json:"partial"`
+	Late    int    `json:"late"`
+	Absent  int    `json:"absent"`
+	Percent int    `json:"percent"`
+}
+
+// jsonExam Структура проверки присутствия студента на экзамене в формате JSON
+type jsonExam struct {
+	FullName  string `json:"full_name"`
+	Variant   string `json:"variant,omitempty"`
+	Window    string `json:"window,omitempty"`
+	JoinTime  string `json:"join_time,omitempty"`
+	LeaveTime string `json:"leave_time,omitempty"`
+	Result    string `json:"result"`
+	Mismatch  bool   `json:"mismatch"`
+}
+
+// jsonMember Структура участника собрания в формате JSON: отметки отчёта и машиночитаемые поля
+type jsonMember struct {
+	ID              string `json:"id,omitempty"`
+	Group           string `json:"group"`
+	FullName        string `json:"full_name"`
+	RecordBook      string `json:"record_book,omitempty"`
+	Presence        string `json:"presence"`
+	Delay           string `json:"delay"`
+	DelayMinutes    int    `json:"delay_minutes,omitempty"`
+	EarlyExit       string `json:"early_exit"`
+	IsPresent       bool   `json:"is_present"`
+	IsLate          bool   `json:"is_late"`
+	JoinTime        string `json:"join_time,omitempty"`
+	LeaveTime       string `json:"leave_time,omitempty"`
+	DurationSeconds int    `json:"duration_seconds"`
+	PresencePercent int    `json:"presence_percent"`
+	Reconnects      int    `json:"reconnects"`
+	Platform        string `json:"platform,omitempty"`
+	Participation   string `json:"participation,omitempty"`
+	ClockDrift      bool   `json:"clock_drift,omitempty"`
+	LMSActive       bool   `json:"lms_active,omitempty"`
+	Booking         string `json:"booking,omitempty"`
+	Excuse          string `json:"excuse,omitempty"`
+}
+
+// jsonReport Структура отчёта в формате JSON
+type jsonReport struct {
+	Header  jsonHeader   `json:"header"`
+	Members []jsonMember `json:"members"`
+	Guests  []jsonMember `json:"guests,omitempty"`
+	Staff   []jsonMember `json:"staff,omitempty"`
+}
+
+/*====================================================================================================================*/
+
+// ParseFormat Функция, проверяющая, что формат итогового отчёта зарегистрирован функцией RegisterExporter().
+// По-умолчанию отчёт формируется в виде .csv файла
+func ParseFormat(source string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(source))
+	if format == "" {
+		return FormatCSV, nil
+	}
+
+	exportersMutex.RLock()
+	defer exportersMutex.RUnlock()
+	if _, ok := exporters[format]; !ok {
+		return "", unknownFormat(source)
+	}
+
+	return format, nil
+}
+
+// JSONPath Функция, возвращающая полный путь до отчёта в формате JSON, сформированного функцией FormJSONReport()
+func JSONPath(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Отчёт о проведение собрания_") + header.FileName() + ".json"
+}
+
+// FormJSONReport Функция, формирующая отчёт в формате JSON для обработки другими программами (например, импорта в
+// LMS). Если путь до директории отчётов - "-", отчёт выводится в стандартный вывод
+func FormJSONReport(ctx context.Context, header Header, members, guests []Member, reportLocationPath string) (err error) {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if reportLocationPath == "-" {
+		return WriteJSON(os.Stdout, header, members, guests)
+	}
+
+	file, err := os.Create(JSONPath(header, reportLocationPath))
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка закрытия файла отчёта: %w", closeErr)
+		}
+	}()
+
+	return WriteJSON(file, header, members, guests)
+}
+
+// WriteJSON Функция, записывающая оглавление отчёта, участников собрания и гостей в формате JSON
+func WriteJSON(out io.Writer, header Header, members, guests []Member) error {
+	data := jsonReport{
+		Header: jsonHeader{header.Title, header.Course, header.Date, header.LessonLabel(), header.Lecturer, "", "",
+			header.Duration, header.Planned(), header.JoinURL, header.RecordingURL, nil, nil, nil, nil, header.Roster,
+			header.ToolVersion, header.Profile, "", header.Warnings},
+		Members: jsonMembers(members),
+		Guests:  jsonMembers(guests),
+		Staff:   jsonMembers(header.Staff),
+	}
+	if !header.StartTime.IsZero() {
+		data.Header.StartTime = header.StartTime.Format(jsonTimeLayout)
+	}
+	if !header.EndTime.IsZero() {
+		data.Header.EndTime = header.EndTime.Format(jsonTimeLayout)
+	}
+	if !header.GeneratedAt.IsZero() {
+		data.Header.GeneratedAt = header.GeneratedAt.Format(time.RFC3339)
+	}
+	//Кворум выводится, только если он проверялся
+	if header.Quorum.Checked {
+		data.Header.Quorum = &jsonQuorum{header.Quorum.Met, header.Quorum.Present, header.Quorum.Expected}
+	}
+	for _, group := range header.Groups {
+		data.Header.Groups = append(data.Header.Groups, jsonGroup{i18n.T(group.Group), group.Present, group.Expected,
+			group.Percent()})
+	}
+	for _, totals := range header.Totals {
+		data.Header.Totals = append(data.Header.Totals, jsonTotals{i18n.T(totals.Group), totals.Invited, totals.Present,
+			totals.Partial, totals.Late, totals.Absent, totals.Percent()})
+	}
+	for _, check := range header.Exam {
+		exam := jsonExam{FullName: check.FullName, Variant: check.Variant, Window: check.Window, Result: check.Result,
+			Mismatch: check.Mismatch}
+		if !check.Join.IsZero() {
+			exam.JoinTime, exam.LeaveTime = check.Join.Format(jsonTimeLayout), check.Leave.Format(jsonTimeLayout)
+		}
+		data.Header.Exam = append(data.Header.Exam, exam)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("ошибка записи отчёта в формате JSON: %w", err)
+	}
+
+	return nil
+}
+
+// parseJSONTime Вспомогательная функция, разбирающая время оглавления отчёта в формате JSON. Пустое или некорректное
+// время возвращается нулевым
+func parseJSONTime(source string) time.Time {
+	parsed, _ := time.Parse(jsonTimeLayout, source)
+
+	return parsed
+}
+
+// jsonMembers Вспомогательная функция, переводящая участников собрания в структуры для записи в формате JSON.
+// Пустые участники (инициатор собрания) пропускаются
+func jsonMembers(members []Member) []jsonMember {
+	result := make([]jsonMember, 0, len(members))
+
+	for _, member := range members {
+		if member.FullName == "" {
+			continue
+		}
+
+		current := jsonMember{
+			ID:              member.ID,
+			Group:           i18n.T(member.Group),
+			FullName:        member.FullName,
+			RecordBook:      member.RecordBook,
+			Presence:        member.Presence.Label(),
+			Delay:           member.DelayLabel(),
+			DelayMinutes:    member.DelayMinutes,
+			EarlyExit:       member.EarlyExit.Label(),
+			IsPresent:       !member.Presence.IsAbsent() && !member.Presence.IsExcused(),
+			IsLate:          member.Delay == DelayLate,
+			DurationSeconds: member.Duration,
+			PresencePercent: member.PresenceShare,
+			Reconnects:      member.Reconnects,
+			Platform:        member.Platform,
+			Participation:   i18n.T(member.Participation),
+			ClockDrift:      member.ClockDrift,
+			LMSActive:       member.LMSActive,
+			Booking:         i18n.T(member.Booking),
+			Excuse:          member.Excuse,
+		}
+		if !member.Join.IsZero() {
+			current.JoinTime = member.Join.Format(jsonTimeLayout)
+		}
+		if !member.Leave.IsZero() {
+			current.LeaveTime = member.Leave.Format(jsonTimeLayout)
+		}
+
+		result = append(result, current)
+	}
+
+	return result
+}
+
+// ReadJSONSheets Функция, считывающая отчёт в формате JSON, сформированный функцией WriteJSON(), и возвращающая его
+// в виде таблиц для просмотра: оглавление собрания, участники, гости и преподаватели
+func ReadJSONSheets(in io.Reader) ([]Sheet, error) {
+	var data jsonReport
+	if err := json.NewDecoder(in).Decode(&data); err != nil {
+		return nil, fmt.Errorf("ошибка чтения отчёта в формате JSON: %w", err)
+	}
+
+	header := data.Header
+	rows := [][]string{{i18n.T("Название собрания"), header.Title}, {i18n.T("Дата проведения собрания"), header.Date},
+		{i18n.T("Номер пары"), header.LessonNumber}}
+	if header.Course != "" {
+		rows = append(rows, []string{i18n.T("Дисциплина"), header.Course})
+	}
+	if header.Lecturer != "" {
+		rows = append(rows, []string{i18n.T("Преподаватель"), header.Lecturer})
+	}
+	//Время и продолжительность собрания выводятся так же, как в отчёте в виде .csv файла
+	held := Header{StartTime: parseJSONTime(header.StartTime), EndTime: parseJSONTime(header.EndTime),
+		Duration: header.Duration}
+	if held.Held() != "" {
+		rows = append(rows, []string{i18n.T("Время собрания"), held.Held()})
+	}
+	if held.Duration > 0 {
+		rows = append(rows, []string{i18n.T("Продолжительность собрания"), held.DurationLabel()})
+	}
+	if header.Planned != "" {
+		rows = append(rows, []string{i18n.T("Время по календарю"), header.Planned})
+	}
+	if header.JoinURL != "" {
+		rows = append(rows, []string{i18n.T("Ссылка на собрание"), header.JoinURL})
+	}
+	if header.RecordingURL != "" {
+		rows = append(rows, []string{i18n.T("Запись собрания"), header.RecordingURL})
+	}
+	if header.Quorum != nil {
+		rows = append(rows, []string{i18n.T("Кворум"), fmt.Sprintf("%d/%d", header.Quorum.Present,
+			header.Quorum.Expected)})
+	}
+	if header.Roster != "" {
+		rows = append(rows, []string{i18n.T("База групп"), header.Roster})
+	}
+	if header.GeneratedAt != "" {
+		rows = append(rows, []string{i18n.T("Сформирован"), header.GeneratedAt})
+	}
+	for _, warning := range header.Warnings {
+		rows = append(rows, []string{i18n.T("Предупреждения разбора"), warning})
+	}
+	sheets := []Sheet{{Name: i18n.T("Собрание"), Rows: rows}}
+
+	//Сравнение групп потоковой лекции выводится отдельной таблицей
+	if len(header.Groups) > 0 {
+		rows := [][]string{{i18n.T("Группа"), i18n.T("Присутствовали"), i18n.T("Студентов"), i18n.T("Посещаемость, %")}}
+		for _, group := range header.Groups {
+			rows = append(rows, []string{group.Group, fmt.Sprint(group.Present), fmt.Sprint(group.Expected),
+				fmt.Sprint(group.Percent)})
+		}
+		sheets = append(sheets, Sheet{Name: i18n.T("Сравнение групп"), Rows: rows})
+	}
+
+	//Итоги посещаемости выводятся отдельной таблицей
+	if len(header.Totals) > 0 {
+		rows := [][]string{TotalsHeader()}
+		for _, totals := range header.Totals {
+			rows = append(rows, []string{totals.Group, fmt.Sprint(totals.Invited), fmt.Sprint(totals.Present),
+				fmt.Sprint(totals.Partial), fmt.Sprint(totals.Late), fmt.Sprint(totals.Absent), fmt.Sprint(totals.Percent)})
+		}
+		sheets = append(sheets, Sheet{Name: i18n.T("Итоги посещаемости"), Rows: rows})
+	}
+
+	//Проверка экзамена выводится отдельной таблицей
+	if len(header.Exam) > 0 {
+		rows := [][]string{{i18n.T("ФИО"), i18n.T("Вариант"), i18n.T("Назначенное время"), i18n.T("Присоединение"),
+			i18n.T("Выход"), i18n.T("Результат")}}
+		for _, check := range header.Exam {
+			rows = append(rows, []string{check.FullName, check.Variant, check.Window, check.JoinTime, check.LeaveTime,
+				check.Result})
+		}
+		sheets = append(sheets, Sheet{Name: i18n.T("Проверка экзамена"), Rows: rows})
+	}
+
+	//Таблицы участников выводятся, только если в них есть строки
+	for _, table := range []struct {
+		name    string
+		members []jsonMember
+	}{{i18n.T("Участники"), data.Members}, {i18n.T("Гости"), data.Guests}, {i18n.T("Преподаватели"), data.Staff}} {
+		if len(table.members) == 0 {
+			continue
+		}
+
+		rows := [][]string{append(ColumnLabels.Header(false, true, false), i18n.T("Присоединение"), i18n.T("Выход"),
+			i18n.T("Минут на собрании"))}
+		for _, member := range table.members {
+			rows = append(rows, []string{member.Group, member.FullName, member.RecordBook, member.Presence,
+				member.Delay, member.EarlyExit, member.JoinTime, member.LeaveTime,
+				fmt.Sprint(member.DurationSeconds / 60)})
+		}
+		sheets = append(sheets, Sheet{Name: table.name, Rows: rows})
+	}
+
+	return sheets, nil
+}
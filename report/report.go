@@ -0,0 +1,644 @@
+// Package report Пакет итогового отчёта о посещаемости: структуры оглавления и участников собрания, сортировка
+// участников и запись отчёта в .csv файл
+package report
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Member Структура члена собрания для вывода в таблицу
+type Member struct {
+	//Группа - первая сортировка
+	Group string
+	//ФИО - вторая сортировка
+	FullName string
+	//Стабильный идентификатор студента для машиночитаемых форматов (хэш ФИО)
+	ID string
+	//Номер зачётной книжки из базы групп (пустой, если в базе не указан)
+	RecordBook string
+	//Адрес электронной почты участника из отчёта MS Teams (пустой, если в отчёте не указан)
+	Email string
+	//Пометка об опоздании
+	Delay DelayStatus
+	//Количество минут опоздания от начала пары (0, если участник не опоздал)
+	DelayMinutes int
+	//Пометка о раннем или позднем выходе с собрания
+	EarlyExit Exit
+	//Пометка о присутствии (или отсутствии)
+	Presence PresenceStatus
+	//Количество переподключений к собранию (повторных строк участника в отчёте)
+	Reconnects int
+	//Устройство, с которого участник присоединился к собранию (если указано в отчёте)
+	Platform string
+	//Время присоединения или выхода участника было вне времени собрания больше допустимого расхождения часов и было
+	// приведено к началу или окончанию собрания
+	ClockDrift bool
+	//Самое раннее время присоединения к собранию (у отсутствовавших - нулевое)
+	Join time.Time
+	//Самое позднее время выхода с собрания (у отсутствовавших - нулевое)
+	Leave time.Time
+	//Суммарная продолжительность нахождения на собрании в секундах
+	Duration int
+	//Доля продолжительности собрания в процентах, которую участник находился на собрании (0, если продолжительность
+	// собрания неизвестна)
+	PresenceShare int
+	//Формат участия в гибридном занятии: "онлайн" или "очно" (пустой, если лист присутствия в аудитории не указан)
+	Participation string
+	//Отсутствовавший студент был активен в СДО во время пары (возможно, проблемы с MS Teams)
+	LMSActive bool
+	//Запись на консультацию: "записан" или "без записи" (пустая, если список записавшихся не указан)
+	Booking string
+	//ФИО гостя из отчёта MS Teams, сопоставленного со студентом базы по похожему ФИО (пустое, если участник не
+	// сопоставлялся)
+	MatchedFrom string
+	//Уважительная причина отсутствия из файла уважительных причин (пустая, если студент не отсутствовал по
+	// уважительной причине)
+	Excuse string
+}
+
+// Header Структура оглавления отчёта
+type Header struct {
+	//Название собрания
+	Title string
+	//Название дисциплины, к которой относится собрание (пустое, если дисциплина не определена по названию собрания)
+	Course string
+	//Дата проведения собрания
+	Date string
+	//Номер пары
+	LessonNumber string
+	//ФИО преподавателя - инициатора собрания
+	Lecturer string
+	//Фактическое время начала и окончания собрания по оглавлению отчёта MS Teams (время окончания нулевое, если оно
+	// не указано или его не удалось разобрать). У объединённых отчётов - начало первого и самое позднее окончание
+	StartTime, EndTime time.Time
+	//Продолжительность собрания в секундах (0, если неизвестна). У объединённых отчётов продолжительности суммируются
+	Duration int
+	//Плановое время начала и окончания собрания по событию календаря организатора (нулевое, если собрание не
+	// сопоставлено с событием календаря)
+	PlannedStart, PlannedEnd time.Time
+	//Ссылка присоединения к собранию и ссылка на запись собрания (пустые, если отчёт загружен не из Microsoft Graph или
+	// запись не найдена), по которым отсутствовавшие студенты могут посмотреть запись пары
+	JoinURL, RecordingURL string
+	//Кворум занятия
+	Quorum Quorum
+	//Посещаемость каждой группы потоковой лекции для сравнения групп (пустая, если на собрании одна группа)
+	Groups []GroupAttendance
+	//Проверка присутствия студентов в назначенное время экзамена (пустая, если список вариантов не указан)
+	Exam []ExamCheck
+	//Итоги посещаемости по группам и по собранию в целом для блока итогов после таблицы участников (пустые, если блок
+	// итогов не включён)
+	Totals []GroupTotals
+	//Преподаватели и ассистенты из файла преподавателей, присоединявшиеся к собранию (с ролью вместо группы), для
+	// отдельного списка в конце отчёта
+	Staff []Member
+	//Предупреждения разбора: пропущенные некорректные строки отчёта MS Teams (файл, номер строки и причина)
+	Warnings []string
+	//Сведения о редакции базы групп, по которой сформирован отчёт
+	Roster string
+	//Хэш содержимого отчётов MS Teams собрания (и листа присутствия, и журнала СДО), по которому повторная обработка
+	// тех же отчётов заменяет собрание в истории и не отправляет оповещения повторно
+	SourceHash string
+	//Номер версии отчёта, добавляемый к названию файлов, если отчёт этого собрания уже был сформирован (0 и 1 - без
+	// номера версии)
+	Version int
+	//Пометки участников сокращённого отчёта, добавляемые к названию его файлов (пусто - полный отчёт)
+	Only StatusFilter
+	//Версия программы, сформировавшей отчёт
+	ToolVersion string
+	//Профиль конфигураций, с которым сформирован отчёт
+	Profile string
+	//Время формирования отчёта (нулевое, если не указано). Не входит в хэш содержимого SourceHash, поэтому повторная
+	// обработка тех же файлов определяется по хэшу, а не по совпадению отчётов
+	GeneratedAt time.Time
+}
+
+// ToolVersion Версия программы, указываемая в конце отчётов. Задаётся при сборке:
+//
+//	go build -ldflags "-X mod.go/report.ToolVersion=1.4.0" ./cmd/trackattendance
+var ToolVersion = "dev"
+
+// generatedLayout Формат времени формирования отчёта
+const generatedLayout = "02.01.2006 15:04:05"
+
+// Columns Структура названий столбцов таблицы участников. Пустое название заменяется стандартным названием на
+// выбранном языке
+type Columns struct {
+	Group         string
+	FullName      string
+	RecordBook    string
+	Presence      string
+	Delay         string
+	EarlyExit     string
+	Participation string
+	Booking       string
+}
+
+// LessonLabels Названия пар для вывода из конфигураций по названиям пар расписания ("Пара 1"). Пары без своего
+// названия выводятся стандартным названием на выбранном языке. Устанавливаются из файла конфигураций
+var LessonLabels = map[string]string{}
+
+// ColumnLabels Названия столбцов таблицы участников из конфигураций (для программ импорта отчётов, которые ищут
+// столбцы по точному названию). Устанавливаются из файла конфигураций
+var ColumnLabels Columns
+
+// Quorum Структура кворума занятия: присутствовала ли на занятии достаточная доля студентов групп собрания
+type Quorum struct {
+	//Проверялся ли кворум (кворум не проверяется для консультаций и если он выключен в конфигурациях)
+	Checked bool
+	//Набран ли кворум
+	Met bool
+	//Количество студентов, присутствовавших достаточное время
+	Present int
+	//Количество студентов групп собрания
+	Expected int
+}
+
+// GroupAttendance Структура посещаемости одной группы на собрании: сколько студентов группы присутствовало
+type GroupAttendance struct {
+	//Группа
+	Group string
+	//Количество присутствовавших студентов группы
+	Present int
+	//Количество студентов группы на собрании (без отсутствовавших по уважительной причине)
+	Expected int
+}
+
+// GroupTotals Структура итогов посещаемости группы на собрании, которые преподаватели сдают еженедельно
+type GroupTotals struct {
+	//Группа (у итоговой строки - "Всего")
+	Group string
+	//Количество студентов группы на собрании (без отсутствовавших по уважительной причине)
+	Invited int
+	//Количество присутствовавших полностью и не полностью
+	Present, Partial int
+	//Количество опоздавших (среди присутствовавших)
+	Late int
+	//Количество отсутствовавших
+	Absent int
+}
+
+// Percent Функция, возвращающая посещаемость группы в процентах (присутствовавшие полностью и не полностью)
+func (totals GroupTotals) Percent() int {
+	if totals.Invited == 0 {
+		return 0
+	}
+
+	return (totals.Present + totals.Partial) * 100 / totals.Invited
+}
+
+// Percent Функция, возвращающая посещаемость группы в процентах
+func (attendance GroupAttendance) Percent() int {
+	if attendance.Expected == 0 {
+		return 0
+	}
+
+	return attendance.Present * 100 / attendance.Expected
+}
+
+// ExamCheck Структура проверки присутствия студента на экзамене в назначенное ему время
+type ExamCheck struct {
+	//ФИО студента
+	FullName string
+	//Вариант экзамена (пустой у участника, которого нет в списке вариантов)
+	Variant string
+	//Назначенное время экзамена в виде ЧЧ:ММ-ЧЧ:ММ
+	Window string
+	//Время присоединения к собранию и выхода с собрания (нулевые, если студент не подключался)
+	Join, Leave time.Time
+	//Результат проверки: "в назначенное время" или описание несоответствия
+	Result string
+	//Обнаружено ли несоответствие
+	Mismatch bool
+}
+
+// String Функция, возвращающая кворум в виде строки отчёта на выбранном языке ("Есть (12 из 25)")
+func (quorum Quorum) String() string {
+	if quorum.Met {
+		return i18n.Sprintf("Есть (%d из %d)", quorum.Present, quorum.Expected)
+	}
+
+	return i18n.Sprintf("Нет (%d из %d)", quorum.Present, quorum.Expected)
+}
+
+/*====================================================================================================================*/
+
+// Header Функция, возвращающая "шапку" таблицы участников: названия столбцов из конфигураций или стандартные названия
+// на выбранном языке. Для гибридного занятия добавляется столбец формата участия, для консультации со списком
+// записавшихся - столбец записи на консультацию, а если в базе групп указаны номера зачёток - столбец номера зачётки
+// после ФИО
+func (columns Columns) Header(hybrid, recordBooks, bookings bool) []string {
+	label := func(custom, standard string) string {
+		if custom != "" {
+			return custom
+		}
+		return i18n.T(standard)
+	}
+
+	header := []string{label(columns.Group, "Группа"), label(columns.FullName, "ФИО")}
+	if recordBooks {
+		header = append(header, label(columns.RecordBook, "Номер зачётки"))
+	}
+	header = append(header, label(columns.Presence, "Присутствие"), label(columns.Delay, "Опоздание"),
+		label(columns.EarlyExit, "Время нахождения на собрании"))
+	if hybrid {
+		header = append(header, label(columns.Participation, "Формат участия"))
+	}
+	if bookings {
+		header = append(header, label(columns.Booking, "Запись на консультацию"))
+	}
+
+	return header
+}
+
+// FormReport Функция, формирующая отчёт в виде .csv файла. Принимает на вход созданное оглавление отчёта и список всех
+// участников собрания, за исключением инициатора(преподавателя). Гости, выводимые отдельно, записываются отдельным
+// списком после таблицы участников
+func FormReport(ctx context.Context, header Header, members, guests []Member, reportLocationPath string) (err error) {
+	//Переменная, содержащая полный путь до сформированного отчёта. Название формируется из названия и даты проведения
+	formedReportRoot := Path(header, reportLocationPath)
+
+	//Создаём файл по сформированному пути
+	file, err := os.Create(formedReportRoot)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+
+	//Закрываем файл по окончанию функции, ошибка закрытия возвращается, если других ошибок не было
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка закрытия файла отчёта: %w", closeErr)
+		}
+	}()
+
+	return WriteReport(ctx, file, header, members, guests)
+}
+
+// WriteReport Функция, записывающая отчёт в формате .csv (с BOM) в поток out: оглавление, таблицу участников и
+// отдельные списки. Позволяет сформировать отчёт без создания файла (например, в памяти)
+func WriteReport(ctx context.Context, out io.Writer, header Header, members, guests []Member) error {
+	//Данная строка указывает на то, что файл записан в кодировки UTF-8 c BOM, т.к. только в такой кодировки MS Exel
+	//корректно отображает кириллицу
+	if _, err := io.WriteString(out, "\xEF\xBB\xBF"); err != nil {
+		return fmt.Errorf("ошибка записи строки с кодировкой: %w", err)
+	}
+
+	//Создаём писец .csv файлов
+	csvWriter := csv.NewWriter(out)
+
+	//Устанавливаем разделитель писца на точку с запятой
+	csvWriter.Comma = ';'
+
+	//Строки оглавления отчёта: название собрания(пары), дата проведения собрания(пары) и номер пары
+	headerComponents := [][]string{
+		{i18n.T("Название собрания"), header.Title},
+		{i18n.T("Дата проведения собрания"), header.Date},
+		{i18n.T("Номер пары"), header.LessonLabel()},
+	}
+	if header.Course != "" {
+		headerComponents = append(headerComponents[:1], append([][]string{{i18n.T("Дисциплина"), header.Course}},
+			headerComponents[1:]...)...)
+	}
+	if held := header.Held(); held != "" {
+		headerComponents = append(headerComponents, []string{i18n.T("Время собрания"), held})
+	}
+	if header.Duration > 0 {
+		headerComponents = append(headerComponents, []string{i18n.T("Продолжительность собрания"),
+			header.DurationLabel()})
+	}
+	if !header.PlannedStart.IsZero() {
+		headerComponents = append(headerComponents, []string{i18n.T("Время по календарю"), header.Planned()})
+	}
+	if header.Quorum.Checked {
+		headerComponents = append(headerComponents, []string{i18n.T("Кворум"), header.Quorum.String()})
+	}
+
+	//Записываем строки оглавления в отчёт
+	for _, headerComponent := range headerComponents {
+		if err := csvWriter.Write(headerComponent); err != nil {
+			return fmt.Errorf("ошибка записи строки оглавления \"%v\": %w", headerComponent[0], err)
+		}
+	}
+
+	//Записываем в отчёт пустую строку, чтобы отделить оглавление от списка участников собрания
+	if err := csvWriter.Write([]string{""}); err != nil {
+		return fmt.Errorf("ошибка записи пустой строки: %w", err)
+	}
+
+	//"Шапка" таблицы участников собрания(студентов). Для гибридного занятия добавляется столбец формата участия, для
+	// консультации со списком записавшихся - столбец записи, а при известных номерах зачёток - столбец номера зачётки
+	hybrid, recordBooks, bookings := IsHybrid(members), HasRecordBooks(members), HasBookings(members)
+	memberHeader := ColumnLabels.Header(hybrid, recordBooks, bookings)
+
+	//Записываем "шапку" таблицы участников собрания(студентов)
+	if err := csvWriter.Write(memberHeader); err != nil {
+		return fmt.Errorf("ошибка записи строки шапки участников: %w", err)
+	}
+
+	//Записываем участников собрания
+	if err := writeMembers(ctx, csvWriter, members, hybrid, recordBooks, bookings); err != nil {
+		return err
+	}
+
+	//Записываем итоги посещаемости по группам сразу после таблицы участников
+	if len(header.Totals) > 0 {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+		rows := [][]string{{i18n.T("Итоги посещаемости")}, TotalsHeader()}
+		for _, totals := range header.Totals {
+			rows = append(rows, totals.Row())
+		}
+		if err := csvWriter.WriteAll(rows); err != nil {
+			return fmt.Errorf("ошибка записи итогов посещаемости: %w", err)
+		}
+	}
+
+	//Записываем отдельный список гостей, отделённый от таблицы участников пустой строкой
+	if len(guests) > 0 {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+		if err := csvWriter.Write([]string{i18n.T("Гости")}); err != nil {
+			return fmt.Errorf("ошибка записи строки гостей: %w", err)
+		}
+		if err := writeMembers(ctx, csvWriter, guests, hybrid, recordBooks, bookings); err != nil {
+			return err
+		}
+	}
+
+	//Записываем сравнение посещаемости групп потоковой лекции
+	if len(header.Groups) > 0 {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+		rows := [][]string{{i18n.T("Сравнение групп")},
+			{i18n.T("Группа"), i18n.T("Присутствовали"), i18n.T("Студентов"), i18n.T("Посещаемость, %")}}
+		for _, group := range header.Groups {
+			rows = append(rows, []string{i18n.T(group.Group), strconv.Itoa(group.Present),
+				strconv.Itoa(group.Expected), strconv.Itoa(group.Percent())})
+		}
+		if err := csvWriter.WriteAll(rows); err != nil {
+			return fmt.Errorf("ошибка записи сравнения групп: %w", err)
+		}
+	}
+
+	//Записываем проверку присутствия студентов в назначенное время экзамена
+	if len(header.Exam) > 0 {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+		rows := [][]string{{i18n.T("Проверка экзамена")},
+			{i18n.T("ФИО"), i18n.T("Вариант"), i18n.T("Назначенное время"), i18n.T("Присоединение"), i18n.T("Выход"),
+				i18n.T("Результат")}}
+		for _, check := range header.Exam {
+			rows = append(rows, []string{check.FullName, check.Variant, check.Window, clockTime(check.Join),
+				clockTime(check.Leave), check.Result})
+		}
+		if err := csvWriter.WriteAll(rows); err != nil {
+			return fmt.Errorf("ошибка записи проверки экзамена: %w", err)
+		}
+	}
+
+	//Записываем отдельный список преподавателей и ассистентов со временем присоединения и выхода
+	if len(header.Staff) > 0 {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+		rows := [][]string{{i18n.T("Преподаватели")},
+			{i18n.T("ФИО"), i18n.T("Роль"), i18n.T("Присоединение"), i18n.T("Выход"), i18n.T("Минут на собрании")}}
+		for _, member := range header.Staff {
+			rows = append(rows, []string{member.FullName, member.Group, clockTime(member.Join),
+				clockTime(member.Leave), strconv.Itoa(member.Duration / 60)})
+		}
+		if err := csvWriter.WriteAll(rows); err != nil {
+			return fmt.Errorf("ошибка записи списка преподавателей: %w", err)
+		}
+	}
+
+	//Записываем в конце отчёта преподавателя, проводившего собрание, и сведения о том, чем сформирован отчёт: редакцию
+	// базы групп, версию программы, профиль конфигураций и время формирования
+	if header.Lecturer != "" || header.Roster != "" || header.ToolVersion != "" || header.Profile != "" ||
+		!header.GeneratedAt.IsZero() {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+	}
+	if header.Lecturer != "" {
+		if err := csvWriter.Write([]string{i18n.T("Преподаватель"), header.Lecturer}); err != nil {
+			return fmt.Errorf("ошибка записи строки преподавателя: %w", err)
+		}
+	}
+	if header.Roster != "" {
+		if err := csvWriter.Write([]string{i18n.T("База групп"), header.Roster}); err != nil {
+			return fmt.Errorf("ошибка записи строки базы групп: %w", err)
+		}
+	}
+	if header.ToolVersion != "" {
+		if err := csvWriter.Write([]string{i18n.T("Версия программы"), header.ToolVersion}); err != nil {
+			return fmt.Errorf("ошибка записи строки версии программы: %w", err)
+		}
+	}
+	if header.Profile != "" {
+		if err := csvWriter.Write([]string{i18n.T("Профиль конфигураций"), header.Profile}); err != nil {
+			return fmt.Errorf("ошибка записи строки профиля конфигураций: %w", err)
+		}
+	}
+	if !header.GeneratedAt.IsZero() {
+		if err := csvWriter.Write([]string{i18n.T("Сформирован"), header.Generated()}); err != nil {
+			return fmt.Errorf("ошибка записи строки времени формирования: %w", err)
+		}
+	}
+
+	//Записываем в конце отчёта предупреждения разбора: строки отчёта MS Teams, пропущенные из-за ошибок
+	if len(header.Warnings) > 0 {
+		if err := csvWriter.Write([]string{""}); err != nil {
+			return fmt.Errorf("ошибка записи пустой строки: %w", err)
+		}
+		if err := csvWriter.Write([]string{i18n.T("Предупреждения разбора")}); err != nil {
+			return fmt.Errorf("ошибка записи строки предупреждений: %w", err)
+		}
+		for _, warning := range header.Warnings {
+			if err := csvWriter.Write([]string{warning}); err != nil {
+				return fmt.Errorf("ошибка записи предупреждения разбора: %w", err)
+			}
+		}
+	}
+
+	//Отчищаем буфер писца
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("ошибка записи отчёта: %w", err)
+	}
+
+	return nil
+}
+
+// Path Функция, возвращающая полный путь до отчёта, сформированного функцией FormReport()
+func Path(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Отчёт о проведение собрания_") + header.FileName() + ".csv"
+}
+
+// IsHybrid Функция, проверяющая, является ли занятие гибридным: формат участия указан хотя бы у одного участника
+func IsHybrid(members []Member) bool {
+	for _, member := range members {
+		if member.Participation != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasRecordBooks Функция, проверяющая, указан ли номер зачётки хотя бы у одного участника собрания
+func HasRecordBooks(members []Member) bool {
+	for _, member := range members {
+		if member.RecordBook != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clockTime Вспомогательная функция, возвращающая время в виде ЧЧ:ММ:СС или пустую строку для нулевого времени
+func clockTime(moment time.Time) string {
+	if moment.IsZero() {
+		return ""
+	}
+
+	return moment.Format("15:04:05")
+}
+
+// HasBookings Функция, проверяющая, указана ли запись на консультацию хотя бы у одного участника собрания
+func HasBookings(members []Member) bool {
+	for _, member := range members {
+		if member.Booking != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PlatformStatsPath Функция, возвращающая полный путь до статистики устройств, сформированной функцией
+// FormPlatformStats()
+func PlatformStatsPath(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Статистика устройств_") + header.FileName() + ".csv"
+}
+
+// writeMembers Вспомогательная функция, записывающая строки участников собрания в отчёт
+func writeMembers(ctx context.Context, csvWriter *csv.Writer, members []Member, hybrid, recordBooks, bookings bool) error {
+	//Цикл по всем участникам собрания
+	for i := 0; i < len(members); i++ {
+		//Прерываем запись, если контекст отменён
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		//Если i-тый участник собрания - пустой, т.е. инициатор(преподаватель), он пропускается в записи
+		if members[i].FullName != "" {
+			//Создаём массив со строкой, которая будет записываться в отчёт. Массив состоит из всех данных участника собрания(студента)
+			memberInformation := []string{i18n.T(members[i].Group), members[i].FullName}
+			if recordBooks {
+				memberInformation = append(memberInformation, members[i].RecordBook)
+			}
+			memberInformation = append(memberInformation, members[i].PresenceLabel(), members[i].DelayLabel(),
+				members[i].EarlyExit.Label())
+			if hybrid {
+				memberInformation = append(memberInformation, i18n.T(members[i].Participation))
+			}
+			if bookings {
+				memberInformation = append(memberInformation, i18n.T(members[i].Booking))
+			}
+			//Записываем массив в строку в отчёт
+			if err := csvWriter.Write(memberInformation); err != nil {
+				return fmt.Errorf("ошибка записи строки участника собрания: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// FormPlatformStats Функция, формирующая .csv файл статистики устройств, с которых участники присоединялись к
+// собранию. Если отчёт MS Teams не содержит сведений об устройствах, файл не создаётся
+func FormPlatformStats(ctx context.Context, header Header, members []Member, reportLocationPath string) (err error) {
+	//Количество присутствовавших участников по каждому устройству и общее количество участников с известным устройством
+	counts := make(map[string]int)
+	total := 0
+	for _, member := range members {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if member.Platform != "" {
+			counts[member.Platform]++
+			total++
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	//Создаём файл статистики рядом с отчётом о посещаемости
+	file, err := os.Create(PlatformStatsPath(header, reportLocationPath))
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("ошибка закрытия файла статистики устройств: %w", closeErr)
+		}
+	}()
+
+	//Файл записывается в кодировке UTF-8 c BOM, как и отчёт о посещаемости
+	if _, err := file.WriteString("\xEF\xBB\xBF"); err != nil {
+		return fmt.Errorf("ошибка записи строки с кодировкой: %w", err)
+	}
+
+	csvWriter := csv.NewWriter(file)
+	csvWriter.Comma = ';'
+
+	//Устройства выводятся в алфавитном порядке
+	platforms := make([]string, 0, len(counts))
+	for platform := range counts {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	rows := [][]string{{i18n.T("Устройство"), i18n.T("Участников"), i18n.T("Доля, %")}}
+	for _, platform := range platforms {
+		rows = append(rows, []string{platform, strconv.Itoa(counts[platform]),
+			strconv.FormatFloat(float64(counts[platform])*100/float64(total), 'f', 1, 64)})
+	}
+	rows = append(rows, []string{i18n.T("Всего"), strconv.Itoa(total), "100.0"})
+
+	if err := csvWriter.WriteAll(rows); err != nil {
+		return fmt.Errorf("ошибка записи статистики устройств: %w", err)
+	}
+
+	return nil
+}
+
+/*====================================================================================================================*/
+
+// SortMembers Функция, совершающая двойную сортировку списка участников собрания сначала по группам, потом по ФИО
+func SortMembers(members []Member) {
+	//Сортировка по группе и ФИО с сохранением исходного порядка равных элементов, чтобы повторная обработка того же
+	// отчёта давала тот же порядок участников
+	sort.SliceStable(members, func(i, j int) (less bool) {
+		if members[i].Group != members[j].Group {
+			return members[i].Group < members[j].Group
+		}
+		return members[i].FullName < members[j].FullName
+	})
+}
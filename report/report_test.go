@@ -0,0 +1,126 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// update Флаг, перезаписывающий эталонные отчёты testdata/*.golden.* сформированными отчётами (go test -update)
+var update = flag.Bool("update", false, "перезаписать эталонные отчёты в testdata")
+
+// testMoment Вспомогательная функция, возвращающая время 15.10.2026 ЧЧ:ММ по Москве, чтобы эталонные отчёты не
+// зависели от времени запуска тестов и часового пояса компьютера
+func testMoment(hour, minute int) time.Time {
+	return time.Date(2026, time.October, 15, hour, minute, 0, 0, time.FixedZone("MSK", 3*60*60))
+}
+
+// testHeader Оглавление собрания, по которому формируются эталонные отчёты: потоковая лекция двух групп с кворумом,
+// итогами посещаемости, преподавателем и предупреждением разбора
+var testHeader = Header{
+	Title:        "Математический анализ",
+	Course:       "Математический анализ",
+	Date:         "15.10.2026",
+	LessonNumber: "Пара 1",
+	Lecturer:     "Лекторов Пётр Сергеевич",
+	StartTime:    testMoment(8, 0),
+	EndTime:      testMoment(9, 30),
+	Duration:     90 * 60,
+	Quorum:       Quorum{Checked: true, Met: true, Present: 3, Expected: 4},
+	Groups: []GroupAttendance{
+		{Group: "МП-51", Present: 2, Expected: 3},
+		{Group: "МП-52", Present: 1, Expected: 1},
+	},
+	Totals: []GroupTotals{
+		{Group: "МП-51", Invited: 3, Present: 1, Partial: 1, Late: 1, Absent: 1},
+		{Group: "МП-52", Invited: 1, Present: 1},
+		{Group: "Всего", Invited: 4, Present: 2, Partial: 1, Late: 1, Absent: 1},
+	},
+	Staff:       []Member{{Group: "Ассистент", FullName: "Помощников Антон Игоревич", Presence: PresenceFull}},
+	Warnings:    []string{"report.csv:12: пустое имя участника"},
+	Roster:      "GroupsBase.csv",
+	SourceHash:  "d41d8cd98f00b204",
+	ToolVersion: "test",
+	GeneratedAt: testMoment(9, 45),
+}
+
+// testMembers Участники собрания эталонных отчётов: по одному на каждую пометку присутствия
+var testMembers = []Member{
+	{Group: "МП-51", FullName: "Иванов Иван Иванович", ID: "a1", Delay: DelayNone, EarlyExit: Exit{Status: ExitFull},
+		Presence: PresenceFull, Join: testMoment(7, 58), Leave: testMoment(9, 30), Duration: 92 * 60,
+		PresenceShare: 100},
+	{Group: "МП-51", FullName: "Петрова Мария Петровна", ID: "b2", Delay: DelayLate, DelayMinutes: 25,
+		EarlyExit: Exit{Status: ExitEarly, Minutes: 20}, Presence: PresencePartial, Reconnects: 1,
+		Join: testMoment(8, 25), Leave: testMoment(9, 10), Duration: 45 * 60, PresenceShare: 50},
+	{Group: "МП-51", FullName: "Сидоров Алексей Викторович", ID: "c3", Presence: PresenceAbsent},
+	{Group: "МП-51", FullName: "Кузнецов Дмитрий Олегович", ID: "d4", Presence: PresenceExcused},
+	{Group: "МП-52", FullName: "Смирнова Анна Павловна", ID: "e5", Delay: DelayNone, EarlyExit: Exit{Status: ExitFull},
+		Presence: PresenceFull, Join: testMoment(8, 1), Leave: testMoment(9, 29), Duration: 88 * 60, PresenceShare: 97},
+}
+
+// testGuests Гости собрания эталонных отчётов
+var testGuests = []Member{
+	{Group: "Гость", FullName: "Внешняя Ольга Сергеевна", Delay: DelayLate, DelayMinutes: 10,
+		EarlyExit: Exit{Status: ExitShort}, Presence: PresencePartial, Join: testMoment(8, 10),
+		Leave: testMoment(8, 20), Duration: 10 * 60, PresenceShare: 11},
+}
+
+/*====================================================================================================================*/
+
+// TestWriteReport Проверка отчёта в виде .csv файла: оглавление, таблица участников, итоги, гости, преподаватели и
+// предупреждения разбора совпадают с эталонным отчётом
+func TestWriteReport(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteReport(context.Background(), &buffer, testHeader, testMembers, testGuests); err != nil {
+		t.Fatalf("ошибка записи отчёта .csv: %v", err)
+	}
+
+	compareGolden(t, filepath.Join("testdata", "report.golden.csv"), buffer.Bytes())
+}
+
+// TestWriteJSON Проверка отчёта в формате JSON: отчёт совпадает с эталонным и считывается функцией ReadJSONSheets()
+func TestWriteJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WriteJSON(&buffer, testHeader, testMembers, testGuests); err != nil {
+		t.Fatalf("ошибка записи отчёта в формате JSON: %v", err)
+	}
+
+	compareGolden(t, filepath.Join("testdata", "report.golden.json"), buffer.Bytes())
+
+	sheets, err := ReadJSONSheets(bytes.NewReader(buffer.Bytes()))
+	if err != nil {
+		t.Fatalf("ошибка чтения отчёта в формате JSON: %v", err)
+	}
+	if len(sheets) == 0 || len(sheets[0].Rows) == 0 || sheets[0].Rows[0][1] != testHeader.Title {
+		t.Errorf("оглавление собрания не считано из отчёта в формате JSON: %v", sheets)
+	}
+}
+
+/*====================================================================================================================*/
+
+// compareGolden Вспомогательная функция, сравнивающая результат с эталонным файлом (или перезаписывающая эталонный
+// файл с флагом -update)
+func compareGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ошибка чтения эталонного файла (для создания запустите go test -update): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("результат отличается от эталонного файла %v:\n%s", path, got)
+	}
+}
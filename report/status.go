@@ -0,0 +1,217 @@
+package report
+
+import (
+	"fmt"
+	"mod.go/i18n"
+)
+
+/*====================================================================================================================*/
+
+// PresenceStatus Пометка о присутствии участника на собрании
+type PresenceStatus int
+
+// Пометки о присутствии. Нулевое значение - пометка не выставлена (например, у пустой строки инициатора собрания)
+const (
+	PresenceUnknown PresenceStatus = iota
+	//Участник присутствовал на паре полностью
+	PresenceFull
+	//Участник присутствовал на паре не полностью (мало находился на собрании или ушёл раньше)
+	PresencePartial
+	//Студент группы собрания не подключался к собранию
+	PresenceAbsent
+	//Студент группы собрания не подключался к собранию по уважительной причине (болезнь, академический отпуск) и не
+	// учитывается в статистике пропусков
+	PresenceExcused
+)
+
+// DelayStatus Пометка об опоздании участника на пару
+type DelayStatus int
+
+// Пометки об опоздании. Нулевое значение - пометка не выставлена (у отсутствовавших и отметившихся в аудитории)
+const (
+	DelayUnknown DelayStatus = iota
+	//Участник присоединился к собранию до порога опоздания
+	DelayNone
+	//Участник присоединился к собранию после порога опоздания
+	DelayLate
+)
+
+// ExitStatus Пометка о времени нахождения участника на собрании
+type ExitStatus int
+
+// Пометки о времени нахождения на собрании. Нулевое значение - пометка не выставлена (у отсутствовавших)
+const (
+	ExitUnknown ExitStatus = iota
+	//Участник находился на собрании больше получаса
+	ExitFull
+	//Участник находился на собрании меньше минуты
+	ExitBrief
+	//Участник находился на собрании меньше получаса
+	ExitShort
+	//Участник вышел с собрания раньше окончания пары больше, чем на порог раннего ухода
+	ExitEarly
+)
+
+// Exit Структура пометки о нахождении участника на собрании: для раннего ухода также указывается, на сколько минут
+// раньше окончания пары участник вышел с собрания
+type Exit struct {
+	Status  ExitStatus
+	Minutes int
+}
+
+/*====================================================================================================================*/
+
+// Подписи пометок на русском языке, которые записываются в историю посещаемости и переводятся в отчётах на выбранный
+// язык. Пометки, которых нет в таблицах, выводятся пустыми
+var (
+	presenceLabels = map[PresenceStatus]string{
+		PresenceFull:    "Присутствовал",
+		PresencePartial: "Присутствовал не полностью",
+		PresenceAbsent:  "Отсутствовал",
+		PresenceExcused: "Отсутствовал (уважительная причина)",
+	}
+	delayLabels = map[DelayStatus]string{
+		DelayNone: "Без опоздания",
+		DelayLate: "Опоздал",
+	}
+	exitLabels = map[ExitStatus]string{
+		ExitFull:  "Полное присутствие на паре",
+		ExitBrief: "Малое присутствие на паре",
+		ExitShort: "Малое нахождение на паре",
+		ExitEarly: "Ушёл раньше на %d мин",
+	}
+)
+
+// delayMinutesLabel Подпись опоздания с количеством минут от начала пары
+const delayMinutesLabel = "Опоздал на %d мин"
+
+// String Функция, возвращающая подпись пометки о присутствии на русском языке
+func (status PresenceStatus) String() string {
+	return presenceLabels[status]
+}
+
+// Label Функция, возвращающая подпись пометки о присутствии для отчёта на выбранном языке
+func (status PresenceStatus) Label() string {
+	return i18n.T(presenceLabels[status])
+}
+
+// IsAbsent Функция, проверяющая, отсутствовал ли участник на собрании
+func (status PresenceStatus) IsAbsent() bool {
+	return status == PresenceAbsent
+}
+
+// IsExcused Функция, проверяющая, отсутствовал ли участник на собрании по уважительной причине
+func (status PresenceStatus) IsExcused() bool {
+	return status == PresenceExcused
+}
+
+// PresenceLabel Функция, возвращающая подпись пометки о присутствии участника для отчёта на выбранном языке. У
+// отсутствовавшего студента, активного в СДО во время пары, добавляется пометка о возможных проблемах с MS Teams
+func (member Member) PresenceLabel() string {
+	if member.LMSActive {
+		return i18n.Sprintf("%v (возможно, проблемы с Teams)", member.Presence.Label())
+	}
+
+	return member.Presence.Label()
+}
+
+// String Функция, возвращающая подпись пометки об опоздании на русском языке
+func (status DelayStatus) String() string {
+	return delayLabels[status]
+}
+
+// Label Функция, возвращающая подпись пометки об опоздании для отчёта на выбранном языке
+func (status DelayStatus) Label() string {
+	return i18n.T(delayLabels[status])
+}
+
+// DelayLabel Функция, возвращающая подпись пометки об опоздании участника для отчёта на выбранном языке. Для
+// опоздавшего участника указывается, на сколько минут от начала пары он опоздал
+func (member Member) DelayLabel() string {
+	if member.Delay == DelayLate && member.DelayMinutes > 0 {
+		return i18n.Sprintf(delayMinutesLabel, member.DelayMinutes)
+	}
+
+	return member.Delay.Label()
+}
+
+// String Функция, возвращающая подпись пометки о нахождении на собрании на русском языке
+func (exit Exit) String() string {
+	if exit.Status == ExitEarly {
+		return fmt.Sprintf(exitLabels[ExitEarly], exit.Minutes)
+	}
+
+	return exitLabels[exit.Status]
+}
+
+// Label Функция, возвращающая подпись пометки о нахождении на собрании для отчёта на выбранном языке
+func (exit Exit) Label() string {
+	if exit.Status == ExitEarly {
+		return i18n.Sprintf(exitLabels[ExitEarly], exit.Minutes)
+	}
+
+	return i18n.T(exitLabels[exit.Status])
+}
+
+// LessonLabel Функция, возвращающая номер пары из оглавления отчёта: название пары из конфигураций или стандартное
+// название на выбранном языке
+func (header Header) LessonLabel() string {
+	if label, ok := LessonLabels[header.LessonNumber]; ok {
+		return label
+	}
+
+	var number int
+	if _, err := fmt.Sscanf(header.LessonNumber, "Пара %d", &number); err == nil {
+		return i18n.Sprintf("Пара %d", number)
+	}
+
+	return i18n.T(header.LessonNumber)
+}
+
+// Generated Функция, возвращающая время формирования отчёта в виде ДД.ММ.ГГГГ ЧЧ:ММ:СС или пустую строку, если
+// время не указано
+func (header Header) Generated() string {
+	if header.GeneratedAt.IsZero() {
+		return ""
+	}
+
+	return header.GeneratedAt.Format(generatedLayout)
+}
+
+// Held Функция, возвращающая фактическое время собрания по отчёту MS Teams в виде ЧЧ:ММ-ЧЧ:ММ, только время начала,
+// если время окончания неизвестно, или пустую строку, если время начала не разобрано
+func (header Header) Held() string {
+	switch {
+	case header.StartTime.IsZero():
+		return ""
+	case header.EndTime.IsZero():
+		return header.StartTime.Format("15:04")
+	}
+
+	return header.StartTime.Format("15:04") + "-" + header.EndTime.Format("15:04")
+}
+
+// DurationLabel Функция, возвращающая продолжительность собрания в виде "1 ч 30 мин" или пустую строку, если она
+// неизвестна
+func (header Header) DurationLabel() string {
+	if header.Duration <= 0 {
+		return ""
+	}
+
+	hours, minutes := header.Duration/3600, header.Duration%3600/60
+	if hours == 0 {
+		return i18n.Sprintf("%d мин", minutes)
+	}
+
+	return i18n.Sprintf("%d ч %d мин", hours, minutes)
+}
+
+// Planned Функция, возвращающая плановое время собрания по календарю организатора в виде ЧЧ:ММ-ЧЧ:ММ или пустую
+// строку, если собрание не сопоставлено с событием календаря
+func (header Header) Planned() string {
+	if header.PlannedStart.IsZero() {
+		return ""
+	}
+
+	return header.PlannedStart.Format("15:04") + "-" + header.PlannedEnd.Format("15:04")
+}
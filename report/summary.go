@@ -0,0 +1,133 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"sort"
+	"strings"
+)
+
+// TotalsGroup Группа итоговой строки итогов посещаемости
+const TotalsGroup = "Всего"
+
+/*====================================================================================================================*/
+
+// SummarizeGroups Функция, подсчитывающая итоги посещаемости по группам участников (в алфавитном порядке групп) и
+// итоговую строку по всем группам. Гости и отсутствовавшие по уважительной причине не учитываются
+func SummarizeGroups(members []Member, guest string) []GroupTotals {
+	indexes := make(map[string]int)
+	var totals []GroupTotals
+	for _, member := range members {
+		if member.FullName == "" || member.Group == guest || member.Presence.IsExcused() {
+			continue
+		}
+		index, ok := indexes[member.Group]
+		if !ok {
+			index = len(totals)
+			indexes[member.Group] = index
+			totals = append(totals, GroupTotals{Group: member.Group})
+		}
+
+		totals[index].Invited++
+		switch member.Presence {
+		case PresenceFull:
+			totals[index].Present++
+		case PresencePartial:
+			totals[index].Partial++
+		case PresenceAbsent:
+			totals[index].Absent++
+		}
+		if member.Delay == DelayLate {
+			totals[index].Late++
+		}
+	}
+	if len(totals) == 0 {
+		return nil
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Group < totals[j].Group })
+
+	//Итоговая строка по всем группам
+	total := GroupTotals{Group: TotalsGroup}
+	for _, group := range totals {
+		total.Invited += group.Invited
+		total.Present += group.Present
+		total.Partial += group.Partial
+		total.Late += group.Late
+		total.Absent += group.Absent
+	}
+
+	return append(totals, total)
+}
+
+// TotalsHeader Функция, возвращающая "шапку" таблицы итогов посещаемости на выбранном языке
+func TotalsHeader() []string {
+	return []string{i18n.T("Группа"), i18n.T("Студентов"), i18n.T("Присутствовали"),
+		i18n.T("Присутствовали не полностью"), i18n.T("Опоздали"), i18n.T("Отсутствовали"), i18n.T("Посещаемость, %")}
+}
+
+// Row Функция, возвращающая строку таблицы итогов посещаемости группы
+func (totals GroupTotals) Row() []string {
+	return []string{i18n.T(totals.Group), fmt.Sprint(totals.Invited), fmt.Sprint(totals.Present),
+		fmt.Sprint(totals.Partial), fmt.Sprint(totals.Late), fmt.Sprint(totals.Absent), fmt.Sprint(totals.Percent())}
+}
+
+/*====================================================================================================================*/
+
+// WriteSummary Функция, записывающая краткую текстовую сводку обработанного собрания: оглавление, количество
+// присутствовавших, опоздавших и отсутствовавших, отсутствующих по группам и путь до итогового отчёта. Сводка
+// предназначена для писем cron с выводом ночной обработки отчётов
+func WriteSummary(out io.Writer, header Header, members []Member, reportPath string) error {
+	summary := Summarize(members)
+
+	//Количество отсутствовавших студентов по группам
+	absentees := make(map[string]int)
+	for _, member := range members {
+		if member.FullName != "" && member.Presence.IsAbsent() {
+			absentees[member.Group]++
+		}
+	}
+	groups := make([]string, 0, len(absentees))
+	for group := range absentees {
+		groups = append(groups, fmt.Sprintf("%v (%d)", group, absentees[group]))
+	}
+	sort.Strings(groups)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%v, %v, %v\n", header.Title, header.Date, header.LessonLabel())
+	fmt.Fprintf(&text, "  %v\n", i18n.Sprintf("Присутствовали: %d, опоздали: %d, отсутствовали: %d", summary.Present,
+		summary.Late, summary.Absent))
+	if header.Quorum.Checked {
+		fmt.Fprintf(&text, "  %v: %v\n", i18n.T("Кворум"), header.Quorum)
+	}
+	if len(groups) > 0 {
+		fmt.Fprintf(&text, "  %v: %v\n", i18n.T("Отсутствовали"), strings.Join(groups, ", "))
+	}
+	if len(header.Groups) > 0 {
+		shares := make([]string, 0, len(header.Groups))
+		for _, group := range header.Groups {
+			shares = append(shares, fmt.Sprintf("%v (%d%%)", i18n.T(group.Group), group.Percent()))
+		}
+		fmt.Fprintf(&text, "  %v: %v\n", i18n.T("Сравнение групп"), strings.Join(shares, ", "))
+	}
+	if len(header.Exam) > 0 {
+		mismatches := 0
+		for _, check := range header.Exam {
+			if check.Mismatch {
+				mismatches++
+			}
+		}
+		fmt.Fprintf(&text, "  %v: %v\n", i18n.T("Проверка экзамена"),
+			i18n.Sprintf("несоответствий %d из %d", mismatches, len(header.Exam)))
+	}
+	if len(header.Warnings) > 0 {
+		fmt.Fprintf(&text, "  %v: %d\n", i18n.T("Предупреждения разбора"), len(header.Warnings))
+	}
+	fmt.Fprintf(&text, "  %v: %v\n", i18n.T("Отчёт"), reportPath)
+
+	if _, err := io.WriteString(out, text.String()); err != nil {
+		return fmt.Errorf("ошибка записи сводки собрания: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"mod.go/i18n"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Template Шаблон отчёта пользователя (text/template), по которому формируется отчёт в формате FormatTemplate.
+// Устанавливается с помощью функции LoadTemplate()
+var Template *template.Template
+
+// TemplateExtension Расширение файла отчёта по шаблону, определяемое по имени файла шаблона ("кафедра.csv.tmpl" -
+// ".csv")
+var TemplateExtension = ".txt"
+
+// templateFuncs Функции, доступные в шаблоне отчёта:
+//
+//	t - перевод строки на выбранный язык: {{t "Присутствовали"}}
+//	csv - строка .csv файла с разделителем ";" из значений: {{csv .Group .FullName .PresenceLabel}}
+//	minutes - продолжительность в секундах в минутах: {{minutes .Duration}}
+//	clock - время присоединения или выхода в виде ЧЧ:ММ:СС: {{clock .Join}}
+var templateFuncs = template.FuncMap{
+	"t":       i18n.T,
+	"csv":     csvLine,
+	"minutes": func(seconds int) int { return seconds / 60 },
+	"clock":   clockTime,
+}
+
+// TemplateData Структура данных, передаваемых в шаблон отчёта
+type TemplateData struct {
+	//Оглавление собрания
+	Header Header
+	//Участники собрания без инициатора, гости и преподаватели
+	Members, Guests, Staff []Member
+	//Сводка посещаемости участников и гостей
+	Summary Summary
+	//Названия столбцов таблицы участников из конфигураций
+	Columns []string
+	//Время формирования отчёта в виде ДД.ММ.ГГГГ ЧЧ:ММ:СС
+	Generated string
+}
+
+/*====================================================================================================================*/
+
+// LoadTemplate Функция, считывающая шаблон отчёта пользователя. Расширение отчёта берётся из имени файла шаблона без
+// ".tmpl", а если его нет - отчёт сохраняется с расширением .txt
+func LoadTemplate(path string) (*template.Template, string, error) {
+	parsed, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("ошибка чтения шаблона отчёта: %w", err)
+	}
+
+	extension := filepath.Ext(strings.TrimSuffix(filepath.Base(path), ".tmpl"))
+	if extension == "" {
+		extension = ".txt"
+	}
+
+	return parsed, extension, nil
+}
+
+// TemplatePath Функция, возвращающая полный путь до отчёта, сформированного по шаблону функцией FormTemplateReport()
+func TemplatePath(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Отчёт о проведение собрания_") + header.FileName() + TemplateExtension
+}
+
+// FormTemplateReport Функция, формирующая отчёт по шаблону пользователя: кафедры выбирают нужные столбцы, их порядок,
+// текст оглавления и подвала. Отчёт с расширением .csv записывается с BOM, как и стандартный отчёт
+func FormTemplateReport(ctx context.Context, header Header, members, guests []Member, reportLocationPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if Template == nil {
+		return fmt.Errorf("не указан шаблон отчёта (template_path в секции [report] cfg.ini)")
+	}
+
+	//Пустые участники (инициатор собрания) в шаблон не передаются
+	listed := func(members []Member) []Member {
+		result := make([]Member, 0, len(members))
+		for _, member := range members {
+			if member.FullName != "" {
+				result = append(result, member)
+			}
+		}
+		return result
+	}
+	data := TemplateData{
+		Header:    header,
+		Members:   listed(members),
+		Guests:    listed(guests),
+		Staff:     header.Staff,
+		Summary:   Summarize(append(append([]Member{}, members...), guests...)),
+		Columns:   ColumnLabels.Header(false, HasRecordBooks(members), false),
+		Generated: header.Generated(),
+	}
+	if data.Generated == "" {
+		data.Generated = time.Now().Format(generatedLayout)
+	}
+
+	//Отчёт сначала формируется в памяти, чтобы ошибка шаблона не оставила недописанный файл
+	var text bytes.Buffer
+	if TemplateExtension == ".csv" {
+		text.WriteString("\xEF\xBB\xBF")
+	}
+	if err := Template.Execute(&text, data); err != nil {
+		return fmt.Errorf("ошибка формирования отчёта по шаблону: %w", err)
+	}
+
+	if err := os.WriteFile(TemplatePath(header, reportLocationPath), text.Bytes(), 0644); err != nil {
+		return fmt.Errorf("ошибка записи отчёта по шаблону: %w", err)
+	}
+
+	return nil
+}
+
+// csvLine Вспомогательная функция шаблона, возвращающая строку .csv файла с разделителем ";" из значений
+func csvLine(values ...interface{}) (string, error) {
+	fields := make([]string, 0, len(values))
+	for _, value := range values {
+		fields = append(fields, fmt.Sprint(value))
+	}
+
+	var line bytes.Buffer
+	writer := csv.NewWriter(&line)
+	writer.Comma = ';'
+	if err := writer.Write(fields); err != nil {
+		return "", err
+	}
+	writer.Flush()
+
+	return strings.TrimSuffix(line.String(), "\n"), writer.Error()
+}
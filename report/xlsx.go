@@ -0,0 +1,204 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Sheet Структура листа книги .xlsx: название листа и строки таблицы
+type Sheet struct {
+	Name string
+	Rows [][]string
+}
+
+// xlsxParts Постоянные части книги .xlsx: типы содержимого и связи книги
+var xlsxParts = map[string]string{
+	"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`%s</Types>`,
+	"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`,
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>%s</sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">%s</Relationships>`,
+}
+
+/*====================================================================================================================*/
+
+// XLSXPath Функция, возвращающая полный путь до отчёта в виде книги .xlsx, сформированного функцией FormXLSXReport()
+func XLSXPath(header Header, reportLocationPath string) string {
+	return reportLocationPath + i18n.T("Отчёт о проведение собрания_") + header.FileName() + ".xlsx"
+}
+
+// FormXLSXReport Функция, формирующая отчёт в виде книги .xlsx. Листы книги совпадают с таблицами отчёта в формате
+// JSON при просмотре командой view: оглавление собрания, итоги, участники, гости и преподаватели. Если задан пароль
+// XLSXPassword, книга шифруется
+func FormXLSXReport(ctx context.Context, header Header, members, guests []Member, reportLocationPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	//Листы книги строятся из отчёта в формате JSON, записанного в памяти
+	var buffer bytes.Buffer
+	if err := WriteJSON(&buffer, header, members, guests); err != nil {
+		return err
+	}
+	sheets, err := ReadJSONSheets(&buffer)
+	if err != nil {
+		return err
+	}
+
+	var workbook bytes.Buffer
+	if err := WriteProtectedXLSX(&workbook, sheets); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(XLSXPath(header, reportLocationPath), workbook.Bytes(), 0644); err != nil {
+		return fmt.Errorf("ошибка создания файла: %w", err)
+	}
+
+	return nil
+}
+
+// WriteProtectedXLSX Функция, записывающая листы в книгу .xlsx, которая шифруется паролем XLSXPassword, если он задан.
+// Через неё записываются все книги с персональными данными: итоговые отчёты и выгрузки веб-панели
+func WriteProtectedXLSX(out io.Writer, sheets []Sheet) error {
+	if XLSXPassword == "" {
+		return WriteXLSX(out, sheets)
+	}
+
+	var workbook bytes.Buffer
+	if err := WriteXLSX(&workbook, sheets); err != nil {
+		return err
+	}
+	data, err := EncryptXLSX(workbook.Bytes(), XLSXPassword)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("ошибка записи книги .xlsx: %w", err)
+	}
+
+	return nil
+}
+
+// WriteXLSX Функция, записывающая листы в книгу .xlsx. Целые числа (без ведущих нулей, чтобы не потерять номера
+// зачёток) записываются числовыми ячейками, остальные значения - текстом
+func WriteXLSX(out io.Writer, sheets []Sheet) error {
+	archive := zip.NewWriter(out)
+
+	var overrides, entries, relationships strings.Builder
+	for i, sheet := range sheets {
+		fmt.Fprintf(&overrides, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&entries, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(sheetName(sheet.Name, i)), i+1,
+			i+1)
+		fmt.Fprintf(&relationships, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+
+	parts := [][2]string{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxParts["[Content_Types].xml"], overrides.String())},
+		{"_rels/.rels", xlsxParts["_rels/.rels"]},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxParts["xl/workbook.xml"], entries.String())},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxParts["xl/_rels/workbook.xml.rels"], relationships.String())},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, [2]string{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheetXML(sheet.Rows)})
+	}
+
+	for _, part := range parts {
+		writer, err := archive.Create(part[0])
+		if err != nil {
+			return fmt.Errorf("ошибка записи книги .xlsx: %w", err)
+		}
+		if _, err := io.WriteString(writer, part[1]); err != nil {
+			return fmt.Errorf("ошибка записи книги .xlsx: %w", err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("ошибка записи книги .xlsx: %w", err)
+	}
+
+	return nil
+}
+
+// sheetXML Вспомогательная функция, формирующая содержимое листа книги .xlsx
+func sheetXML(rows [][]string) string {
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			reference := columnName(j) + strconv.Itoa(i+1)
+			if number, err := strconv.Atoi(value); err == nil && strconv.Itoa(number) == value {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, reference, value)
+			} else {
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, reference,
+					escapeXML(value))
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	return sheet.String()
+}
+
+// columnName Вспомогательная функция, возвращающая буквенное обозначение столбца по его номеру с нуля (0 - "A",
+// 26 - "AA")
+func columnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+
+	return name
+}
+
+// sheetName Вспомогательная функция, приводящая название листа к допустимому в Excel: без символов []:*?/\ и не
+// длиннее 31 символа. Пустое название заменяется номером листа
+func sheetName(name string, index int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = strconv.Itoa(index + 1)
+	}
+
+	return name
+}
+
+// escapeXML Вспомогательная функция, экранирующая текст для XML
+func escapeXML(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+
+	return escaped.String()
+}
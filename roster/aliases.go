@@ -0,0 +1,86 @@
+package roster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// GroupAliases Прежние названия переименованных групп (ключ - прежнее название, значение - текущее название).
+// Устанавливаются из файла конфигураций с помощью функции ParseGroupAliases()
+var GroupAliases = map[string]string{}
+
+/*====================================================================================================================*/
+
+// ParseGroupAliases Функция, разбирающая перечисленные через запятую переименования групп вида "МП-31=МПб-31"
+func ParseGroupAliases(source string) (map[string]string, error) {
+	aliases := make(map[string]string)
+
+	for _, pair := range strings.Split(source, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		names := strings.Split(pair, "=")
+		if len(names) != 2 || strings.TrimSpace(names[0]) == "" || strings.TrimSpace(names[1]) == "" {
+			return nil, fmt.Errorf("некорректное переименование группы \"%v\", ожидается вид \"МП-31=МПб-31\"", pair)
+		}
+
+		aliases[strings.TrimSpace(names[0])] = strings.TrimSpace(names[1])
+	}
+
+	//Цепочки переименований (МП-31 -> МПб-31 -> МПб-31у) сводятся к последнему названию
+	for old, current := range aliases {
+		for i := 0; i < len(aliases); i++ {
+			next, ok := findAlias(aliases, current)
+			if !ok {
+				break
+			}
+			current = next
+		}
+		aliases[old] = current
+	}
+
+	return aliases, nil
+}
+
+// CanonicalGroup Функция, возвращающая текущее название группы по прежнему (без учёта регистра). Название группы,
+// которая не переименовывалась, возвращается без изменений
+func CanonicalGroup(group string) string {
+	if current, ok := findAlias(GroupAliases, group); ok {
+		return current
+	}
+
+	return group
+}
+
+// findAlias Вспомогательная функция, находящая текущее название группы по прежнему без учёта регистра
+func findAlias(aliases map[string]string, group string) (string, bool) {
+	if current, ok := aliases[group]; ok {
+		return current, true
+	}
+	for old, current := range aliases {
+		if strings.EqualFold(old, group) {
+			return current, true
+		}
+	}
+
+	return "", false
+}
+
+// GroupNames Функция, возвращающая все названия группы: текущее и прежние
+func GroupNames(group string) []string {
+	current := CanonicalGroup(group)
+	names := []string{current}
+
+	for old, name := range GroupAliases {
+		if name == current && !strings.EqualFold(old, current) {
+			names = append(names, old)
+		}
+	}
+	sort.Strings(names[1:])
+
+	return names
+}
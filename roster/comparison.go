@@ -0,0 +1,47 @@
+package roster
+
+import (
+	"mod.go/report"
+	"sort"
+)
+
+/*====================================================================================================================*/
+
+// CompareGroups Функция, подсчитывающая посещаемость каждой группы собрания для сравнения групп на потоковой лекции.
+// Гости и студенты, отсутствовавшие по уважительной причине, не учитываются. Группы упорядочены по возрастанию
+// посещаемости, чтобы группы, пропускающие лекцию, были первыми. Если на собрании одна группа, сравнение не
+// формируется
+func CompareGroups(members []report.Member) []report.GroupAttendance {
+	indexes := make(map[string]int)
+	var groups []report.GroupAttendance
+
+	for _, member := range members {
+		if member.FullName == "" || member.Group == Guest || member.Presence.IsExcused() {
+			continue
+		}
+
+		index, ok := indexes[member.Group]
+		if !ok {
+			index = len(groups)
+			indexes[member.Group] = index
+			groups = append(groups, report.GroupAttendance{Group: member.Group})
+		}
+		groups[index].Expected++
+		if !member.Presence.IsAbsent() {
+			groups[index].Present++
+		}
+	}
+
+	if len(groups) < 2 {
+		return nil
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Percent() != groups[j].Percent() {
+			return groups[i].Percent() < groups[j].Percent()
+		}
+		return groups[i].Group < groups[j].Group
+	})
+
+	return groups
+}
@@ -0,0 +1,80 @@
+package roster
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// DefaultTitle Название собрания, которое выставляется в отчёте, если собрание в MS Teams не переименовано ("General")
+const DefaultTitle = "Название по-умолчанию"
+
+// Course Структура дисциплины, к которой относится собрание: по названию собрания определяются название дисциплины,
+// группы, студенты которых ожидаются на собрании, и преподаватель
+type Course struct {
+	//Шаблон названия собрания (регулярное выражение, регистр не учитывается)
+	Pattern *regexp.Regexp
+	//Название дисциплины для оглавления отчёта
+	Name string
+	//Группы дисциплины: их отсутствующие студенты добавляются в отчёт, даже если никто из группы не пришёл
+	Groups []string
+	//ФИО преподавателя дисциплины
+	Teacher string
+}
+
+// Courses Дисциплины в порядке указания в файле конфигураций. Устанавливаются из файла конфигураций
+var Courses []Course
+
+/*====================================================================================================================*/
+
+// ParseCourse Функция, считывающая дисциплину из строки секции courses файла конфигураций: ключ - шаблон названия
+// собрания, значение - "Дисциплина | группы через запятую | преподаватель" (группы и преподаватель необязательны)
+func ParseCourse(pattern, value string) (Course, error) {
+	var course Course
+
+	compiled, err := regexp.Compile("(?i)" + strings.TrimSpace(pattern))
+	if err != nil {
+		return course, fmt.Errorf("некорректный шаблон названия собрания \"%v\": %w", pattern, err)
+	}
+	course.Pattern = compiled
+
+	//Дополняем значение пустыми полями до полного набора полей
+	fields := strings.Split(value, "|")
+	for len(fields) < 3 {
+		fields = append(fields, "")
+	}
+
+	course.Name, course.Teacher = strings.TrimSpace(fields[0]), strings.TrimSpace(fields[2])
+	if course.Name == "" {
+		return course, fmt.Errorf("не указано название дисциплины для шаблона \"%v\"", pattern)
+	}
+	for _, group := range strings.Split(fields[1], ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			course.Groups = append(course.Groups, CanonicalGroup(group))
+		}
+	}
+
+	return course, nil
+}
+
+// FindCourse Функция, находящая дисциплину собрания: первую дисциплину, шаблон которой подходит под название
+// собрания. Собрание, не переименованное в MS Teams, относится к первой дисциплине его преподавателя (инициатора)
+func FindCourse(title, lecturer string) (Course, bool) {
+	for _, course := range Courses {
+		if course.Pattern.MatchString(title) {
+			return course, true
+		}
+	}
+
+	if title == DefaultTitle && lecturer != "" {
+		for _, course := range Courses {
+			if strings.EqualFold(course.Teacher, lecturer) {
+				return course, true
+			}
+		}
+	}
+
+	return Course{}, false
+}
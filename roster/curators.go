@@ -0,0 +1,83 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Curator Структура куратора группы, которому направляются отчёты и оповещения по его группе
+type Curator struct {
+	//Группа, которую курирует куратор
+	Group string
+	//ФИО куратора
+	FullName string
+	//Адрес электронной почты куратора
+	Email string
+	//Имя пользователя или идентификатор чата куратора в Telegram
+	Telegram string
+	//Номер телефона куратора для WhatsApp, Viber или SMS (например, "+79001234567")
+	Phone string
+}
+
+// LoadCurators Функция, считывающая файл кураторов групп (строки вида "Группа,ФИО,Email,Telegram,Телефон") в карту с
+// ключом - группой. Файл кураторов необязателен: если его нет, возвращается пустая карта
+func LoadCurators(path string) (map[string]Curator, error) {
+	//Карта кураторов по группам
+	curators := make(map[string]Curator)
+
+	//Открываем файл кураторов, отсутствие файла не является ошибкой
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return curators, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла кураторов: %w", err)
+	}
+
+	//Закрываем файл после окончания функции
+	defer file.Close()
+
+	//Читаем поток данных из файла кураторов, количество полей в строке может отличаться (email, Telegram и телефон
+	// необязательны)
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	//Цикл по всем строкам в файле
+	for {
+		row, err := reader.Read()
+		//При окончании файла выходим из цикла
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла кураторов: %w", err)
+		}
+
+		//Дополняем строку пустыми полями до полного набора столбцов
+		for len(row) < 5 {
+			row = append(row, "")
+		}
+
+		curator := Curator{
+			Group:    strings.TrimSpace(row[0]),
+			FullName: strings.TrimSpace(row[1]),
+			Email:    strings.TrimSpace(row[2]),
+			Telegram: strings.TrimSpace(row[3]),
+			Phone:    strings.TrimSpace(row[4]),
+		}
+		if curator.Group == "" {
+			return nil, fmt.Errorf("в файле кураторов не указана группа для куратора %v", curator.FullName)
+		}
+
+		curators[curator.Group] = curator
+	}
+
+	return curators, nil
+}
@@ -0,0 +1,69 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Emails Адреса электронной почты студентов (ключ - адрес в нижнем регистре, значение - ФИО из базы групп).
+// Устанавливаются из необязательного четвёртого столбца базы групп с помощью функции LoadEmails()
+var Emails = map[string]string{}
+
+/*====================================================================================================================*/
+
+// LoadEmails Функция, считывающая адреса электронной почты студентов из базы групп (строки вида
+// "ФИО,Группа,Зачётка,Email"). Почта указывается не у всех студентов, а при отсутствии файла базы групп возвращается
+// пустая карта
+func LoadEmails(path string) (map[string]string, error) {
+	emails := make(map[string]string)
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return emails, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла базы групп: %w", err)
+	}
+	defer file.Close()
+
+	//Количество столбцов в строках базы может различаться
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла базы групп: %w", err)
+		}
+
+		if len(row) > 3 && strings.TrimSpace(row[3]) != "" {
+			emails[strings.ToLower(strings.TrimSpace(row[3]))] = row[0]
+		}
+	}
+
+	return emails, nil
+}
+
+// FindByEmail Функция, возвращающая ФИО студента из базы групп по адресу электронной почты участника собрания. Почта
+// надёжнее отображаемого имени, которое студенты меняют, поэтому участник сопоставляется с базой в первую очередь по
+// почте, а по ФИО - только если почты нет в базе
+func FindByEmail(email string) (string, bool) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return "", false
+	}
+	fullName, ok := Emails[email]
+
+	return fullName, ok
+}
@@ -0,0 +1,164 @@
+package roster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/schedule"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Assignment Структура назначения студента на экзамен: вариант и время, в которое студент должен быть на собрании
+type Assignment struct {
+	//ФИО студента
+	FullName string
+	//Вариант экзамена
+	Variant string
+	//Начало и окончание назначенного времени в секундах от начала суток
+	Start, End int
+}
+
+// ExamOnTime Результат проверки студента, присутствовавшего на собрании всё назначенное время
+const ExamOnTime = "в назначенное время"
+
+/*====================================================================================================================*/
+
+// LoadAssignments Функция, считывающая список вариантов экзамена: .csv файл со строками вида "ФИО,Вариант,ЧЧ:ММ-ЧЧ:ММ".
+// Строка "шапки" ("ФИО") и пустые строки пропускаются
+func LoadAssignments(path string) ([]Assignment, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия списка вариантов экзамена: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	var assignments []Assignment
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения списка вариантов экзамена: %w", err)
+		}
+
+		//Убираем BOM, который добавляет MS Excel при сохранении в .csv
+		fullName := strings.TrimSpace(strings.TrimPrefix(row[0], "\uFEFF"))
+		if fullName == "" || strings.EqualFold(fullName, "ФИО") {
+			continue
+		}
+		if len(row) < 3 {
+			return nil, fmt.Errorf("в списке вариантов экзамена не указан вариант или время для студента %v", fullName)
+		}
+
+		assignment := Assignment{FullName: strings.Join(strings.Fields(fullName), " "),
+			Variant: strings.TrimSpace(row[1])}
+		bounds := strings.Split(row[2], "-")
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("некорректное время экзамена \"%v\" для студента %v, ожидается вид ЧЧ:ММ-ЧЧ:ММ",
+				row[2], fullName)
+		}
+		if assignment.Start, err = schedule.ParseClock(bounds[0]); err != nil {
+			return nil, fmt.Errorf("некорректное время экзамена для студента %v: %w", fullName, err)
+		}
+		if assignment.End, err = schedule.ParseClock(bounds[1]); err != nil {
+			return nil, fmt.Errorf("некорректное время экзамена для студента %v: %w", fullName, err)
+		}
+		if assignment.End <= assignment.Start {
+			return nil, fmt.Errorf("окончание времени экзамена раньше начала для студента %v", fullName)
+		}
+
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+// CheckExam Функция, проверяющая, присутствовал ли каждый студент из списка вариантов на собрании всё назначенное ему
+// время (с допуском tolerance в секундах): не подключился позже начала, не вышел раньше окончания и находился на
+// собрании не меньше назначенного времени. ФИО из списка сопоставляется со студентом базы так же, как ФИО гостя.
+// Студенты базы, подключавшиеся к собранию, но отсутствующие в списке вариантов, также отмечаются несоответствием
+func (base Base) CheckExam(members []report.Member, assignments []Assignment, tolerance,
+	distance int) []report.ExamCheck {
+	//Индексы участников собрания по ФИО без учёта регистра и "ё"
+	indexes := make(map[string]int)
+	for i := range members {
+		if members[i].FullName != "" {
+			indexes[string(normalizeName(members[i].FullName))] = i
+		}
+	}
+
+	var checks []report.ExamCheck
+	assigned := make(map[string]bool)
+	for _, assignment := range assignments {
+		//ФИО из списка приводится к ФИО из базы групп, если студент найден
+		fullName := assignment.FullName
+		if _, ok := base[fullName]; !ok {
+			if matched, ok := base.MatchGuest(fullName, distance); ok {
+				fullName = matched
+			}
+		}
+		assigned[string(normalizeName(fullName))] = true
+
+		check := report.ExamCheck{FullName: fullName, Variant: assignment.Variant,
+			Window: schedule.FormatClock(assignment.Start) + "-" + schedule.FormatClock(assignment.End)}
+		index, ok := indexes[string(normalizeName(fullName))]
+		if !ok || members[index].Presence.IsAbsent() || members[index].Join.IsZero() {
+			check.Result, check.Mismatch = i18n.T("не подключался"), true
+			checks = append(checks, check)
+			continue
+		}
+		member := members[index]
+		check.Join, check.Leave = member.Join, member.Leave
+
+		//Время присоединения и выхода сравнивается с назначенным временем в секундах от начала суток
+		join := member.Join.Hour()*3600 + member.Join.Minute()*60 + member.Join.Second()
+		leave := member.Leave.Hour()*3600 + member.Leave.Minute()*60 + member.Leave.Second()
+		var problems []string
+		if join > assignment.Start+tolerance {
+			problems = append(problems, i18n.Sprintf("подключился позже начала на %d мин", (join-assignment.Start)/60))
+		}
+		if leave < assignment.End-tolerance {
+			problems = append(problems, i18n.Sprintf("вышел раньше окончания на %d мин", (assignment.End-leave)/60))
+		}
+		if len(problems) == 0 && member.Duration < assignment.End-assignment.Start-tolerance {
+			problems = append(problems, i18n.Sprintf("отключался во время экзамена (на собрании %d мин из %d)",
+				member.Duration/60, (assignment.End-assignment.Start)/60))
+		}
+
+		check.Result, check.Mismatch = i18n.T(ExamOnTime), len(problems) > 0
+		if check.Mismatch {
+			check.Result = strings.Join(problems, "; ")
+		}
+		checks = append(checks, check)
+	}
+
+	//Студенты базы на собрании, которым не назначен вариант
+	for _, member := range members {
+		if member.FullName == "" || member.Group == Guest || member.Presence.IsAbsent() || member.Join.IsZero() ||
+			assigned[string(normalizeName(member.FullName))] {
+			continue
+		}
+		checks = append(checks, report.ExamCheck{FullName: member.FullName, Join: member.Join, Leave: member.Leave,
+			Result: i18n.T("нет в списке вариантов"), Mismatch: true})
+	}
+
+	//Несоответствия выводятся первыми, далее - по ФИО
+	sort.SliceStable(checks, func(i, j int) bool {
+		if checks[i].Mismatch != checks[j].Mismatch {
+			return checks[i].Mismatch
+		}
+		return checks[i].FullName < checks[j].FullName
+	})
+
+	return checks
+}
@@ -0,0 +1,92 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Excuse Структура уважительной причины отсутствия студента на парах в течение периода (болезнь, академический отпуск)
+type Excuse struct {
+	//ФИО студента из базы групп
+	FullName string
+	//Первый и последний день периода отсутствия
+	From, To time.Time
+	//Причина отсутствия ("болеет", "академ")
+	Reason string
+}
+
+// Excuses Уважительные причины отсутствия студентов. Устанавливаются с помощью функции LoadExcuses()
+var Excuses []Excuse
+
+/*====================================================================================================================*/
+
+// LoadExcuses Функция, считывающая файл уважительных причин (строки вида "ФИО,Период,Причина"). Период указывается
+// датой ("20.04.2022") или периодом ("01.02.2024-28.02.2024"). Строка "шапки" и пустые строки пропускаются. Файл
+// необязателен: если его нет, возвращается пустой список
+func LoadExcuses(path string) ([]Excuse, error) {
+	//Открываем файл уважительных причин, отсутствие файла не является ошибкой
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла уважительных причин: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	var excuses []Excuse
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла уважительных причин: %w", err)
+		}
+
+		//Убираем BOM, который добавляет MS Excel при сохранении в .csv
+		fullName := strings.TrimSpace(strings.TrimPrefix(row[0], "\uFEFF"))
+		if fullName == "" || strings.EqualFold(fullName, "ФИО") {
+			continue
+		}
+		if len(row) < 2 {
+			return nil, fmt.Errorf("в строке файла уважительных причин не указан период отсутствия: %v",
+				strings.Join(row, ","))
+		}
+
+		excuse := Excuse{FullName: fullName}
+		if excuse.From, excuse.To, err = parsePeriod(row[1]); err != nil {
+			return nil, fmt.Errorf("некорректный период отсутствия \"%v\": %w", row[1], err)
+		}
+		if len(row) > 2 {
+			excuse.Reason = strings.TrimSpace(row[2])
+		}
+
+		excuses = append(excuses, excuse)
+	}
+
+	return excuses, nil
+}
+
+// FindExcuse Функция, находящая уважительную причину отсутствия студента в указанный день
+func FindExcuse(fullName string, date time.Time) (Excuse, bool) {
+	for _, excuse := range Excuses {
+		if strings.EqualFold(excuse.FullName, fullName) && !date.Before(excuse.From) && !date.After(excuse.To) {
+			return excuse, true
+		}
+	}
+
+	return Excuse{}, false
+}
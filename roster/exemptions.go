@@ -0,0 +1,152 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/report"
+	"os"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Exemption Структура освобождения студента или группы от посещения пар (например, практика по средам)
+type Exemption struct {
+	//Группа или ФИО студента, на которых распространяется освобождение
+	Subject string
+	//День недели освобождения, если освобождение еженедельное
+	Weekday time.Weekday
+	//Является ли освобождение еженедельным
+	Weekly bool
+	//Первый и последний день освобождения, если освобождение на период (или на один день)
+	From, To time.Time
+	//Причина освобождения
+	Reason string
+}
+
+// weekdays Дни недели в файле освобождений: полные названия и сокращения
+var weekdays = map[string]time.Weekday{
+	"пн": time.Monday, "понедельник": time.Monday,
+	"вт": time.Tuesday, "вторник": time.Tuesday,
+	"ср": time.Wednesday, "среда": time.Wednesday,
+	"чт": time.Thursday, "четверг": time.Thursday,
+	"пт": time.Friday, "пятница": time.Friday,
+	"сб": time.Saturday, "суббота": time.Saturday,
+	"вс": time.Sunday, "воскресенье": time.Sunday,
+}
+
+/*====================================================================================================================*/
+
+// LoadExemptions Функция, считывающая файл освобождений (строки вида "Группа или ФИО,День,Причина"). День указывается
+// днём недели ("ср", "среда"), датой ("20.04.2022") или периодом ("01.02.2024-28.02.2024"). Файл освобождений
+// необязателен: если его нет, возвращается пустой список
+func LoadExemptions(path string) ([]Exemption, error) {
+	//Открываем файл освобождений, отсутствие файла не является ошибкой
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла освобождений: %w", err)
+	}
+
+	//Закрываем файл после окончания функции
+	defer file.Close()
+
+	//Читаем поток данных из файла освобождений, причина освобождения необязательна
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	//Список освобождений
+	var exemptions []Exemption
+
+	//Цикл по всем строкам в файле
+	for {
+		row, err := reader.Read()
+		//При окончании файла выходим из цикла
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла освобождений: %w", err)
+		}
+		if len(row) < 2 {
+			return nil, fmt.Errorf("в строке файла освобождений не указан день освобождения: %v", strings.Join(row, ","))
+		}
+
+		exemption := Exemption{Subject: strings.TrimSpace(row[0])}
+		if len(row) > 2 {
+			exemption.Reason = strings.TrimSpace(row[2])
+		}
+
+		//Разбор дня освобождения: день недели, период или одна дата
+		day := strings.ToLower(strings.TrimSpace(row[1]))
+		if weekday, ok := weekdays[day]; ok {
+			exemption.Weekday, exemption.Weekly = weekday, true
+		} else if exemption.From, exemption.To, err = parsePeriod(day); err != nil {
+			return nil, fmt.Errorf("некорректный день освобождения \"%v\": %w", row[1], err)
+		}
+
+		exemptions = append(exemptions, exemption)
+	}
+
+	return exemptions, nil
+}
+
+// parsePeriod Вспомогательная функция, разбирающая период вида "01.02.2024-28.02.2024" или одну дату "20.04.2022"
+// (первый и последний день периода совпадают)
+func parsePeriod(source string) (time.Time, time.Time, error) {
+	bounds := strings.Split(source, "-")
+	from, err := time.Parse("02.01.2006", strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return from, from, err
+	}
+	to := from
+	if len(bounds) > 1 {
+		if to, err = time.Parse("02.01.2006", strings.TrimSpace(bounds[1])); err != nil {
+			return from, to, err
+		}
+	}
+
+	return from, to, nil
+}
+
+// IsExempt Функция, проверяющая, освобождён ли студент указанной группы от посещения пар в указанный день
+func IsExempt(exemptions []Exemption, fullName, group string, date time.Time) bool {
+	for _, exemption := range exemptions {
+		//Освобождение относится к группе студента или к самому студенту
+		if exemption.Subject != group && exemption.Subject != fullName {
+			continue
+		}
+
+		if exemption.Weekly {
+			if date.Weekday() == exemption.Weekday {
+				return true
+			}
+		} else if !date.Before(exemption.From) && !date.After(exemption.To) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExcludeExempt Функция, убирающая из списка участников собрания отсутствующих студентов, которые в день собрания
+// освобождены от посещения пар
+func ExcludeExempt(members []report.Member, exemptions []Exemption, date time.Time) []report.Member {
+	//Массив участников без освобождённых отсутствующих студентов
+	var result []report.Member
+
+	for _, member := range members {
+		if member.Presence.IsAbsent() && IsExempt(exemptions, member.FullName, member.Group, date) {
+			continue
+		}
+		result = append(result, member)
+	}
+
+	return result
+}
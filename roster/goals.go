@@ -0,0 +1,81 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Goals Цели посещаемости групп в процентах: ключ - группа и месяц ("МТ-201 04.2022") или группа и "*" для всех
+// месяцев, для которых цель не указана отдельно
+type Goals map[string]int
+
+/*====================================================================================================================*/
+
+// LoadGoals Функция, считывающая файл целей посещаемости (строки вида "Группа,Месяц,Цель", где месяц - ММ.ГГГГ или *
+// для всех месяцев, а цель - доля посещённых занятий в процентах). Файл целей необязателен: если его нет, возвращается
+// пустая карта
+func LoadGoals(path string) (Goals, error) {
+	goals := make(Goals)
+
+	//Открываем файл целей, отсутствие файла не является ошибкой
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return goals, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла целей посещаемости: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 3
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла целей посещаемости: %w", err)
+		}
+
+		group, month := CanonicalGroup(strings.TrimSpace(row[0])), strings.TrimSpace(row[1])
+		if month != "*" {
+			if _, err := time.Parse("01.2006", month); err != nil {
+				return nil, fmt.Errorf("некорректный месяц цели посещаемости группы %v: %v, ожидается вид ММ.ГГГГ",
+					group, month)
+			}
+		}
+
+		target, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(row[2]), "%")))
+		if err != nil || target < 0 || target > 100 {
+			return nil, fmt.Errorf("цель посещаемости группы %v должна быть указана в процентах от 0 до 100: %v", group,
+				row[2])
+		}
+
+		goals[group+" "+month] = target
+	}
+
+	return goals, nil
+}
+
+// Target Функция, возвращающая цель посещаемости группы на месяц: цель на этот месяц или, если её нет, цель на все
+// месяцы
+func (goals Goals) Target(group string, month time.Time) (int, bool) {
+	group = CanonicalGroup(group)
+	if target, ok := goals[group+" "+month.Format("01.2006")]; ok {
+		return target, true
+	}
+	target, ok := goals[group+" *"]
+
+	return target, ok
+}
@@ -0,0 +1,130 @@
+package roster
+
+import (
+	"fmt"
+	"mod.go/report"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Способы обработки гостей собрания - участников, которых нет в базе групп
+const (
+	//Гости остаются в общей таблице участников
+	GuestInclude = "include"
+	//Гости убираются из отчёта
+	GuestDrop = "drop"
+	//Гости выводятся отдельным списком после таблицы участников
+	GuestSeparate = "separate"
+	//Гость сопоставляется со студентом базы с похожим ФИО, несопоставленные гости выводятся отдельным списком
+	GuestMatch = "match"
+)
+
+/*====================================================================================================================*/
+
+// ParseGuestPolicy Функция, проверяющая способ обработки гостей из файла конфигураций. По-умолчанию гости остаются
+// в общей таблице участников
+func ParseGuestPolicy(source string) (string, error) {
+	switch policy := strings.ToLower(strings.TrimSpace(source)); policy {
+	case "":
+		return GuestInclude, nil
+	case GuestInclude, GuestDrop, GuestSeparate, GuestMatch:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("неизвестный способ обработки гостей: %v (допустимы include, drop, separate, match)", source)
+	}
+}
+
+// ApplyGuestPolicy Функция, применяющая способ обработки гостей к списку участников собрания. Возвращает участников,
+// попадающих в общую таблицу, и гостей, выводимых отдельным списком
+func (base Base) ApplyGuestPolicy(members []report.Member, policy string, distance int) ([]report.Member, []report.Member) {
+	if policy == GuestInclude {
+		return members, nil
+	}
+
+	var result, guests []report.Member
+	for _, member := range members {
+		if member.Group != Guest {
+			result = append(result, member)
+			continue
+		}
+
+		//Гость, ФИО которого отличается от ФИО студента базы не больше, чем на заданное количество символов,
+		// считается этим студентом
+		if policy == GuestMatch {
+			if fullName, ok := base.MatchGuest(member.FullName, distance); ok {
+				member.MatchedFrom = member.FullName
+				member.FullName, member.Group = fullName, base.SetGroup(fullName)
+				result = append(result, member)
+				continue
+			}
+		}
+
+		if policy != GuestDrop {
+			guests = append(guests, member)
+		}
+	}
+
+	return result, guests
+}
+
+// MatchGuest Функция, находящая в базе студента, ФИО которого ближе всего к ФИО гостя (без учёта регистра, "ё" и
+// лишних пробелов), если расстояние между ними не больше заданного. Если таких студентов несколько, гость не
+// сопоставляется
+func (base Base) MatchGuest(fullName string, distance int) (string, bool) {
+	guest := normalizeName(fullName)
+
+	best, bestDistance, ambiguous := "", distance+1, false
+	for candidate := range base {
+		//Гости сопоставляются только со студентами
+		if base.IsTeacher(candidate) {
+			continue
+		}
+
+		current := levenshtein(guest, normalizeName(candidate))
+		switch {
+		case current < bestDistance:
+			best, bestDistance, ambiguous = candidate, current, false
+		case current == bestDistance:
+			ambiguous = true
+		}
+	}
+
+	if best == "" || ambiguous {
+		return "", false
+	}
+
+	return best, true
+}
+
+// normalizeName Вспомогательная функция, приводящая ФИО к нижнему регистру, заменяющая "ё" на "е" и убирающая
+// лишние пробелы
+func normalizeName(fullName string) []rune {
+	name := strings.Join(strings.Fields(strings.ToLower(fullName)), " ")
+
+	return []rune(strings.ReplaceAll(name, "ё", "е"))
+}
+
+// levenshtein Вспомогательная функция, возвращающая расстояние Левенштейна между строками: наименьшее количество
+// вставок, удалений и замен символов, переводящих одну строку в другую
+func levenshtein(first, second []rune) int {
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(second)]
+}
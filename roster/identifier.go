@@ -0,0 +1,31 @@
+package roster
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"mod.go/report"
+)
+
+/*====================================================================================================================*/
+
+// StudentID Функция, возвращающая стабильный идентификатор студента для машиночитаемых форматов: начало HMAC-SHA256
+// ФИО без учёта регистра, лишних пробелов и "ё" с секретом из конфигураций. Идентификатор позволяет внешним системам
+// сопоставлять данные разных собраний, не завися от точного написания ФИО, и не раскрывает ФИО без секрета
+func StudentID(fullName, salt string) string {
+	if fullName == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(string(normalizeName(fullName))))
+
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// AssignIDs Функция, проставляющая идентификаторы участникам собрания
+func AssignIDs(members []report.Member, salt string) {
+	for i := range members {
+		members[i].ID = StudentID(members[i].FullName, salt)
+	}
+}
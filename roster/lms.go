@@ -0,0 +1,153 @@
+package roster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mod.go/report"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// LMSActivity Активность пользователей СДО Moodle: время событий журнала по ФИО пользователя (ключ - слова ФИО в
+// нижнем регистре в алфавитном порядке, т.к. Moodle выводит имя перед фамилией)
+type LMSActivity map[string][]time.Time
+
+// lmsTimeLayouts Форматы времени событий в выгрузке журнала Moodle (зависят от языкового пакета сайта)
+var lmsTimeLayouts = []string{
+	"02/01/06, 15:04", "02/01/06, 15:04:05", "2/01/06, 15:04",
+	"02.01.06, 15:04", "02.01.06, 15:04:05", "02.01.2006, 15:04", "02.01.2006, 15:04:05",
+}
+
+/*====================================================================================================================*/
+
+// LoadLMSActivity Функция, считывающая выгрузку журнала событий Moodle в формате .csv ("Время", "Полное имя
+// пользователя", ...). Столбцы находятся по названиям на русском или английском языке, иначе время и ФИО считаются
+// первым и вторым столбцами. Время событий сравнивается с расписанием пар без перевода часовых поясов
+func LoadLMSActivity(path string) (LMSActivity, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия журнала СДО: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	activity := make(LMSActivity)
+	timeColumn, nameColumn := 0, 1
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения журнала СДО: %w", err)
+		}
+
+		//Строка "шапки" определяет столбцы времени и ФИО. Убираем BOM, который добавляет MS Excel
+		if line == 1 {
+			found := false
+			for i, cell := range row {
+				switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\uFEFF"))) {
+				case "время", "time":
+					timeColumn, found = i, true
+				case "полное имя пользователя", "user full name":
+					nameColumn, found = i, true
+				}
+			}
+			if found {
+				continue
+			}
+		}
+		if len(row) <= timeColumn || len(row) <= nameColumn {
+			continue
+		}
+
+		//Системные события (без пользователя) пропускаются
+		fullName := strings.TrimSpace(row[nameColumn])
+		if fullName == "" || fullName == "-" {
+			continue
+		}
+		moment, err := parseLMSTime(strings.TrimSpace(strings.TrimPrefix(row[timeColumn], "\uFEFF")))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения журнала СДО в строке %d: %w", line, err)
+		}
+
+		key := lmsKey(fullName)
+		activity[key] = append(activity[key], moment)
+	}
+
+	return activity, nil
+}
+
+// parseLMSTime Вспомогательная функция, разбирающая время события журнала Moodle в одном из известных форматов
+func parseLMSTime(source string) (time.Time, error) {
+	for _, layout := range lmsTimeLayouts {
+		if moment, err := time.Parse(layout, source); err == nil {
+			return moment, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("некорректное время события \"%v\"", source)
+}
+
+// lmsKey Вспомогательная функция, возвращающая ключ ФИО для сопоставления с журналом СДО: слова ФИО без учёта
+// регистра и "ё" в алфавитном порядке
+func lmsKey(fullName string) string {
+	words := strings.Fields(string(normalizeName(fullName)))
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
+
+// ActiveDuring Функция, проверяющая, был ли студент активен в СДО в промежутке времени. ФИО студента сопоставляется с
+// пользователем СДО без учёта порядка слов, а отчество из базы групп может отсутствовать в СДО
+func (activity LMSActivity) ActiveDuring(fullName string, from, to time.Time) bool {
+	words := strings.Fields(lmsKey(fullName))
+	for key, moments := range activity {
+		if !containsWords(words, strings.Fields(key)) {
+			continue
+		}
+		for _, moment := range moments {
+			if !moment.Before(from) && !moment.After(to) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// containsWords Вспомогательная функция, проверяющая, что все (не менее двух) слова пользователя СДО содержатся в
+// словах ФИО студента
+func containsWords(fullName, user []string) bool {
+	if len(user) < 2 {
+		return false
+	}
+	for _, word := range user {
+		index := sort.SearchStrings(fullName, word)
+		if index == len(fullName) || fullName[index] != word {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FlagLMSActive Функция, помечающая отсутствовавших на собрании студентов, которые были активны в СДО во время пары:
+// возможно, они не смогли подключиться из-за проблем с MS Teams
+func FlagLMSActive(members []report.Member, activity LMSActivity, from, to time.Time) {
+	for i := range members {
+		if members[i].FullName == "" || !members[i].Presence.IsAbsent() {
+			continue
+		}
+		if activity.ActiveDuring(members[i].FullName, from, to) {
+			members[i].LMSActive = true
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package roster
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mod.go/i18n"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Metadata Структура сведений о редакции базы групп из строк комментариев в начале файла базы:
+//
+//	# semester: 2022-весна
+//	# version: 12
+//	# updated: 01.04.2022
+type Metadata struct {
+	//Семестр, для которого составлена база
+	Semester string
+	//Номер редакции базы
+	Version string
+	//Дата последнего изменения базы
+	UpdatedAt string
+}
+
+// BaseMetadata Сведения о редакции базы групп, выводимые в отчётах. Устанавливаются с помощью функции LoadMetadata()
+var BaseMetadata Metadata
+
+/*====================================================================================================================*/
+
+// LoadMetadata Функция, считывающая сведения о редакции базы групп из строк комментариев ("# ключ: значение") до
+// первой строки студента. Неизвестные ключи и обычные комментарии пропускаются, а при отсутствии файла базы групп
+// возвращаются пустые сведения
+func LoadMetadata(path string) (Metadata, error) {
+	var metadata Metadata
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return metadata, nil
+	}
+	if err != nil {
+		return metadata, fmt.Errorf("ошибка открытия файла базы групп: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+
+		key, value, ok := cutMetadata(line)
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "semester", "семестр":
+			metadata.Semester = value
+		case "version", "версия":
+			metadata.Version = value
+		case "updated", "updated-at", "updated_at", "обновлена":
+			metadata.UpdatedAt = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return metadata, fmt.Errorf("ошибка чтения из файла базы групп: %w", err)
+	}
+
+	return metadata, nil
+}
+
+// String Функция, возвращающая сведения о редакции базы групп для отчётов (например, "версия 12, обновлена
+// 01.04.2022, семестр 2022-весна") или пустую строку, если сведений нет
+func (metadata Metadata) String() string {
+	var parts []string
+	if metadata.Version != "" {
+		parts = append(parts, i18n.Sprintf("версия %v", metadata.Version))
+	}
+	if metadata.UpdatedAt != "" {
+		parts = append(parts, i18n.Sprintf("обновлена %v", metadata.UpdatedAt))
+	}
+	if metadata.Semester != "" {
+		parts = append(parts, i18n.Sprintf("семестр %v", metadata.Semester))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// SetSemester Функция, записывающая семестр в сведения о редакции базы групп: строка комментария "# semester:"
+// заменяется или добавляется в начало файла базы. Остальные строки базы не изменяются
+func SetSemester(path, semester string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла базы групп: %w", err)
+	}
+
+	//BOM, который добавляет MS Excel, сохраняется в начале файла
+	bom := ""
+	if strings.HasPrefix(string(content), "\ufeff") {
+		bom = "\ufeff"
+	}
+	lines := strings.Split(strings.TrimPrefix(string(content), bom), "\n")
+
+	//Заменяем строку семестра среди строк комментариев до первой строки студента
+	replaced := false
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		if key, _, ok := cutMetadata(line); ok && (key == "semester" || key == "семестр") {
+			lines[i], replaced = "# semester: "+semester, true
+			break
+		}
+	}
+	if !replaced {
+		lines = append([]string{"# semester: " + semester}, lines...)
+	}
+
+	if err := os.WriteFile(path, []byte(bom+strings.Join(lines, "\n")), 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла базы групп: %w", err)
+	}
+
+	return nil
+}
+
+// cutMetadata Вспомогательная функция, разделяющая строку комментария сведений о редакции ("# ключ: значение" или
+// "# ключ=значение") на ключ в нижнем регистре и значение
+func cutMetadata(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(strings.TrimPrefix(line, "#"), ":")
+	if !ok {
+		key, value, ok = strings.Cut(strings.TrimPrefix(line, "#"), "=")
+	}
+
+	return strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value), ok
+}
@@ -0,0 +1,102 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// NameAliasesPath Путь до файла псевдонимов участников
+const NameAliasesPath = "NameAliases.csv"
+
+// NameAliases Псевдонимы участников собраний: имена из отчётов MS Teams, которые всегда относятся к одному студенту
+// базы (ключ - имя без учёта регистра и "ё", значение - ФИО из базы групп). Устанавливаются с помощью функции
+// LoadNameAliases()
+var NameAliases = map[string]string{}
+
+/*====================================================================================================================*/
+
+// LoadNameAliases Функция, считывающая файл псевдонимов участников (строки вида "Имя в MS Teams,ФИО в базе групп").
+// Строка "шапки" и пустые строки пропускаются. Файл необязателен: если его нет, возвращается пустая карта
+func LoadNameAliases(path string) (map[string]string, error) {
+	aliases := make(map[string]string)
+
+	//Открываем файл псевдонимов, отсутствие файла не является ошибкой
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла псевдонимов: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла псевдонимов: %w", err)
+		}
+
+		//Убираем BOM, который добавляет MS Excel при сохранении в .csv
+		name := strings.TrimSpace(strings.TrimPrefix(row[0], "\uFEFF"))
+		if name == "" || strings.EqualFold(name, "Имя в MS Teams") {
+			continue
+		}
+		if len(row) < 2 || strings.TrimSpace(row[1]) == "" {
+			return nil, fmt.Errorf("в файле псевдонимов не указано ФИО из базы групп для имени %v", name)
+		}
+		aliases[string(normalizeName(name))] = strings.TrimSpace(row[1])
+	}
+
+	return aliases, nil
+}
+
+// ResolveName Функция, возвращающая ФИО студента из базы групп по псевдониму участника собрания
+func ResolveName(fullName string) (string, bool) {
+	baseName, ok := NameAliases[string(normalizeName(fullName))]
+
+	return baseName, ok
+}
+
+// AppendNameAliases Функция, дописывающая псевдонимы участников (пары "имя в MS Teams" - "ФИО в базе групп") в файл
+// псевдонимов. Новый файл создаётся со строкой "шапки"
+func AppendNameAliases(path string, aliases [][2]string) error {
+	_, statErr := os.Stat(path)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла псевдонимов: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if errors.Is(statErr, fs.ErrNotExist) {
+		if err := writer.Write([]string{"Имя в MS Teams", "ФИО в базе групп"}); err != nil {
+			return fmt.Errorf("ошибка записи в файл псевдонимов: %w", err)
+		}
+	}
+	for _, alias := range aliases {
+		if err := writer.Write([]string{strings.TrimSpace(alias[0]), strings.TrimSpace(alias[1])}); err != nil {
+			return fmt.Errorf("ошибка записи в файл псевдонимов: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("ошибка записи в файл псевдонимов: %w", err)
+	}
+
+	return file.Close()
+}
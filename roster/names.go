@@ -0,0 +1,114 @@
+package roster
+
+import (
+	"strings"
+	"unicode"
+)
+
+/*====================================================================================================================*/
+
+// latinLetters Соответствие сочетаний латинских букв русским буквам для перевода ФИО, указанного латиницей. Более
+// длинные сочетания проверяются раньше
+var latinLetters = []struct {
+	latin, cyrillic string
+}{
+	{"shch", "щ"}, {"sch", "щ"}, {"zh", "ж"}, {"kh", "х"}, {"tch", "ч"}, {"ts", "ц"}, {"tc", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"iu", "ю"}, {"ju", "ю"}, {"ya", "я"}, {"ia", "я"}, {"ja", "я"}, {"yo", "ё"}, {"ye", "е"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"}, {"g", "г"}, {"h", "х"}, {"i", "и"},
+	{"j", "й"}, {"k", "к"}, {"l", "л"}, {"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"x", "кс"}, {"y", "ы"}, {"z", "з"}, {"'", "ь"},
+}
+
+/*====================================================================================================================*/
+
+// Transliterate Функция, переводящая ФИО, указанное латиницей ("Ivanov Ivan Ivanovich"), в русские буквы. Окончания
+// "i" и "y" после гласной переводятся в "й" ("Sergey", "Nikolai"), "y" после согласной - в "ий" ("Dmitry"). ФИО, в
+// котором есть русские буквы, возвращается как есть
+func Transliterate(fullName string) string {
+	for _, letter := range fullName {
+		if unicode.Is(unicode.Cyrillic, letter) {
+			return fullName
+		}
+	}
+
+	words := strings.Fields(fullName)
+	for i, word := range words {
+		words[i] = transliterateWord(word)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// FindName Функция, находящая в базе групп ФИО участника собрания, указанное не так, как в базе: латиницей или без
+// отчества. Сравнение ведётся без учёта регистра и "ё". ФИО без отчества сопоставляется, только если в базе один
+// человек с такими фамилией и именем. ФИО, которое уже есть в базе, не ищется
+func (base Base) FindName(fullName string) (string, bool) {
+	if _, ok := base[fullName]; ok {
+		return "", false
+	}
+
+	words := strings.Fields(string(normalizeName(Transliterate(fullName))))
+	if len(words) < 2 {
+		return "", false
+	}
+
+	found := ""
+	for candidate := range base {
+		candidateWords := strings.Fields(string(normalizeName(candidate)))
+		if len(candidateWords) < len(words) || strings.Join(candidateWords[:len(words)], " ") != strings.Join(words, " ") {
+			continue
+		}
+		//Несколько подходящих человек в базе - участник не сопоставляется
+		if found != "" {
+			return "", false
+		}
+		found = candidate
+	}
+
+	return found, found != ""
+}
+
+// transliterateWord Вспомогательная функция, переводящая одно слово ФИО из латинских букв в русские с сохранением
+// заглавной первой буквы
+func transliterateWord(word string) string {
+	source := strings.ToLower(word)
+
+	var result strings.Builder
+	previousVowel := false
+	for source != "" {
+		//"i" и "y" после гласной в конце слова - "й" ("Sergey"), "y" после согласной в конце слова - "ий" ("Dmitry")
+		if source == "i" || source == "y" {
+			if previousVowel {
+				result.WriteString("й")
+				break
+			}
+			if source == "y" {
+				result.WriteString("ий")
+				break
+			}
+		}
+
+		matched := false
+		for _, letter := range latinLetters {
+			if strings.HasPrefix(source, letter.latin) {
+				result.WriteString(letter.cyrillic)
+				source, matched = source[len(letter.latin):], true
+				previousVowel = strings.ContainsAny(letter.cyrillic, "аеёиоуыэюя")
+				break
+			}
+		}
+		//Прочие символы (дефис двойной фамилии) переносятся как есть
+		if !matched {
+			character := []rune(source)[0]
+			result.WriteRune(character)
+			source, previousVowel = source[len(string(character)):], false
+		}
+	}
+
+	translated := []rune(result.String())
+	if first := []rune(word); len(first) > 0 && unicode.IsUpper(first[0]) && len(translated) > 0 {
+		translated[0] = unicode.ToUpper(translated[0])
+	}
+
+	return string(translated)
+}
@@ -0,0 +1,31 @@
+package roster
+
+import "mod.go/report"
+
+/*====================================================================================================================*/
+
+// CheckQuorum Функция, проверяющая кворум занятия: присутствовал ли на занятии не меньше чем share процентов
+// студентов групп собрания, каждый из которых находился на собрании не меньше чем timeShare процентов его
+// продолжительности. Гости не учитываются, студенты, отметившиеся в аудитории, считаются присутствовавшими всё занятие.
+// Если продолжительность собрания неизвестна, учитывается только отметка присутствия
+func CheckQuorum(members []report.Member, duration, share, timeShare int) report.Quorum {
+	quorum := report.Quorum{Checked: true}
+
+	for _, member := range members {
+		if member.FullName == "" || member.Group == Guest || member.Presence.IsExcused() {
+			continue
+		}
+		quorum.Expected++
+
+		if member.Presence.IsAbsent() {
+			continue
+		}
+		if member.Participation == InPerson || duration == 0 || member.Duration*100 >= timeShare*duration {
+			quorum.Present++
+		}
+	}
+
+	quorum.Met = quorum.Expected > 0 && quorum.Present*100 >= share*quorum.Expected
+
+	return quorum
+}
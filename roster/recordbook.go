@@ -0,0 +1,63 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/report"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// RecordBooks Номера зачётных книжек студентов (ключ - ФИО, значение - номер зачётки). Устанавливаются из необязательного
+// третьего столбца базы групп с помощью функции LoadRecordBooks()
+var RecordBooks = map[string]string{}
+
+/*====================================================================================================================*/
+
+// LoadRecordBooks Функция, считывающая номера зачётных книжек из базы групп (строки вида "ФИО,Группа,Зачётка"). Номер
+// зачётки указывается не у всех студентов, а при отсутствии файла базы групп возвращается пустая карта
+func LoadRecordBooks(path string) (map[string]string, error) {
+	books := make(map[string]string)
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return books, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла базы групп: %w", err)
+	}
+	defer file.Close()
+
+	//Количество столбцов в строках базы может различаться
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла базы групп: %w", err)
+		}
+
+		if len(row) > 2 && strings.TrimSpace(row[2]) != "" {
+			books[row[0]] = strings.TrimSpace(row[2])
+		}
+	}
+
+	return books, nil
+}
+
+// AssignRecordBooks Функция, проставляющая номера зачётных книжек участникам собрания, которые есть в базе групп
+func AssignRecordBooks(members []report.Member) {
+	for i := range members {
+		members[i].RecordBook = RecordBooks[members[i].FullName]
+	}
+}
@@ -0,0 +1,184 @@
+// Package roster Пакет базы групп: определение группы участника собрания и заполнение списка отсутствующих студентов
+package roster
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mod.go/report"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// BasePath Путь до файла базы групп
+const BasePath = "GroupsBase.csv"
+
+// Guest Группа участника собрания, которого нет в базе групп
+const Guest = "Гость"
+
+// Teacher Группа, которой в базе групп помечаются преподаватели: они не попадают в список участников собрания и не
+// отмечаются отсутствующими
+const Teacher = "Преподаватель"
+
+// MinGroupSize Наименьшее количество студентов группы в базе групп, при котором группа, указанная в имени участника
+// собрания, принимается. Устанавливается из файла конфигураций
+var MinGroupSize = 1
+
+// AllowedGroups Группы, которые принимаются из имени участника собрания, даже если в базе групп их нет или в них меньше
+// MinGroupSize студентов (например, группы другого факультета). Устанавливаются из файла конфигураций
+var AllowedGroups []string
+
+/*====================================================================================================================*/
+
+// Base База групп: ключ - ФИО студента, значение - группа
+type Base map[string]string
+
+/*====================================================================================================================*/
+
+// LoadBase Функция, считывающая базу групп (строки вида "ФИО,Группа" с необязательными номером зачётки третьим
+// столбцом и почтой четвёртым) в карту, чтобы группа каждого участника собрания определялась без повторного чтения файла. Строки,
+// начинающиеся с "#", считаются комментариями
+func LoadBase(path string) (Base, error) {
+	//Открываем файл с базой групп
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла базы групп: %w", err)
+	}
+
+	//Закрываем файл после окончания функции
+	defer file.Close()
+
+	//Читаем поток данных из базы групп
+	reader := csv.NewReader(file)
+
+	//Номер зачётки указывается не у всех студентов, поэтому количество столбцов в строках может различаться
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	//Карта базы групп
+	base := make(Base)
+
+	//Цикл по всем строкам в файле
+	for {
+		//Считываем строку из базы групп
+		row, err := reader.Read()
+		//При окончании файла выходим из цикла
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла базы групп: %w", err)
+		}
+		if len(row) < 2 {
+			return nil, fmt.Errorf("в строке базы групп должны быть указаны ФИО и группа: %v", strings.Join(row, ","))
+		}
+
+		base[row[0]] = row[1]
+	}
+
+	return base, nil
+}
+
+// SetGroup Функция, устанавливающая группу участника собрания, на основе базы групп и ФИО участника
+func (base Base) SetGroup(fullName string) string {
+	//Если участник есть в базе, то группой участника собрания становится текущее название группы из базы
+	if group, ok := base[fullName]; ok {
+		return CanonicalGroup(group)
+	}
+
+	//В случае, если в базе нет данного пользователя, то участник собрания маркируется гостем
+	return Guest
+}
+
+// AcceptGroup Функция, проверяющая, можно ли принять группу, указанную в имени участника собрания: группа должна быть
+// в списке AllowedGroups или в базе групп, и в ней должно быть не меньше MinGroupSize студентов. Иначе группа в имени,
+// вероятно, указана с опечаткой, и группа участника определяется по базе групп
+func (base Base) AcceptGroup(group string) bool {
+	group = CanonicalGroup(group)
+	for _, allowed := range AllowedGroups {
+		if strings.EqualFold(CanonicalGroup(allowed), group) {
+			return true
+		}
+	}
+
+	//Считаем студентов группы в базе (с учётом прежних названий группы), преподаватели не учитываются
+	size := 0
+	for fullName, current := range base {
+		if strings.EqualFold(CanonicalGroup(current), group) && !base.IsTeacher(fullName) {
+			size++
+		}
+	}
+
+	return size > 0 && size >= MinGroupSize
+}
+
+// IsTeacher Функция, проверяющая, помечен ли участник собрания в базе групп как преподаватель
+func (base Base) IsTeacher(fullName string) bool {
+	group, ok := base[fullName]
+
+	return ok && strings.EqualFold(strings.TrimSpace(group), Teacher)
+}
+
+/*====================================================================================================================*/
+
+// FillLostMembers Функция, заполняющая массив участников собрания людьми, которые не присутствовали на собрании.
+// Кроме групп участников, отсутствующими отмечаются студенты ожидаемых групп expected (например, групп дисциплины).
+// Студенты, у которых в день собрания date есть уважительная причина отсутствия, отмечаются отдельной пометкой
+func FillLostMembers(ctx context.Context, base Base, members []report.Member, date time.Time,
+	expected ...string) ([]report.Member, error) {
+	//Множество групп, участники которых были на собрании, и ожидаемых групп
+	groups := make(map[string]bool)
+	for _, group := range expected {
+		groups[CanonicalGroup(group)] = true
+	}
+
+	//Множество ФИО участников собрания. Участник, найденный в базе по почте, отмечает присутствие студента базы с этой
+	// почтой, даже если ФИО в отчёте отличается от ФИО в базе
+	present := make(map[string]bool)
+
+	for _, member := range members {
+		groups[member.Group] = true
+		present[member.FullName] = true
+		if fullName, ok := FindByEmail(member.Email); ok {
+			present[fullName] = true
+		}
+	}
+
+	//Студенты из групп собрания, которых не было на собрании, в порядке ФИО
+	var lost []string
+	for fullName, group := range base {
+		//Если группа студента из базы (или её текущее название) совпадает с одной из групп собрания, а сам студент
+		// на собрании не был, то условие выполняется
+		if groups[CanonicalGroup(group)] && !present[fullName] && !base.IsTeacher(fullName) && !IsStaff(fullName) {
+			lost = append(lost, fullName)
+		}
+	}
+	sort.Strings(lost)
+
+	//Цикл по всем отсутствующим студентам
+	for _, fullName := range lost {
+		//Прерываем заполнение, если контекст отменён
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		//Отсутствующий студент заносится в список с пометкой о полном отсутствии (или об отсутствии по уважительной
+		// причине)
+		member := report.Member{
+			Group:    base.SetGroup(fullName),
+			FullName: fullName,
+			Presence: report.PresenceAbsent,
+		}
+		if excuse, ok := FindExcuse(fullName, date); ok {
+			member.Presence, member.Excuse = report.PresenceExcused, excuse.Reason
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
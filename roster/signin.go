@@ -0,0 +1,104 @@
+package roster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mod.go/report"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Форматы участия в гибридном занятии
+const (
+	//Участник присоединился к собранию MS Teams
+	Online = "онлайн"
+	//Участник отметился в листе присутствия в аудитории
+	InPerson = "очно"
+)
+
+/*====================================================================================================================*/
+
+// LoadSignIn Функция, считывающая лист присутствия в аудитории: .csv файл, в первом столбце которого указаны ФИО
+// студентов. Строка "шапки" ("ФИО") и пустые строки пропускаются
+func LoadSignIn(path string) ([]string, error) {
+	return loadFullNames(path, "листа присутствия")
+}
+
+// loadFullNames Вспомогательная функция, считывающая .csv файл со списком ФИО студентов в первом столбце (лист
+// присутствия, список записавшихся на консультацию). Название списка указывается в сообщениях об ошибках
+func loadFullNames(path, list string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия %v: %w", list, err)
+	}
+	defer file.Close()
+
+	//Количество полей в строке может отличаться (кроме ФИО может быть указана группа или подпись)
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	var fullNames []string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения %v: %w", list, err)
+		}
+
+		//Убираем BOM, который добавляет MS Excel при сохранении в .csv
+		fullName := strings.TrimSpace(strings.TrimPrefix(row[0], "\uFEFF"))
+		if fullName == "" || strings.EqualFold(fullName, "ФИО") {
+			continue
+		}
+		fullNames = append(fullNames, strings.Join(strings.Fields(fullName), " "))
+	}
+
+	return fullNames, nil
+}
+
+// MergeSignIn Функция, объединяющая участников собрания MS Teams со студентами из листа присутствия в аудитории.
+// ФИО из листа сопоставляется со студентом базы так же, как ФИО гостя (с допустимым количеством отличающихся
+// символов). Студент, отметившийся в аудитории, считается присутствовавшим очно полностью, даже если он также
+// подключался к собранию. Остальным участникам собрания проставляется формат участия "онлайн"
+func (base Base) MergeSignIn(members []report.Member, fullNames []string, distance int) []report.Member {
+	//Индексы участников собрания по ФИО без учёта регистра и "ё"
+	indexes := make(map[string]int)
+	for i := range members {
+		members[i].Participation = Online
+		indexes[string(normalizeName(members[i].FullName))] = i
+	}
+
+	for _, fullName := range fullNames {
+		//ФИО из листа приводится к ФИО из базы групп, если студент найден
+		if _, ok := base[fullName]; !ok {
+			if matched, ok := base.MatchGuest(fullName, distance); ok {
+				fullName = matched
+			}
+		}
+
+		//Отметка в аудитории заменяет отметки собрания MS Teams (например, малое нахождение на собрании студента,
+		// который подключался с телефона из аудитории)
+		if index, ok := indexes[string(normalizeName(fullName))]; ok {
+			members[index].Presence, members[index].Delay = report.PresenceFull, report.DelayUnknown
+			members[index].DelayMinutes = 0
+			members[index].EarlyExit, members[index].Participation = report.Exit{Status: report.ExitFull}, InPerson
+			continue
+		}
+
+		indexes[string(normalizeName(fullName))] = len(members)
+		members = append(members, report.Member{
+			Group:         base.SetGroup(fullName),
+			FullName:      fullName,
+			Presence:      report.PresenceFull,
+			EarlyExit:     report.Exit{Status: report.ExitFull},
+			Participation: InPerson,
+		})
+	}
+
+	return members
+}
@@ -0,0 +1,62 @@
+package roster
+
+import (
+	"mod.go/report"
+)
+
+/*====================================================================================================================*/
+
+// Пометки записи на консультацию
+const (
+	//Студент записался на консультацию
+	Booked = "записан"
+	//Студент пришёл на консультацию без записи
+	WalkIn = "без записи"
+)
+
+/*====================================================================================================================*/
+
+// LoadSignUp Функция, считывающая список записавшихся на консультацию: .csv файл, в первом столбце которого указаны
+// ФИО студентов. Строка "шапки" ("ФИО") и пустые строки пропускаются
+func LoadSignUp(path string) ([]string, error) {
+	return loadFullNames(path, "списка записавшихся на консультацию")
+}
+
+// CompareSignUp Функция, сравнивающая участников консультации со списком записавшихся на неё. ФИО из списка
+// сопоставляется со студентом базы так же, как ФИО гостя. Участникам проставляется пометка "записан" или "без
+// записи", а записавшиеся студенты, не подключившиеся к собранию, добавляются отсутствовавшими
+func (base Base) CompareSignUp(members []report.Member, fullNames []string, distance int) []report.Member {
+	//Индексы участников собрания по ФИО без учёта регистра и "ё"
+	indexes := make(map[string]int)
+	for i := range members {
+		if members[i].FullName == "" {
+			continue
+		}
+		members[i].Booking = WalkIn
+		indexes[string(normalizeName(members[i].FullName))] = i
+	}
+
+	for _, fullName := range fullNames {
+		//ФИО из списка приводится к ФИО из базы групп, если студент найден
+		if _, ok := base[fullName]; !ok {
+			if matched, ok := base.MatchGuest(fullName, distance); ok {
+				fullName = matched
+			}
+		}
+
+		if index, ok := indexes[string(normalizeName(fullName))]; ok {
+			members[index].Booking = Booked
+			continue
+		}
+
+		indexes[string(normalizeName(fullName))] = len(members)
+		members = append(members, report.Member{
+			Group:    base.SetGroup(fullName),
+			FullName: fullName,
+			Presence: report.PresenceAbsent,
+			Booking:  Booked,
+		})
+	}
+
+	return members
+}
@@ -0,0 +1,91 @@
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mod.go/report"
+	"os"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// StaffPath Путь до файла преподавателей и ассистентов
+const StaffPath = "StaffBase.csv"
+
+// Staff Преподаватели и ассистенты из файла преподавателей (ключ - ФИО без учёта регистра и "ё", значение - роль,
+// например, "ассистент"). Устанавливаются с помощью функции LoadStaff()
+var Staff = map[string]string{}
+
+/*====================================================================================================================*/
+
+// LoadStaff Функция, считывающая файл преподавателей и ассистентов (строки вида "ФИО,Роль", роль необязательна).
+// Строка "шапки" ("ФИО") и пустые строки пропускаются. Файл необязателен: если его нет, возвращается пустая карта
+func LoadStaff(path string) (map[string]string, error) {
+	staff := make(map[string]string)
+
+	//Открываем файл преподавателей, отсутствие файла не является ошибкой
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return staff, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия файла преподавателей: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения из файла преподавателей: %w", err)
+		}
+
+		//Убираем BOM, который добавляет MS Excel при сохранении в .csv
+		fullName := strings.TrimSpace(strings.TrimPrefix(row[0], "\uFEFF"))
+		if fullName == "" || strings.EqualFold(fullName, "ФИО") {
+			continue
+		}
+		role := ""
+		if len(row) > 1 {
+			role = strings.TrimSpace(row[1])
+		}
+		staff[string(normalizeName(fullName))] = role
+	}
+
+	return staff, nil
+}
+
+// IsStaff Функция, проверяющая, указан ли участник собрания в файле преподавателей и ассистентов
+func IsStaff(fullName string) bool {
+	_, ok := Staff[string(normalizeName(fullName))]
+
+	return ok
+}
+
+// SeparateStaff Функция, отделяющая от участников собрания преподавателей и ассистентов из файла преподавателей.
+// Преподаватели не попадают в таблицу участников, а возвращаются отдельным списком (с ролью вместо группы) для
+// вывода в отчёте
+func SeparateStaff(members []report.Member) ([]report.Member, []report.Member) {
+	var students, staff []report.Member
+	for _, member := range members {
+		if member.FullName == "" || !IsStaff(member.FullName) {
+			students = append(students, member)
+			continue
+		}
+
+		member.Group = Staff[string(normalizeName(member.FullName))]
+		staff = append(staff, member)
+	}
+
+	return students, staff
+}
@@ -0,0 +1,249 @@
+package roster
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// googleSheet Регулярное выражение ссылки на таблицу Google Sheets: идентификатор таблицы и (необязательно) листа
+var googleSheet = regexp.MustCompile(`docs\.google\.com/spreadsheets/d/([\w-]+)(?:.*[#&?]gid=(\d+))?`)
+
+/*====================================================================================================================*/
+
+// Sync Функция, обновляющая базу групп GroupsBase.csv из источника, указанного в конфигурациях: файла .xlsx, ссылки
+// на таблицу Google Sheets (или любой другой ссылки на .csv файл). Если источник не указан или сам является
+// GroupsBase.csv, база групп не обновляется
+func Sync(ctx context.Context, source string) error {
+	switch {
+	case source == "" || filepath.Clean(source) == BasePath:
+		return nil
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		return download(ctx, source)
+	case strings.EqualFold(filepath.Ext(source), ".xlsx"):
+		return convertXLSX(source)
+	default:
+		return fmt.Errorf("неподдерживаемый источник базы групп: %v (ожидается .xlsx файл или ссылка)", source)
+	}
+}
+
+// SheetsExportURL Функция, переводящая ссылку на таблицу Google Sheets в ссылку на её выгрузку в формате .csv.
+// Остальные ссылки возвращаются без изменений
+func SheetsExportURL(source string) string {
+	match := googleSheet.FindStringSubmatch(source)
+	if match == nil || strings.Contains(source, "/pub?") || strings.Contains(source, "format=csv") {
+		return source
+	}
+
+	exportURL := "https://docs.google.com/spreadsheets/d/" + match[1] + "/export?format=csv"
+	if match[2] != "" {
+		exportURL += "&gid=" + match[2]
+	}
+
+	return exportURL
+}
+
+// download Функция, загружающая базу групп по ссылке в файл GroupsBase.csv
+func download(ctx context.Context, source string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, SheetsExportURL(source), nil)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса базы групп: %w", err)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки базы групп: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("ошибка загрузки базы групп: %v", response.Status)
+	}
+
+	//Разбираем загруженную таблицу, страница входа или ошибки вместо таблицы отсеивается при записи базы
+	rows, err := csv.NewReader(response.Body).ReadAll()
+	if err != nil {
+		return fmt.Errorf("ошибка чтения загруженной базы групп: %w", err)
+	}
+
+	return writeBase(rows)
+}
+
+// convertXLSX Функция, переводящая первый лист .xlsx файла (столбцы "ФИО", "Группа" и необязательные "Зачётка" и
+// "Email") в файл GroupsBase.csv
+func convertXLSX(source string) error {
+	archive, err := zip.OpenReader(source)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла базы групп %v: %w", source, err)
+	}
+	defer archive.Close()
+
+	//Общие строки книги, на которые ссылаются текстовые ячейки листа
+	var sharedStrings struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	err = readXML(&archive.Reader, "xl/sharedStrings.xml", &sharedStrings)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Reference string `xml:"r,attr"`
+				Type      string `xml:"t,attr"`
+				Value     string `xml:"v"`
+				Inline    string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	err = readXML(&archive.Reader, "xl/worksheets/sheet1.xml", &sheet)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("в файле базы групп %v не найден первый лист", source)
+	}
+	if err != nil {
+		return err
+	}
+
+	//Переводим строки листа в строки вида "ФИО,Группа,Зачётка,Email"
+	var rows [][]string
+	for _, sheetRow := range sheet.Rows {
+		row := make([]string, 4)
+		for _, cell := range sheetRow.Cells {
+			column := columnIndex(cell.Reference)
+			if column < 0 || column > 3 {
+				continue
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index >= len(sharedStrings.Items) {
+					return fmt.Errorf("некорректная ссылка на строку в ячейке %v файла базы групп", cell.Reference)
+				}
+				item := sharedStrings.Items[index]
+				row[column] = item.Text
+				for _, run := range item.Runs {
+					row[column] += run.Text
+				}
+			case "inlineStr":
+				row[column] = cell.Inline
+			default:
+				row[column] = cell.Value
+			}
+		}
+
+		for i := range row {
+			row[i] = strings.TrimSpace(row[i])
+		}
+		if row[0] != "" && row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+
+	return writeBase(rows)
+}
+
+// readXML Функция, разбирающая XML файл из архива .xlsx. Если файла нет, возвращается os.ErrNotExist
+func readXML(archive *zip.Reader, name string, out interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("ошибка чтения %v из файла базы групп: %w", name, err)
+		}
+		defer reader.Close()
+
+		if err := xml.NewDecoder(reader).Decode(out); err != nil {
+			return fmt.Errorf("ошибка разбора %v из файла базы групп: %w", name, err)
+		}
+		return nil
+	}
+
+	return os.ErrNotExist
+}
+
+// columnIndex Функция, возвращающая номер столбца (с нуля) по ссылке на ячейку вида "B12"
+func columnIndex(reference string) int {
+	column := 0
+	for _, letter := range reference {
+		if letter < 'A' || letter > 'Z' {
+			break
+		}
+		column = column*26 + int(letter-'A') + 1
+	}
+
+	return column - 1
+}
+
+// writeBase Функция, записывающая строки базы групп в файл GroupsBase.csv. Строка "шапки" ("ФИО,Группа") пропускается.
+// Третий и четвёртый столбцы источника (номер зачётки и почта) записываются, если они заполнены
+func writeBase(rows [][]string) error {
+	if len(rows) > 0 && len(rows[0]) > 1 && strings.EqualFold(strings.TrimSpace(rows[0][0]), "ФИО") {
+		rows = rows[1:]
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("источник базы групп не содержит ни одного студента")
+	}
+	for _, row := range rows {
+		if len(row) < 2 {
+			return fmt.Errorf("в строке базы групп должны быть указаны ФИО и группа: %v", strings.Join(row, ","))
+		}
+	}
+
+	//Записываем базу во временный файл и заменяем им GroupsBase.csv, чтобы не повредить базу при ошибке записи
+	temporary := BasePath + ".tmp"
+	file, err := os.Create(temporary)
+	if err != nil {
+		return fmt.Errorf("ошибка создания файла базы групп: %w", err)
+	}
+
+	csvWriter := csv.NewWriter(file)
+	for _, row := range rows {
+		//Пустые необязательные столбцы в конце строки не записываются
+		record := row
+		if len(record) > 4 {
+			record = record[:4]
+		}
+		for len(record) > 2 && strings.TrimSpace(record[len(record)-1]) == "" {
+			record = record[:len(record)-1]
+		}
+		if err := csvWriter.Write(record); err != nil {
+			file.Close()
+			return fmt.Errorf("ошибка записи базы групп: %w", err)
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		file.Close()
+		return fmt.Errorf("ошибка записи базы групп: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия файла базы групп: %w", err)
+	}
+
+	if err := os.Rename(temporary, BasePath); err != nil {
+		return fmt.Errorf("ошибка замены файла базы групп: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,387 @@
+// Package schedule Пакет расписания пар: определение номера пары по времени начала собрания и опоздания участника
+package schedule
+
+import (
+	"fmt"
+	"mod.go/report"
+	"strconv"
+	"strings"
+	"time"
+
+	//База часовых поясов встраивается в программу, т.к. на Windows её может не быть
+	_ "time/tzdata"
+)
+
+/*====================================================================================================================*/
+
+// Consultation Номер пары для собраний, время начала которых не попадает ни в одну пару расписания
+const Consultation = "Консультация"
+
+// TechnicalCall Номер пары для подозрительно коротких собраний (например, пробных звонков перед занятием)
+const TechnicalCall = "Технический созвон"
+
+// Lesson Структура пары из расписания
+type Lesson struct {
+	//Название пары (например, "Пара 1"), под которым пара записывается в историю посещаемости
+	Name string
+	//Название пары для вывода в отчётах и сводках из конфигураций (пустое - стандартное название на выбранном языке)
+	Label string
+	//Время начала пары в секундах от начала суток
+	Start int
+	//Время окончания пары в секундах от начала суток
+	End int
+	//Количество секунд от начала пары, после которого участник считается опоздавшим
+	LateThreshold int
+}
+
+// Schedule Структура расписания пар, считываемого из файла конфигураций
+type Schedule struct {
+	//Список пар в порядке их номеров
+	Lessons []Lesson
+	//Списки пар для отдельных дней недели (например, сдвинутое расписание субботы). Для дней недели без своего списка
+	// используется общий список пар
+	Weekdays map[time.Weekday][]Lesson
+	//Допуск до начала пары в секундах
+	ToleranceBefore int
+	//Допуск после окончания пары в секундах
+	ToleranceAfter int
+	//Количество секунд от начала пары, после которого участник считается опоздавшим (для пар без своего порога)
+	LateThreshold int
+	//Льготный период в секундах, добавляемый к порогу опоздания каждой пары
+	GracePeriod int
+	//Количество секунд до окончания пары, выход раньше которого считается ранним уходом
+	EarlyExitThreshold int
+	//Продолжительность собрания в секундах, меньше которой собрание считается техническим созвоном (0 - не считается)
+	TechnicalCallThreshold int
+	//Доля продолжительности собрания в процентах, начиная с которой участник считается присутствовавшим полностью
+	// (0 - полностью присутствовавшим считается участник, находившийся на собрании больше получаса)
+	PresenceShare int
+	//Допустимое расхождение часов устройств участников в секундах: время присоединения до начала собрания (или выхода
+	// после его окончания) в пределах допуска приводится к началу (окончанию) собрания без пометки
+	ClockDriftTolerance int
+	//Часовой пояс аудитории, в котором задано расписание пар (nil - время отчётов не переводится)
+	TimeZone *time.Location
+	//Часовой пояс, в котором указано время в отчётах MS Teams (nil - совпадает с часовым поясом аудитории)
+	ReportTimeZone *time.Location
+	//Поиск запланированного события календаря организатора по времени начала собрания (nil - пары определяются
+	// только по расписанию)
+	Calendar func(start time.Time) (Event, bool)
+}
+
+// Event Структура запланированного события календаря организатора, с которым сопоставляется собрание
+type Event struct {
+	//Тема события
+	Subject string
+	//Плановое время начала события
+	Start time.Time
+	//Плановое время окончания события
+	End time.Time
+}
+
+/*====================================================================================================================*/
+
+// ParseTime Вспомогательная функция, возвращающая время в секундах в виде целочисленного значения
+func ParseTime(words []string) (int, error) {
+	//Если массив строк содержит 3 переменные (часы, минуты, секунды)
+	if int(len(words)) == 3 {
+		//Переводим первый элемент строкового массива (часы) в целочисленное значение
+		hours, err := strconv.Atoi(words[0])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перевода строки часов в десятичное число: %w", err)
+		}
+
+		//Переводим второй элемент строкового массива (минуты) в целочисленное значение
+		minutes, err := strconv.Atoi(words[1])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перевода строки минут в десятичное число: %w", err)
+		}
+
+		//Переводим третий элемент строкового массива (секунды) в целочисленное значение
+		time, err := strconv.Atoi(words[2])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перевода строки секунд в десятичное число: %w", err)
+		}
+
+		//Возвращаем количество секунд
+		return time + hours*3600 + minutes*60, nil
+		//Иначе массив содержит две строковые переменные
+	} else if len(words) == 2 {
+		//Переводим первый элемент строкового массива (минуты) в целочисленное значение
+		minutes, err := strconv.Atoi(words[0])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перевода строки минут в десятичное число: %w", err)
+		}
+
+		//Переводим второй элемент строкового массива (секунды) в целочисленное значение
+		time, err := strconv.Atoi(words[1])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перевода строки секунд в десятичное число: %w", err)
+		}
+
+		//Возвращаем количество секунд
+		return time + minutes*60, nil
+	}
+
+	return 0, fmt.Errorf("некорректный формат времени: %v", strings.Join(words, ":"))
+}
+
+// ParseClock Вспомогательная функция, переводящая время суток вида ЧЧ:ММ (или ЧЧ:ММ:СС) в секунды
+func ParseClock(source string) (int, error) {
+	//Массив строк, полученных из строки времени путём деления по двоеточию
+	words := strings.Split(strings.TrimSpace(source), ":")
+
+	//Если секунды не указаны, дополняем время нулевыми секундами, чтобы ParseTime() разобрала часы и минуты
+	if len(words) == 2 {
+		words = append(words, "00")
+	}
+
+	//Получаем время в секундах с помощью вспомогательной функции ParseTime()
+	return ParseTime(words)
+}
+
+// FormatClock Вспомогательная функция, переводящая время суток в секундах в строку вида ЧЧ:ММ
+func FormatClock(seconds int) string {
+	return fmt.Sprintf("%02d:%02d", seconds/3600, seconds%3600/60)
+}
+
+// ParseMinutes Вспомогательная функция, переводящая строку с количеством минут в секунды
+func ParseMinutes(source string) (int, error) {
+	//Переводим строку минут в целочисленное значение
+	minutes, err := strconv.Atoi(strings.TrimSpace(source))
+	if err != nil {
+		return 0, fmt.Errorf("ошибка перевода строки минут в десятичное число: %w", err)
+	}
+
+	return minutes * 60, nil
+}
+
+// durationUnits Количество секунд в каждой единице измерения продолжительности отчёта MS Teams
+var durationUnits = map[string]int{"ч": 3600, "мин": 60, "с": 1}
+
+// ParseDuration Вспомогательная функция, переводящая продолжительность вида отчёта MS Teams ("1 ч 27 мин 38 с")
+// в секунды
+func ParseDuration(source string) (int, error) {
+	//Массив строк, в котором числа чередуются с единицами измерения
+	words := strings.Fields(source)
+	if len(words)%2 != 0 {
+		return 0, fmt.Errorf("некорректный формат продолжительности: %v", source)
+	}
+
+	seconds := 0
+	for i := 0; i < len(words); i += 2 {
+		value, err := strconv.Atoi(words[i])
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перевода строки продолжительности в десятичное число: %w", err)
+		}
+
+		unit, ok := durationUnits[words[i+1]]
+		if !ok {
+			return 0, fmt.Errorf("неизвестная единица продолжительности: %v", words[i+1])
+		}
+
+		seconds += value * unit
+	}
+
+	return seconds, nil
+}
+
+// FormatDuration Вспомогательная функция, переводящая продолжительность в секундах в строку вида отчёта MS Teams
+func FormatDuration(seconds int) string {
+	hours, minutes := seconds/3600, seconds%3600/60
+	seconds %= 60
+
+	switch {
+	case hours > 0:
+		return fmt.Sprintf("%d ч %d мин %d с", hours, minutes, seconds)
+	case minutes > 0:
+		return fmt.Sprintf("%d мин %d с", minutes, seconds)
+	default:
+		return fmt.Sprintf("%d с", seconds)
+	}
+}
+
+/*====================================================================================================================*/
+
+// LoadTimeZone Функция, загружающая часовой пояс по названию из базы часовых поясов ("Europe/Moscow", "UTC") или
+// часовой пояс компьютера ("Local"). Для пустого названия возвращается nil
+func LoadTimeZone(name string) (*time.Location, error) {
+	switch name = strings.TrimSpace(name); {
+	case name == "":
+		return nil, nil
+	case strings.EqualFold(name, "Local"):
+		return time.Local, nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс \"%v\": %w", name, err)
+	}
+
+	return location, nil
+}
+
+// ParseTimestamp Функция, разбирающая дату и время из отчёта MS Teams вида "20.04.2022, 9:41:02" в часовом поясе
+// отчёта и переводящая их в часовой пояс аудитории. Если часовые пояса не заданы, время не переводится
+func ParseTimestamp(source string, schedule Schedule) (time.Time, error) {
+	location := time.UTC
+	if schedule.TimeZone != nil {
+		location = schedule.TimeZone
+	}
+	reportLocation := location
+	if schedule.ReportTimeZone != nil {
+		reportLocation = schedule.ReportTimeZone
+	}
+
+	parsed, err := time.ParseInLocation("2.1.2006, 15:04:05", source, reportLocation)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ошибка разбора даты и времени \"%v\": %w", source, err)
+	}
+
+	return parsed.In(location), nil
+}
+
+// FindLesson Функция, возвращающая пару расписания по её названию. Если такой пары нет (например, собрание является
+// консультацией), возвращается ложь
+func FindLesson(name string, schedule Schedule) (Lesson, bool) {
+	for _, lesson := range schedule.Lessons {
+		if lesson.Name == name {
+			return lesson, true
+		}
+	}
+
+	return Lesson{}, false
+}
+
+// ForDate Функция, возвращающая расписание дня собрания: если для дня недели задан свой список пар, он заменяет общий
+// список пар, остальные настройки расписания не изменяются
+func (schedule Schedule) ForDate(date time.Time) Schedule {
+	if lessons, ok := schedule.Weekdays[date.Weekday()]; ok {
+		schedule.Lessons = lessons
+	}
+
+	return schedule
+}
+
+// ForMeeting Функция, возвращающая расписание собрания, начавшегося в start: расписание дня недели собрания, а если
+// собрание сопоставлено с событием календаря организатора, - единственную пару с плановым временем события. Название,
+// подпись и порог опоздания такой пары берутся у пары расписания, к которой относится начало события. Событие вне пар
+// расписания остаётся консультацией, но опоздания на неё отсчитываются от планового начала события
+func (schedule Schedule) ForMeeting(start time.Time) Schedule {
+	schedule = schedule.ForDate(start)
+	if schedule.Calendar == nil {
+		return schedule
+	}
+	event, ok := schedule.Calendar(start)
+	if !ok {
+		return schedule
+	}
+
+	//Плановое время события в секундах от начала суток дня собрания
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	lesson := Lesson{Name: Consultation, Start: int(event.Start.Sub(day).Seconds()),
+		End: int(event.End.Sub(day).Seconds()), LateThreshold: schedule.LateThreshold}
+	if name := LessonNumber(lesson.Start, schedule); name != Consultation {
+		current, _ := FindLesson(name, schedule)
+		lesson.Name, lesson.Label, lesson.LateThreshold = current.Name, current.Label, current.LateThreshold
+	}
+	schedule.Lessons = []Lesson{lesson}
+
+	return schedule
+}
+
+// LessonLabels Функция, возвращающая названия пар для вывода из конфигураций по названиям пар расписания ("Пара 1").
+// Пары без своего названия не включаются
+func (schedule Schedule) LessonLabels() map[string]string {
+	labels := make(map[string]string)
+	for _, lessons := range append([][]Lesson{schedule.Lessons}, weekdayLessons(schedule)...) {
+		for _, lesson := range lessons {
+			if lesson.Label != "" && labels[lesson.Name] == "" {
+				labels[lesson.Name] = lesson.Label
+			}
+		}
+	}
+
+	return labels
+}
+
+// weekdayLessons Функция, возвращающая списки пар отдельных дней недели в порядке дней недели
+func weekdayLessons(schedule Schedule) [][]Lesson {
+	var result [][]Lesson
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if lessons, ok := schedule.Weekdays[weekday]; ok {
+			result = append(result, lessons)
+		}
+	}
+
+	return result
+}
+
+// IsTechnicalCall Функция, проверяющая, является ли собрание с заданной продолжительностью в секундах техническим
+// созвоном. Собрание с неизвестной продолжительностью техническим созвоном не считается
+func IsTechnicalCall(duration int, schedule Schedule) bool {
+	return schedule.TechnicalCallThreshold > 0 && duration > 0 && duration < schedule.TechnicalCallThreshold
+}
+
+// ParseEarlyExit Функция, возвращающая пометку о раннем уходе с пары, если время выхода участника (в секундах от
+// начала суток дня собрания) раньше окончания пары больше, чем на порог раннего ухода. Иначе возвращается ложь
+func ParseEarlyExit(leave int, lesson Lesson, schedule Schedule) (report.Exit, bool) {
+	if leave >= lesson.End-schedule.EarlyExitThreshold {
+		return report.Exit{}, false
+	}
+
+	return report.Exit{Status: report.ExitEarly, Minutes: (lesson.End - leave) / 60}, true
+}
+
+// ParseLessonNumberOrDelay Функция, которая переводит строку времени в номер пары по расписанию
+// Так же функция обрабатывает опоздание
+func ParseLessonNumberOrDelay(source, phase string, schedule Schedule) (string, error) {
+	//Массив из трёх переменных, полученных из строки времени путём деления по двоеточию
+	words := strings.Split(source, ":")
+
+	//Получаем время в секундах с помощью вспомогательной функции ParseTime()
+	time, err := ParseTime(words)
+	if err != nil {
+		return "", err
+	}
+
+	//Если фаза = заполнение оглавления
+	if phase == "header" {
+		return LessonNumber(time, schedule), nil
+		//Если фаза = заполнению члена собрания
+	} else {
+		delay, _ := Delay(time, schedule)
+		return delay.String(), nil
+	}
+}
+
+// LessonNumber Функция, возвращающая номер пары по времени начала собрания в секундах от начала суток
+func LessonNumber(time int, schedule Schedule) string {
+	//Если время начала собрания в секундах лежит в пределах [начало пары - допуск и конец пары + допуск],
+	//то из функции возвращается номер пары, в случае, если ни одна пара не подходит, возвращается Консультация
+	for _, lesson := range schedule.Lessons {
+		if time >= lesson.Start-schedule.ToleranceBefore && time <= lesson.End+schedule.ToleranceAfter {
+			return lesson.Name
+		}
+	}
+
+	return Consultation
+}
+
+// LateAfter Функция, возвращающая количество секунд от начала пары, после которого участник считается опоздавшим:
+// порог опоздания пары с льготным периодом
+func (schedule Schedule) LateAfter(lesson Lesson) int {
+	return lesson.LateThreshold + schedule.GracePeriod
+}
+
+// Delay Функция, возвращающая пометку об опоздании по времени присоединения участника в секундах от начала суток
+// дня собрания (для присоединения после полуночи время больше суток) и количество минут опоздания от начала пары
+func Delay(time int, schedule Schedule) (report.DelayStatus, int) {
+	//Если время присоединения позже порога опоздания от начала пары, то опоздание, иначе без опоздания
+	for _, lesson := range schedule.Lessons {
+		if time >= lesson.Start+schedule.LateAfter(lesson) && time <= lesson.End+schedule.ToleranceAfter {
+			return report.DelayLate, (time - lesson.Start) / 60
+		}
+	}
+
+	return report.DelayNone, 0
+}
@@ -0,0 +1,114 @@
+package schedule
+
+import (
+	"mod.go/report"
+	"reflect"
+	"testing"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// testSchedule Расписание тестов: две пары с допусками 15 мин, пара 2 с порогом опоздания 5 мин, и сдвинутое
+// расписание субботы
+var testSchedule = Schedule{
+	Lessons: []Lesson{
+		{Name: "Пара 1", Start: 8 * 3600, End: 9*3600 + 30*60, LateThreshold: 10 * 60},
+		{Name: "Пара 2", Label: "2 пара", Start: 9*3600 + 40*60, End: 11*3600 + 10*60, LateThreshold: 5 * 60},
+	},
+	Weekdays: map[time.Weekday][]Lesson{
+		time.Saturday: {{Name: "Пара 1", Start: 9 * 3600, End: 10*3600 + 30*60, LateThreshold: 10 * 60}},
+	},
+	ToleranceBefore: 15 * 60,
+	ToleranceAfter:  15 * 60,
+	LateThreshold:   15 * 60,
+}
+
+// testMoment Вспомогательная функция, возвращающая время дня date в часах и минутах
+func testMoment(date string, hour, minute int) time.Time {
+	day, err := time.Parse("02.01.2006", date)
+	if err != nil {
+		panic(err)
+	}
+
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+/*====================================================================================================================*/
+
+// TestForMeeting Проверка расписания собрания: расписание дня недели и единственная пара с плановым временем
+// события календаря, название, подпись и порог опоздания которой берутся у пары расписания
+func TestForMeeting(t *testing.T) {
+	//Событие календаря, запланированное на 10 мин позже пары 2, и событие вне пар расписания
+	events := map[time.Time]Event{
+		testMoment("15.10.2026", 9, 52): {Subject: "Лекция", Start: testMoment("15.10.2026", 9, 50),
+			End: testMoment("15.10.2026", 11, 20)},
+		testMoment("15.10.2026", 18, 0): {Subject: "Консультация", Start: testMoment("15.10.2026", 18, 0),
+			End: testMoment("15.10.2026", 19, 0)},
+	}
+	calendar := testSchedule
+	calendar.Calendar = func(start time.Time) (Event, bool) {
+		event, ok := events[start]
+		return event, ok
+	}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		start    time.Time
+		want     []Lesson
+	}{
+		{"без календаря", testSchedule, testMoment("15.10.2026", 8, 0), testSchedule.Lessons},
+		{"расписание субботы", testSchedule, testMoment("17.10.2026", 9, 0), testSchedule.Weekdays[time.Saturday]},
+		{"собрание без события", calendar, testMoment("15.10.2026", 8, 0), testSchedule.Lessons},
+		{"событие на паре", calendar, testMoment("15.10.2026", 9, 52), []Lesson{{Name: "Пара 2", Label: "2 пара",
+			Start: 9*3600 + 50*60, End: 11*3600 + 20*60, LateThreshold: 5 * 60}}},
+		{"событие вне пар", calendar, testMoment("15.10.2026", 18, 0), []Lesson{{Name: Consultation,
+			Start: 18 * 3600, End: 19 * 3600, LateThreshold: 15 * 60}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.schedule.ForMeeting(test.start)
+			if !reflect.DeepEqual(got.Lessons, test.want) {
+				t.Errorf("пары собрания %+v, ожидалось %+v", got.Lessons, test.want)
+			}
+			if got.ToleranceBefore != test.schedule.ToleranceBefore ||
+				got.LateThreshold != test.schedule.LateThreshold {
+				t.Errorf("настройки расписания изменены: %+v", got)
+			}
+		})
+	}
+}
+
+// TestDelay Проверка пометки об опоздании: порог опоздания пары, льготный период, присоединение после окончания пары
+// и вне пар расписания
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		join    int
+		grace   int
+		status  report.DelayStatus
+		minutes int
+	}{
+		{"до начала пары", 7*3600 + 50*60, 0, report.DelayNone, 0},
+		{"до порога опоздания", 8*3600 + 9*60, 0, report.DelayNone, 0},
+		{"на пороге опоздания", 8*3600 + 10*60, 0, report.DelayLate, 10},
+		{"льготный период", 8*3600 + 12*60, 5 * 60, report.DelayNone, 0},
+		{"после льготного периода", 8*3600 + 16*60, 5 * 60, report.DelayLate, 16},
+		{"свой порог пары", 9*3600 + 46*60, 0, report.DelayLate, 6},
+		{"в допуске после окончания пары", 9*3600 + 40*60 - 1, 0, report.DelayLate, 99},
+		{"вне пар расписания", 12 * 3600, 0, report.DelayNone, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule := testSchedule
+			schedule.GracePeriod = test.grace
+			status, minutes := Delay(test.join, schedule)
+			if status != test.status || minutes != test.minutes {
+				t.Errorf("опоздание %v на %d мин, ожидалось %v на %d мин", status, minutes, test.status, test.minutes)
+			}
+		})
+	}
+}
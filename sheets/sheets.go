@@ -0,0 +1,308 @@
+// Package sheets Пакет выгрузки таблицы посещаемости в таблицу Google Sheets кафедры: строки участников каждого
+// собрания дописываются на лист группы или лист даты от имени сервисного аккаунта Google
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"mod.go/report"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Способы распределения строк участников по листам таблицы
+const (
+	//Отдельный лист для каждой группы
+	LayoutGroup = "group"
+	//Отдельный лист для каждой даты собрания
+	LayoutDate = "date"
+)
+
+// Configuration Структура настроек выгрузки в Google Sheets
+type Configuration struct {
+	//Включена ли выгрузка в Google Sheets
+	Enabled bool
+	//Идентификатор таблицы (часть адреса таблицы между /d/ и /edit)
+	SpreadsheetID string
+	//Путь до файла ключа сервисного аккаунта Google в формате JSON
+	CredentialsPath string
+	//Распределение строк по листам: по группам или по датам
+	Layout string
+}
+
+// credentials Структура ключа сервисного аккаунта Google (используемые поля)
+type credentials struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+/*====================================================================================================================*/
+
+// Адреса Google Sheets API и сервиса авторизации Google (если в ключе сервисного аккаунта не указан другой)
+var (
+	Endpoint      = "https://sheets.googleapis.com/v4/spreadsheets/"
+	TokenEndpoint = "https://oauth2.googleapis.com/token"
+)
+
+// scope Область доступа токена: чтение и изменение таблиц
+const scope = "https://www.googleapis.com/auth/spreadsheets"
+
+/*====================================================================================================================*/
+
+// ParseLayout Функция, проверяющая распределение строк по листам из файла конфигураций. По-умолчанию строки
+// распределяются по листам групп
+func ParseLayout(source string) (string, error) {
+	switch layout := strings.ToLower(strings.TrimSpace(source)); layout {
+	case "":
+		return LayoutGroup, nil
+	case LayoutGroup, LayoutDate:
+		return layout, nil
+	default:
+		return "", fmt.Errorf("неизвестное распределение строк по листам Google Sheets: %v (допустимы group, date)",
+			source)
+	}
+}
+
+// Export Функция, дописывающая строки участников собрания в таблицу Google Sheets. Недостающие листы создаются со
+// строкой "шапки"
+func Export(ctx context.Context, settings Configuration, header report.Header, members []report.Member) error {
+	token, err := RequestToken(ctx, settings.CredentialsPath)
+	if err != nil {
+		return err
+	}
+
+	//Строки участников по листам
+	rows := make(map[string][][]string)
+	for _, member := range members {
+		if member.FullName == "" {
+			continue
+		}
+
+		sheet := header.Date
+		if settings.Layout == LayoutGroup {
+			sheet = i18n.T(member.Group)
+		}
+		rows[sheet] = append(rows[sheet], []string{header.Date, header.LessonLabel(), header.Title,
+			i18n.T(member.Group), member.FullName, member.PresenceLabel(), member.DelayLabel(),
+			member.EarlyExit.Label()})
+	}
+
+	existing, err := sheetTitles(ctx, token, settings.SpreadsheetID)
+	if err != nil {
+		return err
+	}
+
+	//Листы заполняются в алфавитном порядке
+	sheets := make([]string, 0, len(rows))
+	for sheet := range rows {
+		sheets = append(sheets, sheet)
+	}
+	sort.Strings(sheets)
+
+	for _, sheet := range sheets {
+		values := rows[sheet]
+
+		//Новый лист создаётся и начинается со строки "шапки"
+		if !existing[sheet] {
+			if err := addSheet(ctx, token, settings.SpreadsheetID, sheet); err != nil {
+				return err
+			}
+			columns := append([]string{i18n.T("Дата проведения собрания"), i18n.T("Номер пары"),
+				i18n.T("Название собрания")}, report.ColumnLabels.Header(false, false, false)...)
+			values = append([][]string{columns}, values...)
+		}
+
+		if err := appendRows(ctx, token, settings.SpreadsheetID, sheet, values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RequestToken Функция, получающая токен доступа к Google Sheets по ключу сервисного аккаунта: подписанное ключом
+// утверждение JWT обменивается на токен доступа
+func RequestToken(ctx context.Context, credentialsPath string) (string, error) {
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения ключа сервисного аккаунта Google: %w", err)
+	}
+	var key credentials
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("ошибка разбора ключа сервисного аккаунта Google: %w", err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = TokenEndpoint
+	}
+
+	assertion, err := signAssertion(key, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("ошибка формирования запроса токена доступа Google: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("ошибка запроса токена доступа Google: %w", err)
+	}
+	defer response.Body.Close()
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("ошибка чтения ответа сервиса авторизации Google: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("не удалось получить токен доступа Google: %v %v", token.Error, token.ErrorDescription)
+	}
+
+	return token.AccessToken, nil
+}
+
+// signAssertion Вспомогательная функция, формирующая утверждение JWT сервисного аккаунта, подписанное RS256
+func signAssertion(key credentials, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("в ключе сервисного аккаунта Google не найден закрытый ключ")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("ошибка разбора закрытого ключа сервисного аккаунта Google: %w", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("закрытый ключ сервисного аккаунта Google должен быть ключом RSA")
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": scope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("ошибка формирования утверждения JWT: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(nil, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("ошибка подписи утверждения JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+/*====================================================================================================================*/
+
+// sheetTitles Вспомогательная функция, возвращающая множество названий листов таблицы
+func sheetTitles(ctx context.Context, token, spreadsheetID string) (map[string]bool, error) {
+	var spreadsheet struct {
+		Sheets []struct {
+			Properties struct {
+				Title string `json:"title"`
+			} `json:"properties"`
+		} `json:"sheets"`
+	}
+	if err := call(ctx, token, http.MethodGet, url.PathEscape(spreadsheetID)+"?fields=sheets.properties.title", nil,
+		&spreadsheet); err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]bool)
+	for _, sheet := range spreadsheet.Sheets {
+		titles[sheet.Properties.Title] = true
+	}
+
+	return titles, nil
+}
+
+// addSheet Вспомогательная функция, добавляющая в таблицу лист с указанным названием
+func addSheet(ctx context.Context, token, spreadsheetID, title string) error {
+	request := map[string]interface{}{
+		"requests": []interface{}{
+			map[string]interface{}{"addSheet": map[string]interface{}{"properties": map[string]string{"title": title}}},
+		},
+	}
+
+	return call(ctx, token, http.MethodPost, url.PathEscape(spreadsheetID)+":batchUpdate", request, nil)
+}
+
+// appendRows Вспомогательная функция, дописывающая строки после последней заполненной строки листа
+func appendRows(ctx context.Context, token, spreadsheetID, sheet string, rows [][]string) error {
+	//Название листа в диапазоне заключается в одинарные кавычки, кавычки в названии удваиваются
+	sheetRange := "'" + strings.ReplaceAll(sheet, "'", "''") + "'!A1"
+
+	return call(ctx, token, http.MethodPost, url.PathEscape(spreadsheetID)+"/values/"+url.PathEscape(sheetRange)+
+		":append?valueInputOption=RAW&insertDataOption=INSERT_ROWS", map[string]interface{}{"values": rows}, nil)
+}
+
+// call Вспомогательная функция, выполняющая запрос к Google Sheets API с телом в формате JSON и разбирающая ответ в
+// переменную out (если она указана)
+func call(ctx context.Context, token, method, path string, body, out interface{}) error {
+	var content io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("ошибка формирования запроса к Google Sheets: %w", err)
+		}
+		content = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, Endpoint+path, content)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к Google Sheets: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к Google Sheets: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("Google Sheets вернул ошибку %v: %s", response.Status, data)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+			return fmt.Errorf("ошибка чтения ответа Google Sheets: %w", err)
+		}
+	}
+
+	return nil
+}
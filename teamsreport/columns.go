@@ -0,0 +1,105 @@
+package teamsreport
+
+import (
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// Columns Структура индексов столбцов таблицы участников отчёта MS Teams, определяемых по "шапке" таблицы. Индекс -1
+// означает, что столбца в отчёте нет
+type Columns struct {
+	//Имя участника, время присоединения, время выхода и продолжительность нахождения на собрании (обязательные)
+	Name, Join, Leave, Duration int
+	//Адрес электронной почты, роль и устройство участника (необязательные)
+	Email, Role, Platform int
+}
+
+// Известные названия столбцов таблицы участников в отчётах MS Teams на разных языках и в разных версиях MS Teams
+// (без учёта регистра)
+var (
+	nameColumns = []string{"Полное имя", "Имя", "Full Name", "Name", "Participant", "Vollständiger Name",
+		"Nom complet", "Nom", "Nombre completo", "Nombre", "Nome completo", "Повне ім'я", "Ім'я"}
+	joinColumns = []string{"Время присоединения", "Время первого присоединения", "Первое присоединение", "Join Time",
+		"First Join", "First Join Time", "Beitrittszeit", "Erster Beitritt", "Heure de participation",
+		"Première participation", "Hora de unión", "Primera unión", "Ora di partecipazione", "Час приєднання",
+		"Перше приєднання"}
+	leaveColumns = []string{"Время выхода", "Время последнего выхода", "Последний выход", "Leave Time", "Last Leave",
+		"Last Leave Time", "Zeitpunkt des Verlassens", "Letztes Verlassen", "Heure de départ", "Dernier départ",
+		"Hora de salida", "Última salida", "Ora di uscita", "Час виходу", "Останній вихід"}
+	durationColumns = []string{"Продолжительность", "Длительность", "Продолжительность участия", "Duration",
+		"In-Meeting Duration", "Attendance Duration", "Dauer", "Dauer in der Besprechung", "Durée",
+		"Durée de la réunion", "Duración", "Durata", "Тривалість"}
+	emailColumns = []string{"Адрес электронной почты", "Электронная почта", "Email", "E-mail", "Email Address",
+		"E-Mail-Adresse", "Adresse e-mail", "Correo electrónico", "Indirizzo e-mail", "Електронна пошта"}
+	roleColumns = []string{"Роль", "Role", "Rolle", "Rôle", "Función", "Rol", "Ruolo"}
+	//Названия столбца устройства участника на всех языках отчётов, собираемые один раз, а не для каждой строки отчёта
+	platformColumns = localePlatformColumns()
+)
+
+// maxSummaryRows Наибольшее количество строк оглавления отчёта перед "шапкой" таблицы участников
+const maxSummaryRows = 30
+
+/*====================================================================================================================*/
+
+// MapColumns Функция, проверяющая, является ли строка отчёта "шапкой" таблицы участников, и определяющая по названиям
+// столбцов их индексы. Строка считается "шапкой", если в ней найдены столбцы имени, времени присоединения и времени
+// выхода, поэтому добавление и перестановка столбцов в новых версиях MS Teams не нарушают разбор отчёта
+func MapColumns(row []string) (Columns, bool) {
+	columns := Columns{Name: -1, Join: -1, Leave: -1, Duration: -1, Email: -1, Role: -1, Platform: -1}
+
+	fields := []struct {
+		index *int
+		names []string
+	}{{&columns.Name, nameColumns}, {&columns.Join, joinColumns}, {&columns.Leave, leaveColumns},
+		{&columns.Duration, durationColumns}, {&columns.Email, emailColumns}, {&columns.Role, roleColumns},
+		{&columns.Platform, platformColumns}}
+
+	for i, value := range row {
+		//Убираем BOM и пробелы, которые могут оказаться в начале ячейки
+		value = strings.TrimSpace(strings.TrimPrefix(value, "\ufeff"))
+		for _, field := range fields {
+			if *field.index != -1 {
+				continue
+			}
+			for _, name := range field.names {
+				if strings.EqualFold(value, name) {
+					*field.index = i
+					break
+				}
+			}
+			if *field.index == i {
+				break
+			}
+		}
+	}
+
+	return columns, columns.Name != -1 && columns.Join != -1 && columns.Leave != -1
+}
+
+// MinLength Функция, возвращающая наименьшее количество ячеек строки участника, при котором в ней есть все обязательные
+// столбцы
+func (columns Columns) MinLength() int {
+	return max(columns.Name, columns.Join, columns.Leave, columns.Duration) + 1
+}
+
+// cell Вспомогательная функция, возвращающая значение столбца index строки участника или пустую строку, если столбца
+// нет
+func cell(row []string, index int) string {
+	if index < 0 || index >= len(row) {
+		return ""
+	}
+
+	return row[index]
+}
+
+// localePlatformColumns Вспомогательная функция, возвращающая названия столбца устройства участника на всех языках
+// отчётов
+func localePlatformColumns() []string {
+	var columns []string
+	for _, locale := range Locales {
+		columns = append(columns, locale.PlatformColumns...)
+	}
+
+	return columns
+}
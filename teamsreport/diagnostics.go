@@ -0,0 +1,133 @@
+package teamsreport
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mod.go/i18n"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Diagnostics Структура сведений о разборе отчётов собрания для пробного запуска и подробного вывода
+type Diagnostics struct {
+	//Имена участников, из которых не удалось выделить ФИО (такие участники пропускаются)
+	Unparsed []string
+	//Сведения о разборе каждой строки участника (заполняются только при подробном выводе)
+	Rows []DiagnosticRow
+	//Заполнять ли сведения о разборе каждой строки
+	verbose bool
+}
+
+// DiagnosticRow Структура разбора строки участника: выделенное ФИО, группа и её источник, время присоединения и
+// выхода и продолжительность. Строки, не попавшие в список участников, содержат только пояснение Skipped
+type DiagnosticRow struct {
+	//Файл отчёта, номер строки и имя участника в отчёте
+	Path        string
+	Line        int
+	DisplayName string
+	//Причина пропуска строки (пустая, если строка разобрана)
+	Skipped string
+	//Выделенное ФИО, группа и источник группы (пустой у переподключения)
+	FullName, Group, Source string
+	//Время присоединения и выхода и продолжительность в секундах
+	Join, Leave time.Time
+	Duration    int
+	//Переподключение и время устройства вне времени собрания
+	Reconnect, ClockDrift bool
+}
+
+/*====================================================================================================================*/
+
+// DiagnoseCSVReports Функция, читающая отчёты собрания так же, как ReadCSVReports(), и дополнительно возвращающая
+// сведения о разборе: имена, из которых не удалось выделить ФИО, и (при подробном выводе) разбор каждой строки
+// участника. Используется для поиска причин неверного определения группы или отметок студента
+func DiagnoseCSVReports(ctx context.Context, paths []string, lessons schedule.Schedule, base roster.Base,
+	verbose bool) (report.Header, []report.Member, Diagnostics, error) {
+	diagnostics := Diagnostics{verbose: verbose}
+	header, members, err := readCSVReports(ctx, paths, lessons, base, &diagnostics)
+
+	return header, members, diagnostics, err
+}
+
+// String Функция, возвращающая разбор строки участника в виде одной строки текста для пробного запуска
+func (row DiagnosticRow) String() string {
+	if row.Skipped != "" {
+		return fmt.Sprintf("%v:%d: \"%v\" - %v", row.Path, row.Line, row.DisplayName, i18n.T(row.Skipped))
+	}
+
+	group := i18n.T(row.Group)
+	if row.Source != "" {
+		group += " (" + i18n.T(row.Source) + ")"
+	}
+
+	text := fmt.Sprintf("%v:%d: \"%v\" -> %v, %v, %v-%v, %v", row.Path, row.Line, row.DisplayName, row.FullName,
+		group, row.Join.Format("15:04:05"), row.Leave.Format("15:04:05"), schedule.FormatDuration(row.Duration))
+	if row.Reconnect {
+		text += ", " + i18n.T("переподключение")
+	}
+	if row.ClockDrift {
+		text += ", " + i18n.T("время устройства вне времени собрания")
+	}
+
+	return text
+}
+
+// Attrs Функция, возвращающая разбор строки участника в виде атрибутов сообщения журнала для подробного вывода
+func (row DiagnosticRow) Attrs() []interface{} {
+	attrs := []interface{}{slog.String("file", row.Path), slog.Int("line", row.Line),
+		slog.String("name", row.DisplayName)}
+	if row.Skipped != "" {
+		return append(attrs, slog.String("skipped", i18n.T(row.Skipped)))
+	}
+
+	attrs = append(attrs, slog.String("full_name", row.FullName), slog.String("group", i18n.T(row.Group)))
+	if row.Source != "" {
+		attrs = append(attrs, slog.String("group_source", i18n.T(row.Source)))
+	}
+
+	return append(attrs, slog.String("join", row.Join.Format("15:04:05")),
+		slog.String("leave", row.Leave.Format("15:04:05")), slog.Int("duration", row.Duration),
+		slog.Bool("reconnect", row.Reconnect), slog.Bool("clock_drift", row.ClockDrift))
+}
+
+// unparsed Вспомогательная функция, запоминающая имя участника, из которого не удалось выделить ФИО
+func (diagnostics *Diagnostics) unparsed(path string, line int, displayName string) {
+	if diagnostics == nil {
+		return
+	}
+
+	diagnostics.Unparsed = append(diagnostics.Unparsed, displayName)
+	if diagnostics.verbose {
+		diagnostics.Rows = append(diagnostics.Rows, DiagnosticRow{Path: path, Line: line, DisplayName: displayName,
+			Skipped: "не удалось выделить ФИО, строка пропущена"})
+	}
+}
+
+// row Вспомогательная функция, запоминающая разбор строки участника: выделенное ФИО, группу и её источник, время
+// присоединения и выхода и продолжительность
+func (diagnostics *Diagnostics) row(path string, line int, displayName string, member report.Member, source string,
+	join, leave time.Time, duration int, reconnect bool) {
+	if diagnostics == nil || !diagnostics.verbose {
+		return
+	}
+
+	//У переподключения группа уже определена по первой строке участника
+	diagnostics.Rows = append(diagnostics.Rows, DiagnosticRow{Path: path, Line: line, DisplayName: displayName,
+		FullName: member.FullName, Group: member.Group, Source: source, Join: join, Leave: leave, Duration: duration,
+		Reconnect: reconnect, ClockDrift: member.ClockDrift})
+}
+
+// lecturer Вспомогательная функция, запоминающая строку инициатора собрания, которая не попадает в список участников
+func (diagnostics *Diagnostics) lecturer(path string, line int, displayName string) {
+	if diagnostics == nil || !diagnostics.verbose {
+		return
+	}
+
+	diagnostics.Rows = append(diagnostics.Rows, DiagnosticRow{Path: path, Line: line, DisplayName: displayName,
+		Skipped: "инициатор собрания, строка пропущена"})
+}
@@ -0,0 +1,52 @@
+package teamsreport
+
+import (
+	"bufio"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"io"
+)
+
+/*====================================================================================================================*/
+
+// sniffLength Количество первых байт отчёта, по которым определяется кодировка отчёта без BOM
+const sniffLength = 512
+
+/*====================================================================================================================*/
+
+// NewDecodingReader Функция, возвращающая поток отчёта MS Teams в кодировке UTF-8. Кодировка определяется по BOM
+// (UTF-8, UTF-16 Little-Endian или UTF-16 Big-Endian), BOM убирается. Если BOM нет, кодировка определяется по
+// расположению нулевых байт в начале отчёта: в UTF-16 у символов латиницы и цифр один из двух байт нулевой
+func NewDecodingReader(source io.Reader) io.Reader {
+	reader := bufio.NewReaderSize(source, sniffLength)
+
+	//Ошибка чтения (например, слишком короткий отчёт) не мешает определению кодировки по прочитанным байтам
+	prefix, _ := reader.Peek(sniffLength)
+
+	return transform.NewReader(reader, unicode.BOMOverride(detectFallback(prefix)))
+}
+
+// detectFallback Вспомогательная функция, выбирающая декодер отчёта без BOM по количеству нулевых байт на чётных и
+// нечётных позициях. По-умолчанию отчёт считается отчётом в кодировке UTF-8
+func detectFallback(prefix []byte) transform.Transformer {
+	even, odd := 0, 0
+	for i, b := range prefix {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+
+	switch {
+	case odd > len(prefix)/8 && odd > even:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
+	case even > len(prefix)/8 && even > odd:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder()
+	default:
+		return unicode.UTF8.NewDecoder()
+	}
+}
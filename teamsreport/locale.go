@@ -0,0 +1,148 @@
+package teamsreport
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Locale Структура языковых особенностей отчёта MS Teams, позволяющая привести отчёт на любом языке к виду русского
+// отчёта, который разбирают функции пакета
+type Locale struct {
+	//Название языка
+	Name string
+	//Первая строка оглавления отчёта
+	SummaryTitle string
+	//Название первого столбца строки "шапки" таблицы участников
+	FullNameColumn string
+	//Формат даты и времени в отчёте, пустой для русского отчёта, время в котором уже имеет нужный вид
+	TimeLayout string
+	//Соответствие единиц продолжительности нахождения на собрании единицам русского отчёта
+	DurationUnits map[string]string
+	//Соответствие ролей участников ролям русского отчёта
+	Roles map[string]string
+	//Пометки гостя, которые Teams добавляет к имени участника
+	GuestMarkers []string
+	//Возможные названия столбца с устройством, с которого участник присоединился к собранию (есть в новых отчётах)
+	PlatformColumns []string
+}
+
+// Russian Особенности отчёта MS Teams на русском языке
+var Russian = Locale{
+	Name:           "ru",
+	SummaryTitle:   "Сводка собрания",
+	FullNameColumn: "Полное имя",
+	TimeLayout:     "",
+	DurationUnits:  map[string]string{"ч": "ч", "мин": "мин", "с": "с"},
+	Roles: map[string]string{"Инициатор": "Инициатор", "Организатор": "Инициатор", "Выступающий": "Выступающий",
+		"Участник": "Участник"},
+	GuestMarkers:    []string{"(гость)", "(Гость)", "(Guest)", "(внешний)", "(External)"},
+	PlatformColumns: []string{"Источник присоединения", "Устройство", "Платформа"},
+}
+
+// English Особенности отчёта MS Teams на английском языке
+var English = Locale{
+	Name:            "en",
+	SummaryTitle:    "Meeting Summary",
+	FullNameColumn:  "Full Name",
+	TimeLayout:      "1/2/2006, 3:04:05 PM",
+	DurationUnits:   map[string]string{"h": "ч", "hr": "ч", "m": "мин", "min": "мин", "s": "с", "sec": "с"},
+	Roles:           map[string]string{"Organizer": "Инициатор", "Presenter": "Выступающий", "Attendee": "Участник"},
+	GuestMarkers:    []string{"(Guest)", "(External)"},
+	PlatformColumns: []string{"Join Origin", "Device", "Platform"},
+}
+
+// Locales Список поддерживаемых языков отчётов MS Teams
+var Locales = []Locale{Russian, English}
+
+// durationPart Регулярное выражение части продолжительности: число и единица измерения ("27 мин", "27m")
+var durationPart = regexp.MustCompile(`(\d+)\s*([^\d\s]+)`)
+
+/*====================================================================================================================*/
+
+// DetectLocale Функция, определяющая язык отчёта по первой строке оглавления или по "шапке" таблицы участников.
+// Если язык определить не удалось, отчёт считается русским
+func DetectLocale(headerRows [][]string) Locale {
+	for _, locale := range Locales {
+		for _, row := range headerRows {
+			if len(row) == 0 {
+				continue
+			}
+
+			//Убираем BOM и пробелы, которые могут оказаться в начале первой ячейки
+			cell := strings.TrimSpace(strings.TrimPrefix(row[0], "\ufeff"))
+			if cell == locale.SummaryTitle || cell == locale.FullNameColumn {
+				return locale
+			}
+		}
+	}
+
+	return Russian
+}
+
+// NormalizeTimestamp Функция, приводящая дату и время из отчёта к виду русского отчёта ("20.04.2022, 9:41:02")
+func (locale Locale) NormalizeTimestamp(source string) (string, error) {
+	if locale.TimeLayout == "" {
+		return source, nil
+	}
+
+	parsed, err := time.Parse(locale.TimeLayout, strings.TrimSpace(source))
+	if err != nil {
+		return "", fmt.Errorf("ошибка разбора даты и времени \"%v\": %w", source, err)
+	}
+
+	return parsed.Format("02.01.2006, 15:04:05"), nil
+}
+
+// NormalizeDuration Функция, приводящая продолжительность нахождения на собрании к виду русского отчёта
+// ("1 ч 27 мин 38 с"). Неизвестные единицы измерения остаются без изменений
+func (locale Locale) NormalizeDuration(source string) string {
+	var words []string
+
+	for _, part := range durationPart.FindAllStringSubmatch(source, -1) {
+		unit, ok := locale.DurationUnits[part[2]]
+		if !ok {
+			unit = part[2]
+		}
+		words = append(words, part[1], unit)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// NormalizeRole Функция, приводящая роль участника собрания к роли русского отчёта
+func (locale Locale) NormalizeRole(source string) string {
+	if role, ok := locale.Roles[source]; ok {
+		return role
+	}
+
+	return source
+}
+
+// PlatformColumn Функция, возвращающая индекс столбца с устройством участника в "шапке" таблицы участников или -1,
+// если отчёт не содержит такого столбца
+func (locale Locale) PlatformColumn(columns []string) int {
+	for i, column := range columns {
+		for _, name := range locale.PlatformColumns {
+			if strings.EqualFold(strings.TrimSpace(column), name) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// IsGuestMarker Функция, проверяющая, является ли слово имени пометкой гостя
+func (locale Locale) IsGuestMarker(word string) bool {
+	for _, marker := range locale.GuestMarkers {
+		if word == marker {
+			return true
+		}
+	}
+
+	return false
+}
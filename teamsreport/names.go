@@ -0,0 +1,51 @@
+package teamsreport
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*====================================================================================================================*/
+
+// patronymicSuffixes Окончания отчеств (в том числе латиницей), по которым в имени участника находится отчество
+var patronymicSuffixes = []string{"вич", "вна", "ична", "оглы", "кызы", "vich", "vna", "ichna", "ogly", "kyzy"}
+
+// spacedHyphen Регулярное выражение дефиса двойной фамилии, окружённого пробелами ("Петров - Водкин")
+var spacedHyphen = regexp.MustCompile(`\s*-\s*`)
+
+/*====================================================================================================================*/
+
+// orderFullName Вспомогательная функция, приводящая слова имени участника (без группы и пометки гостя) к порядку ФИО.
+// Фамилией считаются слова после отчества, а если отчества нет - последнее слово ("Иван Иванов" - "Иванов Иван").
+// Имя из трёх и более слов, которое заканчивается отчеством, уже указано в виде ФИО и не изменяется. Фамилия с
+// окончанием отчества ("Рабинович") не принимается за отчество, если отчество указано раньше
+func orderFullName(words []string) []string {
+	patronymic := -1
+	for i := 1; i < len(words); i++ {
+		if isPatronymic(words[i]) {
+			patronymic = i
+			break
+		}
+	}
+
+	switch {
+	case patronymic == len(words)-1 && len(words) > 2:
+		return words
+	case patronymic > 0 && patronymic < len(words)-1:
+		return append(append([]string{}, words[patronymic+1:]...), words[:patronymic+1]...)
+	default:
+		return append([]string{words[len(words)-1]}, words[:len(words)-1]...)
+	}
+}
+
+// isPatronymic Вспомогательная функция, проверяющая по окончанию, является ли слово имени отчеством
+func isPatronymic(word string) bool {
+	word = strings.ToLower(word)
+	for _, suffix := range patronymicSuffixes {
+		if strings.HasSuffix(word, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,983 @@
+// Package teamsreport Пакет чтения отчётов о посещаемости, выгруженных из MS Teams: поиск последнего отчёта в
+// директории загрузок и разбор его на оглавление и список участников собрания
+package teamsreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// DefaultGroupPatterns Стандартные шаблоны групп, которые могут быть указаны в имени участника собрания
+const DefaultGroupPatterns = "мп мт мк мн"
+
+// GroupPatterns Шаблоны групп, по которым группа выделяется из имени участника собрания. Устанавливаются из файла
+// конфигураций с помощью функции CompileGroupPatterns()
+var GroupPatterns, _ = CompileGroupPatterns(DefaultGroupPatterns)
+
+// StrictParsing Прерывать ли чтение отчёта на первой некорректной строке участника. Если выключено, некорректные строки
+// пропускаются и выводятся в отчёте в разделе предупреждений разбора. Устанавливается из файла конфигураций
+var StrictParsing = false
+
+// DefaultStaffRoles Стандартные роли участников собрания, которые являются преподавателями, а не студентами
+const DefaultStaffRoles = "Organizer, Presenter, Инициатор, Выступающий"
+
+// StaffRoles Роли участников собрания (инициаторы, организаторы, выступающие), которые не попадают в список
+// участников и не отмечаются отсутствующими. Устанавливаются из файла конфигураций
+var StaffRoles = ParseStaffRoles(DefaultStaffRoles)
+
+// Limits Структура ограничений чтения одного отчёта MS Teams, не позволяющих повреждённому или чужому файлу в
+// директории загрузок занять всю память или надолго остановить обработку. Нулевое ограничение не проверяется
+type Limits struct {
+	//Наибольший размер файла отчёта в байтах
+	MaxFileSize int64
+	//Наибольшее количество строк участников в отчёте
+	MaxRows int
+	//Наибольшее время чтения одного отчёта
+	FileTimeout time.Duration
+}
+
+// ReadLimits Ограничения чтения отчётов. Устанавливаются из файла конфигураций
+var ReadLimits = Limits{MaxFileSize: 50 << 20, MaxRows: 100000, FileTimeout: time.Minute}
+
+// ErrLimitExceeded Ошибка, возвращаемая, если отчёт превышает одно из ограничений чтения: такой отчёт пропускается
+var ErrLimitExceeded = errors.New("отчёт превышает ограничения чтения")
+
+// ErrNoReports Ошибка, возвращаемая, если в директории загрузок нет ни одного отчёта (.csv или .xlsx файла)
+var ErrNoReports = errors.New("в данном каталоге не содержится .csv и .xlsx файлов, вероятно, неверно указан путь до " +
+	"загрузок")
+
+/*====================================================================================================================*/
+
+// FormCSVList Вспомогательная функция, которая возвращает список отчётов (.csv и .xlsx файлов) из загрузок
+func FormCSVList(root string) ([]string, error) {
+	//Массив всех найденных .csv файлов
+	var csvFiles []string
+
+	//Считываем директорию в массив dir, элементы dir являются fs.FileStat
+	dir, err := ioutil.ReadDir(root)
+	//Стандартная проверка на ошибку при чтении директории (файла)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия директории: %w", err)
+	}
+
+	//Цикл по всем элементам массива dir
+	for _, file := range dir {
+		//Условие: если элемент file НЕ является директорией и является отчётом (.csv или .xlsx)
+		if !file.IsDir() && IsReportFile(file.Name()) {
+			//В конец массива добавляется строка, содержащая полный путь до .csv файла
+			csvFiles = append(csvFiles, root+file.Name())
+		}
+	}
+
+	//Если по-указанному в cfg.ini пути до загрузок не оказалось .csv файлов, то возвращается ошибка
+	if len(csvFiles) == 0 {
+		return nil, ErrNoReports
+	}
+
+	return csvFiles, nil
+}
+
+// FindCurrentReport Функция, которая возвращает текущий (последний) .csv файл
+func FindCurrentReport(ctx context.Context, root string) (string, error) {
+	//Формируем список .csv файлов с помощью функции FormCSVList()
+	csvFiles, err := FormCSVList(root)
+	if err != nil {
+		return "", err
+	}
+
+	//Присваиваем первый элемент списка .csv файлов необходимому отчёту для дальнейшего поиска текущего отчёта
+	//(Присваиваем первый элемент, т.к. первым элементом массив чаще всего является последний файл)
+	currentReport := csvFiles[0]
+
+	//Цикл по всем элементам массива .csv файлов, за исключением 1 элемента
+	for i := 1; i < len(csvFiles); i++ {
+		//Прерываем поиск, если контекст отменён
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		//Считываем i-тый элемент массива в виде os.Stat, для получения подробной информации о файле
+		temp, err := os.Stat(csvFiles[i])
+		if err != nil {
+			return "", fmt.Errorf("ошибка открытия файла: %w", err)
+		}
+
+		//Считываем текущий отчёт в виде os.Stat
+		currentReportInfo, err := os.Stat(currentReport)
+		if err != nil {
+			return "", fmt.Errorf("ошибка открытия файла: %w", err)
+		}
+
+		//Условие: если последняя модификация i-того элемента массива была позже текущего отчёта
+		if temp.ModTime().After(currentReportInfo.ModTime()) {
+			//Текущий отчёт становится i-тым элементом списка
+			currentReport = root + temp.Name()
+		}
+	}
+
+	return currentReport, nil
+}
+
+/*====================================================================================================================*/
+
+// ParseStaffRoles Функция, разбирающая перечисленные через запятую роли участников собрания, которые не являются
+// студентами
+func ParseStaffRoles(source string) []string {
+	var roles []string
+	for _, role := range strings.Split(source, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// IsStaffRole Функция, проверяющая, является ли роль участника собрания (как она указана в отчёте или приведённая
+// к роли русского отчёта) одной из ролей преподавателей
+func IsStaffRole(role string, locale Locale) bool {
+	role = strings.TrimSpace(role)
+	for _, staff := range StaffRoles {
+		if strings.EqualFold(role, staff) || strings.EqualFold(locale.NormalizeRole(role), staff) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CompileGroupPatterns Функция, переводящая перечисленные через пробел шаблоны групп в регулярные выражения. Шаблон
+// сопоставляется с началом слова имени без учёта регистра: шаблон "мп" подходит под "МП-31" и "мпб-31", шаблон
+// "ИВТ-" - под "ивт-21", шаблон "CS-\d+" - под "CS-101"
+func CompileGroupPatterns(source string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+
+	for _, word := range strings.Fields(source) {
+		pattern, err := regexp.Compile("(?i)^(?:" + word + ")")
+		if err != nil {
+			return nil, fmt.Errorf("некорректный шаблон группы \"%v\": %w", word, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// ParseFullName Функция, приводящая имя участника собрания из отчёта MS Teams (ИОФ) к виду ФИО. Имя может быть
+// указано и как "Фамилия, Имя Отчество", в том числе латиницей, из двух или четырёх слов, с двойной фамилией через
+// дефис. Если в имени указана группа (при некорректной регистрации на собрание), она возвращается вторым значением и
+// записывается после ФИО. Если в имени нет хотя бы двух слов, из него нельзя получить корректной информации и
+// возвращается ложь
+func ParseFullName(displayName string, locale Locale) (string, string, bool) {
+	//Группа, указанная в имени участника
+	var group string
+
+	//Слова имени без пометки (гость), установленной Teams, и без группы, указанной при некорректной регистрации
+	nameWords := func(source string) []string {
+		var words []string
+		for _, word := range strings.Fields(spacedHyphen.ReplaceAllString(source, "-")) {
+			if locale.IsGuestMarker(word) {
+				continue
+			}
+			//Перменная являющаяся группой в некорректном имени (без скобок, при наличии)
+			mayBeGroup := strings.Trim(word, "()")
+			if mayBeGroup == "" {
+				continue
+			}
+			if isGroup(mayBeGroup) {
+				group = mayBeGroup
+				continue
+			}
+			words = append(words, word)
+		}
+		return words
+	}
+
+	//Запятая отделяет фамилию от имени и отчества ("Иванов, Иван Иванович"), иначе порядок слов определяется функцией
+	// orderFullName()
+	var fullNameArr []string
+	if surname, given, found := strings.Cut(displayName, ","); found {
+		surnameWords, givenWords := nameWords(surname), nameWords(strings.ReplaceAll(given, ",", " "))
+		if len(surnameWords) > 0 && len(givenWords) > 0 {
+			fullNameArr = append(surnameWords, givenWords...)
+		} else if words := append(surnameWords, givenWords...); len(words) > 1 {
+			fullNameArr = orderFullName(words)
+		}
+	} else if words := nameWords(displayName); len(words) > 1 {
+		fullNameArr = orderFullName(words)
+	}
+
+	//Проверка на длину исключает ряд ошибок, связанных с некорректной регистраций на собрание
+	if len(fullNameArr) < 2 {
+		return "", "", false
+	}
+	if group != "" {
+		fullNameArr = append(fullNameArr, group)
+	}
+
+	//Соединяем массив в единую строку
+	return strings.Join(fullNameArr, " "), group, true
+}
+
+// isGroup Вспомогательная функция, проверяющая, подходит ли слово имени участника под один из шаблонов групп
+func isGroup(word string) bool {
+	for _, pattern := range GroupPatterns {
+		if pattern.MatchString(word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckNameGroup Функция, проверяющая группу, выделенную из имени участника собрания функцией ParseFullName(). Если
+// группа не принимается базой групп (вероятно, опечатка в имени), она убирается из ФИО и возвращается ложь: группа
+// участника в этом случае определяется по базе групп
+func CheckNameGroup(base roster.Base, fullName, group string) (string, string, bool) {
+	if group == "" || base.AcceptGroup(group) {
+		return fullName, group, true
+	}
+
+	words := strings.Fields(fullName)
+	for i, word := range words {
+		if word == group {
+			words = append(words[:i], words[i+1:]...)
+			break
+		}
+	}
+
+	return strings.Join(words, " "), "", false
+}
+
+// ParsePlatform Функция, относящая устройство, с которого участник присоединился к собранию, к одному из видов:
+// мобильное устройство, компьютер или браузер
+func ParsePlatform(source string) string {
+	//Приводим строку к нижнему регистру, чтобы не зависеть от написания в отчёте
+	platform := strings.ToLower(strings.TrimSpace(source))
+
+	//Разбор ситуации по ключевым словам устройства
+	switch {
+	case platform == "":
+		return ""
+	case strings.Contains(platform, "android") || strings.Contains(platform, "ios") ||
+		strings.Contains(platform, "iphone") || strings.Contains(platform, "ipad") ||
+		strings.Contains(platform, "mobile") || strings.Contains(platform, "мобил") ||
+		strings.Contains(platform, "телефон"):
+		return "Мобильное устройство"
+	case strings.Contains(platform, "web") || strings.Contains(platform, "browser") ||
+		strings.Contains(platform, "браузер"):
+		return "Браузер"
+	case strings.Contains(platform, "desktop") || strings.Contains(platform, "windows") ||
+		strings.Contains(platform, "mac") || strings.Contains(platform, "linux") ||
+		strings.Contains(platform, "компьютер") || strings.Contains(platform, "настольн"):
+		return "Компьютер"
+	default:
+		return "Другое"
+	}
+}
+
+// GetDateAndLessonNumberOrDelay Функция, обрабатывающая строку с датой и временем начала собрания, и возвращающая
+// их по-отдельности. Так же в функцию поступает значение фазы, которое позволяет применить функцию для
+// определения опоздания
+func GetDateAndLessonNumberOrDelay(source, phase string, lessons schedule.Schedule) (string, string, error) {
+	//Разделяем строку с датой и временем по запятой
+	words := strings.Split(source, ",")
+	if len(words) < 2 {
+		return "", "", fmt.Errorf("некорректный формат даты и времени: %v", source)
+	}
+
+	//Убираем лишний пробел в начале строки времени
+	words[1] = strings.ReplaceAll(words[1], " ", "")
+
+	//Если параметр фазы = заполнению оглавления
+	if phase == "header" {
+		//Переменная, содержащая дату
+		date := words[0]
+
+		//Номер пары получается из строки времени и сопоставляется со временем начала и конца пары из расписания (+- допуск)
+		lessonNumber, err := schedule.ParseLessonNumberOrDelay(words[1], phase, lessons)
+
+		return date, lessonNumber, err
+		//Если параметр фазы = заполнение члена собрания
+	} else {
+		//Пометка об опоздании возвращается из функции ParseLessonNumberOrDelay (второе значение - пустое)
+		delay, err := schedule.ParseLessonNumberOrDelay(words[1], phase, lessons)
+
+		return delay, "_", err
+	}
+}
+
+// GetDurationOfPresence Функция, обрабатывающая строку нахождения участника на собрании и возвращающая пометку
+// о малом или полном нахождении на собрании
+func GetDurationOfPresence(source string) (report.Exit, error) {
+	//Разбиваем строку на массив строк по символам пробела
+	words := strings.Fields(source)
+
+	//Если массив состоит из двух строк, то участник находился на собрании меньше минуты, следовательно,
+	// на паре почти не присутствовал
+	if len(words) == 2 {
+		return report.Exit{Status: report.ExitBrief}, nil
+		//Если массив состоит из 4 строк, то участник был на собрании менее часа, но больше минуты. Требуется обработка
+	} else if len(words) == 4 {
+		//Вспомогательный массив, содержащий только строки чисел
+		timeArray := []string{words[0], words[2]}
+
+		//Получаем время в секундах с помощью функции ParseTime()
+		time, err := schedule.ParseTime(timeArray)
+		if err != nil {
+			return report.Exit{}, err
+		}
+
+		//Разбор ситуации. Если время больше 30 минут, то участник считается полноценным участником собрания,
+		// иначе ставится пометка о малом нахождении на собрании
+		switch {
+		//Время присутствия на паре более 30 минут
+		case time > 1800:
+			return report.Exit{Status: report.ExitFull}, nil
+		default:
+			return report.Exit{Status: report.ExitShort}, nil
+		}
+		//Иначе массив состоит из 6 или более строк, т.е. больше часа, следовательно участник находился на паре
+		// полное время
+	} else {
+		return report.Exit{Status: report.ExitFull}, nil
+	}
+}
+
+// PresenceByShare Функция, возвращающая пометку о нахождении на собрании по доле продолжительности собрания в
+// процентах: начиная с доли присутствия из расписания - полное присутствие, иначе - малое присутствие (меньше минуты)
+// или малое нахождение на паре
+func PresenceByShare(duration, share int, lessons schedule.Schedule) report.Exit {
+	switch {
+	case share >= lessons.PresenceShare:
+		return report.Exit{Status: report.ExitFull}
+	case duration < 60:
+		return report.Exit{Status: report.ExitBrief}
+	default:
+		return report.Exit{Status: report.ExitShort}
+	}
+}
+
+// ClampToMeeting Функция, приводящая время присоединения и выхода участника ко времени собрания: присоединение раньше
+// начала собрания и выход позже его окончания (которые возможны только при неверных часах устройства участника)
+// заменяются началом и окончанием собрания, чтобы участник не относился к другой паре. Возвращает признак расхождения
+// часов больше допуска из расписания. Если время окончания собрания неизвестно (нулевое), выход не проверяется
+func ClampToMeeting(join, leave, start, end time.Time, lessons schedule.Schedule) (time.Time, time.Time, bool) {
+	tolerance := time.Duration(lessons.ClockDriftTolerance) * time.Second
+	drift := false
+
+	if !start.IsZero() && join.Before(start) {
+		drift = drift || start.Sub(join) > tolerance
+		join = start
+	}
+	if !end.IsZero() && leave.After(end) {
+		drift = drift || leave.Sub(end) > tolerance
+		leave = end
+	}
+
+	return join, leave, drift
+}
+
+/*====================================================================================================================*/
+
+// ParseLecturer Функция, приводящая имя инициатора собрания(преподавателя) к виду ФИО. Если имя не удаётся разобрать,
+// оно возвращается как есть
+func ParseLecturer(displayName string, locale Locale) string {
+	if fullName, _, ok := ParseFullName(displayName, locale); ok {
+		return fullName
+	}
+
+	return strings.TrimSpace(displayName)
+}
+
+// meetingMerge Структура участников, собранных из одного или нескольких отчётов одного собрания. Участник,
+// переподключавшийся к собранию, встречается в отчётах несколько раз. Для объединения таких строк хранится индекс
+// участника в массиве по ФИО, а также самое раннее время присоединения, самое позднее время выхода и суммарная
+// продолжительность
+type meetingMerge struct {
+	//Оглавление собрания, заданное первым отчётом
+	header report.Header
+	//Начало суток дня собрания
+	meetingDay time.Time
+	//Время начала собрания по первому отчёту
+	start time.Time
+	//Количество прочитанных отчётов
+	reports int
+	//Массив, содержащий всех членов собрания
+	members   []report.Member
+	indexes   map[string]int
+	joins     []time.Time
+	leaves    []time.Time
+	durations []int
+	//Имена участников, из которых не удалось выделить ФИО, уже записанные в предупреждения разбора
+	unparsed map[string]bool
+	//Сведения о разборе отчётов (только при пробном запуске и подробном выводе)
+	diagnostics *Diagnostics
+}
+
+// ReadCSVReport Функция, которая парсит отчёт на две структуры: оглавление отчёта и массив членов собрания. Группы
+// участников определяются по базе групп
+func ReadCSVReport(ctx context.Context, path string, lessons schedule.Schedule, base roster.Base) (report.Header, []report.Member, error) {
+	return ReadCSVReports(ctx, []string{path}, lessons, base)
+}
+
+// ReadCSVReports Функция, объединяющая несколько отчётов одного собрания (например, пары, прерванной и продолженной
+// в новом собрании MS Teams) в одно оглавление и массив членов собрания. Оглавление берётся из первого отчёта,
+// продолжительности нахождения участников на собраниях суммируются
+func ReadCSVReports(ctx context.Context, paths []string, lessons schedule.Schedule, base roster.Base) (report.Header, []report.Member, error) {
+	return readCSVReports(ctx, paths, lessons, base, nil)
+}
+
+// ReadCSVReportFrom Функция, разбирающая отчёт .csv из потока in (в кодировке UTF-16 или UTF-8) так же, как функция
+// ReadCSVReport() разбирает файл отчёта. Позволяет разобрать отчёт без файла (например, загруженный по сети или
+// хранящийся в памяти). Имя name выводится в предупреждениях разбора вместо пути до файла
+func ReadCSVReportFrom(ctx context.Context, name string, in io.Reader, lessons schedule.Schedule,
+	base roster.Base) (report.Header, []report.Member, error) {
+	merge := meetingMerge{indexes: make(map[string]int)}
+	if err := merge.parse(ctx, name, NewDecodingReader(in), lessons, base); err != nil {
+		return merge.header, nil, err
+	}
+
+	return merge.result(lessons)
+}
+
+// readCSVReports Вспомогательная функция чтения отчётов собрания, дополнительно заполняющая сведения о разборе, если
+// они переданы
+func readCSVReports(ctx context.Context, paths []string, lessons schedule.Schedule, base roster.Base,
+	diagnostics *Diagnostics) (report.Header, []report.Member, error) {
+	if len(paths) == 0 {
+		return report.Header{}, nil, fmt.Errorf("не указаны отчёты собрания")
+	}
+
+	merge := meetingMerge{indexes: make(map[string]int), diagnostics: diagnostics}
+	for _, path := range paths {
+		if err := merge.read(ctx, path, lessons, base); err != nil {
+			return merge.header, nil, err
+		}
+	}
+
+	return merge.result(lessons)
+}
+
+// result Функция, выставляющая пометки участников всех прочитанных отчётов собрания и возвращающая оглавление и
+// участников собрания
+func (merge *meetingMerge) result(lessons schedule.Schedule) (report.Header, []report.Member, error) {
+	//Отчёт, в котором не удалось прочитать ни одного участника, не обрабатывается
+	if len(merge.members) == 0 && len(merge.header.Warnings) > 0 {
+		return merge.header, nil, fmt.Errorf("не удалось прочитать ни одного участника собрания: %v",
+			merge.header.Warnings[0])
+	}
+
+	header, meetingDay := merge.header, merge.meetingDay
+	members, joins, leaves, durations := merge.members, merge.joins, merge.leaves, merge.durations
+
+	//Опоздания и ранний уход определяются по расписанию дня недели собрания или по плановому времени события
+	// календаря организатора, с которым сопоставлено собрание
+	lessons = lessons.ForMeeting(merge.start)
+	if lessons.Calendar != nil {
+		if event, ok := lessons.Calendar(merge.start); ok {
+			header.PlannedStart, header.PlannedEnd = event.Start, event.End
+		}
+	}
+
+	//Подозрительно короткое собрание считается техническим созвоном, а не парой
+	if schedule.IsTechnicalCall(header.Duration, lessons) {
+		header.LessonNumber = schedule.TechnicalCall
+	}
+
+	//Пара, к которой относится собрание, нужна для определения раннего ухода (у консультации и технического созвона
+	// её нет)
+	lesson, isLesson := schedule.FindLesson(header.LessonNumber, lessons)
+
+	//Пометки участников выставляются после объединения всех их строк
+	for i := range members {
+		//Пометка об опоздании по самому раннему времени присоединения участника к собранию, отсчитанному от начала
+		// суток дня собрания
+		members[i].Delay, members[i].DelayMinutes = schedule.Delay(int(joins[i].Sub(meetingDay).Seconds()), lessons)
+
+		//Время присоединения, выхода и продолжительность сохраняются для машиночитаемых форматов отчёта
+		members[i].Join, members[i].Leave, members[i].Duration = joins[i], leaves[i], durations[i]
+
+		//Пометка о малом нахождении на паре (Если меньше получаса - малое присутствие на паре, иначе полное)
+		var err error
+		members[i].EarlyExit, err = GetDurationOfPresence(schedule.FormatDuration(durations[i]))
+		if err != nil {
+			return header, nil, err
+		}
+
+		//Если продолжительность собрания известна, пометка выставляется по доле продолжительности собрания, которую
+		// участник находился на собрании, вместо правила получаса (которое не подходит для коротких консультаций и
+		// длинных лабораторных работ)
+		if header.Duration > 0 {
+			members[i].PresenceShare = min(durations[i]*100/header.Duration, 100)
+			if lessons.PresenceShare > 0 {
+				members[i].EarlyExit = PresenceByShare(durations[i], members[i].PresenceShare, lessons)
+			}
+		}
+
+		//Если участник вышел с собрания раньше окончания пары, ставится пометка о раннем уходе
+		if isLesson {
+			leave := int(leaves[i].Sub(meetingDay).Seconds())
+			if earlyExit, ok := schedule.ParseEarlyExit(leave, lesson, lessons); ok {
+				members[i].EarlyExit = earlyExit
+			}
+		}
+
+		//Если стоит пометка о малом нахождении на паре, то ставится пометка об отсутствии на паре
+		if members[i].EarlyExit.Status == report.ExitFull {
+			members[i].Presence = report.PresenceFull
+		} else {
+			members[i].Presence = report.PresencePartial
+		}
+	}
+
+	return header, members, nil
+}
+
+// read Функция, считывающая один отчёт собрания и добавляющая его участников к уже прочитанным
+func (merge *meetingMerge) read(ctx context.Context, path string, lessons schedule.Schedule, base roster.Base) error {
+	//Считываем отчёт
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла отчёта: %w", err)
+	}
+
+	//Закрываем файл
+	defer file.Close()
+
+	//Слишком большой файл не читается, чтобы не занять всю память
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("ошибка открытия файла отчёта: %w", err)
+	}
+	if ReadLimits.MaxFileSize > 0 && info.Size() > ReadLimits.MaxFileSize {
+		return fmt.Errorf("%w: размер файла %v - %d байт при ограничении %d байт", ErrLimitExceeded, path,
+			info.Size(), ReadLimits.MaxFileSize)
+	}
+
+	//Создаём поток данных файла с отчётом в кодировке UTF-8. Кодировка отчёта (UTF-16 Little-Endian, UTF-16 Big-Endian
+	// или UTF-8) определяется функцией NewDecodingReader(). Отчёт .xlsx новых клиентов MS Teams переводится в строки
+	// отчёта .csv того же вида и разбирается тем же путём
+	var utf8r io.Reader
+	if strings.EqualFold(filepath.Ext(path), ".xlsx") {
+		text, err := convertXLSXReport(file, info.Size())
+		if err != nil {
+			return err
+		}
+		utf8r = bytes.NewReader(text)
+	} else {
+		utf8r = NewDecodingReader(file)
+	}
+
+	return merge.parse(ctx, path, utf8r, lessons, base)
+}
+
+// parse Функция, разбирающая один отчёт собрания из потока в кодировке UTF-8 и добавляющая его участников к уже
+// прочитанным. Путь path (или имя отчёта) выводится в предупреждениях разбора и сведениях о разборе
+func (merge *meetingMerge) parse(ctx context.Context, path string, utf8r io.Reader, lessons schedule.Schedule,
+	base roster.Base) error {
+	//Чтение одного отчёта ограничено по времени
+	if ReadLimits.FileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ReadLimits.FileTimeout)
+		defer cancel()
+	}
+
+	//Переменная, читающая .csv файл
+	data := csv.NewReader(utf8r)
+
+	//Отчёты от MS Teams разделяются символом табуляции, устанавливаем деление на символ табуляции
+	data.Comma = '\t'
+
+	//Убираем количество полей в Reader, чтобы не возникало ошибок о некорректном количество полей в строке
+	data.FieldsPerRecord = -1
+
+	//Считываем строки оглавления до "шапки" таблицы участников, которая определяется по названиям столбцов на любом из
+	// известных языков. По "шапке" определяются индексы столбцов, поэтому новые и переставленные столбцы не мешают
+	// разбору отчёта
+	var headerRows [][]string
+	var columns Columns
+	for {
+		row, err := data.Read()
+		if err == io.EOF {
+			return fmt.Errorf("в отчёте %v не найдена \"шапка\" таблицы участников", path)
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения строки csv файла: %w", err)
+		}
+		headerRows = append(headerRows, row)
+
+		var found bool
+		if columns, found = MapColumns(row); found {
+			break
+		}
+		if len(headerRows) > maxSummaryRows {
+			return fmt.Errorf("в первых %d строках отчёта %v не найдена \"шапка\" таблицы участников", maxSummaryRows,
+				path)
+		}
+	}
+
+	//Определяем язык отчёта по строкам оглавления, чтобы привести даты, продолжительности и роли к виду русского отчёта
+	locale := DetectLocale(headerRows)
+
+	//Оглавление и начало суток дня собрания, относительно которого отсчитывается время присоединения и выхода
+	// участников, чтобы собрание, продолжающееся после полуночи, целиком относилось к дате его начала
+	var header report.Header
+	var meetingDay, startTime, endTime time.Time
+
+	//Цикл по строкам оглавления, формирующий структуру со всеми данными оглавления отчёта
+	for i, row := range headerRows {
+		//Разбор ситуации. В зависимости от номера строки заполняется структура оглавления (или строка пропускается)
+		switch {
+		//В третьей строке указано название собрания
+		case i == 2:
+			//Заполняем поле название собрания второй колонки из отчёта
+			//Если название собрания не было изменено вручную или не было введено, ему присваивается
+			// "Название по-умолчанию"
+			if len(row) > 1 {
+				if row[1] == "General" {
+					header.Title = roster.DefaultTitle
+				} else {
+					header.Title = row[1]
+				}
+			} else {
+				header.Title = roster.DefaultTitle
+			}
+		//В четвёртой строке указаны дата и время начала собрания
+		case i == 3:
+			if len(row) < 2 {
+				return fmt.Errorf("в отчёте не указано время начала собрания")
+			}
+
+			//Приводим дату и время начала собрания к виду русского отчёта
+			start, err := locale.NormalizeTimestamp(row[1])
+			if err != nil {
+				return err
+			}
+
+			//Переводим время начала собрания в часовой пояс аудитории, если он задан в конфигурациях
+			startTime, err = schedule.ParseTimestamp(start, lessons)
+			if err != nil {
+				return fmt.Errorf("ошибка разбора времени начала собрания: %w", err)
+			}
+			if lessons.TimeZone != nil {
+				start = startTime.Format("02.01.2006, 15:04:05")
+			}
+
+			//Заполняются поля с датой проведения пары и номером пары с помощью вспомогательного метода
+			// GetDateAndLessonNumber() по расписанию дня недели собрания или по событию календаря организатора
+			header.Date, header.LessonNumber, err = GetDateAndLessonNumberOrDelay(start, "header",
+				lessons.ForMeeting(startTime))
+			if err != nil {
+				return err
+			}
+
+			header.StartTime = startTime
+			meetingDay = time.Date(startTime.Year(), startTime.Month(), startTime.Day(), 0, 0, 0, 0, startTime.Location())
+		//В пятой строке указаны дата и время окончания собрания, по которым определяется продолжительность собрания.
+		// Если время окончания не удаётся разобрать, продолжительность собрания остаётся неизвестной
+		case i == 4 && len(row) > 1 && !startTime.IsZero():
+			end, err := locale.NormalizeTimestamp(row[1])
+			if err != nil {
+				break
+			}
+			if parsed, err := schedule.ParseTimestamp(end, lessons); err == nil && parsed.After(startTime) {
+				endTime = parsed
+				header.EndTime, header.Duration = endTime, int(endTime.Sub(startTime).Seconds())
+			}
+		//Ссылки на собрание и на его запись есть только в оглавлении отчётов, загруженных из Microsoft Graph
+		case len(row) > 1 && row[0] == "Ссылка на собрание":
+			header.JoinURL = strings.TrimSpace(row[1])
+		case len(row) > 1 && row[0] == "Запись собрания":
+			header.RecordingURL = strings.TrimSpace(row[1])
+		//Во всех остальных строках оглавления не содержится необходимой информации, они пропускаются
+		default:
+		}
+	}
+
+	//Первый отчёт задаёт оглавление собрания, остальные отчёты должны относиться к собранию того же дня
+	//Продолжительности объединяемых собраний суммируются, окончанием собрания становится самое позднее окончание
+	if merge.reports == 0 {
+		merge.header, merge.meetingDay, merge.start = header, meetingDay, startTime
+	} else if header.Date != merge.header.Date {
+		return fmt.Errorf("отчёт %v относится к собранию другого дня (%v вместо %v)", path, header.Date, merge.header.Date)
+	} else {
+		merge.header.Duration += header.Duration
+		if header.EndTime.After(merge.header.EndTime) {
+			merge.header.EndTime = header.EndTime
+		}
+		//Запись собрания могла вестись не с первого сеанса
+		if merge.header.RecordingURL == "" {
+			merge.header.RecordingURL = header.RecordingURL
+		}
+		if merge.header.JoinURL == "" {
+			merge.header.JoinURL = header.JoinURL
+		}
+	}
+	merge.reports++
+
+	//Количество прочитанных строк участников: всего и в текущем разделе отчёта
+	rows, sectionRows := 0, 0
+
+	//Безусловный цикл, в котором будет заполняться массив членов собрания
+	for {
+		//Прерываем чтение, если контекст отменён или истекло время чтения отчёта
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && ReadLimits.FileTimeout > 0 {
+			return fmt.Errorf("%w: чтение файла %v заняло больше %v", ErrLimitExceeded, path, ReadLimits.FileTimeout)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		//Отчёт со слишком большим количеством строк не дочитывается
+		if rows++; ReadLimits.MaxRows > 0 && rows > ReadLimits.MaxRows {
+			return fmt.Errorf("%w: в файле %v больше %d строк участников", ErrLimitExceeded, path, ReadLimits.MaxRows)
+		}
+
+		//Считываем строку из .csv файла
+		row, err := data.Read()
+
+		//Если обнаружен конец файла, то цикл прерывается
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			if err := merge.skip(path, parseErr.StartLine, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения строки csv файла: %w", err)
+		}
+
+		//Пропускаем заголовки разделов отчёта (например, "3. In-Meeting Activities"). В новых отчётах MS Teams за
+		// разделом "Participants" с общей продолжительностью каждого участника следует раздел "In-Meeting Activities"
+		// с отдельной строкой каждого присоединения, поэтому после прочитанного раздела участников чтение прекращается,
+		// иначе продолжительности учитывались бы дважды. "Шапка" без строк участников после неё (пустой раздел)
+		// заменяет индексы столбцов
+		if len(row) == 1 {
+			continue
+		}
+		if mapped, found := MapColumns(row); found {
+			if sectionRows > 0 {
+				break
+			}
+			columns = mapped
+			continue
+		}
+		sectionRows++
+
+		//Номер строки в отчёте для сведений о разборе и предупреждений
+		line, _ := data.FieldPos(0)
+
+		//Строка участника должна содержать имя, время присоединения, время выхода и продолжительность
+		if len(row) < columns.MinLength() {
+			err := fmt.Errorf("некорректное количество столбцов в строке участника: %v", len(row))
+			if err := merge.skip(path, line, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		//Имя, почта и роль участника из столбцов, найденных по "шапке" таблицы
+		name, email, role := row[columns.Name], cell(row, columns.Email), cell(row, columns.Role)
+
+		//Переменная, в которую будет записываться данные из текущей строки отчёта
+		var currentMember report.Member
+
+		//Приводим имя участника к виду ФИО и выделяем группу, указанную в имени, с помощью функции ParseFullName()
+		fullName, group, ok := ParseFullName(name, locale)
+
+		//Участник, почта которого есть в базе групп, получает ФИО и группу из базы, как бы он ни подписался в MS Teams
+		if baseName, found := roster.FindByEmail(email); found {
+			fullName, group, ok = baseName, "", true
+		} else if baseName, found := roster.ResolveName(fullName); ok && found {
+			//Участник, имя которого указано в файле псевдонимов, получает ФИО из базы без сопоставления по похожему ФИО
+			fullName, group = baseName, ""
+		}
+
+		//Группа из имени, которой нет в базе групп, не создаёт отдельную группу в отчёте: группа определяется по базе,
+		// а расхождение выводится в предупреждениях разбора
+		if ok && group != "" {
+			var accepted bool
+			nameGroup := group
+			if fullName, group, accepted = CheckNameGroup(base, fullName, group); !accepted {
+				merge.header.Warnings = append(merge.header.Warnings, fmt.Sprintf("%v:%d: группа %v из имени "+
+					"участника %v не найдена в базе групп, группа определена по базе групп", filepath.Base(path), line,
+					nameGroup, strings.TrimSpace(name)))
+			}
+		}
+
+		//Участник, ФИО которого указано латиницей или без отчества, получает ФИО из базы, если в ней есть ровно один
+		// подходящий человек
+		if ok && group == "" {
+			if baseName, found := base.FindName(fullName); found {
+				fullName = baseName
+			}
+		}
+
+		//Если член собрания является инициатором(преподавателем) по роли или по базе групп, то он пропускается.
+		// Преподаватели из файла преподавателей читаются, чтобы вывести время их присоединения отдельным списком
+		staff := IsStaffRole(role, locale) || (ok && base.IsTeacher(fullName))
+
+		//Запоминаем имя инициатора(преподавателя) для оглавления отчёта, в том числе преподавателя из файла
+		// преподавателей, строки которого читаются как строки участников
+		if staff && merge.header.Lecturer == "" {
+			merge.header.Lecturer = ParseLecturer(name, locale)
+		}
+		if !staff || (ok && roster.IsStaff(fullName)) {
+			if !ok {
+				//В случае, если имя участника собрания написано слитно - это ошибка регистрации на собрание, из данного
+				// пользователя нельзя получить корректной информации. Имя записывается в предупреждения разбора, чтобы
+				// студент не пропал из отчёта незаметно. Возвращение в начала цикла
+				merge.diagnostics.unparsed(path, line, name)
+				merge.unparsedName(path, line, name)
+				continue
+			}
+			source := "из имени"
+			if group != "" {
+				currentMember.Group = roster.CanonicalGroup(group)
+			}
+
+			//Разбираем время присоединения, выхода и продолжительность нахождения на собрании. Строка с некорректным
+			// временем пропускается
+			join, leave, duration, err := parseRowTimes(row, columns, locale, lessons)
+			if err != nil {
+				if err := merge.skip(path, line, err); err != nil {
+					return err
+				}
+				continue
+			}
+
+			//Время присоединения раньше начала собрания и выхода позже окончания приводится ко времени собрания
+			join, leave, drift := ClampToMeeting(join, leave, startTime, endTime, lessons)
+
+			//Если участник уже встречался в отчёте, суммируем продолжительность, берём самое раннее присоединение
+			// и учитываем переподключение
+			if index, ok := merge.indexes[fullName]; ok {
+				merge.durations[index] += duration
+				if join.Before(merge.joins[index]) {
+					merge.joins[index] = join
+				}
+				if leave.After(merge.leaves[index]) {
+					merge.leaves[index] = leave
+				}
+				merge.members[index].Reconnects++
+				merge.members[index].ClockDrift = merge.members[index].ClockDrift || drift
+				merge.diagnostics.row(path, line, name, merge.members[index], "", join, leave, duration, true)
+				continue
+			}
+
+			//Устанавливаем ФИО и почту участника
+			currentMember.FullName, currentMember.Email, currentMember.ClockDrift = fullName, strings.TrimSpace(email), drift
+
+			//Устройство участника определяется по первому присоединению к собранию
+			if columns.Platform != -1 && columns.Platform < len(row) {
+				currentMember.Platform = ParsePlatform(row[columns.Platform])
+			}
+
+			//Если группа у текущего участника собрания не установлена, устанавливаем
+			if currentMember.Group == "" {
+				//Устанавливаем группу у конкретного участника собрания с помощью вспомогательной функции SetGroup()
+				currentMember.Group = base.SetGroup(currentMember.FullName)
+				source = "из базы групп"
+				if currentMember.Group == roster.Guest {
+					source = "нет в базе групп"
+				}
+			}
+			merge.diagnostics.row(path, line, name, currentMember, source, join, leave, duration, false)
+
+			//Добавляем сформированного студента в список всех студентов
+			merge.indexes[fullName] = len(merge.members)
+			merge.members = append(merge.members, currentMember)
+			merge.joins = append(merge.joins, join)
+			merge.leaves = append(merge.leaves, leave)
+			merge.durations = append(merge.durations, duration)
+		} else {
+			merge.diagnostics.lecturer(path, line, name)
+		}
+	}
+
+	return nil
+}
+
+// parseRowTimes Вспомогательная функция, разбирающая время присоединения, время выхода и продолжительность нахождения
+// на собрании (в секундах) из строки участника
+func parseRowTimes(row []string, columns Columns, locale Locale, lessons schedule.Schedule) (time.Time, time.Time, int,
+	error) {
+	//Приводим время присоединения к виду русского отчёта
+	joinSource, err := locale.NormalizeTimestamp(row[columns.Join])
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	join, err := schedule.ParseTimestamp(joinSource, lessons)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("ошибка разбора времени присоединения: %w", err)
+	}
+
+	//Приводим время выхода к виду русского отчёта
+	leaveSource, err := locale.NormalizeTimestamp(row[columns.Leave])
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+	leave, err := schedule.ParseTimestamp(leaveSource, lessons)
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, fmt.Errorf("ошибка разбора времени выхода: %w", err)
+	}
+
+	//Получаем продолжительность нахождения на собрании в секундах. Если столбца продолжительности нет, она считается
+	// по времени присоединения и выхода
+	if columns.Duration == -1 {
+		return join, leave, max(int(leave.Sub(join).Seconds()), 0), nil
+	}
+	duration, err := schedule.ParseDuration(locale.NormalizeDuration(row[columns.Duration]))
+	if err != nil {
+		return time.Time{}, time.Time{}, 0, err
+	}
+
+	return join, leave, duration, nil
+}
+
+// unparsedName Вспомогательная функция, записывающая в предупреждения разбора имя участника, из которого не удалось
+// выделить ФИО. Имя переподключавшегося участника записывается один раз
+func (merge *meetingMerge) unparsedName(path string, line int, displayName string) {
+	displayName = strings.TrimSpace(displayName)
+	if merge.unparsed[displayName] {
+		return
+	}
+	if merge.unparsed == nil {
+		merge.unparsed = make(map[string]bool)
+	}
+	merge.unparsed[displayName] = true
+
+	merge.header.Warnings = append(merge.header.Warnings, fmt.Sprintf("%v:%d: из имени участника \"%v\" не удалось "+
+		"выделить ФИО, участник не добавлен в отчёт", filepath.Base(path), line, displayName))
+}
+
+// skip Вспомогательная функция, обрабатывающая некорректную строку участника: при строгом разборе возвращает ошибку,
+// иначе запоминает предупреждение разбора для отчёта, и строка пропускается
+func (merge *meetingMerge) skip(path string, line int, err error) error {
+	if StrictParsing {
+		return fmt.Errorf("%v, строка %d: %w", path, line, err)
+	}
+
+	merge.header.Warnings = append(merge.header.Warnings, fmt.Sprintf("%v:%d: %v", filepath.Base(path), line, err))
+	return nil
+}
@@ -0,0 +1,163 @@
+package teamsreport
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"mod.go/report"
+	"mod.go/roster"
+	"mod.go/schedule"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/*====================================================================================================================*/
+
+// update Флаг, перезаписывающий эталонные отчёты testdata/*.golden.* результатами разбора (go test -update)
+var update = flag.Bool("update", false, "перезаписать эталонные отчёты в testdata")
+
+// testLessons Расписание пар, по которому разбираются отчёты testdata: две пары с допусками и порогом опоздания 10 мин
+var testLessons = schedule.Schedule{
+	Lessons: []schedule.Lesson{
+		{Name: "Пара 1", Start: 8 * 3600, End: 9*3600 + 30*60, LateThreshold: 10 * 60},
+		{Name: "Пара 2", Start: 9*3600 + 40*60, End: 11*3600 + 10*60, LateThreshold: 10 * 60},
+	},
+	ToleranceBefore:     15 * 60,
+	ToleranceAfter:      15 * 60,
+	LateThreshold:       10 * 60,
+	EarlyExitThreshold:  10 * 60,
+	ClockDriftTolerance: 2 * 60,
+}
+
+// testBase База групп, с которой сопоставляются участники отчётов testdata
+var testBase = roster.Base{
+	"Лекторов Пётр Сергеевич":    roster.Teacher,
+	"Иванов Иван Иванович":       "МП-51",
+	"Петрова Мария Петровна":     "МП-51",
+	"Сидоров Алексей Викторович": "МП-51",
+	"Кузнецов Дмитрий Олегович":  "МП-51",
+}
+
+/*====================================================================================================================*/
+
+// TestReadCSVReportFrom Проверка разбора отчётов MS Teams на русском и английском языках в кодировках UTF-16LE с BOM
+// и UTF-8: отчёты одного собрания в разных кодировках дают одинаковый итоговый отчёт, совпадающий с эталонным
+func TestReadCSVReportFrom(t *testing.T) {
+	tests := []struct {
+		fixture, golden string
+		//Файл преподавателей, с которым разбирается отчёт (пустой - без файла преподавателей)
+		staff string
+	}{
+		{"ru_utf16.csv", "ru", ""},
+		{"ru_utf8.csv", "ru", ""},
+		{"en_utf16.csv", "en", ""},
+		{"en_utf8.csv", "en", ""},
+		//Новый отчёт с разделами: действия во время собрания не добавляются к продолжительности участников
+		{"sections_utf16.csv", "sections", ""},
+		//Инициатор собрания указан в файле преподавателей: он читается как участник, но остаётся преподавателем
+		// в оглавлении отчёта
+		{"ru_utf16.csv", "staff", "StaffBase.csv"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden+"/"+test.fixture, func(t *testing.T) {
+			if test.staff != "" {
+				staff, err := roster.LoadStaff(filepath.Join("testdata", test.staff))
+				if err != nil {
+					t.Fatal(err)
+				}
+				roster.Staff = staff
+				defer func() { roster.Staff = map[string]string{} }()
+			}
+
+			file, err := os.Open(filepath.Join("testdata", test.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			header, members, err := ReadCSVReportFrom(context.Background(), test.fixture, file, testLessons, testBase)
+			if err != nil {
+				t.Fatalf("ошибка разбора отчёта: %v", err)
+			}
+			//Преподаватели из файла преподавателей выводятся отдельным списком, как при обработке отчёта программой
+			if test.staff != "" {
+				members, header.Staff = roster.SeparateStaff(members)
+			}
+
+			var csvReport, jsonReport bytes.Buffer
+			if err := report.WriteReport(context.Background(), &csvReport, header, members, nil); err != nil {
+				t.Fatalf("ошибка записи отчёта .csv: %v", err)
+			}
+			if err := report.WriteJSON(&jsonReport, header, members, nil); err != nil {
+				t.Fatalf("ошибка записи отчёта в формате JSON: %v", err)
+			}
+
+			compareGolden(t, filepath.Join("testdata", test.golden+".golden.csv"), csvReport.Bytes())
+			compareGolden(t, filepath.Join("testdata", test.golden+".golden.json"), jsonReport.Bytes())
+		})
+	}
+}
+
+// TestReadCSVReportFromReconnects Проверка объединения строк переподключившегося участника: продолжительности
+// суммируются, переподключение учитывается, а строки раздела действий нового отчёта не учитываются повторно
+func TestReadCSVReportFromReconnects(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		duration   int
+		reconnects int
+	}{
+		{"ru_utf16.csv", 80 * 60, 1},
+		{"sections_utf16.csv", 80 * 60, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			file, err := os.Open(filepath.Join("testdata", test.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			_, members, err := ReadCSVReportFrom(context.Background(), test.fixture, file, testLessons, testBase)
+			if err != nil {
+				t.Fatalf("ошибка разбора отчёта: %v", err)
+			}
+			for _, member := range members {
+				if member.FullName != "Сидоров Алексей Викторович" {
+					continue
+				}
+				if member.Duration != test.duration || member.Reconnects != test.reconnects {
+					t.Errorf("продолжительность %d с и переподключений %d, ожидалось %d с и %d", member.Duration,
+						member.Reconnects, test.duration, test.reconnects)
+				}
+				return
+			}
+			t.Errorf("участник Сидоров Алексей Викторович не найден в отчёте")
+		})
+	}
+}
+
+/*====================================================================================================================*/
+
+// compareGolden Вспомогательная функция, сравнивающая результат с эталонным файлом (или перезаписывающая эталонный
+// файл с флагом -update)
+func compareGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ошибка чтения эталонного файла (для создания запустите go test -update): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("результат отличается от эталонного файла %v:\n%s", path, got)
+	}
+}
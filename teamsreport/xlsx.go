@@ -0,0 +1,403 @@
+package teamsreport
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mod.go/schedule"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// xlsxSheet Структура листа книги .xlsx: название и строки значений ячеек
+type xlsxSheet struct {
+	Name string
+	Rows [][]string
+}
+
+// Названия строк листа сводки и столбцов листа участников отчёта .xlsx новых клиентов MS Teams (без учёта регистра)
+var (
+	xlsxTitleNames    = []string{"title", "meeting title", "название", "название собрания"}
+	xlsxStartNames    = []string{"start time", "время начала", "время начала собрания"}
+	xlsxEndNames      = []string{"end time", "время окончания", "время окончания собрания"}
+	xlsxNameColumns   = []string{"name", "full name", "имя", "полное имя"}
+	xlsxJoinColumns   = []string{"first join", "join time", "первое присоединение", "время присоединения"}
+	xlsxLeaveColumns  = []string{"last leave", "leave time", "последний выход", "время выхода"}
+	xlsxTimeColumns   = []string{"in-meeting duration", "duration", "продолжительность", "длительность"}
+	xlsxEmailColumns  = []string{"email", "адрес электронной почты", "электронная почта"}
+	xlsxRoleColumns   = []string{"role", "роль"}
+	xlsxUPNColumns    = []string{"participant id (upn)", "идентификатор участника (upn)"}
+	xlsxSummarySheets = []string{"summary", "сводка"}
+	xlsxMemberSheets  = []string{"participants", "участники"}
+)
+
+// xlsxTimeLayouts Форматы даты и времени, в которых время может быть записано текстом в отчёте .xlsx
+var xlsxTimeLayouts = []string{"02.01.2006, 15:04:05", "2.1.2006, 15:04:05", "02.01.06, 15:04:05",
+	"1/2/2006, 3:04:05 PM", "1/2/06, 3:04:05 PM", "1/2/2006, 15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+
+/*====================================================================================================================*/
+
+// IsReportFile Функция, проверяющая по расширению, является ли файл отчётом MS Teams: .csv или .xlsx (отчёт о
+// посещаемости, загруженный из новых клиентов MS Teams)
+func IsReportFile(name string) bool {
+	extension := strings.ToLower(filepath.Ext(name))
+
+	return extension == ".csv" || extension == ".xlsx"
+}
+
+// convertXLSXReport Функция, переводящая отчёт о посещаемости .xlsx новых клиентов MS Teams (листы "Сводка",
+// "Участники" и "Действия во время собрания") в строки отчёта .csv того же вида, что и отчёт, загруженный из MS Teams
+// в формате .csv (разделитель - табуляция, кодировка UTF-8), чтобы разобрать его тем же путём
+func convertXLSXReport(source io.ReaderAt, size int64) ([]byte, error) {
+	sheets, err := readWorkbook(source, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(sheets) < 2 {
+		return nil, fmt.Errorf("в отчёте .xlsx должны быть листы сводки и участников собрания")
+	}
+
+	//Листы сводки и участников находятся по названию, а если названия другие - берутся первые два листа
+	summary, members := sheets[0], sheets[1]
+	for _, sheet := range sheets {
+		switch {
+		case matchesName(sheet.Name, xlsxSummarySheets):
+			summary = sheet
+		case matchesName(sheet.Name, xlsxMemberSheets):
+			members = sheet
+		}
+	}
+
+	//Название, время начала и окончания собрания указаны в строках листа сводки: название - в первой ячейке,
+	// значение - во второй
+	var title, start, end string
+	for _, row := range summary.Rows {
+		if len(row) < 2 {
+			continue
+		}
+		switch {
+		case matchesName(row[0], xlsxTitleNames):
+			title = row[1]
+		case matchesName(row[0], xlsxStartNames):
+			if start, err = xlsxTimestamp(row[1]); err != nil {
+				return nil, err
+			}
+		case matchesName(row[0], xlsxEndNames):
+			if end, err = xlsxTimestamp(row[1]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if start == "" {
+		return nil, fmt.Errorf("в отчёте .xlsx не указано время начала собрания")
+	}
+	duration := ""
+	if startTime, err := time.Parse("02.01.2006, 15:04:05", start); err == nil && end != "" {
+		if endTime, err := time.Parse("02.01.2006, 15:04:05", end); err == nil && endTime.After(startTime) {
+			duration = schedule.FormatDuration(int(endTime.Sub(startTime).Seconds()))
+		}
+	}
+
+	//Находим строку "шапки" листа участников (перед ней может быть строка с названием раздела) и столбцы в ней
+	headerIndex := -1
+	var columns map[string]int
+	for i, row := range members.Rows {
+		columns = map[string]int{}
+		for j, cell := range row {
+			for key, names := range map[string][]string{"name": xlsxNameColumns, "join": xlsxJoinColumns,
+				"leave": xlsxLeaveColumns, "duration": xlsxTimeColumns, "email": xlsxEmailColumns,
+				"role": xlsxRoleColumns, "upn": xlsxUPNColumns} {
+				if _, ok := columns[key]; !ok && matchesName(cell, names) {
+					columns[key] = j
+				}
+			}
+		}
+		if _, ok := columns["name"]; ok {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex == -1 {
+		return nil, fmt.Errorf("в отчёте .xlsx не найдена \"шапка\" таблицы участников")
+	}
+	cell := func(row []string, key string) string {
+		if index, ok := columns[key]; ok && index < len(row) {
+			return strings.TrimSpace(row[index])
+		}
+		return ""
+	}
+
+	//Строки участников переводятся в вид строк отчёта .csv: время - в вид русского отчёта, роль - в роль русского
+	// отчёта. Пустая строка или строка без времени (название следующего раздела) завершает таблицу участников
+	var rows [][]string
+	for _, row := range members.Rows[headerIndex+1:] {
+		name := cell(row, "name")
+		if name == "" || cell(row, "join") == "" && cell(row, "leave") == "" {
+			break
+		}
+		join, err := xlsxTimestamp(cell(row, "join"))
+		if err != nil {
+			return nil, err
+		}
+		leave, err := xlsxTimestamp(cell(row, "leave"))
+		if err != nil {
+			return nil, err
+		}
+		seconds, err := xlsxDuration(cell(row, "duration"))
+		if err != nil {
+			return nil, err
+		}
+		role := English.NormalizeRole(Russian.NormalizeRole(cell(row, "role")))
+
+		rows = append(rows, []string{name, join, leave, schedule.FormatDuration(seconds), cell(row, "email"), role,
+			cell(row, "upn")})
+	}
+
+	//Оглавление повторяет первые 8 непустых строк отчёта MS Teams в формате .csv
+	header := [][]string{
+		{Russian.SummaryTitle},
+		{"Общее число участников", strconv.Itoa(len(rows))},
+		{"Название собрания", title},
+		{"Время начала собрания", start},
+		{"Время окончания собрания", end},
+		{"Идентификатор собрания", ""},
+		{"Продолжительность собрания", duration},
+		{Russian.FullNameColumn, "Время присоединения", "Время выхода", "Продолжительность", "Адрес электронной почты",
+			"Роль", "Идентификатор участника (UPN)"},
+	}
+
+	var text bytes.Buffer
+	writer := csv.NewWriter(&text)
+	writer.Comma = '\t'
+	if err := writer.WriteAll(append(header, rows...)); err != nil {
+		return nil, fmt.Errorf("ошибка перевода отчёта .xlsx: %w", err)
+	}
+
+	return text.Bytes(), nil
+}
+
+// matchesName Вспомогательная функция, проверяющая, совпадает ли значение ячейки (без учёта регистра, пробелов и
+// номера раздела вида "1. ") с одним из названий
+func matchesName(value string, names []string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if index := strings.Index(value, ". "); index > 0 {
+		if _, err := strconv.Atoi(value[:index]); err == nil {
+			value = value[index+2:]
+		}
+	}
+
+	for _, name := range names {
+		if value == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// xlsxTimestamp Вспомогательная функция, приводящая время из ячейки отчёта .xlsx (число дней с 30.12.1899 или
+// текст) к виду русского отчёта ("20.04.2022, 09:41:02"). Пустое время остаётся пустым
+func xlsxTimestamp(source string) (string, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return "", nil
+	}
+
+	//Время, записанное датой Excel: целая часть - дни, дробная - доля суток
+	if days, err := strconv.ParseFloat(source, 64); err == nil {
+		seconds := math.Round(days * 24 * 60 * 60)
+		moment := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).Add(time.Duration(seconds) * time.Second)
+		return moment.Format("02.01.2006, 15:04:05"), nil
+	}
+
+	for _, layout := range xlsxTimeLayouts {
+		if moment, err := time.Parse(layout, source); err == nil {
+			return moment.Format("02.01.2006, 15:04:05"), nil
+		}
+	}
+
+	return "", fmt.Errorf("ошибка разбора даты и времени \"%v\" в отчёте .xlsx", source)
+}
+
+// xlsxDuration Вспомогательная функция, переводящая продолжительность из ячейки отчёта .xlsx (доля суток или текст
+// вида "1h 27m 38s" или "1 ч 27 мин 38 с") в секунды
+func xlsxDuration(source string) (int, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return 0, nil
+	}
+	if days, err := strconv.ParseFloat(source, 64); err == nil {
+		return int(math.Round(days * 24 * 60 * 60)), nil
+	}
+
+	//Количество секунд в каждой единице измерения русского отчёта, единицы английского отчёта приводятся к ним
+	units := map[string]int{"ч": 3600, "мин": 60, "с": 1}
+
+	seconds := 0
+	for _, part := range durationPart.FindAllStringSubmatch(source, -1) {
+		unit, ok := English.DurationUnits[part[2]]
+		if !ok {
+			unit = part[2]
+		}
+		if _, ok := units[unit]; !ok {
+			return 0, fmt.Errorf("некорректный формат продолжительности \"%v\" в отчёте .xlsx", source)
+		}
+		value, _ := strconv.Atoi(part[1])
+		seconds += value * units[unit]
+	}
+
+	return seconds, nil
+}
+
+/*====================================================================================================================*/
+
+// readWorkbook Функция, считывающая листы книги .xlsx в порядке книги: значения ячеек переводятся в строки (числа и
+// даты остаются числами в виде текста)
+func readWorkbook(source io.ReaderAt, size int64) ([]xlsxSheet, error) {
+	archive, err := zip.NewReader(source, size)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия отчёта .xlsx: %w", err)
+	}
+
+	//Листы книги и ссылки на их файлы в архиве
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := readXLSXPart(archive, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	var relationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readXLSXPart(archive, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string)
+	for _, item := range relationships.Items {
+		target := strings.TrimPrefix(item.Target, "/")
+		if !strings.HasPrefix(target, "xl/") {
+			target = path.Join("xl", target)
+		}
+		targets[item.ID] = target
+	}
+
+	//Общие строки книги, на которые ссылаются текстовые ячейки листов
+	var sharedStrings struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	err = readXLSXPart(archive, "xl/sharedStrings.xml", &sharedStrings)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var sheets []xlsxSheet
+	for _, workbookSheet := range workbook.Sheets {
+		var data struct {
+			Rows []struct {
+				Cells []struct {
+					Reference string `xml:"r,attr"`
+					Type      string `xml:"t,attr"`
+					Value     string `xml:"v"`
+					Inline    string `xml:"is>t"`
+				} `xml:"c"`
+			} `xml:"sheetData>row"`
+		}
+		if err := readXLSXPart(archive, targets[workbookSheet.ID], &data); err != nil {
+			return nil, err
+		}
+
+		sheet := xlsxSheet{Name: workbookSheet.Name}
+		for _, dataRow := range data.Rows {
+			var row []string
+			for i, cell := range dataRow.Cells {
+				//Ячейка без ссылки следует за предыдущей
+				column := i
+				if cell.Reference != "" {
+					column = xlsxColumn(cell.Reference)
+				}
+				for len(row) <= column {
+					row = append(row, "")
+				}
+
+				switch cell.Type {
+				case "s":
+					index, err := strconv.Atoi(cell.Value)
+					if err != nil || index >= len(sharedStrings.Items) {
+						return nil, fmt.Errorf("некорректная ссылка на строку в ячейке %v отчёта .xlsx", cell.Reference)
+					}
+					item := sharedStrings.Items[index]
+					row[column] = item.Text
+					for _, run := range item.Runs {
+						row[column] += run.Text
+					}
+				case "inlineStr":
+					row[column] = cell.Inline
+				default:
+					row[column] = cell.Value
+				}
+			}
+			sheet.Rows = append(sheet.Rows, row)
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	return sheets, nil
+}
+
+// readXLSXPart Вспомогательная функция, разбирающая XML файл из архива .xlsx. Если файла нет, возвращается ошибка
+// os.ErrNotExist
+func readXLSXPart(archive *zip.Reader, name string, out interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("ошибка чтения %v из отчёта .xlsx: %w", name, err)
+		}
+		defer reader.Close()
+
+		if err := xml.NewDecoder(reader).Decode(out); err != nil {
+			return fmt.Errorf("ошибка разбора %v из отчёта .xlsx: %w", name, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("в отчёте .xlsx не найден %v: %w", name, os.ErrNotExist)
+}
+
+// xlsxColumn Вспомогательная функция, возвращающая номер столбца (с нуля) по ссылке на ячейку вида "B12"
+func xlsxColumn(reference string) int {
+	column := 0
+	for _, letter := range reference {
+		if letter < 'A' || letter > 'Z' {
+			break
+		}
+		column = column*26 + int(letter-'A') + 1
+	}
+
+	return column - 1
+}
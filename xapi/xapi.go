@@ -0,0 +1,261 @@
+// Package xapi Пакет выгрузки посещаемости собраний в хранилище учебных записей (LRS) в виде утверждений xAPI, чтобы
+// платформа учебной аналитики университета получала посещаемость MS Teams вместе с другими учебными событиями
+package xapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mod.go/i18n"
+	"mod.go/report"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+/*====================================================================================================================*/
+
+// Configuration Структура настроек выгрузки в хранилище учебных записей
+type Configuration struct {
+	//Включена ли выгрузка утверждений xAPI
+	Enabled bool
+	//Адрес xAPI хранилища учебных записей (без statements в конце)
+	Endpoint string
+	//Ключ и секрет хранилища для авторизации Basic
+	Username, Password string
+	//Адрес сайта университета, в пространстве которого указываются учётные записи студентов и собрания
+	HomePage string
+}
+
+// Адреса глаголов xAPI: присутствие на собрании и пропуск собрания
+const (
+	VerbAttended = "http://adlnet.gov/expapi/verbs/attended"
+	VerbSkipped  = "http://id.tincanapi.com/verb/skipped"
+)
+
+// Version Версия спецификации xAPI, указываемая в запросах к хранилищу
+const Version = "1.0.3"
+
+// batchSize Количество утверждений в одном запросе к хранилищу
+const batchSize = 500
+
+// statement Структура утверждения xAPI
+type statement struct {
+	ID        string           `json:"id"`
+	Actor     agent            `json:"actor"`
+	Verb      verb             `json:"verb"`
+	Object    activity         `json:"object"`
+	Result    result           `json:"result"`
+	Context   statementContext `json:"context"`
+	Timestamp string           `json:"timestamp"`
+}
+
+// agent Структура участника утверждения: студента или преподавателя
+type agent struct {
+	ObjectType string   `json:"objectType"`
+	Name       string   `json:"name,omitempty"`
+	Mbox       string   `json:"mbox,omitempty"`
+	Account    *account `json:"account,omitempty"`
+}
+
+// account Структура учётной записи участника в пространстве сайта университета
+type account struct {
+	HomePage string `json:"homePage"`
+	Name     string `json:"name"`
+}
+
+// verb Структура глагола утверждения
+type verb struct {
+	ID      string            `json:"id"`
+	Display map[string]string `json:"display"`
+}
+
+// activity Структура объекта утверждения - собрания (пары)
+type activity struct {
+	ObjectType string             `json:"objectType"`
+	ID         string             `json:"id"`
+	Definition activityDefinition `json:"definition"`
+}
+
+// activityDefinition Структура описания собрания
+type activityDefinition struct {
+	Name map[string]string `json:"name"`
+	Type string            `json:"type"`
+}
+
+// result Структура результата утверждения: полное ли присутствие, продолжительность и отметки отчёта
+type result struct {
+	Completion bool                   `json:"completion"`
+	Duration   string                 `json:"duration"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+// statementContext Структура контекста утверждения: преподаватель, платформа, язык и группа студента
+type statementContext struct {
+	Instructor *agent                 `json:"instructor,omitempty"`
+	Platform   string                 `json:"platform"`
+	Language   string                 `json:"language"`
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+/*====================================================================================================================*/
+
+// Export Функция, выгружающая в хранилище учебных записей по одному утверждению xAPI на каждого студента собрания:
+// "attended" для присутствовавших и "skipped" для отсутствовавших. Идентификаторы утверждений вычисляются по отчёту и
+// студенту, поэтому повторная выгрузка того же отчёта не создаёт в хранилище дубликатов
+func Export(ctx context.Context, settings Configuration, header report.Header, members []report.Member) error {
+	statements, err := buildStatements(settings, header, members)
+	if err != nil {
+		return err
+	}
+
+	for from := 0; from < len(statements); from += batchSize {
+		to := from + batchSize
+		if to > len(statements) {
+			to = len(statements)
+		}
+		if err := post(ctx, settings, statements[from:to]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildStatements Функция, формирующая утверждения xAPI о посещаемости студентов собрания. Пустые участники (инициатор
+// собрания) пропускаются
+func buildStatements(settings Configuration, header report.Header, members []report.Member) ([]statement, error) {
+	date, err := time.ParseInLocation("02.01.2006", header.Date, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения даты собрания для xAPI: %w", err)
+	}
+
+	homePage := strings.TrimSuffix(settings.HomePage, "/")
+	language := "ru-RU"
+	if i18n.Language == "en" {
+		language = "en-US"
+	}
+
+	//Собрание определяется названием, датой и парой
+	object := activity{
+		ObjectType: "Activity",
+		ID: homePage + "/attendance/" + url.PathEscape(header.Title) + "/" + date.Format("2006-01-02") + "/" +
+			url.PathEscape(header.LessonNumber),
+		Definition: activityDefinition{
+			Name: map[string]string{language: header.Title + ", " + header.LessonLabel()},
+			Type: "http://adlnet.gov/expapi/activities/meeting",
+		},
+	}
+	var instructor *agent
+	if header.Lecturer != "" {
+		instructor = &agent{ObjectType: "Agent", Name: header.Lecturer,
+			Account: &account{HomePage: homePage, Name: header.Lecturer}}
+	}
+
+	var statements []statement
+	for _, member := range members {
+		if member.FullName == "" {
+			continue
+		}
+
+		//Студент определяется адресом электронной почты из отчёта или учётной записью с идентификатором студента
+		actor := agent{ObjectType: "Agent", Name: member.FullName}
+		if member.Email != "" {
+			actor.Mbox = "mailto:" + strings.ToLower(member.Email)
+		} else {
+			name := member.ID
+			if name == "" {
+				name = member.FullName
+			}
+			actor.Account = &account{HomePage: homePage, Name: name}
+		}
+
+		current := statement{
+			ID:     statementID(header, member),
+			Actor:  actor,
+			Verb:   verb{ID: VerbAttended, Display: map[string]string{"en-US": "attended", "ru-RU": "посетил"}},
+			Object: object,
+			Result: result{
+				Completion: member.Presence == report.PresenceFull,
+				Duration:   fmt.Sprintf("PT%dS", member.Duration),
+				Extensions: map[string]interface{}{
+					homePage + "/xapi/extensions/presence":      member.PresenceLabel(),
+					homePage + "/xapi/extensions/late":          member.Delay == report.DelayLate,
+					homePage + "/xapi/extensions/delay-minutes": member.DelayMinutes,
+					homePage + "/xapi/extensions/early-exit":    member.EarlyExit.Label(),
+				},
+			},
+			Context: statementContext{
+				Instructor: instructor,
+				Platform:   "Microsoft Teams",
+				Language:   language,
+				Extensions: map[string]interface{}{
+					homePage + "/xapi/extensions/group":  i18n.T(member.Group),
+					homePage + "/xapi/extensions/lesson": header.LessonLabel(),
+				},
+			},
+			Timestamp: date.Format(time.RFC3339),
+		}
+		if member.Presence.IsAbsent() || member.Presence.IsExcused() {
+			current.Verb = verb{ID: VerbSkipped, Display: map[string]string{"en-US": "skipped", "ru-RU": "пропустил"}}
+		}
+		if !member.Join.IsZero() {
+			current.Timestamp = member.Join.Format(time.RFC3339)
+		}
+
+		statements = append(statements, current)
+	}
+
+	return statements, nil
+}
+
+// statementID Вспомогательная функция, возвращающая идентификатор утверждения в виде UUID, вычисленный по отчёту,
+// собранию и студенту
+func statementID(header report.Header, member report.Member) string {
+	sum := sha1.Sum([]byte(header.SourceHash + "\x00" + header.Title + "\x00" + header.Date + "\x00" +
+		header.LessonNumber + "\x00" + member.FullName))
+
+	//Версия 5 и вариант RFC 4122, как у UUID, вычисленных по имени
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// post Вспомогательная функция, отправляющая утверждения в хранилище учебных записей
+func post(ctx context.Context, settings Configuration, statements []statement) error {
+	body, err := json.Marshal(statements)
+	if err != nil {
+		return fmt.Errorf("ошибка формирования утверждений xAPI: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(settings.Endpoint, "/")+
+		"/statements", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("ошибка формирования запроса к хранилищу учебных записей: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Experience-API-Version", Version)
+	if settings.Username != "" {
+		request.SetBasicAuth(settings.Username, settings.Password)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("ошибка запроса к хранилищу учебных записей: %w", err)
+	}
+	defer response.Body.Close()
+
+	//Конфликт означает, что утверждения с такими идентификаторами уже сохранены при прежней выгрузке
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent &&
+		response.StatusCode != http.StatusConflict {
+		text, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("хранилище учебных записей вернуло ошибку %v: %s", response.Status, text)
+	}
+
+	return nil
+}